@@ -0,0 +1,103 @@
+package health
+
+// AggregationPolicy determines whether the healthcheck has failed as a whole
+// (see "Health.Aggregation"), given the states of the checks; the states may
+// be a subset of the checks (ie. "h.State(WithTags(...))"). The policy is
+// evaluated on every update of the states; it must be fast and must not
+// modify the states.
+type AggregationPolicy interface {
+	Failed(states map[string]State) bool
+}
+
+// AggregationPolicyFunc is an adapter to use a func as an
+// "AggregationPolicy".
+type AggregationPolicyFunc func(states map[string]State) bool
+
+// Failed calls f(states).
+func (f AggregationPolicyFunc) Failed(states map[string]State) bool {
+	return f(states)
+}
+
+var (
+	// AggregateAny fails the healthcheck if any critical check has failed;
+	// the default policy
+	AggregateAny AggregationPolicy = AggregationPolicyFunc(anyFatalFailure)
+
+	// AggregateAll only fails the healthcheck if all critical checks have
+	// failed, ie. if every critical check is a redundant path to the same
+	// dependency
+	AggregateAll AggregationPolicy = AggregationPolicyFunc(allFatalFailures)
+)
+
+// Quorum returns a policy that fails the healthcheck if fewer than "n" of the
+// given (redundant) checks can succeed, ie. 2 of 3 replicas: the group fails
+// once more than "len(checks) - n" of them have failed, regardless of whether
+// they are critical. Checks without a state (ie. not executed yet) are not
+// counted as failed. Critical checks outside of the group fail the
+// healthcheck as with "AggregateAny". "n" is clamped between 1 and the number
+// of (distinct) checks.
+func Quorum(n int, checks ...string) AggregationPolicy {
+	group := make(map[string]bool, len(checks))
+	for _, name := range checks {
+		group[name] = true
+	}
+
+	if n < 1 {
+		n = 1
+	}
+
+	if n > len(group) {
+		n = len(group)
+	}
+
+	tolerated := len(group) - n
+
+	return AggregationPolicyFunc(func(states map[string]State) bool {
+		failed := 0
+
+		for name, state := range states {
+			if !group[name] {
+				if state.isFatalFailure() {
+					return true
+				}
+
+				continue
+			}
+
+			if state.isFailure() && !state.Downgraded {
+				failed++
+			}
+		}
+
+		return failed > tolerated
+	})
+}
+
+// indicates whether all critical checks (and at least one) have failed
+func allFatalFailures(states map[string]State) bool {
+	critical := 0
+
+	for _, state := range states {
+		if !state.Fatal {
+			continue
+		}
+
+		if !state.isFatalFailure() {
+			return false
+		}
+
+		critical++
+	}
+
+	return critical > 0
+}
+
+// indicates whether the healthcheck has failed according to the aggregation
+// policy
+func (h *Health) aggregate(states map[string]State) bool {
+	if h.Aggregation == nil {
+		return anyFatalFailure(states)
+	}
+
+	return h.Aggregation.Failed(states)
+}
@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestAggregationPolicies(t *testing.T) {
+	RegisterTestingT(t)
+
+	ok := State{Status: "ok", Fatal: true}
+	failed := State{Status: "failed", Fatal: true}
+	nonFatal := State{Status: "failed"}
+	downgraded := State{Status: "failed", Fatal: true, Downgraded: true}
+
+	t.Run("Should fail if any critical check fails", func(t *testing.T) {
+		Expect(AggregateAny.Failed(map[string]State{"foo": ok, "bar": failed})).To(BeTrue())
+		Expect(AggregateAny.Failed(map[string]State{"foo": ok, "bar": nonFatal, "baz": downgraded})).To(BeFalse())
+	})
+
+	t.Run("Should fail if all critical checks fail", func(t *testing.T) {
+		Expect(AggregateAll.Failed(map[string]State{"foo": failed, "bar": failed, "baz": nonFatal})).To(BeTrue())
+		Expect(AggregateAll.Failed(map[string]State{"foo": failed, "bar": ok})).To(BeFalse())
+		Expect(AggregateAll.Failed(map[string]State{"foo": failed, "bar": downgraded})).To(BeFalse())
+		Expect(AggregateAll.Failed(map[string]State{"foo": nonFatal})).To(BeFalse())
+		Expect(AggregateAll.Failed(nil)).To(BeFalse())
+	})
+
+	t.Run("Should fail if fewer than a quorum of the group succeed", func(t *testing.T) {
+		policy := Quorum(2, "db-1", "db-2", "db-3")
+
+		Expect(policy.Failed(map[string]State{"db-1": failed, "db-2": ok, "db-3": ok})).To(BeFalse())
+		Expect(policy.Failed(map[string]State{"db-1": failed, "db-2": nonFatal, "db-3": ok})).To(BeTrue())
+		Expect(policy.Failed(map[string]State{"db-1": failed, "db-2": downgraded, "db-3": ok})).To(BeFalse())
+
+		// checks without a state are not counted as failed
+		Expect(policy.Failed(map[string]State{"db-1": failed})).To(BeFalse())
+
+		// critical checks outside of the group fail as usual
+		Expect(policy.Failed(map[string]State{"db-1": ok, "cache": failed})).To(BeTrue())
+		Expect(policy.Failed(map[string]State{"db-1": ok, "cache": nonFatal})).To(BeFalse())
+	})
+
+	t.Run("Should clamp the quorum", func(t *testing.T) {
+		Expect(Quorum(0, "foo", "bar").Failed(map[string]State{"foo": failed})).To(BeFalse())
+		Expect(Quorum(0, "foo", "bar").Failed(map[string]State{"foo": failed, "bar": failed})).To(BeTrue())
+		Expect(Quorum(5, "foo", "bar").Failed(map[string]State{"foo": failed})).To(BeTrue())
+
+		// without a group, only the other critical checks count
+		Expect(Quorum(1).Failed(map[string]State{"foo": nonFatal})).To(BeFalse())
+		Expect(Quorum(1).Failed(map[string]State{"foo": failed})).To(BeTrue())
+
+		// duplicate names are counted once
+		Expect(Quorum(2, "foo", "foo").Failed(map[string]State{"foo": ok})).To(BeFalse())
+		Expect(Quorum(2, "foo", "foo").Failed(map[string]State{"foo": failed})).To(BeTrue())
+	})
+}
+
+func TestAggregation(t *testing.T) {
+	RegisterTestingT(t)
+
+	failing := &fakes.FakeICheckable{}
+	failing.StatusReturns(nil, errors.New("things broke"))
+
+	setup := func(policy AggregationPolicy) *Health {
+		h := setupNewTestHealth()
+		h.Aggregation = policy
+
+		Expect(h.AddChecks([]*Config{
+			{Name: "db-1", Checker: failing, Interval: time.Hour, Fatal: true, Tags: []string{"db", "primary"}},
+			{Name: "db-2", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Fatal: true, Tags: []string{"db"}},
+			{Name: "db-3", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Fatal: true},
+		})).To(Succeed())
+
+		Expect(h.Start()).To(Succeed())
+		t.Cleanup(func() { h.Stop() })
+
+		Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(3))
+
+		return h
+	}
+
+	overallStatus := func(h *Health) string {
+		h.statesLock.Lock()
+		defer h.statesLock.Unlock()
+
+		return h.overallStatus()
+	}
+
+	t.Run("Should fail on any critical failure by default", func(t *testing.T) {
+		h := setup(nil)
+
+		_, failed, _ := h.State()
+		Expect(failed).To(BeTrue())
+		Expect(h.Failed()).To(BeTrue())
+		Expect(overallStatus(h)).To(Equal("failed"))
+	})
+
+	t.Run("Should degrade on tolerated critical failures", func(t *testing.T) {
+		h := setup(Quorum(2, "db-1", "db-2", "db-3"))
+
+		_, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+		Expect(h.Failed()).To(BeFalse())
+		Expect(overallStatus(h)).To(Equal("degraded"))
+
+		_, failed, err := h.RunAll(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed).To(BeFalse())
+	})
+
+	t.Run("Should apply the policy to the filtered states", func(t *testing.T) {
+		h := setup(AggregateAll)
+
+		_, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+
+		_, failed, _ = h.State(WithTags("db"))
+		Expect(failed).To(BeFalse())
+
+		states, failed, _ := h.State(WithTags("primary"))
+		Expect(states).To(HaveLen(1))
+		Expect(failed).To(BeTrue())
+	})
+}
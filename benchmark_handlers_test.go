@@ -0,0 +1,86 @@
+package health_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/handlers"
+)
+
+type benchmarkChecker struct{}
+
+func (benchmarkChecker) Status() (interface{}, error) {
+	return nil, nil
+}
+
+// starts a health instance with the given number of (executed) checks
+func startBenchmarkHealth(b *testing.B, checks int) *health.Health {
+	h := health.New()
+	h.DisableLogging()
+
+	for i := 0; i < checks; i++ {
+		h.AddCheck(&health.Config{
+			Name:     fmt.Sprintf("check-%d", i),
+			Checker:  benchmarkChecker{},
+			Interval: time.Hour,
+		})
+	}
+
+	if err := h.Start(); err != nil {
+		b.Fatal(err)
+	}
+
+	for {
+		if states, _, _ := h.State(); len(states) == checks {
+			return h
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func benchmarkHandler(b *testing.B, newHandler func(h health.IHealth) func(*httptest.ResponseRecorder)) {
+	h := startBenchmarkHealth(b, 100)
+	defer h.Stop()
+
+	serve := newHandler(h)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			serve(httptest.NewRecorder())
+		}
+	})
+}
+
+func BenchmarkJSONHandler(b *testing.B) {
+	benchmarkHandler(b, func(h health.IHealth) func(*httptest.ResponseRecorder) {
+		handler := handlers.NewJSONHandlerFunc(h, nil)
+		req := httptest.NewRequest("GET", "/healthcheck", nil)
+
+		return func(rw *httptest.ResponseRecorder) { handler(rw, req) }
+	})
+}
+
+func BenchmarkCachedJSONHandler(b *testing.B) {
+	benchmarkHandler(b, func(h health.IHealth) func(*httptest.ResponseRecorder) {
+		handler := handlers.NewCachedJSONHandlerFunc(h, nil)
+		req := httptest.NewRequest("GET", "/healthcheck", nil)
+
+		return func(rw *httptest.ResponseRecorder) { handler(rw, req) }
+	})
+}
+
+func BenchmarkBasicHandler(b *testing.B) {
+	benchmarkHandler(b, func(h health.IHealth) func(*httptest.ResponseRecorder) {
+		handler := handlers.NewBasicHandlerFunc(h)
+		req := httptest.NewRequest("GET", "/healthcheck", nil)
+
+		return func(rw *httptest.ResponseRecorder) { handler(rw, req) }
+	})
+}
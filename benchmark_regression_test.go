@@ -0,0 +1,63 @@
+//go:build benchmark
+// +build benchmark
+
+package health_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// TestBenchmarkRegressions protects the performance of the hot paths; the
+// thresholds are based on allocations (and relative timings) so that they do
+// not depend on the machine running the benchmarks.
+func TestBenchmarkRegressions(t *testing.T) {
+	RegisterTestingT(t)
+
+	thresholds := []struct {
+		name      string
+		bench     func(*testing.B)
+		maxAllocs int64
+	}{
+		// one allocation per (copied) state
+		{"State", health.BenchmarkState, 1100},
+		{"UpdateState", health.BenchmarkUpdateState, 4},
+		// the state of an execution is pooled
+		{"RunCheck", health.BenchmarkRunCheck, 10},
+		// the score is computed off the shared snapshot without copying it
+		{"ShedderAdmit", health.BenchmarkShedderAdmit, 2},
+		{"ResultHookDispatch", health.BenchmarkResultHookDispatch, 8},
+		{"StateListenerDispatch", health.BenchmarkStateListenerDispatch, 8},
+		{"JSONHandler", BenchmarkJSONHandler, 300},
+		{"CachedJSONHandler", BenchmarkCachedJSONHandler, 20},
+		{"BasicHandler", BenchmarkBasicHandler, 150},
+	}
+
+	for _, th := range thresholds {
+		th := th
+
+		t.Run(th.name, func(t *testing.T) {
+			result := testing.Benchmark(th.bench)
+			t.Logf("%v: %v %v", th.name, result.String(), result.MemString())
+
+			Expect(result.AllocsPerOp()).To(BeNumerically("<=", th.maxAllocs))
+		})
+	}
+
+	t.Run("The cached JSON handler should be considerably faster", func(t *testing.T) {
+		cached := testing.Benchmark(BenchmarkCachedJSONHandler)
+		uncached := testing.Benchmark(BenchmarkJSONHandler)
+
+		Expect(cached.NsPerOp() * 4).To(BeNumerically("<", uncached.NsPerOp()))
+	})
+
+	t.Run("Reading the overall status under concurrent writes should not copy the states", func(t *testing.T) {
+		result := testing.Benchmark(health.BenchmarkFailedConcurrentWrites)
+		state := testing.Benchmark(health.BenchmarkState)
+
+		Expect(result.NsPerOp() * 100).To(BeNumerically("<", state.NsPerOp()))
+	})
+}
@@ -0,0 +1,196 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// the number of checks of the benchmarked health instances
+const benchmarkChecks = 1000
+
+// creates a health instance with "benchmarkChecks" recorded states
+func setupBenchmarkHealth() *Health {
+	h := New()
+	h.DisableLogging()
+
+	for i := 0; i < benchmarkChecks; i++ {
+		h.safeUpdateState(&State{
+			Name:      fmt.Sprintf("check-%d", i),
+			Status:    "ok",
+			CheckTime: time.Now(),
+			Tags:      []string{fmt.Sprintf("group-%d", i%10)},
+		})
+	}
+
+	return h
+}
+
+// keeps updating the states until the returned function is called
+func startBenchmarkWriter(h *Health) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			h.safeUpdateState(&State{
+				Name:      fmt.Sprintf("check-%d", i%benchmarkChecks),
+				Status:    "ok",
+				CheckTime: time.Now(),
+			})
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func BenchmarkState(b *testing.B) {
+	h := setupBenchmarkHealth()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			h.State()
+		}
+	})
+}
+
+func BenchmarkStateConcurrentWrites(b *testing.B) {
+	h := setupBenchmarkHealth()
+
+	stop := startBenchmarkWriter(h)
+	defer stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			h.State()
+		}
+	})
+}
+
+func BenchmarkFailedConcurrentWrites(b *testing.B) {
+	h := setupBenchmarkHealth()
+
+	stop := startBenchmarkWriter(h)
+	defer stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			h.Failed()
+			h.IsHealthy("check-1")
+		}
+	})
+}
+
+func BenchmarkShedderAdmit(b *testing.B) {
+	h := setupBenchmarkHealth()
+	s := NewShedder(h, &ShedderConfig{StateOptions: []StateOption{WithTags("group-1")}})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Admit()
+		}
+	})
+}
+
+func BenchmarkUpdateState(b *testing.B) {
+	h := setupBenchmarkHealth()
+
+	state := &State{Name: "check-1", Status: "ok", CheckTime: time.Now()}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.safeUpdateState(state)
+	}
+}
+
+func BenchmarkRunCheck(b *testing.B) {
+	h := New()
+	h.DisableLogging()
+
+	checker := CheckerFunc(func(ctx context.Context) (interface{}, error) { return nil, nil })
+	if err := h.AddCheck(&Config{Name: "check", Checker: checker, Interval: time.Hour}); err != nil {
+		b.Fatal(err)
+	}
+
+	if err := h.Start(); err != nil {
+		b.Fatal(err)
+	}
+	defer h.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.RunCheck("check")
+	}
+}
+
+type benchmarkResultHook struct {
+	completed int64
+}
+
+func (b *benchmarkResultHook) CheckCompleted(state *State) {
+	atomic.AddInt64(&b.completed, 1)
+}
+
+func BenchmarkResultHookDispatch(b *testing.B) {
+	h := setupBenchmarkHealth()
+	hook := &benchmarkResultHook{}
+	h.ResultHooks = []IResultHook{hook, hook, hook}
+
+	state := &State{Name: "check-1", Status: "ok", CheckTime: time.Now()}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.handleResultHooks(state)
+	}
+}
+
+func BenchmarkStateListenerDispatch(b *testing.B) {
+	h := setupBenchmarkHealth()
+
+	var completed int64
+	h.StateListeners = []IStateListener{&StateListenerFuncs{
+		CheckCompleted: func(state *State) { atomic.AddInt64(&completed, 1) },
+	}}
+	h.startStateListeners()
+	defer h.stopStateListeners()
+
+	state := &State{Name: "check-1", Status: "ok", CheckTime: time.Now()}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.safeUpdateState(state)
+	}
+}
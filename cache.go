@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// validates the cache TTLs of the checks; lazy checks do not run on a timer,
+// so options that alter the timer are rejected
+func validateCacheTTLs(cfgs []*Config) error {
+	for _, c := range cfgs {
+		if c.CacheTTL <= 0 {
+			continue
+		}
+
+		if c.Schedule != "" || c.InitialDelay > 0 || c.AdaptiveInterval || c.Backoff || c.Jitter > 0 {
+			return fmt.Errorf("Check '%v' cannot combine a cache TTL with a schedule, initial delay, "+
+				"adaptive interval, backoff or jitter", c.Name)
+		}
+	}
+
+	return nil
+}
+
+// noopSchedule is the schedule of lazy checks, which never fires
+type noopSchedule struct{}
+
+func (noopSchedule) reset(d time.Duration) {}
+
+func (noopSchedule) stop() {}
+
+// returns the function that executes the lazy check unless its state is
+// younger than its TTL; concurrent calls share a single execution
+func (h *Health) newRefresh(r *runner) func() {
+	var lock sync.Mutex
+
+	return func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if state, ok := h.lookupState(r.cfg.Name); ok && h.clock().Now().Sub(state.CheckTime) < r.cfg.CacheTTL {
+			return
+		}
+
+		r.exec(context.Background())
+	}
+}
+
+// refreshes the expired states of the lazy checks ("Config.CacheTTL") that
+// are included according to the given options; the checks are executed
+// concurrently and waited for
+func (h *Health) refreshLazyChecks(opts []StateOption) {
+	// avoid locking the runners if there are no lazy checks
+	if atomic.LoadInt64(&h.lazyChecks) == 0 {
+		return
+	}
+
+	tags := newStateOptions(opts).tags
+
+	h.runnersLock.Lock()
+	runners := make([]*runner, 0)
+	for _, r := range h.runners {
+		if r.refresh != nil && (len(tags) == 0 || hasAnyTag(r.cfg.Tags, tags)) {
+			runners = append(runners, r)
+		}
+	}
+	h.runnersLock.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(len(runners))
+
+	for _, r := range runners {
+		go func(r *runner) {
+			defer wg.Done()
+			r.refresh()
+		}(r)
+	}
+
+	wg.Wait()
+}
+
+// refreshes the expired state of the lazy check with the given name (if any)
+func (h *Health) refreshLazyCheck(name string) {
+	if atomic.LoadInt64(&h.lazyChecks) == 0 {
+		return
+	}
+
+	h.runnersLock.Lock()
+	r, ok := h.runners[name]
+	h.runnersLock.Unlock()
+
+	if ok && r.refresh != nil {
+		r.refresh()
+	}
+}
@@ -0,0 +1,150 @@
+package health
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestCacheTTL(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should only execute a lazy check when its state is requested", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: checker, Interval: testCheckInterval, CacheTTL: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Consistently(checker.StatusCallCount, 50*time.Millisecond).Should(Equal(0))
+
+		states, failed, err := h.State()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed).To(BeFalse())
+		Expect(states).To(HaveKey("foo"))
+		Expect(states["foo"].Status).To(Equal("ok"))
+		Expect(checker.StatusCallCount()).To(Equal(1))
+
+		// served from the cache
+		h.State()
+		h.Failed()
+		h.Groups()
+		Expect(h.IsHealthy("foo")).To(BeTrue())
+		Expect(checker.StatusCallCount()).To(Equal(1))
+	})
+
+	t.Run("Should execute a lazy check again once its state has expired", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("something went wrong"))
+
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "foo", Checker: checker, CacheTTL: 20 * time.Millisecond, Fatal: true})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Failed()).To(BeTrue())
+		Expect(checker.StatusCallCount()).To(Equal(1))
+
+		checker.StatusReturns(nil, nil)
+		time.Sleep(30 * time.Millisecond)
+
+		Expect(h.Failed()).To(BeFalse())
+		Expect(checker.StatusCallCount()).To(Equal(2))
+	})
+
+	t.Run("Should share a single execution between concurrent requests", func(t *testing.T) {
+		slow := &slowChecker{delay: 20 * time.Millisecond, running: new(int64), maxRunning: new(int64)}
+		calls := &fakes.FakeICheckable{}
+
+		h := setupNewTestHealth()
+		h.AddChecks([]*Config{
+			{Name: "slow", Checker: slow, CacheTTL: time.Hour},
+			{Name: "foo", Checker: calls, CacheTTL: time.Hour},
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				states, _, _ := h.State()
+				Expect(states).To(HaveLen(2))
+			}()
+		}
+		wg.Wait()
+
+		Expect(calls.StatusCallCount()).To(Equal(1))
+		Expect(atomic.LoadInt64(slow.maxRunning)).To(Equal(int64(1)))
+	})
+
+	t.Run("Should only refresh the lazy checks that are requested", func(t *testing.T) {
+		db := &fakes.FakeICheckable{}
+		cache := &fakes.FakeICheckable{}
+
+		h := setupNewTestHealth()
+		h.AddChecks([]*Config{
+			{Name: "db", Checker: db, CacheTTL: time.Hour, Tags: []string{"readiness"}},
+			{Name: "cache", Checker: cache, CacheTTL: time.Hour},
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		states, _, _ := h.State(WithTags("readiness"))
+		Expect(states).To(HaveLen(1))
+		Expect(db.StatusCallCount()).To(Equal(1))
+		Expect(cache.StatusCallCount()).To(Equal(0))
+
+		Expect(h.IsHealthy("cache")).To(BeTrue())
+		Expect(cache.StatusCallCount()).To(Equal(1))
+	})
+
+	t.Run("Should stop refreshing removed checks", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "foo", Checker: checker, CacheTTL: time.Millisecond})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.RemoveCheck("foo")).To(Succeed())
+		Expect(atomic.LoadInt64(&h.lazyChecks)).To(BeZero())
+
+		states, _, _ := h.State()
+		Expect(states).To(BeEmpty())
+		Expect(checker.StatusCallCount()).To(Equal(0))
+	})
+
+	t.Run("Should error when combined with options of the timer", func(t *testing.T) {
+		cases := []*Config{
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, CacheTTL: time.Second, Schedule: "@hourly"},
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, CacheTTL: time.Second, InitialDelay: time.Second},
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, CacheTTL: time.Second, Backoff: true},
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, CacheTTL: time.Second, Jitter: 0.1},
+		}
+
+		for _, cfg := range cases {
+			h := setupNewTestHealth()
+			h.AddCheck(cfg)
+
+			err := h.Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Check 'foo' cannot combine a cache TTL"))
+		}
+	})
+}
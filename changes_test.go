@@ -0,0 +1,58 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestChanges(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should only return states that changed after the given time", func(t *testing.T) {
+		checker1 := &fakes.FakeICheckable{}
+		checker2 := newToggleChecker(nil)
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker1, Interval: testCheckInterval},
+			{Name: "bar", Checker: checker2, Interval: testCheckInterval},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		time.Sleep(15 * time.Millisecond)
+
+		// both checks were recorded for the first time
+		Expect(h.Changes(time.Time{})).To(HaveLen(2))
+
+		since := time.Now()
+		time.Sleep(15 * time.Millisecond)
+
+		// nothing changed even though the checks kept running
+		Expect(h.Changes(since)).To(BeEmpty())
+
+		checker2.SetErr(errors.New("things broke"))
+		time.Sleep(15 * time.Millisecond)
+
+		changes := h.Changes(since)
+		Expect(changes).To(HaveLen(1))
+		Expect(changes).To(HaveKey("bar"))
+		Expect(changes["bar"].Err).To(Equal("things broke"))
+	})
+
+	t.Run("Should carry the time of the last change", func(t *testing.T) {
+		h := setupNewTestHealth()
+		first := time.Now()
+
+		h.safeUpdateState(&State{Name: "foo", Status: "ok", CheckTime: first})
+		h.safeUpdateState(&State{Name: "foo", Status: "ok", CheckTime: first.Add(time.Second)})
+		Expect(h.states["foo"].ChangedAt).To(Equal(first))
+
+		h.safeUpdateState(&State{Name: "foo", Status: "ok", Warning: "slow", CheckTime: first.Add(2 * time.Second)})
+		Expect(h.states["foo"].ChangedAt).To(Equal(first.Add(2 * time.Second)))
+	})
+}
@@ -0,0 +1,29 @@
+package health
+
+import (
+	"context"
+)
+
+// CheckerFunc is an adapter to use an ordinary function as a checker, ie. to
+// register ad-hoc inline checks without writing a struct for every tiny probe:
+//
+//	h.AddCheck(&health.Config{
+//		Name: "queue-depth",
+//		Checker: health.CheckerFunc(func(ctx context.Context) (interface{}, error) {
+//			return nil, queue.Ping(ctx)
+//		}),
+//	})
+//
+// The context is canceled once the "Config.Timeout" of the check is exceeded.
+type CheckerFunc func(ctx context.Context) (interface{}, error)
+
+// Status calls f with a background context; it satisfies the "ICheckable"
+// interface.
+func (f CheckerFunc) Status() (interface{}, error) {
+	return f(context.Background())
+}
+
+// StatusContext calls f(ctx); it satisfies the "ICheckableContext" interface.
+func (f CheckerFunc) StatusContext(ctx context.Context) (interface{}, error) {
+	return f(ctx)
+}
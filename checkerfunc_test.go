@@ -0,0 +1,49 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCheckerFunc(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should return the result of the function", func(t *testing.T) {
+		checker := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			return "details", errors.New("something went wrong")
+		})
+
+		data, err := checker.Status()
+		Expect(data).To(Equal("details"))
+		Expect(err).To(MatchError("something went wrong"))
+	})
+
+	t.Run("Should cancel the context once the timeout is exceeded", func(t *testing.T) {
+		canceled := make(chan struct{})
+
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{
+			Name:     "foo",
+			Interval: time.Minute,
+			Timeout:  10 * time.Millisecond,
+			Checker: CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				<-ctx.Done()
+				close(canceled)
+				return nil, ctx.Err()
+			}),
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(canceled).Should(BeClosed())
+		Eventually(func() bool {
+			states, _, _ := h.State()
+			return states["foo"].TimedOut
+		}).Should(BeTrue())
+	})
+}
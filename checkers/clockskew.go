@@ -0,0 +1,192 @@
+package checkers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/InVisionApp/go-health/describe"
+)
+
+const (
+	defaultClockSkewMax = time.Duration(2) * time.Second
+)
+
+// ClockSkewSource is the signature for a function that returns the current
+// time according to a peer (ie. a time API).
+type ClockSkewSource func() (time.Time, error)
+
+// ClockSkewConfig is used for configuring a clock skew check. At least one of
+// "URLs" or "Sources" must be set.
+//
+// "URLs" is optional; peers whose `Date` response header is compared against
+// the local clock. Note that the `Date` header only has a resolution of one
+// second, so "MaxSkew" should be larger than that.
+//
+// "Sources" is optional; a map of named, arbitrary time sources.
+//
+// "MaxSkew" is optional and defaults to "2s"; the check fails if any peer is
+// skewed by more than that (in either direction).
+//
+// "Client" is optional; if undefined, a new client will be created using "Timeout".
+//
+// "Timeout" is optional and defaults to "DefaultTimeout".
+type ClockSkewConfig struct {
+	URLs    []*url.URL
+	Sources map[string]ClockSkewSource
+	MaxSkew time.Duration // Optional (default 2s)
+	Client  *http.Client  // Optional
+	Timeout time.Duration // Optional (default DefaultTimeout)
+}
+
+// ClockSkewDetails is returned as the details of a clock skew check; skews are
+// positive when the peer's clock is ahead of the local clock.
+type ClockSkewDetails struct {
+	Skews map[string]string `json:"skews"`
+}
+
+// ClockSkew implements the "ICheckable" interface.
+type ClockSkew struct {
+	Config *ClockSkewConfig
+
+	now func() time.Time
+}
+
+// NewClockSkew creates a new clock skew checker that can be used for
+// ".AddCheck(s)".
+func NewClockSkew(cfg *ClockSkewConfig) (*ClockSkew, error) {
+	if err := validateClockSkewConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate clock skew config: %w", err)
+	}
+
+	return &ClockSkew{
+		Config: cfg,
+		now:    time.Now,
+	}, nil
+}
+
+// Describe returns the type and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (c *ClockSkew) Describe() describe.Description {
+	return describe.Description{
+		Type:         "clock_skew",
+		Capabilities: []string{"clock_skew"},
+	}
+}
+
+// Status is used for comparing the local clock against the configured peers;
+// it satisfies the "ICheckable" interface.
+func (c *ClockSkew) Status() (interface{}, error) {
+	details := &ClockSkewDetails{
+		Skews: make(map[string]string, 0),
+	}
+
+	failures := []string{}
+
+	record := func(name string, skew time.Duration) {
+		details.Skews[name] = skew.String()
+
+		if skew > c.Config.MaxSkew || -skew > c.Config.MaxSkew {
+			failures = append(failures, fmt.Sprintf("'%v' (%v)", name, skew))
+		}
+	}
+
+	for _, u := range c.Config.URLs {
+		skew, err := c.skewFromDateHeader(u)
+		if err != nil {
+			return details, err
+		}
+
+		record(u.String(), skew)
+	}
+
+	for name, source := range c.Config.Sources {
+		skew, err := c.skewFromSource(source)
+		if err != nil {
+			return details, fmt.Errorf("Unable to fetch time from '%v': %v", name, err)
+		}
+
+		record(name, skew)
+	}
+
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return details, fmt.Errorf("Clock skew exceeds %v for %v", c.Config.MaxSkew, strings.Join(failures, ", "))
+	}
+
+	return details, nil
+}
+
+// compares against the midpoint of the request to compensate for latency
+func (c *ClockSkew) skewFromSource(source ClockSkewSource) (time.Duration, error) {
+	start := c.now()
+
+	peer, err := source()
+	if err != nil {
+		return 0, err
+	}
+
+	end := c.now()
+	local := start.Add(end.Sub(start) / 2)
+
+	return peer.Sub(local), nil
+}
+
+func (c *ClockSkew) skewFromDateHeader(u *url.URL) (time.Duration, error) {
+	skew, err := c.skewFromSource(func() (time.Time, error) {
+		resp, err := c.Config.Client.Head(u.String())
+		if err != nil {
+			return time.Time{}, err
+		}
+		resp.Body.Close()
+
+		date := resp.Header.Get("Date")
+		if date == "" {
+			return time.Time{}, fmt.Errorf("response did not contain a Date header")
+		}
+
+		return http.ParseTime(date)
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("Unable to fetch time from '%v': %v", u, err)
+	}
+
+	// the Date header is truncated to the second; compensate by assuming the
+	// peer was half way through that second
+	return skew + time.Duration(500)*time.Millisecond, nil
+}
+
+func validateClockSkewConfig(cfg *ClockSkewConfig) error {
+	v := &validation{}
+
+	if v.check(cfg == nil, "", "Main config cannot be nil") {
+		return v.err()
+	}
+
+	v.check(len(cfg.URLs) == 0 && len(cfg.Sources) == 0, "", "At minimum, either cfg.URLs or cfg.Sources must be set")
+
+	for i, u := range cfg.URLs {
+		v.check(u == nil, fmt.Sprintf("URLs[%v]", i), "URLs cannot contain a nil URL")
+	}
+
+	v.check(cfg.MaxSkew < 0, "MaxSkew", "MaxSkew cannot be negative")
+	v.check(cfg.Timeout < 0, "Timeout", "Timeout cannot be negative")
+
+	if cfg.MaxSkew == 0 {
+		cfg.MaxSkew = defaultClockSkewMax
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return v.err()
+}
@@ -0,0 +1,92 @@
+package checkers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewClockSkew(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		u, _ := url.Parse("http://example.com")
+		c, err := NewClockSkew(&ClockSkewConfig{URLs: []*url.URL{u}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Config.MaxSkew).To(Equal(defaultClockSkewMax))
+		Expect(c.Config.Client).ToNot(BeNil())
+	})
+
+	t.Run("Should error with a nil cfg", func(t *testing.T) {
+		_, err := NewClockSkew(nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("Should error without peers", func(t *testing.T) {
+		_, err := NewClockSkew(&ClockSkewConfig{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("either cfg.URLs or cfg.Sources must be set"))
+	})
+}
+
+func TestClockSkewStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path using a Date header", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		u, _ := url.Parse(server.URL)
+		c, err := NewClockSkew(&ClockSkewConfig{URLs: []*url.URL{u}})
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := c.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.(*ClockSkewDetails).Skews).To(HaveKey(server.URL))
+	})
+
+	t.Run("Should fail if a peer is skewed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("Date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		}))
+		defer server.Close()
+
+		u, _ := url.Parse(server.URL)
+		c, err := NewClockSkew(&ClockSkewConfig{URLs: []*url.URL{u}})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Clock skew exceeds 2s"))
+	})
+
+	t.Run("Should use custom sources", func(t *testing.T) {
+		c, err := NewClockSkew(&ClockSkewConfig{
+			Sources: map[string]ClockSkewSource{
+				"behind": func() (time.Time, error) { return time.Now().Add(-time.Minute), nil },
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("'behind'"))
+	})
+
+	t.Run("Should error if a source fails", func(t *testing.T) {
+		c, _ := NewClockSkew(&ClockSkewConfig{
+			Sources: map[string]ClockSkewSource{
+				"broken": func() (time.Time, error) { return time.Time{}, fmt.Errorf("boom") },
+			},
+		})
+
+		_, err := c.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to fetch time from 'broken': boom"))
+	})
+}
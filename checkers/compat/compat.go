@@ -0,0 +1,75 @@
+// Package compat wraps the check functions of other health check libraries
+// as go-health checkers, smoothing the migration onto this package: the
+// checks of heptiolabs/healthcheck, alexliesenfeld/health and
+// hellofresh/health-go can be registered with "h.AddCheck()" as they are.
+//
+// None of the libraries is imported; their check funcs are plain func types
+// that can be passed to the adapters directly.
+package compat
+
+import (
+	"context"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/describe"
+)
+
+// Checker is a wrapped check function; it implements "health.ICheckable",
+// "health.ICheckableContext" and "health.ICheckableDescriber".
+type Checker struct {
+	kind    string
+	check   func(ctx context.Context) error
+	degrade bool
+}
+
+// Heptio wraps a heptiolabs/healthcheck "Check" (ie.
+// "healthcheck.TCPDialCheck()"). The check is not cancelable; wrap it with
+// "healthcheck.Timeout()" or set "health.Config.Timeout".
+func Heptio(check func() error) *Checker {
+	return &Checker{
+		kind:  "heptio",
+		check: func(ctx context.Context) error { return check() },
+	}
+}
+
+// AlexLiesenfeld wraps the "Check" func of an alexliesenfeld/health "Check";
+// map its "Timeout" to "health.Config.Timeout" (the context passed to the
+// func is canceled once it is exceeded).
+func AlexLiesenfeld(check func(ctx context.Context) error) *Checker {
+	return &Checker{kind: "alexliesenfeld", check: check}
+}
+
+// HelloFresh wraps the "Check" func of a hellofresh/health-go "Config"; map
+// its "Timeout" to "health.Config.Timeout". With "skipOnErr" (as set in the
+// "Config"), errors are reported as degraded (see "health.DegradedError")
+// instead of failing the check, matching the "partially available" status of
+// health-go.
+func HelloFresh(check func(ctx context.Context) error, skipOnErr bool) *Checker {
+	return &Checker{kind: "hellofresh", check: check, degrade: skipOnErr}
+}
+
+// Status calls the wrapped check with a background context; it satisfies
+// the "health.ICheckable" interface.
+func (c *Checker) Status() (interface{}, error) {
+	return c.StatusContext(context.Background())
+}
+
+// StatusContext calls the wrapped check; it satisfies the
+// "health.ICheckableContext" interface.
+func (c *Checker) StatusContext(ctx context.Context) (interface{}, error) {
+	err := c.check(ctx)
+	if err != nil && c.degrade {
+		return nil, health.Degraded("%v", err)
+	}
+
+	return nil, err
+}
+
+// Describe returns the library the check function originates from (see
+// "health.ICheckableDescriber").
+func (c *Checker) Describe() describe.Description {
+	return describe.Description{
+		Type:         "compat",
+		Capabilities: []string{c.kind},
+	}
+}
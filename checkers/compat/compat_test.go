@@ -0,0 +1,89 @@
+package compat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// mirrors the check func types of the wrapped libraries
+type heptioCheck func() error
+
+type helloFreshCheckFunc func(ctx context.Context) error
+
+func TestHeptio(t *testing.T) {
+	RegisterTestingT(t)
+
+	var check heptioCheck = func() error { return errors.New("dial tcp: connection refused") }
+
+	_, err := Heptio(check).Status()
+	Expect(err).To(MatchError("dial tcp: connection refused"))
+
+	_, err = Heptio(func() error { return nil }).Status()
+	Expect(err).ToNot(HaveOccurred())
+}
+
+func TestAlexLiesenfeld(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should pass the context to the check", func(t *testing.T) {
+		c := AlexLiesenfeld(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := c.StatusContext(ctx)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+	})
+
+	t.Run("Should be cancelable by the timeout of the check", func(t *testing.T) {
+		h := health.New()
+		h.DisableLogging()
+		h.WaitOnStart = true
+
+		Expect(h.AddCheck(&health.Config{
+			Name: "slow",
+			Checker: AlexLiesenfeld(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+			Interval: time.Hour,
+			Timeout:  10 * time.Millisecond,
+			Fatal:    true,
+		})).To(Succeed())
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Failed()).To(BeTrue())
+	})
+}
+
+func TestHelloFresh(t *testing.T) {
+	RegisterTestingT(t)
+
+	var check helloFreshCheckFunc = func(ctx context.Context) error { return errors.New("replica lag") }
+
+	_, err := HelloFresh(check, false).Status()
+	Expect(err).To(MatchError("replica lag"))
+
+	_, err = HelloFresh(check, true).Status()
+
+	var degraded *health.DegradedError
+	Expect(errors.As(err, &degraded)).To(BeTrue())
+	Expect(err).To(MatchError("replica lag"))
+}
+
+func TestDescribe(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(Heptio(func() error { return nil }).Describe().Capabilities).To(Equal([]string{"heptio"}))
+}
@@ -0,0 +1,280 @@
+// Package composite contains a checker that wraps multiple checkers and
+// succeeds based on a policy: all of them must pass, any one of them must pass
+// or at least N of them must pass, ie. for "either the primary or the replica
+// DB is enough" setups.
+package composite
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/checkers"
+	"github.com/InVisionApp/go-health/describe"
+)
+
+// Policy determines how many members of a composite must pass.
+type Policy int
+
+const (
+	// PolicyAll requires all members to pass
+	PolicyAll Policy = iota
+
+	// PolicyAny requires at least one member to pass
+	PolicyAny
+
+	// PolicyAtLeastN requires at least "Config.N" members to pass
+	PolicyAtLeastN
+)
+
+// Config is used for configuring a composite check.
+type Config struct {
+	Members []*Member // Required
+	Policy  Policy    // Optional (default PolicyAll)
+	N       int       // Required with PolicyAtLeastN
+
+	// Degrade reports the composite as degraded (see "health.DegradedError")
+	// if the policy is satisfied but some members have failed, ie. while the
+	// primary DB is down but the replica is up
+	Degrade bool // Optional
+}
+
+// Member is a named checker that is part of a composite.
+type Member struct {
+	Name    string            // Required
+	Checker health.ICheckable // Required
+}
+
+// Details contains the results of all members.
+type Details struct {
+	Passed  int                      `json:"passed"`
+	Failed  int                      `json:"failed"`
+	Members map[string]*MemberResult `json:"members"`
+}
+
+// MemberResult is the result of a single member.
+type MemberResult struct {
+	Status  string      `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Composite implements the "ICheckable" (and "ICheckableContext") interface.
+type Composite struct {
+	Config *Config
+}
+
+// NewComposite creates a new composite checker; the members are executed
+// concurrently on every check.
+func NewComposite(cfg *Config) (*Composite, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate composite config: %w", err)
+	}
+
+	return &Composite{Config: cfg}, nil
+}
+
+// All returns a composite that passes if all members pass.
+func All(members ...*Member) (*Composite, error) {
+	return NewComposite(&Config{Members: members, Policy: PolicyAll})
+}
+
+// Any returns a composite that passes if at least one member passes.
+func Any(members ...*Member) (*Composite, error) {
+	return NewComposite(&Config{Members: members, Policy: PolicyAny})
+}
+
+// AtLeastN returns a composite that passes if at least n members pass.
+func AtLeastN(n int, members ...*Member) (*Composite, error) {
+	return NewComposite(&Config{Members: members, Policy: PolicyAtLeastN, N: n})
+}
+
+func validateConfig(cfg *Config) error {
+	errs := make([]*checkers.FieldError, 0)
+
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, &checkers.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if cfg == nil {
+		fail("", "Passed in config cannot be nil")
+		return &checkers.ValidationError{Errors: errs}
+	}
+
+	if len(cfg.Members) == 0 {
+		fail("Members", "At least one member must be set")
+	}
+
+	names := make(map[string]bool, len(cfg.Members))
+
+	for i, m := range cfg.Members {
+		field := fmt.Sprintf("Members[%d]", i)
+
+		if m == nil {
+			fail(field, "%v cannot be nil", field)
+			continue
+		}
+
+		if m.Name == "" {
+			fail(field+".Name", "%v.Name must be set", field)
+		} else if names[m.Name] {
+			fail(field+".Name", "Member '%v' is defined more than once", m.Name)
+		}
+		names[m.Name] = true
+
+		if m.Checker == nil {
+			fail(field+".Checker", "%v.Checker cannot be nil", field)
+		}
+	}
+
+	switch cfg.Policy {
+	case PolicyAll, PolicyAny:
+	case PolicyAtLeastN:
+		if cfg.N < 1 || cfg.N > len(cfg.Members) {
+			fail("N", "N must be between 1 and the number of members (%d)", len(cfg.Members))
+		}
+	default:
+		fail("Policy", "Unknown policy %d", cfg.Policy)
+	}
+
+	if len(errs) > 0 {
+		return &checkers.ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// returns the number of members that must pass
+func (c *Composite) required() int {
+	switch c.Config.Policy {
+	case PolicyAny:
+		return 1
+	case PolicyAtLeastN:
+		return c.Config.N
+	}
+
+	return len(c.Config.Members)
+}
+
+// Describe returns the type and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (c *Composite) Describe() describe.Description {
+	policy := "all"
+	switch c.Config.Policy {
+	case PolicyAny:
+		policy = "any"
+	case PolicyAtLeastN:
+		policy = fmt.Sprintf("at_least_%d", c.Config.N)
+	}
+
+	return describe.Description{
+		Type:         "composite",
+		Capabilities: []string{policy},
+	}
+}
+
+// Status executes all members and verifies that enough of them pass; it
+// satisfies the "ICheckable" interface.
+func (c *Composite) Status() (interface{}, error) {
+	return c.StatusContext(context.Background())
+}
+
+type memberResult struct {
+	name string
+	data interface{}
+	err  error
+}
+
+// StatusContext is like "Status()"; the context is passed to the members that
+// implement "health.ICheckableContext". Members that have not returned once
+// the context is done are recorded as failed.
+func (c *Composite) StatusContext(ctx context.Context) (interface{}, error) {
+	results := make(chan memberResult, len(c.Config.Members))
+
+	for _, m := range c.Config.Members {
+		go func(m *Member) {
+			r := memberResult{name: m.Name}
+			defer func() { results <- r }()
+
+			// a panicking member must not crash the process
+			defer func() {
+				if v := recover(); v != nil {
+					r.data, r.err = nil, &health.PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}()
+
+			if cc, ok := m.Checker.(health.ICheckableContext); ok {
+				r.data, r.err = cc.StatusContext(ctx)
+			} else {
+				r.data, r.err = m.Checker.Status()
+			}
+		}(m)
+	}
+
+	details := &Details{
+		Members: make(map[string]*MemberResult, len(c.Config.Members)),
+	}
+
+	collect := func(r memberResult) {
+		result := &MemberResult{Status: "ok", Details: r.data}
+
+		if r.err != nil {
+			result.Status = "failed"
+			result.Error = r.err.Error()
+			details.Failed++
+		} else {
+			details.Passed++
+		}
+
+		details.Members[r.name] = result
+	}
+
+wait:
+	for range c.Config.Members {
+		select {
+		case r := <-results:
+			collect(r)
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	// members that have not returned in time
+	for _, m := range c.Config.Members {
+		if _, ok := details.Members[m.Name]; !ok {
+			collect(memberResult{name: m.Name, err: ctx.Err()})
+		}
+	}
+
+	required := c.required()
+
+	if details.Passed < required {
+		return details, fmt.Errorf("Only %d of %d members passed (%d required): %v",
+			details.Passed, len(c.Config.Members), required, failures(details))
+	}
+
+	if details.Failed > 0 && c.Config.Degrade {
+		return details, health.Degraded("%d of %d members failed: %v",
+			details.Failed, len(c.Config.Members), failures(details))
+	}
+
+	return details, nil
+}
+
+// returns the errors of the failed members, sorted by name
+func failures(details *Details) string {
+	msgs := make([]string, 0, details.Failed)
+
+	for name, r := range details.Members {
+		if r.Status == "failed" {
+			msgs = append(msgs, fmt.Sprintf("%v: %v", name, r.Error))
+		}
+	}
+
+	sort.Strings(msgs)
+
+	return strings.Join(msgs, "; ")
+}
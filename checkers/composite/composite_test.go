@@ -0,0 +1,169 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/checkers"
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func member(name string, err error) *Member {
+	checker := &fakes.FakeICheckable{}
+	checker.StatusReturns(name+"-details", err)
+
+	return &Member{Name: name, Checker: checker}
+}
+
+func TestNewComposite(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error with an invalid config", func(t *testing.T) {
+		_, err := NewComposite(&Config{
+			Members: []*Member{{Name: "a"}, {Name: "a", Checker: &fakes.FakeICheckable{}}, nil},
+			Policy:  PolicyAtLeastN,
+			N:       4,
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to validate composite config: "))
+
+		var verr *checkers.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields()).To(Equal([]string{"Members[0].Checker", "Members[1].Name", "Members[2]", "N"}))
+	})
+
+	t.Run("Should error without members", func(t *testing.T) {
+		_, err := Any()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("At least one member must be set"))
+
+		_, err = NewComposite(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Passed in config cannot be nil"))
+	})
+}
+
+func TestCompositeStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	failing := errors.New("connection refused")
+
+	t.Run("All should require every member to pass", func(t *testing.T) {
+		c, err := All(member("primary", nil), member("replica", nil))
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := c.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(&Details{
+			Passed: 2,
+			Members: map[string]*MemberResult{
+				"primary": {Status: "ok", Details: "primary-details"},
+				"replica": {Status: "ok", Details: "replica-details"},
+			},
+		}))
+
+		c, err = All(member("primary", nil), member("replica", failing))
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err = c.Status()
+		Expect(err).To(MatchError("Only 1 of 2 members passed (2 required): replica: connection refused"))
+		Expect(data.(*Details).Members["replica"]).To(Equal(&MemberResult{
+			Status:  "failed",
+			Error:   "connection refused",
+			Details: "replica-details",
+		}))
+	})
+
+	t.Run("Any should require a single member to pass", func(t *testing.T) {
+		c, err := Any(member("primary", failing), member("replica", nil))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		c, err = Any(member("primary", failing), member("replica", failing))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).To(MatchError("Only 0 of 2 members passed (1 required): " +
+			"primary: connection refused; replica: connection refused"))
+	})
+
+	t.Run("AtLeastN should require N members to pass", func(t *testing.T) {
+		c, err := AtLeastN(2, member("a", nil), member("b", failing), member("c", nil))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		c, err = AtLeastN(2, member("a", nil), member("b", failing), member("c", failing))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("Should be degraded if some members have failed", func(t *testing.T) {
+		c, err := NewComposite(&Config{
+			Members: []*Member{member("primary", failing), member("replica", nil)},
+			Policy:  PolicyAny,
+			Degrade: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+
+		var degraded *health.DegradedError
+		Expect(errors.As(err, &degraded)).To(BeTrue())
+		Expect(err).To(MatchError("1 of 2 members failed: primary: connection refused"))
+	})
+
+	t.Run("Should fail members that do not return before the context is done", func(t *testing.T) {
+		slow := &Member{
+			Name: "slow",
+			Checker: health.CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				time.Sleep(time.Second)
+				return nil, nil
+			}),
+		}
+
+		c, err := Any(member("fast", nil), slow)
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		data, err := c.StatusContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.(*Details).Members["slow"].Error).To(Equal(context.DeadlineExceeded.Error()))
+	})
+
+	t.Run("Should fail members that panic", func(t *testing.T) {
+		panicking := &Member{
+			Name: "panicking",
+			Checker: health.CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				panic("nil map")
+			}),
+		}
+
+		c, err := Any(member("fast", nil), panicking)
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := c.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.(*Details).Members["panicking"].Error).To(Equal("Checker panicked: nil map"))
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	RegisterTestingT(t)
+
+	c, err := AtLeastN(1, member("a", nil))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(c.Describe().Capabilities).To(Equal([]string{"at_least_1"}))
+}
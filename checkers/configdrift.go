@@ -0,0 +1,199 @@
+package checkers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/InVisionApp/go-health/describe"
+)
+
+// ConfigSourceReader is the signature for a function that returns the raw
+// contents of a configuration source (a file, a remote KV entry, etc).
+type ConfigSourceReader func() ([]byte, error)
+
+// ConfigDriftConfig is used for configuring a configuration consistency check.
+// At least one of "Files" or "Sources" must be set.
+//
+// "Files" is optional; a list of config file paths to re-read on every check.
+//
+// "Sources" is optional; a map of named, arbitrary config sources.
+//
+// "Loaded" is optional; a function returning the hash (as computed by
+// "ConfigHash()") of the config the service currently has in memory, for the
+// given source name. If undefined, the hash of every source is captured when
+// the checker is created (ie. it assumes the config was loaded at startup)
+// and can be refreshed via "MarkLoaded()" after a reload.
+type ConfigDriftConfig struct {
+	Files   []string
+	Sources map[string]ConfigSourceReader
+	Loaded  func(source string) string
+}
+
+// ConfigDriftDetails is returned as the details of a config drift check.
+type ConfigDriftDetails struct {
+	Loaded  map[string]string `json:"loaded"`
+	Current map[string]string `json:"current"`
+	Drifted []string          `json:"drifted,omitempty"`
+}
+
+// ConfigDrift implements the "ICheckable" interface; it reports when the
+// config source(s) no longer match what has been loaded into memory, meaning
+// that a restart/reload is pending.
+type ConfigDrift struct {
+	Config *ConfigDriftConfig
+
+	sources map[string]ConfigSourceReader
+	loaded  map[string]string
+	mu      sync.Mutex
+}
+
+// NewConfigDrift creates a new config drift checker that can be used for
+// ".AddCheck(s)".
+func NewConfigDrift(cfg *ConfigDriftConfig) (*ConfigDrift, error) {
+	if err := validateConfigDriftConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate config drift config: %w", err)
+	}
+
+	sources := make(map[string]ConfigSourceReader, len(cfg.Sources)+len(cfg.Files))
+
+	for name, reader := range cfg.Sources {
+		sources[name] = reader
+	}
+
+	for _, path := range cfg.Files {
+		sources[path] = fileSourceReader(path)
+	}
+
+	c := &ConfigDrift{
+		Config:  cfg,
+		sources: sources,
+	}
+
+	if cfg.Loaded == nil {
+		if err := c.MarkLoaded(); err != nil {
+			return nil, fmt.Errorf("Unable to read initial config: %v", err)
+		}
+	}
+
+	return c, nil
+}
+
+// ConfigHash returns the hex encoded sha256 hash of the given config contents.
+func ConfigHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// MarkLoaded re-reads every config source and records its hash as the one
+// currently loaded in memory. Call it after the service has reloaded its
+// configuration. It is a noop if "ConfigDriftConfig.Loaded" is set.
+func (c *ConfigDrift) MarkLoaded() error {
+	if c.Config.Loaded != nil {
+		return nil
+	}
+
+	current, err := c.readAll()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loaded = current
+
+	return nil
+}
+
+// Describe returns the type and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (c *ConfigDrift) Describe() describe.Description {
+	return describe.Description{
+		Type:         "config_drift",
+		Capabilities: []string{"config_drift"},
+	}
+}
+
+// Status is used for comparing the config source(s) against the loaded
+// config; it satisfies the "ICheckable" interface.
+func (c *ConfigDrift) Status() (interface{}, error) {
+	current, err := c.readAll()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read config: %v", err)
+	}
+
+	details := &ConfigDriftDetails{
+		Loaded:  make(map[string]string, len(current)),
+		Current: current,
+	}
+
+	// the user supplied "Loaded" is called without holding the lock, since it
+	// may block or call back into the checker
+	if c.Config.Loaded != nil {
+		for name := range current {
+			details.Loaded[name] = c.Config.Loaded(name)
+		}
+	} else {
+		c.mu.Lock()
+		for name := range current {
+			details.Loaded[name] = c.loaded[name]
+		}
+		c.mu.Unlock()
+	}
+
+	for name, hash := range current {
+		if details.Loaded[name] != hash {
+			details.Drifted = append(details.Drifted, name)
+		}
+	}
+
+	if len(details.Drifted) > 0 {
+		sort.Strings(details.Drifted)
+		return details, fmt.Errorf("Config drift detected in '%v'; a restart/reload is pending",
+			strings.Join(details.Drifted, "', '"))
+	}
+
+	return details, nil
+}
+
+func (c *ConfigDrift) readAll() (map[string]string, error) {
+	hashes := make(map[string]string, len(c.sources))
+
+	for name, reader := range c.sources {
+		data, err := reader()
+		if err != nil {
+			return nil, fmt.Errorf("source '%v': %v", name, err)
+		}
+
+		hashes[name] = ConfigHash(data)
+	}
+
+	return hashes, nil
+}
+
+func fileSourceReader(path string) ConfigSourceReader {
+	return func() ([]byte, error) {
+		return ioutil.ReadFile(path)
+	}
+}
+
+func validateConfigDriftConfig(cfg *ConfigDriftConfig) error {
+	v := &validation{}
+
+	if v.check(cfg == nil, "", "Main config cannot be nil") {
+		return v.err()
+	}
+
+	v.check(len(cfg.Files) == 0 && len(cfg.Sources) == 0, "", "At minimum, either cfg.Files or cfg.Sources must be set")
+
+	for name, reader := range cfg.Sources {
+		v.check(reader == nil, fmt.Sprintf("Sources[%v]", name), "Reader for source '%v' cannot be nil", name)
+	}
+
+	return v.err()
+}
@@ -0,0 +1,132 @@
+package checkers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewConfigDrift(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		c, err := NewConfigDrift(&ConfigDriftConfig{
+			Sources: map[string]ConfigSourceReader{
+				"inline": func() ([]byte, error) { return []byte("foo"), nil },
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.loaded).To(HaveKeyWithValue("inline", ConfigHash([]byte("foo"))))
+	})
+
+	t.Run("Should error with a nil cfg", func(t *testing.T) {
+		_, err := NewConfigDrift(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+	})
+
+	t.Run("Should error without sources", func(t *testing.T) {
+		_, err := NewConfigDrift(&ConfigDriftConfig{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("either cfg.Files or cfg.Sources must be set"))
+	})
+
+	t.Run("Should error if initial config can't be read", func(t *testing.T) {
+		_, err := NewConfigDrift(&ConfigDriftConfig{Files: []string{"/does/not/exist"}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to read initial config"))
+	})
+}
+
+func TestConfigDriftStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should detect drift in a file and clear it after MarkLoaded", func(t *testing.T) {
+		f, err := ioutil.TempFile("", "go-health-config")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(f.Name())
+
+		Expect(ioutil.WriteFile(f.Name(), []byte("a: 1"), 0644)).To(Succeed())
+
+		c, err := NewConfigDrift(&ConfigDriftConfig{Files: []string{f.Name()}})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ioutil.WriteFile(f.Name(), []byte("a: 2"), 0644)).To(Succeed())
+
+		data, err := c.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Config drift detected"))
+		Expect(data.(*ConfigDriftDetails).Drifted).To(ConsistOf(f.Name()))
+
+		Expect(c.MarkLoaded()).To(Succeed())
+
+		_, err = c.Status()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("Should compare against a user supplied loaded hash", func(t *testing.T) {
+		c, err := NewConfigDrift(&ConfigDriftConfig{
+			Sources: map[string]ConfigSourceReader{
+				"inline": func() ([]byte, error) { return []byte("foo"), nil },
+			},
+			Loaded: func(source string) string { return ConfigHash([]byte("bar")) },
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("'inline'"))
+	})
+
+	t.Run("Should not hold the lock while calling the loaded hash func", func(t *testing.T) {
+		var c *ConfigDrift
+
+		c, err := NewConfigDrift(&ConfigDriftConfig{
+			Sources: map[string]ConfigSourceReader{
+				"inline": func() ([]byte, error) { return []byte("foo"), nil },
+			},
+			Loaded: func(source string) string {
+				// would deadlock if the lock was held
+				c.mu.Lock()
+				defer c.mu.Unlock()
+
+				return ConfigHash([]byte("foo"))
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := c.Status()
+			done <- err
+		}()
+
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	t.Run("Should error if a source can't be read", func(t *testing.T) {
+		fail := false
+		c, err := NewConfigDrift(&ConfigDriftConfig{
+			Sources: map[string]ConfigSourceReader{
+				"inline": func() ([]byte, error) {
+					if fail {
+						return nil, fmt.Errorf("unavailable")
+					}
+					return []byte("foo"), nil
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		fail = true
+		_, err = c.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to read config: source 'inline': unavailable"))
+	})
+}
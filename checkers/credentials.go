@@ -0,0 +1,71 @@
+package checkers
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+)
+
+// Credentials are the username and password (or short-lived token) used to
+// authenticate a connection.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialsProvider returns the current credentials of a dependency, so
+// that rotated passwords or short-lived tokens are picked up without
+// rebuilding the checker or restarting the service. It is consulted on every
+// check (Mongo) or for every new connection (Redis and SQL, see
+// "NewSQLConnector()"); implementations that fetch the credentials remotely
+// should cache them.
+type CredentialsProvider interface {
+	Credentials() (Credentials, error)
+}
+
+// CredentialsProviderFunc is an adapter to use a func as a
+// "CredentialsProvider".
+type CredentialsProviderFunc func() (Credentials, error)
+
+// Credentials calls f().
+func (f CredentialsProviderFunc) Credentials() (Credentials, error) {
+	return f()
+}
+
+// returns the current credentials of the provider
+func fetchCredentials(provider CredentialsProvider) (Credentials, error) {
+	creds, err := provider.Credentials()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("Unable to fetch credentials: %v", err)
+	}
+
+	return creds, nil
+}
+
+// NewSQLConnector returns a "driver.Connector" that opens every new connection
+// with the current credentials of the provider, formatted into a DSN by "dsn";
+// open the database of the SQL checker with "sql.OpenDB()" to pick up rotated
+// credentials. Connections that are already open keep their credentials until
+// they are closed (see "sql.DB.SetConnMaxLifetime()").
+func NewSQLConnector(drv driver.Driver, dsn func(creds Credentials) string, provider CredentialsProvider) driver.Connector {
+	return &sqlConnector{driver: drv, dsn: dsn, provider: provider}
+}
+
+type sqlConnector struct {
+	driver   driver.Driver
+	dsn      func(creds Credentials) string
+	provider CredentialsProvider
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	creds, err := fetchCredentials(c.provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.driver.Open(c.dsn(creds))
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,66 @@
+package checkers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestNewSQLConnector(t *testing.T) {
+	RegisterTestingT(t)
+
+	password := "first"
+	provider := CredentialsProviderFunc(func() (Credentials, error) {
+		if password == "" {
+			return Credentials{}, errors.New("vault is sealed")
+		}
+
+		return Credentials{Username: "health", Password: password}, nil
+	})
+
+	dsn := func(creds Credentials) string {
+		return "credentials_" + creds.Username + "_" + creds.Password
+	}
+
+	// sqlmock only accepts the DSNs of the mocks that have been created
+	first, _, err := sqlmock.NewWithDSN("credentials_health_first")
+	Expect(err).ToNot(HaveOccurred())
+	defer first.Close()
+
+	second, _, err := sqlmock.NewWithDSN("credentials_health_second")
+	Expect(err).ToNot(HaveOccurred())
+	defer second.Close()
+
+	connector := NewSQLConnector(first.Driver(), dsn, provider)
+	Expect(connector.Driver()).To(Equal(first.Driver()))
+
+	t.Run("Should open connections with the current credentials", func(t *testing.T) {
+		conn, err := connector.Connect(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close()
+
+		password = "second"
+
+		conn, err = connector.Connect(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close()
+
+		password = "third"
+
+		_, err = connector.Connect(context.Background())
+		Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("Should fail if the credentials cannot be fetched", func(t *testing.T) {
+		password = ""
+
+		db := sql.OpenDB(connector)
+		defer db.Close()
+
+		Expect(db.Ping()).To(MatchError("Unable to fetch credentials: vault is sealed"))
+	})
+}
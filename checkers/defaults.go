@@ -0,0 +1,11 @@
+package checkers
+
+import (
+	"time"
+)
+
+// DefaultTimeout is the timeout of the checkers that do not set one in their
+// config (ie. "HTTPConfig.Timeout" or "TLSConfig.Timeout"); it is resolved
+// when the checker is created. Note that "health.Config.Timeout" (or its
+// defaults) additionally limits the duration of the check as a whole.
+var DefaultTimeout = time.Duration(3) * time.Second
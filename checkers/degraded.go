@@ -0,0 +1,27 @@
+package checkers
+
+import (
+	"fmt"
+)
+
+// DegradedError is returned by a checker whose dependency is degraded but
+// still usable (ie. the usage is close to a limit); the check is reported as
+// "degraded" instead of "failed", like with "health.DegradedError".
+type DegradedError struct {
+	Reason string
+}
+
+// Error satisfies the "error" interface.
+func (e *DegradedError) Error() string {
+	return e.Reason
+}
+
+// Degraded marks the error as degraded for the health instance.
+func (e *DegradedError) Degraded() bool {
+	return true
+}
+
+// returns a "DegradedError" with the given (formatted) reason
+func degraded(format string, args ...interface{}) error {
+	return &DegradedError{Reason: fmt.Sprintf(format, args...)}
+}
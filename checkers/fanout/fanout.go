@@ -0,0 +1,341 @@
+// Package fanout contains a checker that discovers targets (ie. via DNS SRV,
+// the Consul catalog or a static list) and adds a child check per target to
+// the health instance, so that every replica or shard is reported
+// individually:
+//
+//	replicas, err := fanout.New(h, &fanout.Config{
+//		Name:    "replicas",
+//		Lister:  fanout.Static("db-1:5432", "db-2:5432"),
+//		Factory: func(target string) (health.ICheckable, error) {
+//			return checkers.NewReachableChecker(&checkers.ReachableConfig{URL: &url.URL{Host: target}})
+//		},
+//		Check: &health.Config{Interval: 10 * time.Second, Fatal: true},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	h.AddCheck(&health.Config{Name: "replicas", Checker: replicas, Interval: time.Minute})
+//
+// The targets are listed on every execution of the fan-out check; the child
+// checks ("replicas/db-1:5432") are added and removed as the targets change.
+package fanout
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/checkers"
+	"github.com/InVisionApp/go-health/describe"
+)
+
+// Lister returns the current targets, ie. "host:port" addresses.
+type Lister func(ctx context.Context) ([]string, error)
+
+// Factory creates the checker of a target.
+type Factory func(target string) (health.ICheckable, error)
+
+// Static returns a lister of a fixed set of targets.
+func Static(targets ...string) Lister {
+	return func(ctx context.Context) ([]string, error) {
+		return targets, nil
+	}
+}
+
+// Config is used for configuring a fan-out check.
+//
+// "Name" is _required_; the child checks are named "<Name>/<target>".
+//
+// "Lister" and "Factory" are _required_; the factory is only called for
+// targets that have not been checked before.
+//
+// "Check" is optional; the template of the child checks (ie. "Interval",
+// "Timeout", "Fatal" or "Tags"), whose "Name" and "Checker" are set per
+// target. Defaults to the default interval and timeout of the instance.
+type Config struct {
+	Name    string
+	Lister  Lister
+	Factory Factory
+	Check   *health.Config
+}
+
+// Details is returned as the details of a fan-out check.
+type Details struct {
+	// Targets are the targets that have child checks, sorted
+	Targets []string `json:"targets"`
+
+	// Errors contains the errors of the factory by target; the targets are
+	// retried on the next execution
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// FanOut implements the "ICheckable" (and "ICheckableContext") interface; it
+// fails if the targets cannot be listed or a checker cannot be created. The
+// child checks are left untouched in that case.
+type FanOut struct {
+	Config *Config
+
+	health *health.Health
+
+	lock     sync.Mutex
+	children map[string]*health.Config // by target
+}
+
+// New creates a new fan-out checker that adds the child checks to the health
+// instance; use it for ".AddCheck(s)" of the same instance.
+func New(h *health.Health, cfg *Config) (*FanOut, error) {
+	if err := validateConfig(h, cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate fan-out config: %w", err)
+	}
+
+	return &FanOut{Config: cfg, health: h, children: make(map[string]*health.Config)}, nil
+}
+
+// Targets returns the targets that have child checks, sorted.
+func (f *FanOut) Targets() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.targets()
+}
+
+// Describe returns the type and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (f *FanOut) Describe() describe.Description {
+	return describe.Description{
+		Type:         "fanout",
+		Capabilities: []string{"discovery"},
+	}
+}
+
+// Status lists the targets and syncs the child checks; it satisfies the
+// "ICheckable" interface.
+func (f *FanOut) Status() (interface{}, error) {
+	return f.StatusContext(context.Background())
+}
+
+// StatusContext is like "Status()"; the context is passed to the lister.
+func (f *FanOut) StatusContext(ctx context.Context) (interface{}, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	targets, err := f.Config.Lister(ctx)
+	if err != nil {
+		return &Details{Targets: f.targets()}, fmt.Errorf("Unable to list targets: %v", err)
+	}
+
+	children := make(map[string]*health.Config, len(targets))
+	cfgs := make([]*health.Config, 0, len(targets))
+	errs := make(map[string]string)
+	var built []*health.Config
+
+	for _, target := range targets {
+		if _, ok := children[target]; ok {
+			continue
+		}
+
+		// existing targets keep their check (and checker)
+		if prev, ok := f.children[target]; ok {
+			children[target] = prev
+			cfgs = append(cfgs, prev)
+			continue
+		}
+
+		checker, err := f.Config.Factory(target)
+		if err != nil {
+			errs[target] = err.Error()
+			continue
+		}
+
+		cfg := f.child(target, checker)
+		built = append(built, cfg)
+		children[target] = cfg
+		cfgs = append(cfgs, cfg)
+	}
+
+	managed := make([]string, 0, len(f.children))
+	for _, cfg := range f.children {
+		managed = append(managed, cfg.Name)
+	}
+
+	if _, err := f.health.SyncChecks(cfgs, health.SyncOnly(managed...)); err != nil {
+		closeCheckers(built)
+		return &Details{Targets: f.targets()}, err
+	}
+
+	var removed []*health.Config
+	for target, cfg := range f.children {
+		if _, ok := children[target]; !ok {
+			removed = append(removed, cfg)
+		}
+	}
+
+	closeCheckers(removed)
+
+	f.children = children
+
+	details := &Details{Targets: f.targets()}
+
+	if len(errs) > 0 {
+		details.Errors = errs
+		return details, fmt.Errorf("Unable to create checkers for %v", failures(errs))
+	}
+
+	return details, nil
+}
+
+// Close removes the child checks from the health instance and closes their
+// checkers (if they implement "io.Closer"), ie. once the fan-out check has
+// been removed; it is called by "h.StopWithContext()".
+func (f *FanOut) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	managed := make([]string, 0, len(f.children))
+	children := make([]*health.Config, 0, len(f.children))
+	for _, cfg := range f.children {
+		managed = append(managed, cfg.Name)
+		children = append(children, cfg)
+	}
+
+	_, err := f.health.SyncChecks(nil, health.SyncOnly(managed...))
+
+	closeCheckers(children)
+
+	f.children = make(map[string]*health.Config)
+
+	return err
+}
+
+// returns the config of the child check of the target
+func (f *FanOut) child(target string, checker health.ICheckable) *health.Config {
+	cfg := &health.Config{}
+	if f.Config.Check != nil {
+		*cfg = *f.Config.Check
+	}
+
+	cfg.Name = f.Config.Name + "/" + target
+	cfg.Checker = &child{ICheckable: checker, target: target}
+
+	return cfg
+}
+
+func (f *FanOut) targets() []string {
+	targets := make([]string, 0, len(f.children))
+	for target := range f.children {
+		targets = append(targets, target)
+	}
+
+	sort.Strings(targets)
+
+	return targets
+}
+
+// closes the checkers of the child checks
+func closeCheckers(cfgs []*health.Config) {
+	for _, cfg := range cfgs {
+		cfg.Checker.(io.Closer).Close()
+	}
+}
+
+// returns the targets along with their errors, sorted
+func failures(errs map[string]string) string {
+	msgs := make([]string, 0, len(errs))
+	for target, err := range errs {
+		msgs = append(msgs, fmt.Sprintf("'%v' (%v)", target, err))
+	}
+
+	sort.Strings(msgs)
+
+	return strings.Join(msgs, ", ")
+}
+
+// child wraps the checker of a target; the checker is closed at most once,
+// since the child checks are closed both when they are removed and by
+// "h.StopWithContext()"
+type child struct {
+	health.ICheckable
+
+	target string
+	once   sync.Once
+	err    error
+}
+
+// StatusContext passes the context on to checkers that implement
+// "health.ICheckableContext".
+func (c *child) StatusContext(ctx context.Context) (interface{}, error) {
+	if cc, ok := c.ICheckable.(health.ICheckableContext); ok {
+		return cc.StatusContext(ctx)
+	}
+
+	return c.ICheckable.Status()
+}
+
+// Describe returns the description of the checker; the target defaults to
+// the target of the child check.
+func (c *child) Describe() describe.Description {
+	var desc describe.Description
+	if d, ok := c.ICheckable.(health.ICheckableDescriber); ok {
+		desc = d.Describe()
+	}
+
+	if desc.Type == "" {
+		desc.Type = fmt.Sprintf("%T", c.ICheckable)
+	}
+
+	if desc.Target == "" {
+		desc.Target = c.target
+	}
+
+	return desc
+}
+
+func (c *child) Close() error {
+	c.once.Do(func() {
+		if closer, ok := c.ICheckable.(io.Closer); ok {
+			c.err = closer.Close()
+		}
+	})
+
+	return c.err
+}
+
+func validateConfig(h *health.Health, cfg *Config) error {
+	errs := make([]*checkers.FieldError, 0)
+
+	fail := func(field, msg string) {
+		errs = append(errs, &checkers.FieldError{Field: field, Message: msg})
+	}
+
+	if h == nil {
+		fail("", "Health instance cannot be nil")
+	}
+
+	if cfg == nil {
+		fail("", "Passed in config cannot be nil")
+		return &checkers.ValidationError{Errors: errs}
+	}
+
+	if cfg.Name == "" {
+		fail("Name", "Name must be set")
+	}
+
+	if cfg.Lister == nil {
+		fail("Lister", "Lister cannot be nil")
+	}
+
+	if cfg.Factory == nil {
+		fail("Factory", "Factory cannot be nil")
+	}
+
+	if len(errs) > 0 {
+		return &checkers.ValidationError{Errors: errs}
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/checkers"
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+// closableChecker counts how often it has been closed
+type closableChecker struct {
+	fakes.FakeICheckable
+
+	lock   sync.Mutex
+	closed int
+}
+
+func (c *closableChecker) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed++
+
+	return nil
+}
+
+func (c *closableChecker) closeCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.closed
+}
+
+// lister returns the targets that are currently set
+type lister struct {
+	lock    sync.Mutex
+	targets []string
+	err     error
+}
+
+func (l *lister) set(err error, targets ...string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.targets, l.err = targets, err
+}
+
+func (l *lister) list(ctx context.Context) ([]string, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.targets, l.err
+}
+
+func names(h *health.Health) []string {
+	var names []string
+	for _, desc := range h.Descriptions() {
+		names = append(names, desc.Name)
+	}
+
+	return names
+}
+
+func TestNew(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error with an invalid config", func(t *testing.T) {
+		_, err := New(nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("Unable to validate fan-out config: ")))
+
+		_, err = New(health.New(), &Config{})
+
+		var verr *checkers.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields()).To(Equal([]string{"Name", "Lister", "Factory"}))
+	})
+}
+
+func TestStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	setup := func(l *lister, factory Factory) (*FanOut, *health.Health) {
+		h := health.New()
+		h.DisableLogging()
+
+		f, err := New(h, &Config{
+			Name:    "replicas",
+			Lister:  l.list,
+			Factory: factory,
+			Check:   &health.Config{Interval: time.Minute, Fatal: true},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(h.AddCheck(&health.Config{Name: "replicas", Checker: f, Interval: time.Minute})).To(Succeed())
+		Expect(h.Start()).To(Succeed())
+		t.Cleanup(func() { h.Stop() })
+
+		return f, h
+	}
+
+	t.Run("Should add and remove a child check per target", func(t *testing.T) {
+		l := &lister{targets: []string{"db-1:5432", "db-2:5432"}}
+		built := make(map[string]*closableChecker)
+		var lock sync.Mutex
+
+		f, h := setup(l, func(target string) (health.ICheckable, error) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			built[target] = &closableChecker{}
+			if target == "db-2:5432" {
+				built[target].StatusReturns(nil, errors.New("connection refused"))
+			}
+
+			return built[target], nil
+		})
+
+		Eventually(f.Targets).Should(Equal([]string{"db-1:5432", "db-2:5432"}))
+		Expect(names(h)).To(Equal([]string{"replicas", "replicas/db-1:5432", "replicas/db-2:5432"}))
+
+		// every target is reported individually
+		Eventually(func() map[string]health.State { states, _, _ := h.State(); return states }).Should(HaveKey("replicas/db-2:5432"))
+		Eventually(h.Failed).Should(BeTrue())
+
+		states, _, _ := h.State()
+		Expect(states["replicas/db-1:5432"].Status).To(Equal("ok"))
+		Expect(states["replicas/db-2:5432"].Status).To(Equal("failed"))
+		Expect(states["replicas/db-2:5432"].Fatal).To(BeTrue())
+
+		desc, err := h.Describe("replicas/db-1:5432")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc.Target).To(Equal("db-1:5432"))
+
+		l.set(nil, "db-1:5432", "db-3:5432")
+
+		details, err := h.RunCheck("replicas")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details.Details).To(Equal(&Details{Targets: []string{"db-1:5432", "db-3:5432"}}))
+		Expect(names(h)).To(Equal([]string{"replicas", "replicas/db-1:5432", "replicas/db-3:5432"}))
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		// only the removed target has been closed; existing targets are kept
+		Expect(built).To(HaveLen(3))
+		Expect(built["db-1:5432"].closeCount()).To(Equal(0))
+		Expect(built["db-2:5432"].closeCount()).To(Equal(1))
+
+		Eventually(h.Failed).Should(BeFalse())
+	})
+
+	t.Run("Should fail and keep the children if the targets cannot be listed", func(t *testing.T) {
+		l := &lister{targets: []string{"db-1:5432"}}
+
+		f, h := setup(l, func(target string) (health.ICheckable, error) { return &fakes.FakeICheckable{}, nil })
+		Eventually(f.Targets).Should(HaveLen(1))
+
+		l.set(errors.New("catalog unavailable"))
+
+		state, err := h.RunCheck("replicas")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("failed"))
+		Expect(state.Err).To(Equal("Unable to list targets: catalog unavailable"))
+		Expect(names(h)).To(Equal([]string{"replicas", "replicas/db-1:5432"}))
+	})
+
+	t.Run("Should retry targets whose checker cannot be created", func(t *testing.T) {
+		l := &lister{targets: []string{"db-1:5432", "db-2:5432"}}
+		broken := true
+
+		f, h := setup(l, func(target string) (health.ICheckable, error) {
+			if target == "db-2:5432" && broken {
+				return nil, errors.New("invalid address")
+			}
+
+			return &fakes.FakeICheckable{}, nil
+		})
+		Eventually(f.Targets).Should(Equal([]string{"db-1:5432"}))
+
+		state, err := h.RunCheck("replicas")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Err).To(Equal("Unable to create checkers for 'db-2:5432' (invalid address)"))
+		Expect(state.Details.(*Details).Errors).To(Equal(map[string]string{"db-2:5432": "invalid address"}))
+
+		broken = false
+
+		state, err = h.RunCheck("replicas")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("ok"))
+		Expect(f.Targets()).To(Equal([]string{"db-1:5432", "db-2:5432"}))
+	})
+
+	t.Run("Should remove and close the children once closed", func(t *testing.T) {
+		l := &lister{targets: []string{"db-1:5432"}}
+		checker := &closableChecker{}
+
+		f, h := setup(l, func(target string) (health.ICheckable, error) { return checker, nil })
+		Eventually(f.Targets).Should(HaveLen(1))
+
+		Expect(h.RemoveCheck("replicas")).To(Succeed())
+		Expect(f.Close()).To(Succeed())
+
+		Expect(names(h)).To(BeEmpty())
+		Expect(checker.closeCount()).To(Equal(1))
+	})
+
+	t.Run("Should close the children once on shutdown", func(t *testing.T) {
+		l := &lister{targets: []string{"db-1:5432"}}
+		checker := &closableChecker{}
+
+		f, h := setup(l, func(target string) (health.ICheckable, error) { return checker, nil })
+		Eventually(f.Targets).Should(HaveLen(1))
+
+		Expect(h.StopWithContext(context.Background())).To(Succeed())
+		Expect(checker.closeCount()).To(Equal(1))
+	})
+}
@@ -0,0 +1,68 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SRVResolver looks up SRV records; "*net.Resolver" implements it.
+type SRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// SRVConfig is used for configuring a lister of DNS SRV records.
+//
+// "Service", "Proto" and "Name" are the arguments of "net.LookupSRV()", ie.
+// `postgres`, `tcp` and `db.example.com` for `_postgres._tcp.db.example.com`;
+// if "Service" and "Proto" are empty, "Name" is looked up directly.
+//
+// "Resolver" is optional; defaults to "net.DefaultResolver".
+type SRVConfig struct {
+	Service  string
+	Proto    string
+	Name     string // Required
+	Resolver SRVResolver
+}
+
+// SRV returns a lister of the targets of DNS SRV records ("host:port",
+// sorted), so that the checked backends follow service discovery (ie.
+// Consul or Kubernetes headless services) as the dependency is scaled. The
+// records are looked up on every execution of the fan-out check; a failed
+// lookup (or one without records) fails the fan-out check and keeps the
+// child checks.
+func SRV(cfg *SRVConfig) (Lister, error) {
+	if cfg == nil || cfg.Name == "" {
+		return nil, errors.New("Unable to validate SRV config: Name must be set")
+	}
+
+	resolver := cfg.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return func(ctx context.Context) ([]string, error) {
+		_, records, err := resolver.LookupSRV(ctx, cfg.Service, cfg.Proto, cfg.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to look up SRV records: %v", err)
+		}
+
+		if len(records) == 0 {
+			return nil, fmt.Errorf("No SRV records found for '%v'", cfg.Name)
+		}
+
+		targets := make([]string, 0, len(records))
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			targets = append(targets, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+		}
+
+		sort.Strings(targets)
+
+		return targets, nil
+	}, nil
+}
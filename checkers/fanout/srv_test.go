@@ -0,0 +1,59 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type fakeResolver struct {
+	records []*net.SRV
+	err     error
+	lookups []string
+}
+
+func (r *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	r.lookups = append(r.lookups, service+"/"+proto+"/"+name)
+	return "", r.records, r.err
+}
+
+func TestSRV(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should list the targets of the records", func(t *testing.T) {
+		resolver := &fakeResolver{records: []*net.SRV{
+			{Target: "db-2.example.com.", Port: 5432},
+			{Target: "db-1.example.com.", Port: 5433},
+			{Target: "fd00::1", Port: 5432},
+		}}
+
+		list, err := SRV(&SRVConfig{Service: "postgres", Proto: "tcp", Name: "db.example.com", Resolver: resolver})
+		Expect(err).ToNot(HaveOccurred())
+
+		targets, err := list(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets).To(Equal([]string{"[fd00::1]:5432", "db-1.example.com:5433", "db-2.example.com:5432"}))
+		Expect(resolver.lookups).To(Equal([]string{"postgres/tcp/db.example.com"}))
+	})
+
+	t.Run("Should error if the lookup fails", func(t *testing.T) {
+		list, err := SRV(&SRVConfig{Name: "db.example.com", Resolver: &fakeResolver{err: errors.New("no such host")}})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = list(context.Background())
+		Expect(err).To(MatchError("Unable to look up SRV records: no such host"))
+
+		list, _ = SRV(&SRVConfig{Name: "db.example.com", Resolver: &fakeResolver{}})
+
+		_, err = list(context.Background())
+		Expect(err).To(MatchError("No SRV records found for 'db.example.com'"))
+	})
+
+	t.Run("Should error with an invalid config", func(t *testing.T) {
+		_, err := SRV(&SRVConfig{Service: "postgres"})
+		Expect(err).To(MatchError("Unable to validate SRV config: Name must be set"))
+	})
+}
@@ -0,0 +1,310 @@
+package checkers
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/InVisionApp/go-health/describe"
+)
+
+const (
+	// FileDescriptorsDefaultWarnThreshold is the usage ratio at which the
+	// file descriptor checker will start reporting as degraded.
+	FileDescriptorsDefaultWarnThreshold = 0.80
+
+	// FileDescriptorsDefaultFailThreshold is the usage ratio at which the
+	// file descriptor checker will fail.
+	FileDescriptorsDefaultFailThreshold = 0.95
+
+	procSelfFD         = "/proc/self/fd"
+	devFD              = "/dev/fd"
+	procPortRange      = "/proc/sys/net/ipv4/ip_local_port_range"
+	procTCPStateListen = "0A"
+)
+
+var procNetTCP = []string{"/proc/net/tcp", "/proc/net/tcp6"}
+
+// FileDescriptorsConfig is used for configuring the file descriptor check.
+//
+// "WarnThreshold" is optional and defaults to "0.80"; when the ratio of open
+// file descriptors (or ephemeral ports) to the limit crosses it, the check is
+// reported as degraded (see "DegradedError") and a warning is added to
+// the check details.
+//
+// "FailThreshold" is optional and defaults to "0.95"; when the ratio crosses
+// it, the check fails.
+//
+// "EphemeralPorts" is optional; if set, the checker will also compare the
+// number of sockets bound to a local ephemeral port against the kernel's
+// ephemeral port range (linux only).
+type FileDescriptorsConfig struct {
+	WarnThreshold  float64 // Optional (default 0.80)
+	FailThreshold  float64 // Optional (default 0.95)
+	EphemeralPorts bool    // Optional
+}
+
+// FileDescriptorsDetails is returned as the details of a file descriptor check.
+type FileDescriptorsDetails struct {
+	Open  int     `json:"open"`
+	Limit uint64  `json:"limit"`
+	Usage float64 `json:"usage"`
+
+	EphemeralPortsInUse int     `json:"ephemeral_ports_in_use,omitempty"`
+	EphemeralPortsTotal int     `json:"ephemeral_ports_total,omitempty"`
+	EphemeralPortsUsage float64 `json:"ephemeral_ports_usage,omitempty"`
+
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// FileDescriptors implements the "ICheckable" interface; it compares the
+// number of open file descriptors against the process rlimit (linux and
+// darwin only).
+type FileDescriptors struct {
+	Config *FileDescriptorsConfig
+
+	openFDs        func() (int, error)
+	fdLimit        func() (uint64, error)
+	ephemeralPorts func() (int, int, error)
+}
+
+// NewFileDescriptors creates a new file descriptor checker that can be used
+// for ".AddCheck(s)".
+func NewFileDescriptors(cfg *FileDescriptorsConfig) (*FileDescriptors, error) {
+	if cfg == nil {
+		cfg = &FileDescriptorsConfig{}
+	}
+
+	if err := validateFileDescriptorsConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate file descriptors config: %w", err)
+	}
+
+	return &FileDescriptors{
+		Config:         cfg,
+		openFDs:        countOpenFDs,
+		fdLimit:        getFDLimit,
+		ephemeralPorts: countEphemeralPorts,
+	}, nil
+}
+
+// Describe returns the type and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (f *FileDescriptors) Describe() describe.Description {
+	caps := []string{"file_descriptors"}
+	if f.Config.EphemeralPorts {
+		caps = append(caps, "ephemeral_ports")
+	}
+
+	return describe.Description{
+		Type:         "file_descriptors",
+		Capabilities: caps,
+	}
+}
+
+// Status is used for comparing file descriptor (and optionally ephemeral
+// port) usage against the configured thresholds; it satisfies the
+// "ICheckable" interface.
+func (f *FileDescriptors) Status() (interface{}, error) {
+	open, err := f.openFDs()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to count open file descriptors: %v", err)
+	}
+
+	limit, err := f.fdLimit()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to fetch file descriptor limit: %v", err)
+	}
+
+	details := &FileDescriptorsDetails{
+		Open:  open,
+		Limit: limit,
+	}
+
+	if limit > 0 {
+		details.Usage = float64(open) / float64(limit)
+	}
+
+	if details.Usage >= f.Config.FailThreshold {
+		return details, fmt.Errorf("Open file descriptors (%v) exceed %v%% of the limit (%v)",
+			open, f.Config.FailThreshold*100, limit)
+	}
+
+	if details.Usage >= f.Config.WarnThreshold {
+		details.Warnings = append(details.Warnings, fmt.Sprintf("open file descriptors at %.1f%% of the limit", details.Usage*100))
+	}
+
+	if !f.Config.EphemeralPorts {
+		return details, f.degraded(details)
+	}
+
+	inUse, total, err := f.ephemeralPorts()
+	if err != nil {
+		return details, fmt.Errorf("Unable to count ephemeral ports: %v", err)
+	}
+
+	details.EphemeralPortsInUse = inUse
+	details.EphemeralPortsTotal = total
+
+	if total > 0 {
+		details.EphemeralPortsUsage = float64(inUse) / float64(total)
+	}
+
+	if details.EphemeralPortsUsage >= f.Config.FailThreshold {
+		return details, fmt.Errorf("Ephemeral ports in use (%v) exceed %v%% of the port range (%v)",
+			inUse, f.Config.FailThreshold*100, total)
+	}
+
+	if details.EphemeralPortsUsage >= f.Config.WarnThreshold {
+		details.Warnings = append(details.Warnings, fmt.Sprintf("ephemeral ports at %.1f%% of the port range", details.EphemeralPortsUsage*100))
+	}
+
+	return details, f.degraded(details)
+}
+
+// returns a "DegradedError" if the usage has crossed the warn threshold
+func (f *FileDescriptors) degraded(details *FileDescriptorsDetails) error {
+	if len(details.Warnings) == 0 {
+		return nil
+	}
+
+	return degraded("Usage exceeds %v%% of the limit: %v", f.Config.WarnThreshold*100,
+		strings.Join(details.Warnings, ", "))
+}
+
+func validateFileDescriptorsConfig(cfg *FileDescriptorsConfig) error {
+	v := &validation{}
+
+	if cfg.WarnThreshold == 0 {
+		cfg.WarnThreshold = FileDescriptorsDefaultWarnThreshold
+	}
+
+	if cfg.FailThreshold == 0 {
+		cfg.FailThreshold = FileDescriptorsDefaultFailThreshold
+	}
+
+	warnValid := !v.check(cfg.WarnThreshold < 0 || cfg.WarnThreshold > 1, "WarnThreshold", "WarnThreshold must be between 0 and 1")
+	failValid := !v.check(cfg.FailThreshold < 0 || cfg.FailThreshold > 1, "FailThreshold", "FailThreshold must be between 0 and 1")
+
+	if warnValid && failValid {
+		v.check(cfg.WarnThreshold > cfg.FailThreshold, "WarnThreshold", "WarnThreshold cannot be greater than FailThreshold")
+	}
+
+	return v.err()
+}
+
+func countOpenFDs() (int, error) {
+	var dir string
+
+	switch runtime.GOOS {
+	case "linux":
+		dir = procSelfFD
+	case "darwin":
+		dir = devFD
+	default:
+		return 0, fmt.Errorf("Counting open file descriptors is not supported on %v", runtime.GOOS)
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return 0, err
+	}
+
+	// the descriptor of the directory itself is listed as well
+	return len(names) - 1, nil
+}
+
+// returns the number of sockets bound to a local port within the ephemeral
+// port range and the size of the range itself
+func countEphemeralPorts() (int, int, error) {
+	data, err := ioutil.ReadFile(procPortRange)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	low, high, err := parsePortRange(string(data))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	inUse := 0
+
+	for _, path := range procNetTCP {
+		n, err := countPortsInRange(path, low, high)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return 0, 0, err
+		}
+		inUse += n
+	}
+
+	return inUse, high - low + 1, nil
+}
+
+func parsePortRange(data string) (int, int, error) {
+	fields := strings.Fields(data)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected port range format '%v'", strings.TrimSpace(data))
+	}
+
+	low, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to parse port range: %v", err)
+	}
+
+	high, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to parse port range: %v", err)
+	}
+
+	return low, high, nil
+}
+
+func countPortsInRange(path string, low, high int) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+
+	// skip the header line
+	scanner.Scan()
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[3] == procTCPStateListen {
+			continue
+		}
+
+		// local address is formatted as "HEXIP:HEXPORT"
+		idx := strings.LastIndex(fields[1], ":")
+		if idx < 0 {
+			continue
+		}
+
+		port, err := strconv.ParseInt(fields[1][idx+1:], 16, 32)
+		if err != nil {
+			continue
+		}
+
+		if int(port) >= low && int(port) <= high {
+			count++
+		}
+	}
+
+	return count, scanner.Err()
+}
@@ -0,0 +1,164 @@
+package checkers
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewFileDescriptors(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		fd, err := NewFileDescriptors(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fd).ToNot(BeNil())
+		Expect(fd.Config.WarnThreshold).To(Equal(FileDescriptorsDefaultWarnThreshold))
+		Expect(fd.Config.FailThreshold).To(Equal(FileDescriptorsDefaultFailThreshold))
+	})
+
+	t.Run("Should error if warn threshold is above fail threshold", func(t *testing.T) {
+		fd, err := NewFileDescriptors(&FileDescriptorsConfig{
+			WarnThreshold: 0.9,
+			FailThreshold: 0.5,
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("WarnThreshold cannot be greater than FailThreshold"))
+		Expect(fd).To(BeNil())
+	})
+
+	t.Run("Should error on out of range thresholds", func(t *testing.T) {
+		_, err := NewFileDescriptors(&FileDescriptorsConfig{FailThreshold: 2})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("FailThreshold must be between 0 and 1"))
+	})
+}
+
+func TestFileDescriptorsStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	setup := func(open int, limit uint64) *FileDescriptors {
+		fd, err := NewFileDescriptors(&FileDescriptorsConfig{})
+		Expect(err).ToNot(HaveOccurred())
+
+		fd.openFDs = func() (int, error) { return open, nil }
+		fd.fdLimit = func() (uint64, error) { return limit, nil }
+
+		return fd
+	}
+
+	t.Run("Happy path", func(t *testing.T) {
+		data, err := setup(10, 100).Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		details := data.(*FileDescriptorsDetails)
+		Expect(details.Open).To(Equal(10))
+		Expect(details.Limit).To(Equal(uint64(100)))
+		Expect(details.Warnings).To(BeEmpty())
+	})
+
+	t.Run("Should be degraded above the warn threshold", func(t *testing.T) {
+		data, err := setup(85, 100).Status()
+		Expect(data.(*FileDescriptorsDetails).Warnings).To(HaveLen(1))
+
+		var degraded *DegradedError
+		Expect(errors.As(err, &degraded)).To(BeTrue())
+		Expect(err.Error()).To(Equal("Usage exceeds 80% of the limit: open file descriptors at 85.0% of the limit"))
+	})
+
+	t.Run("Should be degraded if ephemeral ports cross the warn threshold", func(t *testing.T) {
+		fd := setup(10, 100)
+		fd.Config.EphemeralPorts = true
+		fd.ephemeralPorts = func() (int, int, error) { return 90, 100, nil }
+
+		_, err := fd.Status()
+
+		var degraded *DegradedError
+		Expect(errors.As(err, &degraded)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("ephemeral ports at 90.0% of the port range"))
+	})
+
+	t.Run("Should fail above the fail threshold", func(t *testing.T) {
+		_, err := setup(96, 100).Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Open file descriptors (96) exceed"))
+	})
+
+	t.Run("Should error if open file descriptors can't be counted", func(t *testing.T) {
+		fd := setup(0, 100)
+		fd.openFDs = func() (int, error) { return 0, fmt.Errorf("no proc") }
+
+		_, err := fd.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to count open file descriptors: no proc"))
+	})
+
+	t.Run("Should fail when ephemeral ports are exhausted", func(t *testing.T) {
+		fd := setup(10, 100)
+		fd.Config.EphemeralPorts = true
+		fd.ephemeralPorts = func() (int, int, error) { return 99, 100, nil }
+
+		data, err := fd.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Ephemeral ports in use (99) exceed"))
+		Expect(data.(*FileDescriptorsDetails).EphemeralPortsTotal).To(Equal(100))
+	})
+}
+
+func TestCountOpenFDs(t *testing.T) {
+	RegisterTestingT(t)
+
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("not supported on " + runtime.GOOS)
+	}
+
+	before, err := countOpenFDs()
+	Expect(err).ToNot(HaveOccurred())
+
+	f, err := os.Open(os.DevNull)
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+
+	// the descriptor of the listed directory is not counted
+	after, err := countOpenFDs()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(after).To(Equal(before + 1))
+}
+
+func TestCountPortsInRange(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "go-health")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "tcp")
+		contents := "  sl  local_address rem_address   st\n" +
+			"   0: 0100007F:1F90 00000000:0000 0A\n" + // listening, 8080
+			"   1: 0100007F:9C40 0100007F:1F90 01\n" + // established, 40000
+			"   2: 0100007F:0050 0100007F:1F90 01\n" // established, 80
+
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+
+		count, err := countPortsInRange(path, 32768, 60999)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(1))
+	})
+
+	t.Run("Should parse the kernel port range", func(t *testing.T) {
+		low, high, err := parsePortRange("32768\t60999\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(low).To(Equal(32768))
+		Expect(high).To(Equal(60999))
+
+		_, _, err = parsePortRange("nope")
+		Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package checkers
+
+import "syscall"
+
+func getFDLimit() (uint64, error) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0, err
+	}
+
+	return uint64(rlimit.Cur), nil
+}
@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package checkers
+
+import "errors"
+
+func getFDLimit() (uint64, error) {
+	return 0, errors.New("file descriptor limits are not supported on windows")
+}
@@ -0,0 +1,124 @@
+// Package heartbeat contains a deadman checker: the application calls
+// "Beat()" from its worker loop and the check fails if no beat has been
+// received within the window, ie. to detect stuck background workers (not
+// just failing external dependencies).
+//
+//	hb, err := heartbeat.New(&heartbeat.Config{Window: time.Minute})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	h.AddCheck(&health.Config{Name: "order-worker", Checker: hb, Interval: 10 * time.Second})
+//
+//	for job := range jobs {
+//		process(job)
+//		hb.Beat()
+//	}
+package heartbeat
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/InVisionApp/go-health/checkers"
+	"github.com/InVisionApp/go-health/describe"
+)
+
+// Config is used for configuring a heartbeat check.
+//
+// "Window" is _required_; the check fails if no beat has been received for
+// longer than that. The window starts when the checker is created, so that a
+// worker that never beats is detected as well.
+type Config struct {
+	Window time.Duration
+}
+
+// Details is returned as the details of a heartbeat check.
+type Details struct {
+	// LastBeat is the time of the latest beat; zero if none has been received
+	LastBeat time.Time `json:"last_beat,omitempty"`
+
+	// Beats is the number of beats that have been received
+	Beats int64 `json:"beats"`
+}
+
+// Heartbeat implements the "ICheckable" interface; "Beat()" is safe for
+// concurrent use and cheap enough to be called on every iteration of a loop.
+type Heartbeat struct {
+	Config *Config
+
+	// unix nanoseconds of the creation and of the latest beat
+	created  int64
+	lastBeat int64
+	beats    int64
+
+	now func() time.Time
+}
+
+// New creates a new heartbeat checker that can be used for ".AddCheck(s)".
+func New(cfg *Config) (*Heartbeat, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate heartbeat config: %w", err)
+	}
+
+	hb := &Heartbeat{Config: cfg, now: time.Now}
+	hb.created = hb.now().UnixNano()
+
+	return hb, nil
+}
+
+// Beat records a beat of the worker.
+func (h *Heartbeat) Beat() {
+	atomic.StoreInt64(&h.lastBeat, h.now().UnixNano())
+	atomic.AddInt64(&h.beats, 1)
+}
+
+// Describe returns the type and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (h *Heartbeat) Describe() describe.Description {
+	return describe.Description{
+		Type:         "heartbeat",
+		Capabilities: []string{"heartbeat"},
+	}
+}
+
+// Status is used for checking whether a beat has been received within the
+// window; it satisfies the "ICheckable" interface.
+func (h *Heartbeat) Status() (interface{}, error) {
+	details := &Details{Beats: atomic.LoadInt64(&h.beats)}
+
+	since := atomic.LoadInt64(&h.lastBeat)
+	if since != 0 {
+		details.LastBeat = time.Unix(0, since)
+	} else {
+		since = h.created
+	}
+
+	elapsed := h.now().Sub(time.Unix(0, since))
+	if elapsed <= h.Config.Window {
+		return details, nil
+	}
+
+	if details.Beats == 0 {
+		return details, fmt.Errorf("No heartbeat received within %v", h.Config.Window)
+	}
+
+	return details, fmt.Errorf("No heartbeat received within %v (last beat %v ago)", h.Config.Window, elapsed.Round(time.Millisecond))
+}
+
+func validateConfig(cfg *Config) error {
+	errs := make([]*checkers.FieldError, 0)
+
+	if cfg == nil {
+		errs = append(errs, &checkers.FieldError{Message: "Passed in config cannot be nil"})
+	} else if cfg.Window <= 0 {
+		errs = append(errs, &checkers.FieldError{Field: "Window", Message: "Window must be set"})
+	}
+
+	if len(errs) > 0 {
+		return &checkers.ValidationError{Errors: errs}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package heartbeat
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/checkers"
+)
+
+// returns a heartbeat whose clock is advanced by the returned func
+func newTestHeartbeat(window time.Duration) (*Heartbeat, func(d time.Duration)) {
+	now := time.Date(2017, 12, 5, 19, 17, 23, 0, time.UTC)
+
+	hb, err := New(&Config{Window: window})
+	Expect(err).ToNot(HaveOccurred())
+
+	hb.now = func() time.Time { return now }
+	hb.created = now.UnixNano()
+
+	return hb, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestNew(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error with an invalid config", func(t *testing.T) {
+		_, err := New(nil)
+		Expect(err).To(MatchError(ContainSubstring("Unable to validate heartbeat config: Passed in config cannot be nil")))
+
+		_, err = New(&Config{})
+
+		var verr *checkers.ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields()).To(Equal([]string{"Window"}))
+	})
+
+	t.Run("Should describe the checker", func(t *testing.T) {
+		hb, err := New(&Config{Window: time.Minute})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hb.Describe().Type).To(Equal("heartbeat"))
+	})
+}
+
+func TestStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should pass while beats are received within the window", func(t *testing.T) {
+		hb, advance := newTestHeartbeat(time.Minute)
+
+		for i := 0; i < 3; i++ {
+			advance(50 * time.Second)
+			hb.Beat()
+
+			details, err := hb.Status()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(details.(*Details).Beats).To(Equal(int64(i + 1)))
+			Expect(details.(*Details).LastBeat).To(BeTemporally("==", hb.now()))
+		}
+	})
+
+	t.Run("Should fail if no beat has been received within the window", func(t *testing.T) {
+		hb, advance := newTestHeartbeat(time.Minute)
+
+		hb.Beat()
+		advance(90 * time.Second)
+
+		details, err := hb.Status()
+		Expect(err).To(MatchError("No heartbeat received within 1m0s (last beat 1m30s ago)"))
+		Expect(details.(*Details).Beats).To(Equal(int64(1)))
+
+		// recovers with the next beat
+		hb.Beat()
+
+		_, err = hb.Status()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("Should fail if the worker never beats", func(t *testing.T) {
+		hb, advance := newTestHeartbeat(time.Minute)
+
+		_, err := hb.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		advance(61 * time.Second)
+
+		details, err := hb.Status()
+		Expect(err).To(MatchError("No heartbeat received within 1m0s"))
+		Expect(details.(*Details).LastBeat.IsZero()).To(BeTrue())
+	})
+
+	t.Run("Should be safe for concurrent use", func(t *testing.T) {
+		hb, err := New(&Config{Window: time.Minute})
+		Expect(err).ToNot(HaveOccurred())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 100; j++ {
+					hb.Beat()
+					hb.Status()
+				}
+			}()
+		}
+		wg.Wait()
+
+		details, err := hb.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details.(*Details).Beats).To(Equal(int64(1000)))
+	})
+}
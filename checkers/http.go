@@ -2,43 +2,130 @@ package checkers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
+	"text/template"
 	"time"
+
+	"github.com/InVisionApp/go-health/describe"
+	"github.com/InVisionApp/go-health/retry"
 )
 
 const (
-	defaultHTTPTimeout = time.Duration(3) * time.Second
+	// HTTPProtocolHTTP1 forces the HTTP checker to use HTTP/1.1
+	HTTPProtocolHTTP1 = "http/1.1"
+
+	// HTTPProtocolHTTP2 forces the HTTP checker to use HTTP/2 over TLS (negotiated via ALPN)
+	HTTPProtocolHTTP2 = "h2"
+
+	// HTTPProtocolH2C forces the HTTP checker to use cleartext HTTP/2 with prior
+	// knowledge; it requires Go 1.24 or later
+	HTTPProtocolH2C = "h2c"
+
+	// HTTPProtocolHTTP3 forces the HTTP checker to use HTTP/3 (QUIC); it requires
+	// "HTTPConfig.Transport" to be set to an HTTP/3 capable round tripper
+	HTTPProtocolHTTP3 = "h3"
 )
 
+// the major protocol version that each of the supported protocols must negotiate
+var httpProtocolVersions = map[string]int{
+	HTTPProtocolHTTP1: 1,
+	HTTPProtocolHTTP2: 2,
+	HTTPProtocolH2C:   2,
+	HTTPProtocolHTTP3: 3,
+}
+
 // HTTPConfig is used for configuring an HTTP check. The only required field is `URL`.
 //
-// "Method" is optional and defaults to `GET` if undefined.
+// "Method" is optional and defaults to `GET` if undefined; any method (ie.
+// `HEAD`, `OPTIONS` or a custom one) can be used.
 //
 // "Payload" is optional and can accept `string`, `[]byte` or will attempt to
 // marshal the input to JSON for use w/ `bytes.NewReader()`.
 //
+// "PayloadTemplate" is optional; a "text/template" that is rendered on every
+// check and used as the request body. Refer to "HTTPTemplateData" for the
+// available variables (ie. `{"ts": "{{.Unix}}", "host": "{{.Hostname}}"}`).
+//
+// "Multipart" is optional; if set, the request body is sent as
+// "multipart/form-data". Only one of "Payload", "PayloadTemplate" or
+// "Multipart" can be set.
+//
+// "Header" is optional; additional headers to send with the request.
+//
 // "StatusCode" is optional and defaults to `200`.
 //
 // "Expect" is optional; if defined, operates as a basic "body should contain <string>".
 //
 // "Client" is optional; if undefined, a new client will be created using "Timeout".
 //
-// "Timeout" is optional and defaults to "3s".
+// "Timeout" is optional and defaults to "DefaultTimeout".
+//
+// "Protocol" is optional; if set to one of the "HTTPProtocol*" constants, the
+// checker will force that protocol and fail if a different protocol was
+// negotiated. Note that the protocol is only forced when the checker creates
+// its own client (ie. "Client" is undefined); the negotiated protocol is
+// asserted either way.
+//
+// "Transport" is optional; the round tripper used when the checker creates its
+// own client. It is required for "HTTPProtocolHTTP3" (ie. quic-go's
+// "http3.RoundTripper"), as the standard library does not support HTTP/3.
+//
+// "Metrics" is optional; receives the status code and the response size on
+// every check. Note that the response body is read in full if set.
+//
+// "Retry" is optional; a failed request (or unexpected response) is retried
+// within the same check according to the policy (ie. "retry.Exponential").
 type HTTPConfig struct {
-	URL        *url.URL      // Required
-	Method     string        // Optional (default GET)
-	Payload    interface{}   // Optional
-	StatusCode int           // Optional (default 200)
-	Expect     string        // Optional
-	Client     *http.Client  // Optional
-	Timeout    time.Duration // Optional (default 3s)
+	URL        *url.URL          // Required
+	Method     string            // Optional (default GET)
+	Payload    interface{}       // Optional
+	StatusCode int               // Optional (default 200)
+	Expect     string            // Optional
+	Client     *http.Client      // Optional
+	Timeout    time.Duration     // Optional (default DefaultTimeout)
+	Protocol   string            // Optional
+	Transport  http.RoundTripper // Optional
+
+	PayloadTemplate string            // Optional
+	TemplateVars    map[string]string // Optional
+	Multipart       *HTTPMultipart    // Optional
+	Header          http.Header       // Optional
+	Metrics         MetricsCollector  // Optional
+	Retry           retry.Policy      // Optional
+
+	payloadTemplate *template.Template
+}
+
+// HTTPTemplateData contains the variables available to "HTTPConfig.PayloadTemplate".
+type HTTPTemplateData struct {
+	Timestamp time.Time         // time of the check
+	Unix      int64             // time of the check as a unix timestamp
+	Hostname  string            // hostname of the machine performing the check
+	Vars      map[string]string // "HTTPConfig.TemplateVars"
+}
+
+// HTTPMultipart describes a "multipart/form-data" request body.
+type HTTPMultipart struct {
+	Fields map[string]string
+	Files  []HTTPMultipartFile
+}
+
+// HTTPMultipartFile is a single file that is uploaded as part of a multipart
+// request body.
+type HTTPMultipartFile struct {
+	FieldName string
+	FileName  string
+	Content   []byte
 }
 
 // HTTP implements the "ICheckable" interface.
@@ -53,7 +140,7 @@ func NewHTTP(cfg *HTTPConfig) (*HTTP, error) {
 	}
 
 	if err := cfg.prepare(); err != nil {
-		return nil, fmt.Errorf("Unable to prepare given config: %v", err)
+		return nil, fmt.Errorf("Unable to prepare given config: %w", err)
 	}
 
 	return &HTTP{
@@ -61,15 +148,52 @@ func NewHTTP(cfg *HTTPConfig) (*HTTP, error) {
 	}, nil
 }
 
+// Describe returns the type, target and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (h *HTTP) Describe() describe.Description {
+	caps := []string{"request", "status_code"}
+	if h.Config.Expect != "" {
+		caps = append(caps, "body")
+	}
+
+	return describe.Description{
+		Type:         "http",
+		Target:       h.Config.URL.Redacted(),
+		Capabilities: caps,
+	}
+}
+
 // Status is used for performing an HTTP check against a dependency; it satisfies
 // the "ICheckable" interface.
 func (h *HTTP) Status() (interface{}, error) {
+	var data interface{}
+
+	err := retry.Do(context.Background(), h.Config.Retry, func() (err error) {
+		data, err = h.status()
+		return err
+	})
+
+	return data, err
+}
+
+// performs a single request
+func (h *HTTP) status() (interface{}, error) {
 	resp, err := h.do()
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if h.Config.Metrics != nil {
+		resp.Body = h.emitMetrics(resp)
+	}
+
+	// Check if the expected protocol was negotiated
+	if h.Config.Protocol != "" && resp.ProtoMajor != httpProtocolVersions[h.Config.Protocol] {
+		return nil, fmt.Errorf("Negotiated protocol '%v' does not match expected protocol '%v'",
+			resp.Proto, h.Config.Protocol)
+	}
+
 	// Check if StatusCode matches
 	if resp.StatusCode != h.Config.StatusCode {
 		return nil, fmt.Errorf("Received status code '%v' does not match expected status code '%v'",
@@ -92,8 +216,22 @@ func (h *HTTP) Status() (interface{}, error) {
 	return nil, nil
 }
 
+// emits the response metrics; returns a body that can be read again
+func (h *HTTP) emitMetrics(resp *http.Response) io.ReadCloser {
+	tags := map[string]string{"url": h.Config.URL.String()}
+
+	h.Config.Metrics.Gauge(MetricHTTPStatusCode, float64(resp.StatusCode), tags)
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err == nil {
+		h.Config.Metrics.Gauge(MetricHTTPResponseSize, float64(len(data)), tags)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(data))
+}
+
 func (h *HTTP) do() (*http.Response, error) {
-	payload, err := parsePayload(h.Config.Payload)
+	payload, contentType, err := h.body()
 	if err != nil {
 		return nil, fmt.Errorf("error parsing payload: %v", err)
 	}
@@ -103,6 +241,14 @@ func (h *HTTP) do() (*http.Response, error) {
 		return nil, fmt.Errorf("Unable to create new HTTP request for HTTPMonitor check: %v", err)
 	}
 
+	for k, v := range h.Config.Header {
+		req.Header[k] = v
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
 	resp, err := h.Config.Client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("Ran into error while performing '%v' request: %v", h.Config.Method, err)
@@ -112,9 +258,10 @@ func (h *HTTP) do() (*http.Response, error) {
 }
 
 func (h *HTTPConfig) prepare() error {
-	if h.URL == nil {
-		return errors.New("URL cannot be nil")
-	}
+	v := &validation{}
+
+	v.check(h.URL == nil, "URL", "URL cannot be nil")
+	v.check(h.Timeout < 0, "Timeout", "Timeout cannot be negative")
 
 	// Default StatusCode to 200
 	if h.StatusCode == 0 {
@@ -126,12 +273,46 @@ func (h *HTTPConfig) prepare() error {
 		h.Method = "GET"
 	}
 
+	h.Method = strings.ToUpper(h.Method)
+
 	if h.Timeout == 0 {
-		h.Timeout = defaultHTTPTimeout
+		h.Timeout = DefaultTimeout
+	}
+
+	if h.PayloadTemplate != "" {
+		tmpl, err := template.New("payload").Parse(h.PayloadTemplate)
+		if err != nil {
+			v.fail("PayloadTemplate", "Unable to parse payload template: %v", err)
+		}
+		h.payloadTemplate = tmpl
+	}
+
+	bodies := 0
+	for _, set := range []bool{h.Payload != nil, h.PayloadTemplate != "", h.Multipart != nil} {
+		if set {
+			bodies++
+		}
+	}
+
+	v.check(bodies > 1, "", "Only one of Payload, PayloadTemplate or Multipart can be set")
+
+	if h.Protocol != "" {
+		_, ok := httpProtocolVersions[h.Protocol]
+		v.check(!ok, "Protocol", "Unsupported protocol '%v'", h.Protocol)
+	}
+
+	if err := v.err(); err != nil {
+		return err
 	}
 
 	if h.Client == nil {
-		h.Client = &http.Client{Timeout: h.Timeout}
+		transport, err := h.transport()
+		if err != nil {
+			v.fail("Transport", "%v", err)
+			return v.err()
+		}
+
+		h.Client = &http.Client{Timeout: h.Timeout, Transport: transport}
 	} else {
 		h.Client.Timeout = h.Timeout
 	}
@@ -139,6 +320,94 @@ func (h *HTTPConfig) prepare() error {
 	return nil
 }
 
+// builds a round tripper that is restricted to the configured protocol
+func (h *HTTPConfig) transport() (http.RoundTripper, error) {
+	if h.Protocol == HTTPProtocolHTTP3 {
+		if h.Transport == nil {
+			return nil, errors.New("Transport must be set to an HTTP/3 capable round tripper when using HTTP/3")
+		}
+		return h.Transport, nil
+	}
+
+	if h.Protocol == "" {
+		return h.Transport, nil
+	}
+
+	base, ok := h.Transport.(*http.Transport)
+	if h.Transport != nil && !ok {
+		return nil, fmt.Errorf("Transport must be an *http.Transport when forcing protocol '%v'", h.Protocol)
+	}
+
+	var transport *http.Transport
+	if base != nil {
+		transport = base.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	// see http_protocols.go (and http_protocols_legacy.go for Go < 1.24)
+	if err := restrictProtocol(transport, h.Protocol); err != nil {
+		return nil, err
+	}
+
+	return transport, nil
+}
+
+// returns the request body along with its content type (if it should be set)
+func (h *HTTP) body() (io.Reader, string, error) {
+	switch {
+	case h.Config.payloadTemplate != nil:
+		hostname, _ := os.Hostname()
+		now := time.Now()
+
+		buf := &bytes.Buffer{}
+		err := h.Config.payloadTemplate.Execute(buf, &HTTPTemplateData{
+			Timestamp: now,
+			Unix:      now.Unix(),
+			Hostname:  hostname,
+			Vars:      h.Config.TemplateVars,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to render payload template: %v", err)
+		}
+
+		return buf, "", nil
+	case h.Config.Multipart != nil:
+		return parseMultipart(h.Config.Multipart)
+	default:
+		payload, err := parsePayload(h.Config.Payload)
+		return payload, "", err
+	}
+}
+
+func parseMultipart(m *HTTPMultipart) (io.Reader, string, error) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+
+	for k, v := range m.Fields {
+		if err := w.WriteField(k, v); err != nil {
+			return nil, "", fmt.Errorf("failed to write multipart field: %v", err)
+		}
+	}
+
+	for _, f := range m.Files {
+		part, err := w.CreateFormFile(f.FieldName, f.FileName)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create multipart file: %v", err)
+		}
+
+		if _, err := part.Write(f.Content); err != nil {
+			return nil, "", fmt.Errorf("failed to write multipart file: %v", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart writer: %v", err)
+	}
+
+	return buf, w.FormDataContentType(), nil
+}
+
 func parsePayload(b interface{}) (io.Reader, error) {
 	if b == nil {
 		return nil, nil
@@ -0,0 +1,26 @@
+//go:build go1.24
+// +build go1.24
+
+package checkers
+
+import (
+	"net/http"
+)
+
+// restricts the transport to the protocol (other than HTTP/3)
+func restrictProtocol(transport *http.Transport, protocol string) error {
+	protocols := new(http.Protocols)
+
+	switch protocol {
+	case HTTPProtocolHTTP1:
+		protocols.SetHTTP1(true)
+	case HTTPProtocolHTTP2:
+		protocols.SetHTTP2(true)
+	case HTTPProtocolH2C:
+		protocols.SetUnencryptedHTTP2(true)
+	}
+
+	transport.Protocols = protocols
+
+	return nil
+}
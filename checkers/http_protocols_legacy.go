@@ -0,0 +1,26 @@
+//go:build !go1.24
+// +build !go1.24
+
+package checkers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// restricts the transport to the protocol (other than HTTP/3); "http.Protocols"
+// is not available before Go 1.24, so cleartext HTTP/2 is not supported
+func restrictProtocol(transport *http.Transport, protocol string) error {
+	switch protocol {
+	case HTTPProtocolHTTP1:
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	case HTTPProtocolHTTP2:
+		transport.ForceAttemptHTTP2 = true
+	case HTTPProtocolH2C:
+		return fmt.Errorf("Protocol '%v' requires Go 1.24 or later", protocol)
+	}
+
+	return nil
+}
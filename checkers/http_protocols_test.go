@@ -0,0 +1,35 @@
+//go:build go1.24
+// +build go1.24
+
+package checkers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestHTTPProtocolH2C(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should use HTTP/2 prior knowledge with h2c", func(t *testing.T) {
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		ts.Config.Protocols = new(http.Protocols)
+		ts.Config.Protocols.SetHTTP1(true)
+		ts.Config.Protocols.SetUnencryptedHTTP2(true)
+		ts.Start()
+		defer ts.Close()
+
+		testURL, _ := url.Parse(ts.URL)
+		checker, err := NewHTTP(&HTTPConfig{URL: testURL, Protocol: HTTPProtocolH2C})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+	})
+}
@@ -1,15 +1,20 @@
 package checkers
 
 import (
+	"io/ioutil"
 	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"testing"
 	"time"
 
 	"fmt"
 	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/checkers/internal/mockdeps"
+	"github.com/InVisionApp/go-health/retry"
 )
 
 func TestNewHTTP(t *testing.T) {
@@ -94,7 +99,7 @@ func TestPrepare(t *testing.T) {
 
 		Expect(h.StatusCode).To(Equal(http.StatusOK))
 		Expect(h.Method).To(Equal("GET"))
-		Expect(h.Timeout).To(Equal(defaultHTTPTimeout))
+		Expect(h.Timeout).To(Equal(DefaultTimeout))
 		Expect(h.Client.Timeout).To(Equal(h.Timeout))
 	})
 
@@ -283,6 +288,33 @@ func TestHTTPStatus(t *testing.T) {
 		Expect(err.Error()).To(ContainSubstring("Unable to read response body to perform content expectancy check"))
 		Expect(data).To(BeNil())
 	})
+
+	t.Run("Should retry failed requests according to the retry policy", func(t *testing.T) {
+		server := mockdeps.NewHTTP()
+		defer server.Close()
+
+		server.SetResponse(http.StatusServiceUnavailable, "down")
+
+		testURL, err := url.Parse(server.URL())
+		Expect(err).ToNot(HaveOccurred())
+
+		checker, err := NewHTTP(&HTTPConfig{
+			URL:   testURL,
+			Retry: retry.Constant{Delay: time.Millisecond, Retries: 2},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("'503' does not match expected status code"))
+		Expect(server.Requests()).To(Equal(3))
+
+		server.SetResponse(http.StatusOK, "ok")
+
+		_, err = checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.Requests()).To(Equal(4))
+	})
 }
 
 type CustomTransport struct{}
@@ -302,3 +334,131 @@ type mockReader struct{}
 
 func (m *mockReader) Read(p []byte) (n int, err error) { return 0, fmt.Errorf("foo") }
 func (m *mockReader) Close() error                     { return nil }
+
+func TestHTTPProtocol(t *testing.T) {
+	RegisterTestingT(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("Should assert the negotiated protocol", func(t *testing.T) {
+		ts := httptest.NewTLSServer(handler)
+		defer ts.Close()
+
+		testURL, _ := url.Parse(ts.URL)
+		checker, err := NewHTTP(&HTTPConfig{URL: testURL, Protocol: HTTPProtocolHTTP2, Client: ts.Client()})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Negotiated protocol 'HTTP/1.1' does not match expected protocol 'h2'"))
+	})
+
+	t.Run("Should accept a negotiated HTTP/2 connection", func(t *testing.T) {
+		ts := httptest.NewUnstartedServer(handler)
+		ts.EnableHTTP2 = true
+		ts.StartTLS()
+		defer ts.Close()
+
+		testURL, _ := url.Parse(ts.URL)
+		checker, err := NewHTTP(&HTTPConfig{URL: testURL, Protocol: HTTPProtocolHTTP2, Client: ts.Client()})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("Should error on unsupported protocols", func(t *testing.T) {
+		testURL, _ := url.Parse("http://google.com")
+		_, err := NewHTTP(&HTTPConfig{URL: testURL, Protocol: "spdy"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unsupported protocol 'spdy'"))
+	})
+
+	t.Run("Should require a transport for HTTP/3", func(t *testing.T) {
+		testURL, _ := url.Parse("https://google.com")
+		_, err := NewHTTP(&HTTPConfig{URL: testURL, Protocol: HTTPProtocolHTTP3})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("HTTP/3 capable round tripper"))
+	})
+}
+
+func TestHTTPRequestBody(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should render the payload template on every request", func(t *testing.T) {
+		var received []string
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			received = append(received, r.Method+" "+string(data))
+		}))
+		defer ts.Close()
+
+		testURL, _ := url.Parse(ts.URL)
+		checker, err := NewHTTP(&HTTPConfig{
+			URL:             testURL,
+			Method:          "put",
+			PayloadTemplate: `{"host": "{{.Hostname}}", "env": "{{.Vars.env}}", "ts": {{.Unix}}}`,
+			TemplateVars:    map[string]string{"env": "test"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		hostname, _ := os.Hostname()
+		Expect(received).To(HaveLen(1))
+		Expect(received[0]).To(HavePrefix(fmt.Sprintf(`PUT {"host": "%v", "env": "test", "ts": `, hostname)))
+	})
+
+	t.Run("Should send multipart uploads", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseMultipartForm(1024); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			f, _, err := r.FormFile("upload")
+			if err != nil || r.FormValue("name") != "canary" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			defer f.Close()
+
+			data, _ := ioutil.ReadAll(f)
+			w.Write(data)
+		}))
+		defer ts.Close()
+
+		testURL, _ := url.Parse(ts.URL)
+		checker, err := NewHTTP(&HTTPConfig{
+			URL:    testURL,
+			Method: "POST",
+			Expect: "file contents",
+			Multipart: &HTTPMultipart{
+				Fields: map[string]string{"name": "canary"},
+				Files:  []HTTPMultipartFile{{FieldName: "upload", FileName: "canary.txt", Content: []byte("file contents")}},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("Should error if more than one body is set", func(t *testing.T) {
+		testURL, _ := url.Parse("http://google.com")
+		_, err := NewHTTP(&HTTPConfig{URL: testURL, Payload: "foo", PayloadTemplate: "bar"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Only one of Payload, PayloadTemplate or Multipart can be set"))
+	})
+
+	t.Run("Should error on an invalid template", func(t *testing.T) {
+		testURL, _ := url.Parse("http://google.com")
+		_, err := NewHTTP(&HTTPConfig{URL: testURL, PayloadTemplate: "{{.Nope"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to parse payload template"))
+	})
+}
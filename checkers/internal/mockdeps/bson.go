@@ -0,0 +1,294 @@
+package mockdeps
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// bsonDoc is an ordered BSON document; only the subset of BSON types that is
+// exchanged with the mongo checker is supported: doubles, strings, documents,
+// arrays, binary data, object IDs, booleans, dates, null, 32/64-bit integers
+// and timestamps.
+type bsonDoc []bsonElem
+
+type bsonElem struct {
+	Key   string
+	Value interface{}
+}
+
+// bsonTimestamp is a BSON timestamp (ie. "$clusterTime" of OP_MSG requests)
+type bsonTimestamp uint64
+
+// bsonObjectID is a BSON object ID
+type bsonObjectID [12]byte
+
+// returns the value of the key
+func (d bsonDoc) get(key string) (interface{}, bool) {
+	for _, e := range d {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// encodes the document
+func encodeBSON(doc bsonDoc) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// the length is filled in below
+	buf.Write([]byte{0, 0, 0, 0})
+
+	for _, e := range doc {
+		if err := encodeBSONElem(&buf, e.Key, e.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte(0)
+
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data, uint32(len(data)))
+
+	return data, nil
+}
+
+func encodeBSONElem(buf *bytes.Buffer, key string, value interface{}) error {
+	writeCString := func(s string) {
+		buf.WriteString(s)
+		buf.WriteByte(0)
+	}
+
+	writeInt32 := func(n int32) {
+		binary.Write(buf, binary.LittleEndian, n)
+	}
+
+	writeInt64 := func(n int64) {
+		binary.Write(buf, binary.LittleEndian, n)
+	}
+
+	switch v := value.(type) {
+	case float64:
+		buf.WriteByte(0x01)
+		writeCString(key)
+		binary.Write(buf, binary.LittleEndian, math.Float64bits(v))
+	case string:
+		buf.WriteByte(0x02)
+		writeCString(key)
+		writeInt32(int32(len(v) + 1))
+		writeCString(v)
+	case bsonDoc:
+		buf.WriteByte(0x03)
+		writeCString(key)
+
+		data, err := encodeBSON(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	case []interface{}:
+		buf.WriteByte(0x04)
+		writeCString(key)
+
+		arr := make(bsonDoc, 0, len(v))
+		for i, item := range v {
+			arr = append(arr, bsonElem{Key: fmt.Sprint(i), Value: item})
+		}
+
+		data, err := encodeBSON(arr)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	case []byte:
+		buf.WriteByte(0x05)
+		writeCString(key)
+		writeInt32(int32(len(v)))
+		buf.WriteByte(0x00) // generic subtype
+		buf.Write(v)
+	case bsonObjectID:
+		buf.WriteByte(0x07)
+		writeCString(key)
+		buf.Write(v[:])
+	case bool:
+		buf.WriteByte(0x08)
+		writeCString(key)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case time.Time:
+		buf.WriteByte(0x09)
+		writeCString(key)
+		writeInt64(v.UnixNano() / int64(time.Millisecond))
+	case nil:
+		buf.WriteByte(0x0A)
+		writeCString(key)
+	case int32:
+		buf.WriteByte(0x10)
+		writeCString(key)
+		writeInt32(v)
+	case int:
+		buf.WriteByte(0x10)
+		writeCString(key)
+		writeInt32(int32(v))
+	case bsonTimestamp:
+		buf.WriteByte(0x11)
+		writeCString(key)
+		binary.Write(buf, binary.LittleEndian, uint64(v))
+	case int64:
+		buf.WriteByte(0x12)
+		writeCString(key)
+		writeInt64(v)
+	default:
+		return fmt.Errorf("Unsupported BSON value %T for '%v'", value, key)
+	}
+
+	return nil
+}
+
+// decodes the document at the start of "data"; returns the document and its
+// length in bytes
+func decodeBSON(data []byte) (bsonDoc, int, error) {
+	if len(data) < 5 {
+		return nil, 0, fmt.Errorf("BSON document is too short")
+	}
+
+	size := int(int32(binary.LittleEndian.Uint32(data)))
+	if size < 5 || size > len(data) || data[size-1] != 0 {
+		return nil, 0, fmt.Errorf("Invalid BSON document length %d", size)
+	}
+
+	doc := make(bsonDoc, 0)
+	pos := 4
+
+	for pos < size-1 {
+		typ := data[pos]
+		pos++
+
+		key, n, err := readCString(data[pos:size])
+		if err != nil {
+			return nil, 0, err
+		}
+		pos += n
+
+		value, n, err := decodeBSONValue(typ, data[pos:size-1])
+		if err != nil {
+			return nil, 0, fmt.Errorf("Unable to decode '%v': %v", key, err)
+		}
+		pos += n
+
+		doc = append(doc, bsonElem{Key: key, Value: value})
+	}
+
+	return doc, size, nil
+}
+
+// decodes a single value of the given type; returns the value and its length
+// in bytes
+func decodeBSONValue(typ byte, data []byte) (interface{}, int, error) {
+	need := func(n int) error {
+		if len(data) < n {
+			return fmt.Errorf("Value is too short")
+		}
+		return nil
+	}
+
+	switch typ {
+	case 0x01:
+		if err := need(8); err != nil {
+			return nil, 0, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), 8, nil
+	case 0x02:
+		if err := need(4); err != nil {
+			return nil, 0, err
+		}
+
+		n := int(int32(binary.LittleEndian.Uint32(data)))
+		if n < 1 || need(4+n) != nil {
+			return nil, 0, fmt.Errorf("Invalid string length %d", n)
+		}
+		return string(data[4 : 4+n-1]), 4 + n, nil
+	case 0x03, 0x04:
+		doc, n, err := decodeBSON(data)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if typ == 0x03 {
+			return doc, n, nil
+		}
+
+		arr := make([]interface{}, 0, len(doc))
+		for _, e := range doc {
+			arr = append(arr, e.Value)
+		}
+		return arr, n, nil
+	case 0x05:
+		if err := need(5); err != nil {
+			return nil, 0, err
+		}
+
+		n := int(int32(binary.LittleEndian.Uint32(data)))
+		if n < 0 || need(5+n) != nil {
+			return nil, 0, fmt.Errorf("Invalid binary length %d", n)
+		}
+		return append([]byte(nil), data[5:5+n]...), 5 + n, nil
+	case 0x07:
+		if err := need(12); err != nil {
+			return nil, 0, err
+		}
+
+		var id bsonObjectID
+		copy(id[:], data)
+		return id, 12, nil
+	case 0x08:
+		if err := need(1); err != nil {
+			return nil, 0, err
+		}
+		return data[0] != 0, 1, nil
+	case 0x09:
+		if err := need(8); err != nil {
+			return nil, 0, err
+		}
+
+		ms := int64(binary.LittleEndian.Uint64(data))
+		return time.Unix(0, ms*int64(time.Millisecond)), 8, nil
+	case 0x0A:
+		return nil, 0, nil
+	case 0x10:
+		if err := need(4); err != nil {
+			return nil, 0, err
+		}
+		return int32(binary.LittleEndian.Uint32(data)), 4, nil
+	case 0x11:
+		if err := need(8); err != nil {
+			return nil, 0, err
+		}
+		return bsonTimestamp(binary.LittleEndian.Uint64(data)), 8, nil
+	case 0x12:
+		if err := need(8); err != nil {
+			return nil, 0, err
+		}
+		return int64(binary.LittleEndian.Uint64(data)), 8, nil
+	}
+
+	return nil, 0, fmt.Errorf("Unsupported BSON type 0x%02x", typ)
+}
+
+// reads a null-terminated string; returns the string and its length in bytes
+// (including the terminator)
+func readCString(data []byte) (string, int, error) {
+	i := bytes.IndexByte(data, 0)
+	if i < 0 {
+		return "", 0, fmt.Errorf("Unterminated BSON string")
+	}
+
+	return string(data[:i]), i + 1, nil
+}
@@ -0,0 +1,71 @@
+package mockdeps
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+)
+
+// HTTP is an HTTP server that responds to every request with a configurable
+// status code and body (200 and "ok" by default).
+type HTTP struct {
+	server *httptest.Server
+
+	status   int
+	body     string
+	lock     sync.Mutex
+	requests int64
+}
+
+// NewHTTP starts an HTTP server.
+func NewHTTP() *HTTP {
+	h := &HTTP{
+		status: http.StatusOK,
+		body:   "ok",
+	}
+
+	h.server = httptest.NewServer(http.HandlerFunc(h.serve))
+
+	return h
+}
+
+func (h *HTTP) serve(rw http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&h.requests, 1)
+
+	h.lock.Lock()
+	status, body := h.status, h.body
+	h.lock.Unlock()
+
+	rw.WriteHeader(status)
+	rw.Write([]byte(body))
+}
+
+// SetResponse changes the status code and body of all following responses
+func (h *HTTP) SetResponse(status int, body string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.status, h.body = status, body
+}
+
+// Requests returns the number of requests served so far
+func (h *HTTP) Requests() int {
+	return int(atomic.LoadInt64(&h.requests))
+}
+
+// URL returns the base URL of the server, ie. "http://127.0.0.1:1234"
+func (h *HTTP) URL() string {
+	return h.server.URL
+}
+
+// Addr returns the "host:port" the server listens on
+func (h *HTTP) Addr() string {
+	return h.server.Listener.Addr().String()
+}
+
+// Close stops the server
+func (h *HTTP) Close() error {
+	h.server.Close()
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package mockdeps contains lightweight, in-process fakes of the dependencies
+// of the bundled checkers (a TCP echo server, an HTTP server, a Redis server
+// speaking a subset of RESP and a Mongo wire protocol responder), so that the
+// checker tests neither require Docker nor a locally running database.
+//
+// All servers listen on a random port of the loopback interface; use "Addr()"
+// to point a checker at them and "Close()" to shut them down.
+package mockdeps
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// server accepts connections and serves each of them in its own goroutine
+type server struct {
+	listener net.Listener
+	handle   func(conn net.Conn)
+
+	conns map[net.Conn]struct{}
+	lock  sync.Mutex
+	wg    sync.WaitGroup
+}
+
+// starts a server that passes every accepted connection to "handle"; the
+// connection is closed once "handle" returns
+func newServer(handle func(conn net.Conn)) (*server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to listen: %v", err)
+	}
+
+	s := &server{
+		listener: listener,
+		handle:   handle,
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.accept()
+
+	return s, nil
+}
+
+func (s *server) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.lock.Lock()
+		s.conns[conn] = struct{}{}
+		s.lock.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.forget(conn)
+
+			s.handle(conn)
+		}()
+	}
+}
+
+// closes the connection and stops tracking it
+func (s *server) forget(conn net.Conn) {
+	conn.Close()
+
+	s.lock.Lock()
+	delete(s.conns, conn)
+	s.lock.Unlock()
+}
+
+// Addr returns the "host:port" the server listens on
+func (s *server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Disconnect closes all open connections; the server keeps accepting new ones
+func (s *server) Disconnect() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// Close stops the server, closes all open connections and waits for their
+// handlers to return
+func (s *server) Close() error {
+	err := s.listener.Close()
+
+	s.lock.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+
+	return err
+}
@@ -0,0 +1,300 @@
+package mockdeps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	. "github.com/onsi/gomega"
+)
+
+func TestTCPEcho(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, err := NewTCPEcho()
+	Expect(err).ToNot(HaveOccurred())
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Addr())
+	Expect(err).ToNot(HaveOccurred())
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello\n"))
+	Expect(err).ToNot(HaveOccurred())
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	Expect(err).ToNot(HaveOccurred())
+	Expect(line).To(Equal("hello\n"))
+
+	t.Run("Should close open connections on close", func(t *testing.T) {
+		Expect(server.Close()).To(Succeed())
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, err := conn.Read(make([]byte, 1))
+		Expect(err).To(Equal(io.EOF))
+
+		_, err = net.Dial("tcp", server.Addr())
+		Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	RegisterTestingT(t)
+
+	server := NewHTTP()
+	defer server.Close()
+
+	get := func() (int, string) {
+		res, err := http.Get(server.URL())
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+
+		body, _ := ioutil.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	status, body := get()
+	Expect(status).To(Equal(http.StatusOK))
+	Expect(body).To(Equal("ok"))
+
+	server.SetResponse(http.StatusServiceUnavailable, "down")
+
+	status, body = get()
+	Expect(status).To(Equal(http.StatusServiceUnavailable))
+	Expect(body).To(Equal("down"))
+	Expect(server.Requests()).To(Equal(2))
+}
+
+func TestRedis(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should serve the supported commands", func(t *testing.T) {
+		server, err := NewRedis()
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		client := redis.NewClient(&redis.Options{Addr: server.Addr(), DB: 2})
+		defer client.Close()
+
+		Expect(client.Ping().Val()).To(Equal("PONG"))
+		Expect(client.Echo("hi").Val()).To(Equal("hi"))
+
+		Expect(client.Set("foo", "bar", time.Minute).Err()).ToNot(HaveOccurred())
+		Expect(client.Get("foo").Val()).To(Equal("bar"))
+		Expect(client.TTL("foo").Val()).To(BeNumerically("~", time.Minute, time.Second))
+		Expect(client.SetNX("foo", "baz", 0).Val()).To(BeFalse())
+		Expect(client.SetXX("missing", "baz", 0).Val()).To(BeFalse())
+		Expect(client.Exists("foo", "missing").Val()).To(Equal(int64(1)))
+		Expect(client.Del("foo").Val()).To(Equal(int64(1)))
+
+		_, err = client.Get("foo").Result()
+		Expect(err).To(Equal(redis.Nil))
+
+		Expect(client.Set("short", "lived", time.Millisecond).Err()).ToNot(HaveOccurred())
+		time.Sleep(5 * time.Millisecond)
+		Expect(client.Exists("short").Val()).To(BeZero())
+
+		server.Set(2, "seeded", "value")
+		Expect(client.Get("seeded").Val()).To(Equal("value"))
+
+		_, ok := server.Get(0, "seeded")
+		Expect(ok).To(BeFalse())
+
+		err = client.Do("FLUSHALL").Err()
+		Expect(err).To(MatchError("ERR unknown command 'FLUSHALL'"))
+
+		Expect(server.Commands()).To(ContainElement("SELECT"))
+	})
+
+	t.Run("Should require a password if set", func(t *testing.T) {
+		server, err := NewRedis()
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		server.RequirePassword("secret")
+
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer client.Close()
+
+		Expect(client.Ping().Err()).To(MatchError("NOAUTH Authentication required."))
+
+		client = redis.NewClient(&redis.Options{Addr: server.Addr(), Password: "wrong"})
+		defer client.Close()
+
+		Expect(client.Ping().Err()).To(MatchError("ERR invalid password"))
+
+		client = redis.NewClient(&redis.Options{Addr: server.Addr(), Password: "secret"})
+		defer client.Close()
+
+		Expect(client.Ping().Err()).ToNot(HaveOccurred())
+	})
+
+	t.Run("Should drop open connections on Disconnect", func(t *testing.T) {
+		server, err := NewRedis()
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer client.Close()
+
+		Expect(client.Ping().Err()).ToNot(HaveOccurred())
+
+		server.Disconnect()
+
+		// the pooled connection is broken; the client reconnects afterwards
+		Eventually(func() error { return client.Ping().Err() }).Should(Succeed())
+		Expect(client.PoolStats().Misses).To(BeNumerically(">=", 2))
+	})
+}
+
+func TestMongo(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, err := NewMongo()
+	Expect(err).ToNot(HaveOccurred())
+	defer server.Close()
+
+	server.SetVersion("4.0.3")
+	server.AddCollection("db", "users")
+
+	conn, err := net.Dial("tcp", server.Addr())
+	Expect(err).ToNot(HaveOccurred())
+	defer conn.Close()
+
+	t.Run("Should answer OP_QUERY commands", func(t *testing.T) {
+		reply := roundTripQuery(conn, "admin.$cmd", bsonDoc{{"ismaster", int32(1)}})
+		Expect(field(reply, "ismaster")).To(BeTrue())
+		Expect(field(reply, "maxWireVersion")).To(Equal(int32(mongoMaxWireVersion)))
+
+		reply = roundTripQuery(conn, "admin.$cmd", bsonDoc{{"$query", bsonDoc{{"buildInfo", int32(1)}}}})
+		Expect(field(reply, "version")).To(Equal("4.0.3"))
+		Expect(field(reply, "versionArray")).To(Equal([]interface{}{int32(4), int32(0), int32(3), int32(0)}))
+
+		reply = roundTripQuery(conn, "db.$cmd", bsonDoc{{"listCollections", int32(1)}})
+		cursor, _ := reply.get("cursor")
+		batch, _ := cursor.(bsonDoc).get("firstBatch")
+		Expect(batch).To(HaveLen(1))
+		Expect(field(batch.([]interface{})[0].(bsonDoc), "name")).To(Equal("users"))
+	})
+
+	t.Run("Should answer OP_MSG commands", func(t *testing.T) {
+		reply := roundTripMsg(conn, bsonDoc{{"ping", int32(1)}, {"$db", "admin"}})
+		Expect(field(reply, "ok")).To(Equal(1.0))
+
+		reply = roundTripMsg(conn, bsonDoc{{"dropDatabase", int32(1)}, {"$db", "db"}})
+		Expect(field(reply, "ok")).To(Equal(0.0))
+		Expect(field(reply, "codeName")).To(Equal("CommandNotFound"))
+	})
+
+	Expect(server.Commands()).To(Equal([]string{"ismaster", "buildInfo", "listCollections", "ping", "dropDatabase"}))
+}
+
+func TestBSON(t *testing.T) {
+	RegisterTestingT(t)
+
+	doc := bsonDoc{
+		{"double", 1.5},
+		{"string", "foo"},
+		{"doc", bsonDoc{{"nested", true}}},
+		{"array", []interface{}{"a", int32(1)}},
+		{"binary", []byte{1, 2, 3}},
+		{"id", bsonObjectID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+		{"date", time.Unix(1500000000, 0)},
+		{"null", nil},
+		{"int32", int32(-1)},
+		{"timestamp", bsonTimestamp(42)},
+		{"int64", int64(1) << 40},
+	}
+
+	data, err := encodeBSON(doc)
+	Expect(err).ToNot(HaveOccurred())
+
+	decoded, n, err := decodeBSON(data)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(n).To(Equal(len(data)))
+	Expect(decoded).To(Equal(doc))
+
+	_, _, err = decodeBSON(data[:len(data)-1])
+	Expect(err).To(HaveOccurred())
+
+	_, err = encodeBSON(bsonDoc{{"foo", struct{}{}}})
+	Expect(err).To(MatchError("Unsupported BSON value struct {} for 'foo'"))
+}
+
+// returns the value of the key
+func field(doc bsonDoc, key string) interface{} {
+	v, _ := doc.get(key)
+	return v
+}
+
+// sends an OP_QUERY and returns the first document of the OP_REPLY
+func roundTripQuery(conn net.Conn, collection string, query bsonDoc) bsonDoc {
+	data, err := encodeBSON(query)
+	Expect(err).ToNot(HaveOccurred())
+
+	var body bytes.Buffer
+	binary.Write(&body, binary.LittleEndian, int32(0))
+	body.WriteString(collection)
+	body.WriteByte(0)
+	binary.Write(&body, binary.LittleEndian, int32(0))
+	binary.Write(&body, binary.LittleEndian, int32(-1))
+	body.Write(data)
+
+	reply := roundTrip(conn, mongoOpQuery, body.Bytes(), mongoOpReply)
+	Expect(binary.LittleEndian.Uint32(reply[16:])).To(Equal(uint32(1)))
+
+	doc, _, err := decodeBSON(reply[20:])
+	Expect(err).ToNot(HaveOccurred())
+
+	return doc
+}
+
+// sends an OP_MSG and returns the body of the reply
+func roundTripMsg(conn net.Conn, cmd bsonDoc) bsonDoc {
+	data, err := encodeBSON(cmd)
+	Expect(err).ToNot(HaveOccurred())
+
+	var body bytes.Buffer
+	binary.Write(&body, binary.LittleEndian, uint32(0))
+	body.WriteByte(0)
+	body.Write(data)
+
+	reply := roundTrip(conn, mongoOpMsg, body.Bytes(), mongoOpMsg)
+	Expect(reply[4]).To(Equal(byte(0)))
+
+	doc, _, err := decodeBSON(reply[5:])
+	Expect(err).ToNot(HaveOccurred())
+
+	return doc
+}
+
+// sends a message; returns the body of the reply
+func roundTrip(conn net.Conn, opCode int32, body []byte, replyOpCode int32) []byte {
+	err := binary.Write(conn, binary.LittleEndian, mongoHeader{
+		Length:    int32(16 + len(body)),
+		RequestID: 7,
+		OpCode:    opCode,
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	_, err = conn.Write(body)
+	Expect(err).ToNot(HaveOccurred())
+
+	var header mongoHeader
+	Expect(binary.Read(conn, binary.LittleEndian, &header)).To(Succeed())
+	Expect(header.ResponseTo).To(Equal(int32(7)))
+	Expect(header.OpCode).To(Equal(replyOpCode))
+
+	reply := make([]byte, header.Length-16)
+	_, err = io.ReadFull(conn, reply)
+	Expect(err).ToNot(HaveOccurred())
+
+	return reply
+}
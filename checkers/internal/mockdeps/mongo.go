@@ -0,0 +1,339 @@
+package mockdeps
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// wire protocol opcodes
+const (
+	mongoOpReply = 1
+	mongoOpQuery = 2004
+	mongoOpMsg   = 2013
+)
+
+// the highest wire protocol version reported by the server (MongoDB 3.4), so
+// that legacy drivers keep using OP_QUERY
+const mongoMaxWireVersion = 5
+
+// Mongo is a server that speaks enough of the mongo wire protocol (OP_QUERY
+// and OP_MSG) to answer the commands issued by the mongo checker:
+// "isMaster"/"hello", "ping", "buildInfo" and "listCollections". Other
+// commands fail with "CommandNotFound".
+type Mongo struct {
+	*server
+
+	version     string
+	collections map[string][]string // by database
+	commands    []string
+	lock        sync.Mutex
+
+	requestID int32
+}
+
+// NewMongo starts a mongo server; it reports version "3.4.0" by default.
+func NewMongo() (*Mongo, error) {
+	m := &Mongo{
+		version:     "3.4.0",
+		collections: make(map[string][]string),
+	}
+
+	s, err := newServer(m.serve)
+	if err != nil {
+		return nil, err
+	}
+
+	m.server = s
+
+	return m, nil
+}
+
+// SetVersion changes the version reported by "buildInfo"
+func (m *Mongo) SetVersion(version string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.version = version
+}
+
+// AddCollection adds a collection that is reported by "listCollections"
+func (m *Mongo) AddCollection(db, name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.collections[db] = append(m.collections[db], name)
+}
+
+// Commands returns the names of all commands received so far
+func (m *Mongo) Commands() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]string(nil), m.commands...)
+}
+
+// mongoHeader is the header of every wire protocol message
+type mongoHeader struct {
+	Length     int32
+	RequestID  int32
+	ResponseTo int32
+	OpCode     int32
+}
+
+func (m *Mongo) serve(conn net.Conn) {
+	for {
+		var header mongoHeader
+		if err := binary.Read(conn, binary.LittleEndian, &header); err != nil {
+			return
+		}
+
+		if header.Length < 16 {
+			return
+		}
+
+		body := make([]byte, header.Length-16)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+
+		var reply []byte
+		var err error
+
+		switch header.OpCode {
+		case mongoOpQuery:
+			reply, err = m.handleQuery(header, body)
+		case mongoOpMsg:
+			reply, err = m.handleMsg(header, body)
+		default:
+			// ie. OP_KILL_CURSORS, which has no reply
+			continue
+		}
+
+		if err != nil {
+			return
+		}
+
+		if _, err := conn.Write(reply); err != nil {
+			return
+		}
+	}
+}
+
+// answers an OP_QUERY with an OP_REPLY
+func (m *Mongo) handleQuery(header mongoHeader, body []byte) ([]byte, error) {
+	if len(body) < 4 {
+		return nil, fmt.Errorf("OP_QUERY is too short")
+	}
+
+	collection, n, err := readCString(body[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	// skip the flags, the collection name, "numberToSkip" and "numberToReturn"
+	pos := 4 + n + 8
+	if pos > len(body) {
+		return nil, fmt.Errorf("OP_QUERY is too short")
+	}
+
+	query, _, err := decodeBSON(body[pos:])
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]bsonDoc, 0, 1)
+
+	// only commands are answered; regular queries return no documents
+	if strings.HasSuffix(collection, ".$cmd") {
+		// the command may be wrapped, ie. along with a read preference
+		if wrapped, ok := query.get("$query"); ok {
+			if doc, ok := wrapped.(bsonDoc); ok {
+				query = doc
+			}
+		}
+
+		docs = append(docs, m.command(strings.TrimSuffix(collection, ".$cmd"), query))
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, int32(0)) // response flags
+	binary.Write(&buf, binary.LittleEndian, int64(0)) // cursor ID
+	binary.Write(&buf, binary.LittleEndian, int32(0)) // starting from
+	binary.Write(&buf, binary.LittleEndian, int32(len(docs)))
+
+	for _, doc := range docs {
+		data, err := encodeBSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+
+	return m.message(header, mongoOpReply, buf.Bytes()), nil
+}
+
+// answers an OP_MSG with an OP_MSG
+func (m *Mongo) handleMsg(header mongoHeader, body []byte) ([]byte, error) {
+	if len(body) < 5 {
+		return nil, fmt.Errorf("OP_MSG is too short")
+	}
+
+	flags := binary.LittleEndian.Uint32(body)
+	end := len(body)
+
+	// the checksum is not verified
+	if flags&1 != 0 {
+		end -= 4
+	}
+
+	var cmd bsonDoc
+
+	for pos := 4; pos < end; {
+		kind := body[pos]
+		pos++
+
+		switch kind {
+		case 0:
+			doc, n, err := decodeBSON(body[pos:end])
+			if err != nil {
+				return nil, err
+			}
+
+			cmd = doc
+			pos += n
+		case 1:
+			// document sequences (ie. of inserts) are skipped
+			if pos+4 > end {
+				return nil, fmt.Errorf("OP_MSG is too short")
+			}
+
+			pos += int(int32(binary.LittleEndian.Uint32(body[pos:])))
+		default:
+			return nil, fmt.Errorf("Unsupported OP_MSG section kind %d", kind)
+		}
+	}
+
+	if cmd == nil {
+		return nil, fmt.Errorf("OP_MSG without body")
+	}
+
+	db, _ := cmd.get("$db")
+	dbName, _ := db.(string)
+
+	data, err := encodeBSON(m.command(dbName, cmd))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // flags
+	buf.WriteByte(0)                                   // body section
+	buf.Write(data)
+
+	return m.message(header, mongoOpMsg, buf.Bytes()), nil
+}
+
+// prepends the header of a reply to the request
+func (m *Mongo) message(request mongoHeader, opCode int32, body []byte) []byte {
+	m.lock.Lock()
+	m.requestID++
+	id := m.requestID
+	m.lock.Unlock()
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, mongoHeader{
+		Length:     int32(16 + len(body)),
+		RequestID:  id,
+		ResponseTo: request.RequestID,
+		OpCode:     opCode,
+	})
+	buf.Write(body)
+
+	return buf.Bytes()
+}
+
+// executes the command against the given database; returns the reply
+func (m *Mongo) command(db string, cmd bsonDoc) bsonDoc {
+	if len(cmd) == 0 {
+		return mongoError("Empty command", 59, "CommandNotFound")
+	}
+
+	name := cmd[0].Key
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.commands = append(m.commands, name)
+
+	switch strings.ToLower(name) {
+	case "ismaster", "hello":
+		return bsonDoc{
+			{"ismaster", true},
+			{"isWritablePrimary", true},
+			{"maxBsonObjectSize", int32(16 * 1024 * 1024)},
+			{"maxMessageSizeBytes", int32(48000000)},
+			{"maxWriteBatchSize", int32(100000)},
+			{"localTime", time.Now()},
+			{"minWireVersion", int32(0)},
+			{"maxWireVersion", int32(mongoMaxWireVersion)},
+			{"ok", 1.0},
+		}
+
+	case "ping":
+		return bsonDoc{{"ok", 1.0}}
+
+	case "buildinfo":
+		versionArray := make([]interface{}, 0, 4)
+		for _, part := range strings.Split(m.version, ".") {
+			n, _ := strconv.Atoi(part)
+			versionArray = append(versionArray, int32(n))
+		}
+
+		for len(versionArray) < 4 {
+			versionArray = append(versionArray, int32(0))
+		}
+
+		return bsonDoc{
+			{"version", m.version},
+			{"versionArray", versionArray},
+			{"ok", 1.0},
+		}
+
+	case "listcollections":
+		batch := make([]interface{}, 0, len(m.collections[db]))
+		for _, name := range m.collections[db] {
+			batch = append(batch, bsonDoc{
+				{"name", name},
+				{"type", "collection"},
+				{"options", bsonDoc{}},
+			})
+		}
+
+		return bsonDoc{
+			{"cursor", bsonDoc{
+				{"id", int64(0)},
+				{"ns", db + ".$cmd.listCollections"},
+				{"firstBatch", batch},
+			}},
+			{"ok", 1.0},
+		}
+	}
+
+	return mongoError(fmt.Sprintf("no such command: '%v'", name), 59, "CommandNotFound")
+}
+
+func mongoError(msg string, code int32, codeName string) bsonDoc {
+	return bsonDoc{
+		{"ok", 0.0},
+		{"errmsg", msg},
+		{"code", code},
+		{"codeName", codeName},
+	}
+}
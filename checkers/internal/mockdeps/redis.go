@@ -0,0 +1,361 @@
+package mockdeps
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// number of databases of the redis server
+const redisDatabases = 16
+
+// Redis is a redis server that speaks the subset of RESP used by the redis
+// checker: "PING", "ECHO", "AUTH", "SELECT", "SET" (with "EX", "PX", "NX" and
+// "XX"), "GET", "DEL", "EXISTS", "TTL", "PTTL" and "QUIT".
+type Redis struct {
+	*server
+
+	password string
+	dbs      [redisDatabases]map[string]*redisEntry
+	commands []string
+	lock     sync.Mutex
+}
+
+type redisEntry struct {
+	value   string
+	expires time.Time // zero if the key does not expire
+}
+
+// per-connection state
+type redisConn struct {
+	authenticated bool
+	db            int
+}
+
+// NewRedis starts a redis server.
+func NewRedis() (*Redis, error) {
+	r := &Redis{}
+	for i := range r.dbs {
+		r.dbs[i] = make(map[string]*redisEntry)
+	}
+
+	s, err := newServer(r.serve)
+	if err != nil {
+		return nil, err
+	}
+
+	r.server = s
+
+	return r, nil
+}
+
+// RequirePassword makes the server reject all commands (but "AUTH") of
+// connections that have not authenticated with the given password
+func (r *Redis) RequirePassword(password string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.password = password
+}
+
+// Set stores the key in the given database
+func (r *Redis) Set(db int, key, value string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.dbs[db][key] = &redisEntry{value: value}
+}
+
+// Get returns the (unexpired) value of the key in the given database
+func (r *Redis) Get(db int, key string) (string, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	e := r.lookup(db, key)
+	if e == nil {
+		return "", false
+	}
+
+	return e.value, true
+}
+
+// Commands returns the (upper-cased) names of all commands received so far
+func (r *Redis) Commands() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string(nil), r.commands...)
+}
+
+func (r *Redis) serve(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	state := &redisConn{}
+
+	for {
+		args, err := readRedisCommand(reader)
+		if err != nil {
+			return
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		reply, quit := r.exec(state, args)
+		if _, err := io.WriteString(conn, reply); err != nil || quit {
+			return
+		}
+	}
+}
+
+// executes the command; returns the encoded reply and whether the connection
+// should be closed
+func (r *Redis) exec(state *redisConn, args []string) (string, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cmd := strings.ToUpper(args[0])
+	r.commands = append(r.commands, cmd)
+
+	if cmd == "AUTH" {
+		// the username of "AUTH username password" is not checked
+		if len(args) != 2 && len(args) != 3 {
+			return redisArityError(cmd), false
+		}
+
+		if r.password == "" {
+			return redisError("ERR Client sent AUTH, but no password is set"), false
+		}
+
+		if args[len(args)-1] != r.password {
+			return redisError("ERR invalid password"), false
+		}
+
+		state.authenticated = true
+		return "+OK\r\n", false
+	}
+
+	if r.password != "" && !state.authenticated {
+		return redisError("NOAUTH Authentication required."), false
+	}
+
+	switch cmd {
+	case "PING":
+		if len(args) > 1 {
+			return redisBulk(args[1]), false
+		}
+		return "+PONG\r\n", false
+
+	case "ECHO":
+		if len(args) != 2 {
+			return redisArityError(cmd), false
+		}
+		return redisBulk(args[1]), false
+
+	case "SELECT":
+		if len(args) != 2 {
+			return redisArityError(cmd), false
+		}
+
+		db, err := strconv.Atoi(args[1])
+		if err != nil || db < 0 || db >= redisDatabases {
+			return redisError("ERR DB index is out of range"), false
+		}
+
+		state.db = db
+		return "+OK\r\n", false
+
+	case "SET":
+		return r.set(state.db, args), false
+
+	case "GET":
+		if len(args) != 2 {
+			return redisArityError(cmd), false
+		}
+
+		if e := r.lookup(state.db, args[1]); e != nil {
+			return redisBulk(e.value), false
+		}
+		return "$-1\r\n", false
+
+	case "DEL", "EXISTS":
+		if len(args) < 2 {
+			return redisArityError(cmd), false
+		}
+
+		n := 0
+		for _, key := range args[1:] {
+			if r.lookup(state.db, key) != nil {
+				n++
+
+				if cmd == "DEL" {
+					delete(r.dbs[state.db], key)
+				}
+			}
+		}
+		return redisInt(int64(n)), false
+
+	case "TTL", "PTTL":
+		if len(args) != 2 {
+			return redisArityError(cmd), false
+		}
+
+		e := r.lookup(state.db, args[1])
+		if e == nil {
+			return redisInt(-2), false
+		}
+
+		if e.expires.IsZero() {
+			return redisInt(-1), false
+		}
+
+		unit := time.Second
+		if cmd == "PTTL" {
+			unit = time.Millisecond
+		}
+		return redisInt(int64(time.Until(e.expires) / unit)), false
+
+	case "QUIT":
+		return "+OK\r\n", true
+	}
+
+	return redisError(fmt.Sprintf("ERR unknown command '%v'", args[0])), false
+}
+
+// executes "SET key value [EX seconds|PX milliseconds] [NX|XX]"; the caller
+// must hold the lock
+func (r *Redis) set(db int, args []string) string {
+	if len(args) < 3 {
+		return redisArityError("SET")
+	}
+
+	key, entry := args[1], &redisEntry{value: args[2]}
+	nx, xx := false, false
+
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); opt {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				return redisError("ERR syntax error")
+			}
+
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return redisError("ERR invalid expire time in set")
+			}
+
+			unit := time.Second
+			if opt == "PX" {
+				unit = time.Millisecond
+			}
+
+			entry.expires = time.Now().Add(time.Duration(n) * unit)
+			i++
+		default:
+			return redisError("ERR syntax error")
+		}
+	}
+
+	exists := r.lookup(db, key) != nil
+	if (nx && exists) || (xx && !exists) {
+		return "$-1\r\n"
+	}
+
+	r.dbs[db][key] = entry
+
+	return "+OK\r\n"
+}
+
+// returns the unexpired entry of the key (or nil); the caller must hold the
+// lock
+func (r *Redis) lookup(db int, key string) *redisEntry {
+	e, ok := r.dbs[db][key]
+	if !ok {
+		return nil
+	}
+
+	if !e.expires.IsZero() && !time.Now().Before(e.expires) {
+		delete(r.dbs[db], key)
+		return nil
+	}
+
+	return e
+}
+
+// reads a command, either as RESP array of bulk strings or inline
+func readRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readRedisLine(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("Invalid multibulk length '%v'", line)
+	}
+
+	args := make([]string, 0, n)
+
+	for i := 0; i < n; i++ {
+		line, err := readRedisLine(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("Expected '$', got '%v'", line)
+		}
+
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("Invalid bulk length '%v'", line)
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+
+		args = append(args, string(buf[:size]))
+	}
+
+	return args, nil
+}
+
+func readRedisLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func redisBulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func redisInt(n int64) string {
+	return fmt.Sprintf(":%d\r\n", n)
+}
+
+func redisError(msg string) string {
+	return "-" + msg + "\r\n"
+}
+
+func redisArityError(cmd string) string {
+	return redisError(fmt.Sprintf("ERR wrong number of arguments for '%v' command", strings.ToLower(cmd)))
+}
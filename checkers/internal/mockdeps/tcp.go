@@ -0,0 +1,24 @@
+package mockdeps
+
+import (
+	"io"
+	"net"
+)
+
+// TCPEcho is a TCP server that writes back everything it receives, ie. for
+// the reachable checker.
+type TCPEcho struct {
+	*server
+}
+
+// NewTCPEcho starts a TCP echo server.
+func NewTCPEcho() (*TCPEcho, error) {
+	s, err := newServer(func(conn net.Conn) {
+		io.Copy(conn, conn)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TCPEcho{server: s}, nil
+}
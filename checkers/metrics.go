@@ -0,0 +1,45 @@
+package checkers
+
+// MetricsCollector can optionally be passed to the Mongo, Redis and HTTP
+// checkers (via their configs) to receive dependency-specific gauges (ie.
+// connection pool size, number of live servers or response size) on every
+// check. Implement it as an adapter for whatever metrics library is in use.
+type MetricsCollector interface {
+	Gauge(name string, value float64, tags map[string]string)
+}
+
+// MetricsCollectorFunc is an adapter that allows the use of an ordinary
+// function as a "MetricsCollector".
+type MetricsCollectorFunc func(name string, value float64, tags map[string]string)
+
+// Gauge calls f(name, value, tags).
+func (f MetricsCollectorFunc) Gauge(name string, value float64, tags map[string]string) {
+	f(name, value, tags)
+}
+
+const (
+	// MetricMongoLiveServers is the number of mongo servers the session is connected to
+	MetricMongoLiveServers = "mongo_live_servers"
+
+	// MetricMongoSocketsAlive is the number of sockets in the mongo connection pool
+	MetricMongoSocketsAlive = "mongo_pool_sockets_alive"
+
+	// MetricMongoSocketsInUse is the number of mongo connection pool sockets in use
+	MetricMongoSocketsInUse = "mongo_pool_sockets_in_use"
+
+	// MetricRedisTotalConns is the number of connections in the redis connection pool
+	MetricRedisTotalConns = "redis_pool_total_conns"
+
+	// MetricRedisIdleConns is the number of idle connections in the redis connection pool
+	MetricRedisIdleConns = "redis_pool_idle_conns"
+
+	// MetricRedisPoolTimeouts is the number of redis connection pool timeouts
+	MetricRedisPoolTimeouts = "redis_pool_timeouts"
+
+	// MetricHTTPStatusCode is the status code returned by the HTTP dependency
+	MetricHTTPStatusCode = "http_status_code"
+
+	// MetricHTTPResponseSize is the size of the response body (in bytes)
+	// returned by the HTTP dependency
+	MetricHTTPResponseSize = "http_response_size_bytes"
+)
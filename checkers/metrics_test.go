@@ -0,0 +1,104 @@
+package checkers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/globalsign/mgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeMetricsCollector struct {
+	sync.Mutex
+	gauges map[string]float64
+	tags   map[string]map[string]string
+}
+
+func newFakeMetricsCollector() *fakeMetricsCollector {
+	return &fakeMetricsCollector{
+		gauges: map[string]float64{},
+		tags:   map[string]map[string]string{},
+	}
+}
+
+func (f *fakeMetricsCollector) Gauge(name string, value float64, tags map[string]string) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.gauges[name] = value
+	f.tags[name] = tags
+}
+
+func TestMetricsCollectorFunc(t *testing.T) {
+	RegisterTestingT(t)
+
+	var got string
+	var c MetricsCollector = MetricsCollectorFunc(func(name string, value float64, tags map[string]string) {
+		got = name
+	})
+
+	c.Gauge("foo", 1, nil)
+	Expect(got).To(Equal("foo"))
+}
+
+func TestHTTPMetrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer ts.Close()
+
+	testURL, _ := url.Parse(ts.URL)
+	collector := newFakeMetricsCollector()
+
+	checker, err := NewHTTP(&HTTPConfig{URL: testURL, Expect: "world", Metrics: collector})
+	Expect(err).ToNot(HaveOccurred())
+
+	_, err = checker.Status()
+	Expect(err).ToNot(HaveOccurred(), "body should still be readable for the Expect check")
+
+	Expect(collector.gauges[MetricHTTPStatusCode]).To(Equal(float64(200)))
+	Expect(collector.gauges[MetricHTTPResponseSize]).To(Equal(float64(11)))
+	Expect(collector.tags[MetricHTTPResponseSize]).To(HaveKeyWithValue("url", ts.URL))
+}
+
+func TestRedisMetrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	collector := newFakeMetricsCollector()
+
+	checker, server, err := setupRedis(&RedisConfig{Ping: true, Metrics: collector})
+	Expect(err).ToNot(HaveOccurred())
+	defer server.Close()
+
+	_, err = checker.Status()
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(collector.gauges).To(HaveKeyWithValue(MetricRedisTotalConns, float64(1)))
+	Expect(collector.gauges).To(HaveKey(MetricRedisIdleConns))
+	Expect(collector.gauges).To(HaveKey(MetricRedisPoolTimeouts))
+	Expect(collector.tags[MetricRedisTotalConns]).To(HaveKeyWithValue("addr", server.Addr()))
+}
+
+func TestMongoMetrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	collector := newFakeMetricsCollector()
+
+	m := &Mongo{
+		Config: &MongoConfig{Metrics: collector},
+		poolStats: func() mgo.Stats {
+			return mgo.Stats{SocketsAlive: 5, SocketsInUse: 2}
+		},
+	}
+
+	_, err := m.Status()
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(collector.gauges).To(HaveKeyWithValue(MetricMongoSocketsAlive, float64(5)))
+	Expect(collector.gauges).To(HaveKeyWithValue(MetricMongoSocketsInUse, float64(2)))
+}
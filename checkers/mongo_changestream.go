@@ -0,0 +1,279 @@
+package checkers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	defaultMongoChangeStreamMaxAwait = time.Duration(1) * time.Second
+
+	// upper bound of the delay between re-opening streams that keep closing
+	// right away, unless "MaxInterval / 2" is longer
+	maxMongoChangeStreamBackoff = time.Duration(30) * time.Second
+)
+
+// MongoChangeStreamOptions contains attributes that alter the behavior of the
+// change stream liveness check. When enabled, the checker keeps a change
+// stream open on the given namespace in the background (resuming it if it
+// errors) and fails if nothing was received from it within "MaxInterval".
+// The stream is resumed after "MaxInterval / 2"; the delay doubles (up to 30s)
+// while it keeps closing before anything has been received.
+//
+// "DB" and "Collection" are _required_; the namespace to watch.
+//
+// "MaxInterval" is _required_; the maximum time allowed between two events
+// (or heartbeats).
+//
+// "RequireEvents" is optional; by default, empty batches returned by the
+// server (heartbeats) are enough to consider the stream alive; if set, only
+// actual change events count.
+//
+// "MaxAwaitTime" is optional and defaults to "1s"; how long the server waits
+// for new events before returning an empty batch.
+type MongoChangeStreamOptions struct {
+	DB            string
+	Collection    string
+	MaxInterval   time.Duration
+	RequireEvents bool
+	MaxAwaitTime  time.Duration
+}
+
+// MongoChangeStreamDetails contains the state of the change stream check.
+type MongoChangeStreamDetails struct {
+	LastEvent     time.Time `json:"last_event,omitempty"`
+	LastHeartbeat time.Time `json:"last_heartbeat,omitempty"`
+	Resumes       int       `json:"resumes"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// the subset of "*mgo.ChangeStream" used by the checker
+type mongoChangeStream interface {
+	Next(result interface{}) bool
+	Timeout() bool
+	Err() error
+	Close() error
+	ResumeToken() *bson.Raw
+}
+
+type mongoChangeStreamWatcher struct {
+	opts *MongoChangeStreamOptions
+	open func(resumeAfter *bson.Raw) (mongoChangeStream, error)
+
+	started       time.Time
+	lastEvent     time.Time
+	lastHeartbeat time.Time
+	lastError     error
+	resumes       int
+	lock          sync.Mutex
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newMongoChangeStreamWatcher(opts *MongoChangeStreamOptions, open func(*bson.Raw) (mongoChangeStream, error)) *mongoChangeStreamWatcher {
+	return &mongoChangeStreamWatcher{
+		opts:    opts,
+		open:    open,
+		started: time.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (m *Mongo) openChangeStream(resumeAfter *bson.Raw) (mongoChangeStream, error) {
+	opts := m.Config.ChangeStream
+
+	maxAwait := opts.MaxAwaitTime
+	if maxAwait == 0 {
+		maxAwait = defaultMongoChangeStreamMaxAwait
+	}
+
+	return m.Session.DB(opts.DB).C(opts.Collection).Watch([]bson.M{}, mgo.ChangeStreamOptions{
+		ResumeAfter:    resumeAfter,
+		MaxAwaitTimeMS: maxAwait,
+	})
+}
+
+// runs until "close()" is called; the stream is re-opened (and resumed) on
+// errors, after a delay that doubles while the stream cannot be opened or
+// keeps closing before anything has been received
+func (w *mongoChangeStreamWatcher) run() {
+	defer close(w.done)
+
+	var resumeToken *bson.Raw
+	failures := 0
+
+	for {
+		stream, err := w.open(resumeToken)
+		if err != nil {
+			w.recordError(err)
+
+			failures++
+			if !w.wait(w.backoff(failures)) {
+				return
+			}
+
+			continue
+		}
+
+		var received bool
+		resumeToken, received = w.consume(stream)
+		stream.Close()
+
+		if received {
+			failures = 0
+		}
+
+		failures++
+		if !w.wait(w.backoff(failures)) {
+			return
+		}
+
+		w.lock.Lock()
+		w.resumes++
+		w.lock.Unlock()
+	}
+}
+
+// returns the delay before re-opening the stream after the given number of
+// consecutive failures
+func (w *mongoChangeStreamWatcher) backoff(failures int) time.Duration {
+	delay := w.opts.MaxInterval / 2
+
+	limit := maxMongoChangeStreamBackoff
+	if delay > limit {
+		limit = delay
+	}
+
+	for i := 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+
+	if delay > limit {
+		delay = limit
+	}
+
+	return delay
+}
+
+// waits for the given duration; returns false if the watcher has been stopped
+// in the meantime
+func (w *mongoChangeStreamWatcher) wait(d time.Duration) bool {
+	select {
+	case <-w.stop:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// reads from the stream until it errors, closes or the watcher is stopped;
+// returns the token to resume from and whether anything (an event or a
+// heartbeat) has been received
+func (w *mongoChangeStreamWatcher) consume(stream mongoChangeStream) (*bson.Raw, bool) {
+	event := bson.M{}
+	received := false
+
+	for {
+		select {
+		case <-w.stop:
+			return stream.ResumeToken(), received
+		default:
+		}
+
+		if stream.Next(&event) {
+			w.lock.Lock()
+			w.lastEvent = time.Now()
+			w.lastHeartbeat = w.lastEvent
+			w.lastError = nil
+			w.lock.Unlock()
+
+			received = true
+			continue
+		}
+
+		if err := stream.Err(); err != nil {
+			w.recordError(err)
+			return stream.ResumeToken(), received
+		}
+
+		if stream.Timeout() {
+			w.lock.Lock()
+			w.lastHeartbeat = time.Now()
+			w.lastError = nil
+			w.lock.Unlock()
+
+			received = true
+			continue
+		}
+
+		// the stream was closed without an error
+		return stream.ResumeToken(), received
+	}
+}
+
+func (w *mongoChangeStreamWatcher) recordError(err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.lastError = err
+}
+
+func (w *mongoChangeStreamWatcher) status() (*MongoChangeStreamDetails, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	details := &MongoChangeStreamDetails{
+		LastEvent:     w.lastEvent,
+		LastHeartbeat: w.lastHeartbeat,
+		Resumes:       w.resumes,
+	}
+
+	if w.lastError != nil {
+		details.LastError = w.lastError.Error()
+	}
+
+	last := w.lastHeartbeat
+	kind := "heartbeat"
+	if w.opts.RequireEvents {
+		last = w.lastEvent
+		kind = "event"
+	}
+
+	// give the stream a chance to deliver something after startup
+	if last.IsZero() {
+		last = w.started
+	}
+
+	if elapsed := time.Since(last); elapsed > w.opts.MaxInterval {
+		if w.lastError != nil {
+			return details, fmt.Errorf("no change stream %v received in %v: %v", kind, elapsed.Round(time.Millisecond), w.lastError)
+		}
+		return details, fmt.Errorf("no change stream %v received in %v", kind, elapsed.Round(time.Millisecond))
+	}
+
+	return details, nil
+}
+
+// stops the watcher and waits for it; safe to call more than once
+func (w *mongoChangeStreamWatcher) close() {
+	w.closeOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+func validateMongoChangeStreamOptions(opts *MongoChangeStreamOptions) error {
+	v := &validation{}
+
+	v.check(opts.DB == "" || opts.Collection == "", "ChangeStream.Collection",
+		"If cfg.ChangeStream is used, cfg.ChangeStream.DB and cfg.ChangeStream.Collection must be set")
+
+	v.check(opts.MaxInterval <= 0, "ChangeStream.MaxInterval", "If cfg.ChangeStream is used, cfg.ChangeStream.MaxInterval must be set")
+
+	return v.err()
+}
@@ -0,0 +1,189 @@
+package checkers
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	. "github.com/onsi/gomega"
+)
+
+type fakeChangeStream struct {
+	sync.Mutex
+	events  int
+	err     error
+	timeout bool
+	closed  bool
+}
+
+func (f *fakeChangeStream) Next(result interface{}) bool {
+	time.Sleep(time.Millisecond)
+
+	f.Lock()
+	defer f.Unlock()
+
+	if f.events > 0 {
+		f.events--
+		return true
+	}
+	return false
+}
+
+func (f *fakeChangeStream) Timeout() bool {
+	f.Lock()
+	defer f.Unlock()
+	return f.timeout
+}
+
+func (f *fakeChangeStream) Err() error {
+	f.Lock()
+	defer f.Unlock()
+	return f.err
+}
+
+func (f *fakeChangeStream) Close() error {
+	f.Lock()
+	defer f.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeChangeStream) ResumeToken() *bson.Raw { return nil }
+
+func TestMongoChangeStream(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should pass while heartbeats are received", func(t *testing.T) {
+		stream := &fakeChangeStream{timeout: true}
+		w := newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 50 * time.Millisecond},
+			func(*bson.Raw) (mongoChangeStream, error) { return stream, nil })
+		go w.run()
+		defer w.close()
+
+		time.Sleep(10 * time.Millisecond)
+
+		details, err := w.status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details.LastHeartbeat.IsZero()).To(BeFalse())
+	})
+
+	t.Run("Should fail if events are required but only heartbeats are received", func(t *testing.T) {
+		stream := &fakeChangeStream{timeout: true}
+		w := newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 20 * time.Millisecond, RequireEvents: true},
+			func(*bson.Raw) (mongoChangeStream, error) { return stream, nil })
+		go w.run()
+		defer w.close()
+
+		time.Sleep(30 * time.Millisecond)
+
+		_, err := w.status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("no change stream event received"))
+	})
+
+	t.Run("Should record events", func(t *testing.T) {
+		stream := &fakeChangeStream{timeout: true, events: 1}
+		w := newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 50 * time.Millisecond, RequireEvents: true},
+			func(*bson.Raw) (mongoChangeStream, error) { return stream, nil })
+		go w.run()
+		defer w.close()
+
+		time.Sleep(10 * time.Millisecond)
+
+		details, err := w.status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details.LastEvent.IsZero()).To(BeFalse())
+	})
+
+	t.Run("Should resume the stream on errors and report the last error", func(t *testing.T) {
+		w := newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 20 * time.Millisecond},
+			func(*bson.Raw) (mongoChangeStream, error) {
+				return &fakeChangeStream{err: fmt.Errorf("cursor killed")}, nil
+			})
+		go w.run()
+		defer w.close()
+
+		time.Sleep(30 * time.Millisecond)
+
+		details, err := w.status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cursor killed"))
+		Expect(details.Resumes).To(BeNumerically(">", 0))
+	})
+
+	t.Run("Should back off while the stream keeps closing right away", func(t *testing.T) {
+		var lock sync.Mutex
+		opened := 0
+
+		w := newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 20 * time.Millisecond},
+			func(*bson.Raw) (mongoChangeStream, error) {
+				lock.Lock()
+				defer lock.Unlock()
+
+				opened++
+
+				// closed without an error or anything received
+				return &fakeChangeStream{}, nil
+			})
+		go w.run()
+
+		// re-opened after 10ms, 20ms, 40ms, 80ms...
+		time.Sleep(100 * time.Millisecond)
+		w.close()
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		Expect(opened).To(BeNumerically(">=", 2))
+		Expect(opened).To(BeNumerically("<=", 5))
+
+		details, _ := w.status()
+		Expect(details.Resumes).To(Equal(opened - 1))
+	})
+
+	t.Run("Should cap the backoff", func(t *testing.T) {
+		w := newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 20 * time.Millisecond}, nil)
+		Expect(w.backoff(1)).To(Equal(10 * time.Millisecond))
+		Expect(w.backoff(3)).To(Equal(40 * time.Millisecond))
+		Expect(w.backoff(100)).To(Equal(maxMongoChangeStreamBackoff))
+
+		w = newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 2 * time.Minute}, nil)
+		Expect(w.backoff(5)).To(Equal(time.Minute))
+	})
+
+	t.Run("Should be safe to close twice", func(t *testing.T) {
+		w := newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 20 * time.Millisecond},
+			func(*bson.Raw) (mongoChangeStream, error) { return &fakeChangeStream{timeout: true}, nil })
+		go w.run()
+
+		w.close()
+		Expect(w.close).ToNot(Panic())
+	})
+
+	t.Run("Should report errors opening the stream", func(t *testing.T) {
+		w := newMongoChangeStreamWatcher(&MongoChangeStreamOptions{MaxInterval: 10 * time.Millisecond},
+			func(*bson.Raw) (mongoChangeStream, error) { return nil, fmt.Errorf("not a replica set") })
+		go w.run()
+		defer w.close()
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err := w.status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not a replica set"))
+	})
+}
+
+func TestValidateMongoChangeStreamOptions(t *testing.T) {
+	RegisterTestingT(t)
+
+	err := validateMongoChangeStreamOptions(&MongoChangeStreamOptions{MaxInterval: time.Second})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("cfg.ChangeStream.DB and cfg.ChangeStream.Collection must be set"))
+
+	err = validateMongoChangeStreamOptions(&MongoChangeStreamOptions{DB: "db", Collection: "c"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("cfg.ChangeStream.MaxInterval must be set"))
+}
@@ -0,0 +1,110 @@
+package checkers
+
+import (
+	"fmt"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// MongoQueryOptions describes a data-presence assertion against a single
+// collection (in "MongoConfig.DB"). Either a filter with expected document
+// counts or an aggregation pipeline with a result assertion can be used.
+//
+// "Collection" is _required_; the collection to query.
+//
+// "Filter" is optional; the filter used for counting documents (ie.
+// `bson.M{"active": true}`); all documents are counted if undefined.
+//
+// "MinCount" is optional; fail if fewer documents match the filter (ie. set it
+// to `1` for "config collection must not be empty").
+//
+// "MaxCount" is optional; fail if more documents match the filter; zero means
+// no upper bound.
+//
+// "Pipeline" is optional; an aggregation pipeline to run instead of counting
+// documents. "Expect" must be set along with it.
+//
+// "Expect" is optional; the BYO function that asserts the aggregation result.
+type MongoQueryOptions struct {
+	Collection string
+	Filter     interface{}
+	MinCount   int
+	MaxCount   int
+	Pipeline   interface{}
+	Expect     func(results []bson.M) error
+}
+
+// MongoQueryDetails contains the result of a single query assertion.
+type MongoQueryDetails struct {
+	Collection string `json:"collection"`
+	Count      int    `json:"count"`
+}
+
+func (m *Mongo) checkQueries() ([]*MongoQueryDetails, error) {
+	details := make([]*MongoQueryDetails, 0, len(m.Config.Queries))
+
+	for _, q := range m.Config.Queries {
+		if q.Pipeline != nil {
+			results, err := m.aggregate(q.Collection, q.Pipeline)
+			if err != nil {
+				return details, fmt.Errorf("unable to run aggregation on collection %v: %v", q.Collection, err)
+			}
+
+			details = append(details, &MongoQueryDetails{Collection: q.Collection, Count: len(results)})
+
+			if err := q.Expect(results); err != nil {
+				return details, fmt.Errorf("aggregation result assertion on collection %v failed: %v", q.Collection, err)
+			}
+
+			continue
+		}
+
+		count, err := m.countDocuments(q.Collection, q.Filter)
+		if err != nil {
+			return details, fmt.Errorf("unable to count documents in collection %v: %v", q.Collection, err)
+		}
+
+		details = append(details, &MongoQueryDetails{Collection: q.Collection, Count: count})
+
+		if count < q.MinCount {
+			return details, fmt.Errorf("collection %v contains %v matching document(s), expected at least %v",
+				q.Collection, count, q.MinCount)
+		}
+
+		if q.MaxCount > 0 && count > q.MaxCount {
+			return details, fmt.Errorf("collection %v contains %v matching document(s), expected at most %v",
+				q.Collection, count, q.MaxCount)
+		}
+	}
+
+	return details, nil
+}
+
+func (m *Mongo) runCount(collection string, filter interface{}) (int, error) {
+	return m.Session.DB(m.Config.DB).C(collection).Find(filter).Count()
+}
+
+func (m *Mongo) runAggregate(collection string, pipeline interface{}) ([]bson.M, error) {
+	results := []bson.M{}
+	err := m.Session.DB(m.Config.DB).C(collection).Pipe(pipeline).All(&results)
+
+	return results, err
+}
+
+func validateMongoQueryOptions(queries []*MongoQueryOptions) error {
+	v := &validation{}
+
+	for i, q := range queries {
+		if v.check(q == nil || q.Collection == "", fmt.Sprintf("Queries[%v].Collection", i), "cfg.Queries[%v].Collection must be set", i) {
+			continue
+		}
+
+		v.check(q.Pipeline != nil && q.Expect == nil, fmt.Sprintf("Queries[%v].Expect", i),
+			"If cfg.Queries[%v].Pipeline is used, cfg.Queries[%v].Expect must be set", i, i)
+
+		v.check(q.MaxCount > 0 && q.MinCount > q.MaxCount, fmt.Sprintf("Queries[%v].MinCount", i),
+			"cfg.Queries[%v].MinCount cannot be greater than MaxCount", i)
+	}
+
+	return v.err()
+}
@@ -0,0 +1,95 @@
+package checkers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+	. "github.com/onsi/gomega"
+)
+
+func newQueryMongo(queries []*MongoQueryOptions, counts map[string]int, results []bson.M) *Mongo {
+	return &Mongo{
+		Config: &MongoConfig{Queries: queries},
+		countDocuments: func(collection string, filter interface{}) (int, error) {
+			count, ok := counts[collection]
+			if !ok {
+				return 0, fmt.Errorf("unauthorized")
+			}
+			return count, nil
+		},
+		aggregate: func(collection string, pipeline interface{}) ([]bson.M, error) {
+			return results, nil
+		},
+	}
+}
+
+func TestMongoQueriesStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		m := newQueryMongo([]*MongoQueryOptions{
+			{Collection: "config", MinCount: 1},
+			{Collection: "jobs", Filter: bson.M{"state": "stuck"}, MaxCount: 10},
+		}, map[string]int{"config": 3, "jobs": 2}, nil)
+
+		data, err := m.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.(*MongoDetails).Queries).To(HaveLen(2))
+		Expect(data.(*MongoDetails).Queries[0].Count).To(Equal(3))
+	})
+
+	t.Run("Should fail below the minimum count", func(t *testing.T) {
+		m := newQueryMongo([]*MongoQueryOptions{{Collection: "config", MinCount: 1}}, map[string]int{"config": 0}, nil)
+
+		_, err := m.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("collection config contains 0 matching document(s), expected at least 1"))
+	})
+
+	t.Run("Should fail above the maximum count", func(t *testing.T) {
+		m := newQueryMongo([]*MongoQueryOptions{{Collection: "jobs", MaxCount: 1}}, map[string]int{"jobs": 5}, nil)
+
+		_, err := m.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("expected at most 1"))
+	})
+
+	t.Run("Should fail if counting fails", func(t *testing.T) {
+		m := newQueryMongo([]*MongoQueryOptions{{Collection: "secret", MinCount: 1}}, map[string]int{}, nil)
+
+		_, err := m.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unable to count documents in collection secret: unauthorized"))
+	})
+
+	t.Run("Should assert aggregation results", func(t *testing.T) {
+		expect := func(results []bson.M) error {
+			if len(results) != 1 || results[0]["total"] != 42 {
+				return fmt.Errorf("unexpected total")
+			}
+			return nil
+		}
+
+		m := newQueryMongo([]*MongoQueryOptions{{Collection: "orders", Pipeline: []bson.M{}, Expect: expect}},
+			nil, []bson.M{{"total": 42}})
+
+		_, err := m.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		m = newQueryMongo([]*MongoQueryOptions{{Collection: "orders", Pipeline: []bson.M{}, Expect: expect}},
+			nil, []bson.M{{"total": 1}})
+
+		_, err = m.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("aggregation result assertion on collection orders failed: unexpected total"))
+	})
+}
+
+func TestValidateMongoQueryOptions(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(validateMongoQueryOptions([]*MongoQueryOptions{{}})).To(MatchError(ContainSubstring("cfg.Queries[0].Collection must be set")))
+	Expect(validateMongoQueryOptions([]*MongoQueryOptions{{Collection: "c", Pipeline: []bson.M{}}})).To(MatchError(ContainSubstring("Expect must be set")))
+	Expect(validateMongoQueryOptions([]*MongoQueryOptions{{Collection: "c", MinCount: 5, MaxCount: 1}})).To(MatchError(ContainSubstring("MinCount cannot be greater than MaxCount")))
+}
@@ -0,0 +1,159 @@
+package checkers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// MongoShardOptions contains attributes that alter the behavior of the
+// sharded cluster check. The checker must be connected via mongos; the shards
+// are discovered via the "listShards" command and each one of them is pinged
+// (using the credentials from "MongoAuthConfig").
+//
+// "BalancerMode" is optional; if set, the balancer mode reported by the
+// "balancerStatus" command (ie. "full" or "off") must match it.
+//
+// "Timeout" is optional and defaults to "DefaultTimeout"; used when connecting to each shard.
+type MongoShardOptions struct {
+	BalancerMode string
+	Timeout      time.Duration
+}
+
+// MongoShardDetails contains the per-shard status of a sharded cluster check.
+type MongoShardDetails struct {
+	Shards   map[string]string `json:"shards"`
+	Balancer string            `json:"balancer,omitempty"`
+}
+
+type mongoShard struct {
+	ID         string `bson:"_id"`
+	Host       string `bson:"host"`
+	State      int    `bson:"state"`
+	Draining   bool   `bson:"draining"`
+	replicaSet string
+	addrs      []string
+}
+
+type mongoListShardsResult struct {
+	Shards []*mongoShard `bson:"shards"`
+}
+
+type mongoBalancerStatusResult struct {
+	Mode string `bson:"mode"`
+}
+
+func (m *Mongo) checkShards() (*MongoShardDetails, error) {
+	result := &mongoListShardsResult{}
+	if err := m.runCommand(bson.D{{Name: "listShards", Value: 1}}, result); err != nil {
+		return nil, fmt.Errorf("unable to list shards (is the checker connected via mongos?): %v", err)
+	}
+
+	if len(result.Shards) == 0 {
+		return nil, fmt.Errorf("no shards found")
+	}
+
+	details := &MongoShardDetails{
+		Shards: make(map[string]string, len(result.Shards)),
+	}
+
+	// ping all shards concurrently
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	failed := []string{}
+
+	for _, shard := range result.Shards {
+		parseShardHost(shard)
+
+		wg.Add(1)
+		go func(shard *mongoShard) {
+			defer wg.Done()
+
+			status := "ok"
+			if err := m.pingShard(shard); err != nil {
+				status = fmt.Sprintf("failed: %v", err)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			details.Shards[shard.ID] = status
+			if status != "ok" {
+				failed = append(failed, shard.ID)
+			}
+		}(shard)
+	}
+
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return details, fmt.Errorf("unable to reach shard(s) '%v'", strings.Join(failed, "', '"))
+	}
+
+	if m.Config.Shards.BalancerMode != "" {
+		balancer := &mongoBalancerStatusResult{}
+		if err := m.runCommand(bson.D{{Name: "balancerStatus", Value: 1}}, balancer); err != nil {
+			return details, fmt.Errorf("unable to fetch balancer status: %v", err)
+		}
+
+		details.Balancer = balancer.Mode
+
+		if balancer.Mode != m.Config.Shards.BalancerMode {
+			return details, fmt.Errorf("balancer mode '%v' does not match expected mode '%v'",
+				balancer.Mode, m.Config.Shards.BalancerMode)
+		}
+	}
+
+	return details, nil
+}
+
+func (m *Mongo) runAdminCommand(cmd interface{}, result interface{}) error {
+	return m.Session.Run(cmd, result)
+}
+
+func (m *Mongo) dialShard(shard *mongoShard) error {
+	timeout := m.Config.Shards.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	creds, err := m.credentials()
+	if err != nil {
+		return err
+	}
+
+	session, err := mgo.DialWithInfo(&mgo.DialInfo{
+		Addrs:          shard.addrs,
+		ReplicaSetName: shard.replicaSet,
+		Timeout:        timeout,
+		Username:       creds.Username,
+		Password:       creds.Password,
+		Source:         creds.Source,
+		Mechanism:      creds.Mechanism,
+	})
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	return session.Ping()
+}
+
+// shard hosts are formatted as "replicaSet/host1:port,host2:port" (or just
+// "host:port" for standalone shards)
+func parseShardHost(shard *mongoShard) {
+	hosts := shard.Host
+
+	if idx := strings.Index(hosts, "/"); idx >= 0 {
+		shard.replicaSet = hosts[:idx]
+		hosts = hosts[idx+1:]
+	}
+
+	shard.addrs = strings.Split(hosts, ",")
+}
@@ -0,0 +1,98 @@
+package checkers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+	. "github.com/onsi/gomega"
+)
+
+func newShardedMongo(opts *MongoShardOptions, shards []*mongoShard, balancer string) *Mongo {
+	m := &Mongo{
+		Config: &MongoConfig{Auth: &MongoAuthConfig{}, Shards: opts},
+	}
+
+	m.runCommand = func(cmd interface{}, result interface{}) error {
+		switch cmd.(bson.D)[0].Name {
+		case "listShards":
+			result.(*mongoListShardsResult).Shards = shards
+		case "balancerStatus":
+			result.(*mongoBalancerStatusResult).Mode = balancer
+		default:
+			return fmt.Errorf("unexpected command")
+		}
+		return nil
+	}
+
+	m.pingShard = func(shard *mongoShard) error {
+		if shard.ID == "down" {
+			return fmt.Errorf("no reachable servers")
+		}
+		return nil
+	}
+
+	return m
+}
+
+func TestMongoShardsStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		m := newShardedMongo(&MongoShardOptions{BalancerMode: "full"}, []*mongoShard{
+			{ID: "shard01", Host: "rs1/host1:27017,host2:27017"},
+			{ID: "shard02", Host: "host3:27017"},
+		}, "full")
+
+		data, err := m.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		details := data.(*MongoDetails).Shards
+		Expect(details.Shards).To(Equal(map[string]string{"shard01": "ok", "shard02": "ok"}))
+		Expect(details.Balancer).To(Equal("full"))
+	})
+
+	t.Run("Should report unreachable shards", func(t *testing.T) {
+		m := newShardedMongo(&MongoShardOptions{}, []*mongoShard{
+			{ID: "shard01", Host: "host1:27017"},
+			{ID: "down", Host: "host2:27017"},
+		}, "")
+
+		data, err := m.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unable to reach shard(s) 'down'"))
+		Expect(data.(*MongoDetails).Shards.Shards["down"]).To(ContainSubstring("no reachable servers"))
+	})
+
+	t.Run("Should fail if the balancer is in an unexpected mode", func(t *testing.T) {
+		m := newShardedMongo(&MongoShardOptions{BalancerMode: "full"}, []*mongoShard{
+			{ID: "shard01", Host: "host1:27017"},
+		}, "off")
+
+		_, err := m.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("balancer mode 'off' does not match expected mode 'full'"))
+	})
+
+	t.Run("Should fail if there are no shards", func(t *testing.T) {
+		m := newShardedMongo(&MongoShardOptions{}, nil, "")
+
+		_, err := m.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("no shards found"))
+	})
+}
+
+func TestParseShardHost(t *testing.T) {
+	RegisterTestingT(t)
+
+	shard := &mongoShard{Host: "rs1/host1:27017,host2:27017"}
+	parseShardHost(shard)
+	Expect(shard.replicaSet).To(Equal("rs1"))
+	Expect(shard.addrs).To(Equal([]string{"host1:27017", "host2:27017"}))
+
+	shard = &mongoShard{Host: "host3:27017"}
+	parseShardHost(shard)
+	Expect(shard.replicaSet).To(BeEmpty())
+	Expect(shard.addrs).To(Equal([]string{"host3:27017"}))
+}
@@ -0,0 +1,58 @@
+//go:build (linux || darwin || freebsd) && cgo
+// +build linux darwin freebsd
+// +build cgo
+
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"plugin"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// OpenGo loads a Go plugin (built with `go build -buildmode=plugin`) and
+// creates its checker via the exported "NewChecker" func (see "Factory").
+//
+// Go plugins must be built with the same Go version (and versions of shared
+// packages) as the service; they are only supported on Linux, macOS and
+// FreeBSD with cgo enabled. A plugin is loaded once per process; opening it
+// again creates another checker.
+func OpenGo(path string, settings map[string]interface{}) (health.ICheckable, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open plugin: %v", err)
+	}
+
+	sym, err := p.Lookup(FactorySymbol)
+	if err != nil {
+		return nil, fmt.Errorf("Plugin does not export '%v'", FactorySymbol)
+	}
+
+	var factory Factory
+
+	switch f := sym.(type) {
+	case Factory:
+		factory = f
+	case *Factory: // exported as a variable
+		factory = *f
+	default:
+		return nil, fmt.Errorf("'%v' of the plugin is a %T, expected a %T", FactorySymbol, sym, factory)
+	}
+
+	if factory == nil {
+		return nil, fmt.Errorf("'%v' of the plugin is nil", FactorySymbol)
+	}
+
+	checker, err := factory(settings)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create checker of plugin: %v", err)
+	}
+
+	if checker == nil {
+		return nil, errors.New("Plugin has returned a nil checker")
+	}
+
+	return checker, nil
+}
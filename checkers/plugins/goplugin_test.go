@@ -0,0 +1,56 @@
+//go:build (linux || darwin || freebsd) && cgo && !race
+// +build linux darwin freebsd
+// +build cgo
+// +build !race
+
+package plugins
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// builds the plugin of "testdata/checker" (plugins built without "-race"
+// cannot be loaded by race-enabled tests)
+func buildPlugin(t *testing.T) string {
+	if testing.Short() {
+		t.Skip("Building a plugin is skipped in short mode")
+	}
+
+	path := filepath.Join(t.TempDir(), "checker.so")
+
+	out, err := exec.Command("go", "build", "-buildmode=plugin", "-o", path, "./testdata/checker").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Unable to build plugin: %v\n%s", err, out)
+	}
+
+	return path
+}
+
+func TestOpenGo(t *testing.T) {
+	RegisterTestingT(t)
+
+	path := buildPlugin(t)
+
+	t.Run("Should create the checker of the plugin", func(t *testing.T) {
+		checker, err := Open(path, map[string]interface{}{"queue": "orders"})
+		Expect(err).ToNot(HaveOccurred())
+
+		details, err := checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details).To(Equal(map[string]string{"queue": "orders"}))
+	})
+
+	t.Run("Should error if the checker cannot be created", func(t *testing.T) {
+		_, err := OpenGo(path, nil)
+		Expect(err).To(MatchError("Unable to create checker of plugin: queue must be set"))
+	})
+
+	t.Run("Should error if the plugin cannot be opened", func(t *testing.T) {
+		_, err := OpenGo(filepath.Join(t.TempDir(), "missing.so"), nil)
+		Expect(err).To(MatchError(ContainSubstring("Unable to open plugin")))
+	})
+}
@@ -0,0 +1,16 @@
+//go:build !((linux || darwin || freebsd) && cgo)
+// +build !linux,!darwin,!freebsd !cgo
+
+package plugins
+
+import (
+	"errors"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// OpenGo is not supported on this platform (Go plugins require Linux, macOS
+// or FreeBSD with cgo enabled); load the checker from a WASM module instead.
+func OpenGo(path string, settings map[string]interface{}) (health.ICheckable, error) {
+	return nil, errors.New("Go plugins are not supported on this platform")
+}
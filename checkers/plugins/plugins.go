@@ -0,0 +1,45 @@
+// Package plugins loads checkers that are shipped separately from the
+// services that run them, ie. organization-specific checks maintained by a
+// platform team, so that they can be rolled out without rebuilding every
+// service: either from Go plugins (see "OpenGo()") or from WASM modules
+// conforming to a small ABI (see "NewWASM()").
+//
+// Refer to the README of the package for both ABIs.
+package plugins
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// Factory is the type of the "NewChecker" func that Go plugins must export
+// (see "OpenGo()"). It receives the settings of the check and returns the
+// checker; the checker may also implement "health.ICheckableContext",
+// "health.ICheckableDescriber" and "io.Closer".
+//
+// The checker is returned as an unnamed interface, so that plugins do not
+// have to import this package (and depend on its exact version).
+type Factory = func(settings map[string]interface{}) (interface{ Status() (interface{}, error) }, error)
+
+// FactorySymbol is the name of the func that Go plugins must export.
+const FactorySymbol = "NewChecker"
+
+// Open loads the checker from a Go plugin (`.so`, see "OpenGo()") or a WASM
+// module (`.wasm`, see "OpenWASM()") depending on the extension of the file.
+func Open(path string, settings map[string]interface{}) (health.ICheckable, error) {
+	switch filepath.Ext(path) {
+	case ".so":
+		return OpenGo(path, settings)
+	case ".wasm":
+		checker, err := OpenWASM(path, settings)
+		if err != nil {
+			return nil, err
+		}
+
+		return checker, nil
+	}
+
+	return nil, fmt.Errorf("Unknown plugin type '%v'; expected a '.so' or '.wasm' file", filepath.Ext(path))
+}
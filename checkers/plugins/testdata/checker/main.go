@@ -0,0 +1,26 @@
+// A Go plugin for the tests; built with `go build -buildmode=plugin`.
+package main
+
+import (
+	"errors"
+)
+
+type checker struct {
+	queue string
+}
+
+func (c *checker) Status() (interface{}, error) {
+	return map[string]string{"queue": c.queue}, nil
+}
+
+// NewChecker is the factory of the plugin (see "plugins.Factory")
+func NewChecker(settings map[string]interface{}) (interface{ Status() (interface{}, error) }, error) {
+	queue, ok := settings["queue"].(string)
+	if !ok {
+		return nil, errors.New("queue must be set")
+	}
+
+	return &checker{queue: queue}, nil
+}
+
+func main() {}
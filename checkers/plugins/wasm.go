@@ -0,0 +1,261 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/describe"
+)
+
+const (
+	// WASMAllocFunc is the name of the func that WASM modules must export to
+	// allocate the buffer of the settings: `health_alloc(size i32) i32`
+	WASMAllocFunc = "health_alloc"
+
+	// WASMCheckFunc is the name of the func that WASM modules must export to
+	// run the check: `health_check(ptr i32, len i32) i64`; it receives the
+	// settings (JSON) and returns the pointer (upper 32 bits) and length
+	// (lower 32 bits) of the result (JSON, see "WASMResult")
+	WASMCheckFunc = "health_check"
+)
+
+// WASMRuntime instantiates WASM modules; this package does not depend on a
+// WASM runtime, so that services only pull one in if they load WASM
+// modules. Refer to the README for an adapter of wazero.
+type WASMRuntime interface {
+	Instantiate(ctx context.Context, module []byte) (WASMModule, error)
+}
+
+// WASMModule is an instance of a WASM module; the methods mirror the ones of
+// the module (and its memory) of wazero.
+type WASMModule interface {
+	// Call calls the exported func and returns its results
+	Call(ctx context.Context, name string, params ...uint64) ([]uint64, error)
+
+	// Read reads from the memory of the module; returns false if out of
+	// bounds
+	Read(offset, size uint32) ([]byte, bool)
+
+	// Write writes to the memory of the module; returns false if out of
+	// bounds
+	Write(offset uint32, data []byte) bool
+
+	Close(ctx context.Context) error
+}
+
+// DefaultRuntime is used for WASM modules that do not set
+// "WASMConfig.Runtime" (and by "OpenWASM()"); set it once at startup.
+var DefaultRuntime WASMRuntime
+
+// WASMResult is the result of "health_check" (JSON); the check fails if
+// "Error" is set (or is reported as degraded if "Degraded" is set too, see
+// "health.DegradedError"). "Details" are returned as the details of the
+// check.
+type WASMResult struct {
+	Error    string          `json:"error"`
+	Degraded bool            `json:"degraded"`
+	Details  json.RawMessage `json:"details"`
+}
+
+// WASMConfig is used for configuring a WASM checker.
+//
+// "Module" is _required_; the binary of the WASM module.
+//
+// "Name" is optional; describes the module (ie. its file name).
+//
+// "Settings" is optional; passed to the module (as JSON) on every check.
+//
+// "Runtime" is optional; defaults to "DefaultRuntime" (one of both must be
+// set).
+type WASMConfig struct {
+	Module   []byte
+	Name     string
+	Settings map[string]interface{}
+	Runtime  WASMRuntime
+}
+
+// WASM runs the checks of a WASM module; it implements the "ICheckable",
+// "ICheckableContext" and "ICheckableDescriber" interfaces and "io.Closer".
+//
+// The module is instantiated once and its settings are written to the buffer
+// returned by "health_alloc", which must stay valid for the lifetime of the
+// instance; the same buffer is passed to every "health_check" call. Calls
+// are serialized. If a call fails (ie. traps or is canceled by the timeout of
+// the check), the instance is discarded and the module is instantiated again
+// on the next check.
+type WASM struct {
+	Config *WASMConfig
+
+	runtime  WASMRuntime
+	settings []byte
+
+	lock     sync.Mutex
+	instance WASMModule
+	ptr      uint32 // of the settings in the memory of the instance
+}
+
+// NewWASM creates a new WASM checker that can be used for ".AddCheck(s)";
+// the module is instantiated right away, so that an invalid module is
+// reported up front.
+func NewWASM(cfg *WASMConfig) (*WASM, error) {
+	if cfg == nil || len(cfg.Module) == 0 {
+		return nil, errors.New("Module must be set")
+	}
+
+	runtime := cfg.Runtime
+	if runtime == nil {
+		runtime = DefaultRuntime
+	}
+
+	if runtime == nil {
+		return nil, errors.New("Runtime must be set (or plugins.DefaultRuntime)")
+	}
+
+	settings := cfg.Settings
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal settings: %v", err)
+	}
+
+	w := &WASM{Config: cfg, runtime: runtime, settings: data}
+
+	if err := w.instantiate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// OpenWASM reads the WASM module from the file and creates its checker with
+// "DefaultRuntime" (see "NewWASM()").
+func OpenWASM(path string, settings map[string]interface{}) (*WASM, error) {
+	module, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read module: %v", err)
+	}
+
+	return NewWASM(&WASMConfig{Module: module, Name: filepath.Base(path), Settings: settings})
+}
+
+// Status runs the check of the module with a background context; it
+// satisfies the "health.ICheckable" interface.
+func (w *WASM) Status() (interface{}, error) {
+	return w.StatusContext(context.Background())
+}
+
+// StatusContext runs the check of the module; it satisfies the
+// "health.ICheckableContext" interface.
+func (w *WASM) StatusContext(ctx context.Context) (interface{}, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.instance == nil {
+		if err := w.instantiate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := w.instance.Call(ctx, WASMCheckFunc, uint64(w.ptr), uint64(len(w.settings)))
+	if err != nil {
+		// the instance may be unusable (ie. after a trap)
+		w.discard()
+		return nil, fmt.Errorf("Unable to call '%v' of module: %v", WASMCheckFunc, err)
+	}
+
+	if len(results) != 1 {
+		return nil, fmt.Errorf("'%v' of module has returned %d values, expected 1", WASMCheckFunc, len(results))
+	}
+
+	data, ok := w.instance.Read(uint32(results[0]>>32), uint32(results[0]))
+	if !ok {
+		return nil, errors.New("Result of module is out of bounds")
+	}
+
+	result := &WASMResult{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("Unable to parse result of module: %v", err)
+	}
+
+	var details interface{}
+	if len(result.Details) > 0 {
+		if err := json.Unmarshal(result.Details, &details); err != nil {
+			return nil, fmt.Errorf("Unable to parse details of module: %v", err)
+		}
+	}
+
+	switch {
+	case result.Error == "":
+		return details, nil
+	case result.Degraded:
+		return details, health.Degraded("%v", result.Error)
+	}
+
+	return details, errors.New(result.Error)
+}
+
+// Describe returns the name of the module (see "health.ICheckableDescriber").
+func (w *WASM) Describe() describe.Description {
+	return describe.Description{
+		Type:   "wasm",
+		Target: w.Config.Name,
+	}
+}
+
+// Close closes the instance of the module (see "health.StopWithContext()").
+func (w *WASM) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.instance == nil {
+		return nil
+	}
+
+	err := w.instance.Close(context.Background())
+	w.instance = nil
+
+	return err
+}
+
+// instantiates the module and writes the settings to its memory
+func (w *WASM) instantiate(ctx context.Context) error {
+	instance, err := w.runtime.Instantiate(ctx, w.Config.Module)
+	if err != nil {
+		return fmt.Errorf("Unable to instantiate module: %v", err)
+	}
+
+	results, err := instance.Call(ctx, WASMAllocFunc, uint64(len(w.settings)))
+	if err == nil && len(results) != 1 {
+		err = fmt.Errorf("returned %d values, expected 1", len(results))
+	}
+
+	if err != nil {
+		instance.Close(context.Background())
+		return fmt.Errorf("Unable to call '%v' of module: %v", WASMAllocFunc, err)
+	}
+
+	ptr := uint32(results[0])
+	if !instance.Write(ptr, w.settings) {
+		instance.Close(context.Background())
+		return errors.New("Buffer of the settings is out of bounds")
+	}
+
+	w.instance, w.ptr = instance, ptr
+
+	return nil
+}
+
+// closes the instance; it is instantiated again on the next check
+func (w *WASM) discard() {
+	w.instance.Close(context.Background())
+	w.instance = nil
+}
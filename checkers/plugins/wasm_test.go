@@ -0,0 +1,229 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// fakeRuntime instantiates modules that implement the ABI in Go
+type fakeRuntime struct {
+	check     func(settings []byte) (string, error)
+	err       error
+	instances []*fakeModule
+}
+
+func (r *fakeRuntime) Instantiate(ctx context.Context, module []byte) (WASMModule, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	m := &fakeModule{mem: make([]byte, 4096), check: r.check}
+	r.instances = append(r.instances, m)
+
+	return m, nil
+}
+
+type fakeModule struct {
+	mem    []byte
+	check  func(settings []byte) (string, error)
+	closed bool
+}
+
+func (m *fakeModule) Call(ctx context.Context, name string, params ...uint64) ([]uint64, error) {
+	if m.closed {
+		return nil, errors.New("module closed")
+	}
+
+	switch name {
+	case WASMAllocFunc:
+		return []uint64{16}, nil
+	case WASMCheckFunc:
+		settings, _ := m.Read(uint32(params[0]), uint32(params[1]))
+
+		result, err := m.check(settings)
+		if err != nil {
+			return nil, err
+		}
+
+		if result == "oob" {
+			// points past the memory
+			return []uint64{uint64(8192)<<32 | 10}, nil
+		}
+
+		offset := uint32(1024)
+		m.Write(offset, []byte(result))
+
+		return []uint64{uint64(offset)<<32 | uint64(len(result))}, nil
+	}
+
+	return nil, fmt.Errorf("function '%v' is not exported", name)
+}
+
+func (m *fakeModule) Read(offset, size uint32) ([]byte, bool) {
+	if int(offset)+int(size) > len(m.mem) {
+		return nil, false
+	}
+
+	return m.mem[offset : offset+size], true
+}
+
+func (m *fakeModule) Write(offset uint32, data []byte) bool {
+	if int(offset)+len(data) > len(m.mem) {
+		return false
+	}
+
+	copy(m.mem[offset:], data)
+
+	return true
+}
+
+func (m *fakeModule) Close(ctx context.Context) error {
+	m.closed = true
+	return nil
+}
+
+func TestNewWASM(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should instantiate the module", func(t *testing.T) {
+		runtime := &fakeRuntime{}
+
+		w, err := NewWASM(&WASMConfig{Module: []byte("wasm"), Name: "checks.wasm", Runtime: runtime})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runtime.instances).To(HaveLen(1))
+		Expect(w.Describe().Type).To(Equal("wasm"))
+		Expect(w.Describe().Target).To(Equal("checks.wasm"))
+	})
+
+	t.Run("Should error on invalid configs", func(t *testing.T) {
+		_, err := NewWASM(nil)
+		Expect(err).To(MatchError("Module must be set"))
+
+		_, err = NewWASM(&WASMConfig{Module: []byte("wasm")})
+		Expect(err).To(MatchError("Runtime must be set (or plugins.DefaultRuntime)"))
+
+		_, err = NewWASM(&WASMConfig{Module: []byte("wasm"), Runtime: &fakeRuntime{err: errors.New("invalid magic number")}})
+		Expect(err).To(MatchError("Unable to instantiate module: invalid magic number"))
+	})
+
+	t.Run("OpenWASM should use the default runtime", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "checks.wasm")
+		Expect(os.WriteFile(path, []byte("wasm"), 0600)).To(Succeed())
+
+		DefaultRuntime = &fakeRuntime{}
+		defer func() { DefaultRuntime = nil }()
+
+		checker, err := Open(path, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(checker.(*WASM).Describe().Target).To(Equal("checks.wasm"))
+
+		_, err = Open(filepath.Join(t.TempDir(), "missing.wasm"), nil)
+		Expect(err).To(MatchError(ContainSubstring("Unable to read module")))
+
+		_, err = Open("checks.jar", nil)
+		Expect(err).To(MatchError("Unknown plugin type '.jar'; expected a '.so' or '.wasm' file"))
+	})
+}
+
+func TestWASMStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	newWASM := func(check func(settings []byte) (string, error)) (*WASM, *fakeRuntime) {
+		runtime := &fakeRuntime{check: check}
+
+		w, err := NewWASM(&WASMConfig{
+			Module:   []byte("wasm"),
+			Settings: map[string]interface{}{"queue": "orders", "max_lag": 30},
+			Runtime:  runtime,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		return w, runtime
+	}
+
+	t.Run("Should pass the settings and return the details", func(t *testing.T) {
+		var received string
+
+		w, _ := newWASM(func(settings []byte) (string, error) {
+			received = string(settings)
+			return `{"details": {"lag": 3}}`, nil
+		})
+
+		details, err := w.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details).To(Equal(map[string]interface{}{"lag": float64(3)}))
+		Expect(received).To(MatchJSON(`{"queue": "orders", "max_lag": 30}`))
+	})
+
+	t.Run("Should report errors of the module", func(t *testing.T) {
+		w, _ := newWASM(func(settings []byte) (string, error) {
+			return `{"error": "lag is too high", "details": {"lag": 45}}`, nil
+		})
+
+		details, err := w.Status()
+		Expect(err).To(MatchError("lag is too high"))
+		Expect(details).To(Equal(map[string]interface{}{"lag": float64(45)}))
+		Expect(errors.As(err, new(*health.DegradedError))).To(BeFalse())
+
+		w, _ = newWASM(func(settings []byte) (string, error) {
+			return `{"error": "lag is rising", "degraded": true}`, nil
+		})
+
+		_, err = w.Status()
+		Expect(err).To(MatchError("lag is rising"))
+		Expect(errors.As(err, new(*health.DegradedError))).To(BeTrue())
+	})
+
+	t.Run("Should instantiate the module again after a failed call", func(t *testing.T) {
+		trap := true
+
+		w, runtime := newWASM(func(settings []byte) (string, error) {
+			if trap {
+				return "", errors.New("wasm error: unreachable")
+			}
+
+			return `{}`, nil
+		})
+
+		_, err := w.Status()
+		Expect(err).To(MatchError("Unable to call 'health_check' of module: wasm error: unreachable"))
+		Expect(runtime.instances[0].closed).To(BeTrue())
+
+		trap = false
+
+		_, err = w.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runtime.instances).To(HaveLen(2))
+	})
+
+	t.Run("Should error on invalid results", func(t *testing.T) {
+		for result, msg := range map[string]string{
+			`not json`:             "Unable to parse result of module",
+			`oob`:                  "Result of module is out of bounds",
+			`{"details": "a", "x"`: "Unable to parse result of module",
+		} {
+			result := result
+
+			w, _ := newWASM(func(settings []byte) (string, error) { return result, nil })
+
+			_, err := w.Status()
+			Expect(err).To(MatchError(ContainSubstring(msg)), result)
+		}
+	})
+
+	t.Run("Should close the instance", func(t *testing.T) {
+		w, runtime := newWASM(func(settings []byte) (string, error) { return `{}`, nil })
+
+		Expect(w.Close()).To(Succeed())
+		Expect(runtime.instances[0].closed).To(BeTrue())
+		Expect(w.Close()).To(Succeed())
+	})
+}
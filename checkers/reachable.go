@@ -1,9 +1,14 @@
 package checkers
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"net/url"
 	"time"
+
+	"github.com/InVisionApp/go-health/describe"
+	"github.com/InVisionApp/go-health/retry"
 )
 
 const (
@@ -17,9 +22,15 @@ const (
 
 var (
 	// ReachableDefaultTimeout is the default timeout used when reachable is checking the URL
-	ReachableDefaultTimeout = time.Duration(3) * time.Second
+	//
+	// Deprecated: set "DefaultTimeout" instead, which applies to all
+	// checkers; this variable is still honoured if it has been changed.
+	ReachableDefaultTimeout = reachableDefaultTimeout
 )
 
+// the initial value of "ReachableDefaultTimeout"
+const reachableDefaultTimeout = time.Duration(3) * time.Second
+
 // ReachableDialer is the signature for a function that checks if an address is reachable
 type ReachableDialer func(network, address string, timeout time.Duration) (net.Conn, error)
 
@@ -32,7 +43,8 @@ type ReachableDatadogIncrementer interface {
 //
 // "Dialer" is optional and defaults to using net.DialTimeout.
 //
-// "Timeout" is optional and defaults to "3s".
+// "Timeout" is optional and defaults to "DefaultTimeout" (or
+// "ReachableDefaultTimeout", if it has been changed).
 //
 // "Network" is optional and defaults to "tcp"; it should be one of "tcp",
 // "tcp4", "tcp6", "unix", "unixpacket", "udp", "udp4", "udp6", "unixgram" or an
@@ -42,13 +54,17 @@ type ReachableDatadogIncrementer interface {
 // "DatadogClient" is optional; if defined metrics will be sent via statsd.
 //
 // "DatadogTags" is optional; defines the tags that are passed to datadog when there is a failure
+//
+// "Retry" is optional; a failed dial is retried within the same check according
+// to the policy (ie. "retry.Constant"); only the final failure is sent to datadog.
 type ReachableConfig struct {
 	URL           *url.URL                    // Required
 	Dialer        ReachableDialer             // Optional (default net.DialTimeout)
-	Timeout       time.Duration               // Optional (default 3s)
+	Timeout       time.Duration               // Optional (default DefaultTimeout)
 	Network       string                      // Optional (default tcp)
 	DatadogClient ReachableDatadogIncrementer // Optional
 	DatadogTags   []string                    // Optional
+	Retry         retry.Policy                // Optional
 }
 
 // ReachableChecker checks that URL responds to a TCP request
@@ -59,11 +75,19 @@ type ReachableChecker struct {
 	url     *url.URL
 	datadog ReachableDatadogIncrementer
 	tags    []string
+	retry   retry.Policy
 }
 
 // NewReachableChecker creates a new reachable health checker
 func NewReachableChecker(cfg *ReachableConfig) (*ReachableChecker, error) {
-	t := ReachableDefaultTimeout
+	if err := validateReachableConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate reachable config: %w", err)
+	}
+
+	t := DefaultTimeout
+	if ReachableDefaultTimeout != reachableDefaultTimeout {
+		t = ReachableDefaultTimeout
+	}
 	if cfg.Timeout != 0 {
 		t = cfg.Timeout
 	}
@@ -82,10 +106,21 @@ func NewReachableChecker(cfg *ReachableConfig) (*ReachableChecker, error) {
 		url:     cfg.URL,
 		datadog: cfg.DatadogClient,
 		tags:    cfg.DatadogTags,
+		retry:   cfg.Retry,
 	}
 	return r, nil
 }
 
+// Describe returns the type, target and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (r *ReachableChecker) Describe() describe.Description {
+	return describe.Description{
+		Type:         "reachable",
+		Target:       r.url.Host,
+		Capabilities: []string{r.network},
+	}
+}
+
 // Status checks if the endpoint is reachable
 func (r *ReachableChecker) Status() (interface{}, error) {
 	// We must provide a port so when a port is not set in the URL provided use
@@ -95,15 +130,19 @@ func (r *ReachableChecker) Status() (interface{}, error) {
 		port = ReachableDefaultPort
 	}
 
-	conn, err := r.dialer(r.network, r.url.Hostname()+":"+port, r.timeout)
+	err := retry.Do(context.Background(), r.retry, func() error {
+		conn, err := r.dialer(r.network, r.url.Hostname()+":"+port, r.timeout)
+		if err != nil {
+			return err
+		}
+		if conn != nil {
+			return conn.Close()
+		}
+		return nil
+	})
 	if err != nil {
 		return r.fail(err)
 	}
-	if conn != nil {
-		if errClose := conn.Close(); errClose != nil {
-			return r.fail(errClose)
-		}
-	}
 	return nil, nil
 }
 
@@ -113,3 +152,16 @@ func (r *ReachableChecker) fail(err error) (interface{}, error) {
 	}
 	return nil, err
 }
+
+func validateReachableConfig(cfg *ReachableConfig) error {
+	v := &validation{}
+
+	if v.check(cfg == nil, "", "Main config cannot be nil") {
+		return v.err()
+	}
+
+	v.check(cfg.URL == nil, "URL", "URL cannot be nil")
+	v.check(cfg.Timeout < 0, "Timeout", "Timeout cannot be negative")
+
+	return v.err()
+}
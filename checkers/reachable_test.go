@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/InVisionApp/go-health/checkers"
+	"github.com/InVisionApp/go-health/checkers/internal/mockdeps"
 	"github.com/InVisionApp/go-health/fakes"
 	"github.com/InVisionApp/go-health/fakes/netfakes"
+	"github.com/InVisionApp/go-health/retry"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +41,27 @@ func TestReachableSuccessUsingDefaults(t *testing.T) {
 	assert.Equal(0, dd.IncrCallCount())
 }
 
+func TestReachableCustomDefaultTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	prev := checkers.ReachableDefaultTimeout
+	checkers.ReachableDefaultTimeout = 7 * time.Second
+	defer func() { checkers.ReachableDefaultTimeout = prev }()
+
+	u, _ := url.Parse("http://example.com")
+	c, err := checkers.NewReachableChecker(&checkers.ReachableConfig{
+		URL: u,
+		Dialer: func(network, address string, timeout time.Duration) (net.Conn, error) {
+			assert.Equal(7*time.Second, timeout)
+			return nil, nil
+		},
+	})
+	assert.NoError(err)
+
+	_, err = c.Status()
+	assert.NoError(err)
+}
+
 func TestReachableSuccess(t *testing.T) {
 	assert := assert.New(t)
 	dd := &fakes.FakeReachableDatadogIncrementer{}
@@ -133,3 +156,58 @@ func TestReachableErrorWithDatadog(t *testing.T) {
 	assert.Equal(ddTags, tags)
 	assert.Equal(1.0, num)
 }
+
+func TestReachableRetry(t *testing.T) {
+	assert := assert.New(t)
+	dd := &fakes.FakeReachableDatadogIncrementer{}
+	dials := 0
+	u, _ := url.Parse("http://example.com")
+	cfg := &checkers.ReachableConfig{
+		URL: u,
+		Dialer: func(network, address string, timeout time.Duration) (net.Conn, error) {
+			dials++
+			if dials < 3 {
+				return nil, errors.New("Failed check")
+			}
+			return nil, nil
+		},
+		DatadogClient: dd,
+		Retry:         retry.Constant{Retries: 2},
+	}
+	c, err := checkers.NewReachableChecker(cfg)
+	assert.NoError(err)
+
+	_, err = c.Status()
+	assert.NoError(err)
+	assert.Equal(3, dials)
+	assert.Equal(0, dd.IncrCallCount())
+
+	dials = 0
+	cfg.Retry = retry.Constant{Retries: 1}
+	c, err = checkers.NewReachableChecker(cfg)
+	assert.NoError(err)
+
+	_, err = c.Status()
+	assert.EqualError(err, "Failed check")
+	assert.Equal(2, dials)
+	assert.Equal(1, dd.IncrCallCount())
+}
+
+func TestReachableUsingTCPServer(t *testing.T) {
+	assert := assert.New(t)
+
+	server, err := mockdeps.NewTCPEcho()
+	assert.NoError(err)
+
+	u, _ := url.Parse("tcp://" + server.Addr())
+	c, err := checkers.NewReachableChecker(&checkers.ReachableConfig{URL: u})
+	assert.NoError(err)
+
+	_, err = c.Status()
+	assert.NoError(err)
+
+	server.Close()
+
+	_, err = c.Status()
+	assert.Error(err)
+}
@@ -1,10 +1,16 @@
 package checkers
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis"
+
+	"github.com/InVisionApp/go-health/checkers/dsn"
+	"github.com/InVisionApp/go-health/describe"
 )
 
 const (
@@ -23,21 +29,50 @@ const (
 //
 // "Set" is optional; perform a "SET" on a key; refer to the "RedisSetOptions" docs for details.
 //
+// "Keys" is optional; assert the existence, TTL and freshness of keys; refer
+// to the "RedisKeyOptions" docs for details.
+//
+// "PubSub" is optional; perform a Pub/Sub round-trip on a canary channel;
+// refer to the "RedisPubSubOptions" docs for details.
+//
+// "Metrics" is optional; receives the connection pool statistics on every check.
+//
 // Note: At least _one_ check method must be set/enabled; you can also enable
 // _all_ of the check methods (ie. perform a ping, set this key and now try to
 // retrieve that key).
 type RedisConfig struct {
-	Auth *RedisAuthConfig
-	Ping bool
-	Set  *RedisSetOptions
-	Get  *RedisGetOptions
+	Auth    *RedisAuthConfig
+	Ping    bool
+	Set     *RedisSetOptions
+	Get     *RedisGetOptions
+	Keys    []*RedisKeyOptions
+	PubSub  *RedisPubSubOptions
+	Metrics MetricsCollector
+}
+
+// RedisDetails is returned as the details of a redis check if any of the
+// check methods that report details are enabled.
+type RedisDetails struct {
+	Keys   []*RedisKeyDetails  `json:"keys,omitempty"`
+	PubSub *RedisPubSubDetails `json:"pubsub,omitempty"`
 }
 
 // RedisAuthConfig defines how to connect to redis.
+//
+// "Addr" is either in `host:port` format or a `redis://:password@host:port/db`
+// URL (`rediss://` connects via TLS); the password and db of the URL are used
+// unless "Password" or "DB" are set. Refer to the "dsn" package for all
+// accepted formats.
+//
+// "CredentialsProvider" is optional; every new connection authenticates with
+// its current credentials instead of "Password", so that rotated passwords
+// are picked up without rebuilding the checker (the username is only sent if
+// set, for Redis 6 ACLs).
 type RedisAuthConfig struct {
-	Addr     string // `host:port` format
-	Password string // leave blank if no password
-	DB       int    // leave unset if no specific db
+	Addr                string // `host:port` format or redis:// URL
+	Password            string // leave blank if no password
+	DB                  int    // leave unset if no specific db
+	CredentialsProvider CredentialsProvider
 }
 
 // RedisSetOptions contains attributes that can alter the behavior of the redis
@@ -75,35 +110,79 @@ type RedisGetOptions struct {
 type Redis struct {
 	Config *RedisConfig
 	client *redis.Client
+
+	pubSubRoundTrip func(channel, message string, timeout time.Duration) error
 }
 
 // NewRedis creates a new "go-redis/redis" checker that can be used w/ "AddChecks()".
 func NewRedis(cfg *RedisConfig) (*Redis, error) {
 	// validate settings
 	if err := validateRedisConfig(cfg); err != nil {
-		return nil, fmt.Errorf("Unable to validate redis config: %v", err)
+		return nil, fmt.Errorf("Unable to validate redis config: %w", err)
 	}
 
+	// the addr has been validated above
+	opts, _ := redisOptions(cfg.Auth)
+
 	// try to connect
-	c := redis.NewClient(&redis.Options{
-		Addr:     cfg.Auth.Addr,
-		Password: cfg.Auth.Password, // no password set
-		DB:       cfg.Auth.DB,       // use default DB
-	})
+	c := redis.NewClient(opts)
 
 	if _, err := c.Ping().Result(); err != nil {
 		return nil, fmt.Errorf("Unable to establish initial connection to redis: %v", err)
 	}
 
-	return &Redis{
+	r := &Redis{
 		Config: cfg,
 		client: c,
-	}, nil
+	}
+
+	r.pubSubRoundTrip = r.runPubSubRoundTrip
+
+	return r, nil
+}
+
+// Describe returns the type, target and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (r *Redis) Describe() describe.Description {
+	desc := describe.Description{
+		Type:         "redis",
+		Capabilities: make([]string, 0),
+	}
+
+	if r.client != nil {
+		desc.Target = r.client.Options().Addr
+	}
+
+	if r.Config.Ping {
+		desc.Capabilities = append(desc.Capabilities, "ping")
+	}
+
+	if r.Config.Set != nil {
+		desc.Capabilities = append(desc.Capabilities, "set")
+	}
+
+	if r.Config.Get != nil {
+		desc.Capabilities = append(desc.Capabilities, "get")
+	}
+
+	if len(r.Config.Keys) > 0 {
+		desc.Capabilities = append(desc.Capabilities, "keys")
+	}
+
+	if r.Config.PubSub != nil {
+		desc.Capabilities = append(desc.Capabilities, "pubsub")
+	}
+
+	return desc
 }
 
 // Status is used for performing a redis check against a dependency; it satisfies
 // the "ICheckable" interface.
 func (r *Redis) Status() (interface{}, error) {
+	if r.Config.Metrics != nil {
+		r.emitMetrics()
+	}
+
 	if r.Config.Ping {
 		if _, err := r.client.Ping().Result(); err != nil {
 			return nil, fmt.Errorf("Ping failed: %v", err)
@@ -137,32 +216,73 @@ func (r *Redis) Status() (interface{}, error) {
 		}
 	}
 
-	return nil, nil
+	if len(r.Config.Keys) == 0 && r.Config.PubSub == nil {
+		return nil, nil
+	}
+
+	details := &RedisDetails{}
+
+	if len(r.Config.Keys) > 0 {
+		keyDetails, err := r.checkKeys()
+		details.Keys = keyDetails
+		if err != nil {
+			return details, err
+		}
+	}
+
+	if r.Config.PubSub != nil {
+		pubSubDetails, err := r.checkPubSub()
+		details.PubSub = pubSubDetails
+		if err != nil {
+			return details, err
+		}
+	}
+
+	return details, nil
 }
 
-func validateRedisConfig(cfg *RedisConfig) error {
-	if cfg == nil {
-		return fmt.Errorf("Main config cannot be nil")
+// Close closes the underlying redis client (see "health.StopWithContext()").
+func (r *Redis) Close() error {
+	if r.client == nil {
+		return nil
 	}
 
-	if cfg.Auth == nil {
-		return fmt.Errorf("Auth config cannot be nil")
+	return r.client.Close()
+}
+
+func (r *Redis) emitMetrics() {
+	tags := map[string]string{"addr": r.client.Options().Addr}
+	stats := r.client.PoolStats()
+
+	r.Config.Metrics.Gauge(MetricRedisTotalConns, float64(stats.TotalConns), tags)
+	r.Config.Metrics.Gauge(MetricRedisIdleConns, float64(stats.IdleConns), tags)
+	r.Config.Metrics.Gauge(MetricRedisPoolTimeouts, float64(stats.Timeouts), tags)
+}
+
+func validateRedisConfig(cfg *RedisConfig) error {
+	v := &validation{}
+
+	if v.check(cfg == nil, "", "Main config cannot be nil") {
+		return v.err()
 	}
 
-	if cfg.Auth.Addr == "" {
-		return fmt.Errorf("Addr string must be set in auth config")
+	if !v.check(cfg.Auth == nil, "Auth", "Auth config cannot be nil") {
+		if !v.check(cfg.Auth.Addr == "", "Auth.Addr", "Addr string must be set in auth config") {
+			if _, err := redisOptions(cfg.Auth); err != nil {
+				v.fail("Auth.Addr", "%v", err)
+			}
+		}
 	}
 
 	// At least one check method must be set
-	if !cfg.Ping && cfg.Set == nil && cfg.Get == nil {
-		return fmt.Errorf("At minimum, either cfg.Ping, cfg.Set or cfg.Get must be set")
-	}
+	v.check(!cfg.Ping && cfg.Set == nil && cfg.Get == nil && len(cfg.Keys) == 0 && cfg.PubSub == nil, "",
+		"At minimum, either cfg.Ping, cfg.Set, cfg.Get, cfg.Keys or cfg.PubSub must be set")
+
+	v.merge(validateRedisKeyOptions(cfg.Keys))
 
 	// If .Set is set, verify that at minimum .Key is set
 	if cfg.Set != nil {
-		if cfg.Set.Key == "" {
-			return fmt.Errorf("If cfg.Set is used, cfg.Set.Key must be set")
-		}
+		v.check(cfg.Set.Key == "", "Set.Key", "If cfg.Set is used, cfg.Set.Key must be set")
 
 		if cfg.Set.Value == "" {
 			cfg.Set.Value = RedisDefaultSetValue
@@ -171,10 +291,69 @@ func validateRedisConfig(cfg *RedisConfig) error {
 
 	// If .Get is set, verify that at minimum .Key is set
 	if cfg.Get != nil {
-		if cfg.Get.Key == "" {
-			return fmt.Errorf("If cfg.Get is used, cfg.Get.Key must be set")
-		}
+		v.check(cfg.Get.Key == "", "Get.Key", "If cfg.Get is used, cfg.Get.Key must be set")
+	}
+
+	if cfg.PubSub != nil {
+		setRedisPubSubDefaults(cfg.PubSub)
+	}
+
+	return v.err()
+}
+
+// builds the client options from the auth config
+func redisOptions(auth *RedisAuthConfig) (*redis.Options, error) {
+	addr, err := dsn.Parse(auth.Addr, dsn.Redis)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse Addr: %v", err)
+	}
+
+	opts := &redis.Options{
+		Addr:     addr.Addr(),
+		Password: auth.Password,
+		DB:       auth.DB,
+	}
+
+	if opts.Password == "" {
+		opts.Password = addr.Password
+	}
+
+	if opts.DB == 0 && addr.Database != "" {
+		// validated by the "dsn" package
+		opts.DB, _ = strconv.Atoi(addr.Database)
+	}
+
+	if addr.Scheme == "rediss" {
+		host, _, _ := net.SplitHostPort(opts.Addr)
+		opts.TLSConfig = &tls.Config{ServerName: host}
+	}
+
+	if auth.CredentialsProvider != nil {
+		opts.Password = ""
+		opts.OnConnect = redisAuthenticator(auth.CredentialsProvider)
 	}
 
-	return nil
+	return opts, nil
+}
+
+// authenticates every new connection with the current credentials of the
+// provider
+func redisAuthenticator(provider CredentialsProvider) func(cn *redis.Conn) error {
+	return func(cn *redis.Conn) error {
+		creds, err := fetchCredentials(provider)
+		if err != nil {
+			return err
+		}
+
+		if creds.Password == "" {
+			return nil
+		}
+
+		args := []interface{}{"auth", creds.Password}
+		if creds.Username != "" {
+			args = []interface{}{"auth", creds.Username, creds.Password}
+		}
+
+		return cn.Process(redis.NewStatusCmd(args...))
+	}
 }
@@ -0,0 +1,128 @@
+package checkers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// RedisKeyOptions contains attributes for asserting the state of a single key
+// (ie. a key maintained by a cache warmer or a session store).
+//
+// "Key" is _required_; the key must exist.
+//
+// "MinTTL" is optional; fail if the remaining TTL of the key is lower.
+//
+// "MaxTTL" is optional; fail if the remaining TTL of the key is higher. If
+// either "MinTTL" or "MaxTTL" is set, the key must have a TTL.
+//
+// "MaxAge" is optional; the value of the key is parsed as a timestamp and the
+// check fails if it is older than "MaxAge".
+//
+// "TimestampLayout" is optional; the layout (as used by "time.Parse") of the
+// timestamp stored in the key; defaults to a unix timestamp (in seconds).
+type RedisKeyOptions struct {
+	Key             string
+	MinTTL          time.Duration
+	MaxTTL          time.Duration
+	MaxAge          time.Duration
+	TimestampLayout string
+}
+
+// RedisKeyDetails contains the observed state of a single key.
+type RedisKeyDetails struct {
+	Key string `json:"key"`
+	TTL string `json:"ttl,omitempty"`
+	Age string `json:"age,omitempty"`
+}
+
+func (r *Redis) checkKeys() ([]*RedisKeyDetails, error) {
+	details := make([]*RedisKeyDetails, 0, len(r.Config.Keys))
+
+	for _, k := range r.Config.Keys {
+		keyDetails := &RedisKeyDetails{Key: k.Key}
+		details = append(details, keyDetails)
+
+		exists, err := r.client.Exists(k.Key).Result()
+		if err != nil {
+			return details, fmt.Errorf("Unable to check existence of key '%v': %v", k.Key, err)
+		}
+
+		if exists == 0 {
+			return details, fmt.Errorf("Key '%v' does not exist", k.Key)
+		}
+
+		if k.MinTTL > 0 || k.MaxTTL > 0 {
+			ttl, err := r.client.TTL(k.Key).Result()
+			if err != nil {
+				return details, fmt.Errorf("Unable to get TTL of key '%v': %v", k.Key, err)
+			}
+
+			// redis returns a negative TTL for keys without an expiration
+			if ttl < 0 {
+				return details, fmt.Errorf("Key '%v' does not have a TTL", k.Key)
+			}
+
+			keyDetails.TTL = ttl.String()
+
+			if k.MinTTL > 0 && ttl < k.MinTTL {
+				return details, fmt.Errorf("TTL of key '%v' (%v) is lower than the minimum (%v)", k.Key, ttl, k.MinTTL)
+			}
+
+			if k.MaxTTL > 0 && ttl > k.MaxTTL {
+				return details, fmt.Errorf("TTL of key '%v' (%v) is higher than the maximum (%v)", k.Key, ttl, k.MaxTTL)
+			}
+		}
+
+		if k.MaxAge > 0 {
+			val, err := r.client.Get(k.Key).Result()
+			if err != nil && err != redis.Nil {
+				return details, fmt.Errorf("Unable to get value of key '%v': %v", k.Key, err)
+			}
+
+			ts, err := parseRedisTimestamp(val, k.TimestampLayout)
+			if err != nil {
+				return details, fmt.Errorf("Unable to parse timestamp in key '%v': %v", k.Key, err)
+			}
+
+			age := time.Since(ts)
+			keyDetails.Age = age.String()
+
+			if age > k.MaxAge {
+				return details, fmt.Errorf("Key '%v' is stale; age (%v) exceeds the maximum (%v)", k.Key, age, k.MaxAge)
+			}
+		}
+	}
+
+	return details, nil
+}
+
+func parseRedisTimestamp(val, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.Parse(layout, val)
+	}
+
+	secs, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(secs, 0), nil
+}
+
+func validateRedisKeyOptions(keys []*RedisKeyOptions) error {
+	v := &validation{}
+
+	for i, k := range keys {
+		if v.check(k == nil || k.Key == "", fmt.Sprintf("Keys[%v].Key", i), "cfg.Keys[%v].Key must be set", i) {
+			continue
+		}
+
+		v.check(k.MinTTL > 0 && k.MaxTTL > 0 && k.MinTTL > k.MaxTTL, fmt.Sprintf("Keys[%v].MinTTL", i),
+			"cfg.Keys[%v].MinTTL cannot be greater than MaxTTL", i)
+	}
+
+	return v.err()
+}
@@ -0,0 +1,104 @@
+package checkers
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRedisKeysStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		cfg := &RedisConfig{
+			Keys: []*RedisKeyOptions{
+				{Key: "config"},
+				{Key: "session", MinTTL: time.Minute, MaxTTL: time.Hour},
+				{Key: "warmed-at", MaxAge: time.Minute},
+			},
+		}
+		checker, server, err := setupRedis(cfg)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		server.Set("config", "{}")
+		server.Set("session", "abc")
+		server.SetTTL("session", 30*time.Minute)
+		server.Set("warmed-at", strconv.FormatInt(time.Now().Unix(), 10))
+
+		data, err := checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		details := data.(*RedisDetails)
+		Expect(details.Keys).To(HaveLen(3))
+		Expect(details.Keys[1].TTL).To(Equal("30m0s"))
+		Expect(details.Keys[2].Age).ToNot(BeEmpty())
+	})
+
+	t.Run("Should error if the key does not exist", func(t *testing.T) {
+		checker, server, err := setupRedis(&RedisConfig{Keys: []*RedisKeyOptions{{Key: "missing"}}})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		_, err = checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Key 'missing' does not exist"))
+	})
+
+	t.Run("Should error if the TTL is out of bounds", func(t *testing.T) {
+		checker, server, err := setupRedis(&RedisConfig{
+			Keys: []*RedisKeyOptions{{Key: "session", MinTTL: time.Hour}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		server.Set("session", "abc")
+
+		_, err = checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Key 'session' does not have a TTL"))
+
+		server.SetTTL("session", time.Minute)
+
+		_, err = checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is lower than the minimum"))
+	})
+
+	t.Run("Should error if the key is stale", func(t *testing.T) {
+		checker, server, err := setupRedis(&RedisConfig{
+			Keys: []*RedisKeyOptions{{Key: "warmed-at", MaxAge: time.Minute, TimestampLayout: time.RFC3339}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		server.Set("warmed-at", time.Now().Add(-time.Hour).Format(time.RFC3339))
+
+		_, err = checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Key 'warmed-at' is stale"))
+	})
+
+	t.Run("Should error if the timestamp cannot be parsed", func(t *testing.T) {
+		checker, server, err := setupRedis(&RedisConfig{
+			Keys: []*RedisKeyOptions{{Key: "warmed-at", MaxAge: time.Minute}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		server.Set("warmed-at", "yesterday")
+
+		_, err = checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to parse timestamp in key 'warmed-at'"))
+	})
+}
+
+func TestValidateRedisKeyOptions(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(validateRedisKeyOptions([]*RedisKeyOptions{{}})).To(MatchError(ContainSubstring("cfg.Keys[0].Key must be set")))
+	Expect(validateRedisKeyOptions([]*RedisKeyOptions{{Key: "k", MinTTL: time.Hour, MaxTTL: time.Minute}})).To(MatchError(ContainSubstring("MinTTL cannot be greater than MaxTTL")))
+}
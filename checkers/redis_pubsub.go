@@ -0,0 +1,100 @@
+package checkers
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	// RedisDefaultPubSubChannel will be used if the Pub/Sub check method is
+	// enabled and "RedisPubSubOptions.Channel" is _not_ set.
+	RedisDefaultPubSubChannel = "go-health/canary"
+
+	defaultRedisPubSubTimeout = time.Duration(1) * time.Second
+)
+
+// RedisPubSubOptions contains attributes that can alter the behavior of the
+// redis Pub/Sub round-trip check. The check subscribes to a canary channel,
+// publishes a unique message to it and verifies that its own subscription
+// receives the message; this validates that Pub/Sub (and not just regular
+// commands) works, including through proxies such as Envoy or Twemproxy.
+//
+// "Channel" is optional; the canary channel; if not set, it will be set to
+// "RedisDefaultPubSubChannel".
+//
+// "Timeout" is optional; how long to wait for the published message to be
+// received; defaults to "1s".
+type RedisPubSubOptions struct {
+	Channel string
+	Timeout time.Duration
+}
+
+// RedisPubSubDetails contains the result of the Pub/Sub round-trip check.
+type RedisPubSubDetails struct {
+	Channel   string `json:"channel"`
+	RoundTrip string `json:"round_trip,omitempty"`
+}
+
+func (r *Redis) checkPubSub() (*RedisPubSubDetails, error) {
+	details := &RedisPubSubDetails{Channel: r.Config.PubSub.Channel}
+
+	// other instances may use the same canary channel; only our own message counts
+	hostname, _ := os.Hostname()
+	message := fmt.Sprintf("%v-%v-%v", hostname, os.Getpid(), time.Now().UnixNano())
+
+	start := time.Now()
+
+	if err := r.pubSubRoundTrip(r.Config.PubSub.Channel, message, r.Config.PubSub.Timeout); err != nil {
+		return details, fmt.Errorf("Pub/Sub round-trip failed: %v", err)
+	}
+
+	details.RoundTrip = time.Since(start).String()
+
+	return details, nil
+}
+
+func (r *Redis) runPubSubRoundTrip(channel, message string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	ps := r.client.Subscribe(channel)
+	defer ps.Close()
+
+	// wait for the subscription to be confirmed, otherwise the message could
+	// be published before we are subscribed
+	if _, err := ps.ReceiveTimeout(timeout); err != nil {
+		return fmt.Errorf("unable to subscribe to channel '%v': %v", channel, err)
+	}
+
+	if err := r.client.Publish(channel, message).Err(); err != nil {
+		return fmt.Errorf("unable to publish to channel '%v': %v", channel, err)
+	}
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("message was not received within %v", timeout)
+		}
+
+		msg, err := ps.ReceiveTimeout(remaining)
+		if err != nil {
+			return fmt.Errorf("message was not received within %v: %v", timeout, err)
+		}
+
+		if m, ok := msg.(*redis.Message); ok && m.Payload == message {
+			return nil
+		}
+	}
+}
+
+func setRedisPubSubDefaults(opts *RedisPubSubOptions) {
+	if opts.Channel == "" {
+		opts.Channel = RedisDefaultPubSubChannel
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultRedisPubSubTimeout
+	}
+}
@@ -0,0 +1,79 @@
+package checkers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRedisPubSubStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		checker, server, err := setupRedis(&RedisConfig{PubSub: &RedisPubSubOptions{}})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		var gotChannel, gotMessage string
+		var gotTimeout time.Duration
+
+		checker.pubSubRoundTrip = func(channel, message string, timeout time.Duration) error {
+			gotChannel, gotMessage, gotTimeout = channel, message, timeout
+			return nil
+		}
+
+		data, err := checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.(*RedisDetails).PubSub.Channel).To(Equal(RedisDefaultPubSubChannel))
+		Expect(data.(*RedisDetails).PubSub.RoundTrip).ToNot(BeEmpty())
+
+		Expect(gotChannel).To(Equal(RedisDefaultPubSubChannel))
+		Expect(gotMessage).ToNot(BeEmpty())
+		Expect(gotTimeout).To(Equal(defaultRedisPubSubTimeout))
+	})
+
+	t.Run("Should publish a unique message on every check", func(t *testing.T) {
+		checker, server, err := setupRedis(&RedisConfig{PubSub: &RedisPubSubOptions{Channel: "canary"}})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		messages := map[string]bool{}
+		checker.pubSubRoundTrip = func(channel, message string, timeout time.Duration) error {
+			messages[message] = true
+			return nil
+		}
+
+		checker.Status()
+		checker.Status()
+
+		Expect(messages).To(HaveLen(2))
+	})
+
+	t.Run("Should error if the round-trip fails", func(t *testing.T) {
+		checker, server, err := setupRedis(&RedisConfig{PubSub: &RedisPubSubOptions{}})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		checker.pubSubRoundTrip = func(channel, message string, timeout time.Duration) error {
+			return fmt.Errorf("message was not received within %v", timeout)
+		}
+
+		data, err := checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Pub/Sub round-trip failed: message was not received within 1s"))
+		Expect(data.(*RedisDetails).PubSub.RoundTrip).To(BeEmpty())
+	})
+
+	t.Run("Should error if the server does not support Pub/Sub", func(t *testing.T) {
+		// miniredis does not implement SUBSCRIBE
+		checker, server, err := setupRedis(&RedisConfig{PubSub: &RedisPubSubOptions{Timeout: 100 * time.Millisecond}})
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		_, err = checker.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Pub/Sub round-trip failed"))
+	})
+}
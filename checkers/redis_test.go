@@ -2,10 +2,13 @@ package checkers
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/alicebob/miniredis"
 	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/checkers/internal/mockdeps"
 )
 
 func TestNewRedis(t *testing.T) {
@@ -28,6 +31,77 @@ func TestNewRedis(t *testing.T) {
 		Expect(r).ToNot(BeNil())
 	})
 
+	t.Run("Should authenticate and select the DB", func(t *testing.T) {
+		server, err := mockdeps.NewRedis()
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		server.RequirePassword("secret")
+
+		r, err := NewRedis(&RedisConfig{
+			Ping: true,
+			Set:  &RedisSetOptions{Key: "foo", Value: "bar"},
+			Auth: &RedisAuthConfig{
+				Addr:     server.Addr(),
+				Password: "secret",
+				DB:       3,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = r.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		value, ok := server.Get(3, "foo")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal("bar"))
+	})
+
+	t.Run("Should authenticate new connections with the current credentials", func(t *testing.T) {
+		server, err := mockdeps.NewRedis()
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		server.RequirePassword("first")
+
+		var lock sync.Mutex
+		password := "first"
+
+		r, err := NewRedis(&RedisConfig{
+			Ping: true,
+			Auth: &RedisAuthConfig{
+				Addr:     server.Addr(),
+				Password: "ignored",
+				CredentialsProvider: CredentialsProviderFunc(func() (Credentials, error) {
+					lock.Lock()
+					defer lock.Unlock()
+
+					return Credentials{Username: "health", Password: password}, nil
+				}),
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer r.Close()
+
+		// rotate the password; the open connection stays authenticated
+		server.RequirePassword("second")
+
+		lock.Lock()
+		password = "second"
+		lock.Unlock()
+
+		_, err = r.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		// new connections pick up the rotated password
+		server.Disconnect()
+
+		Eventually(func() error {
+			_, err := r.Status()
+			return err
+		}).Should(Succeed())
+	})
+
 	t.Run("Bad config should error", func(t *testing.T) {
 		var cfg *RedisConfig
 		r, err := NewRedis(cfg)
@@ -88,7 +162,7 @@ func TestValidateRedisConfig(t *testing.T) {
 
 		err := validateRedisConfig(cfg)
 		Expect(err).To(HaveOccurred())
-		Expect(err.Error()).To(ContainSubstring("At minimum, either cfg.Ping, cfg.Set or cfg.Get"))
+		Expect(err.Error()).To(ContainSubstring("At minimum, either cfg.Ping, cfg.Set, cfg.Get, cfg.Keys or cfg.PubSub"))
 	})
 
 	t.Run("Should error if .Set is used but key is undefined", func(t *testing.T) {
@@ -131,6 +205,47 @@ func TestValidateRedisConfig(t *testing.T) {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(cfg.Set.Value).To(Equal(RedisDefaultSetValue))
 	})
+
+	t.Run("Should error if addr has wrong format", func(t *testing.T) {
+		cfg := &RedisConfig{
+			Ping: true,
+			Auth: &RedisAuthConfig{
+				Addr: "mongodb://localhost:6379",
+			},
+		}
+
+		err := validateRedisConfig(cfg)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to parse Addr: Unsupported scheme 'mongodb'"))
+	})
+}
+
+func TestRedisOptions(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should accept host:port", func(t *testing.T) {
+		opts, err := redisOptions(&RedisAuthConfig{Addr: "localhost", Password: "secret", DB: 2})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opts.Addr).To(Equal("localhost:6379"))
+		Expect(opts.Password).To(Equal("secret"))
+		Expect(opts.DB).To(Equal(2))
+		Expect(opts.TLSConfig).To(BeNil())
+	})
+
+	t.Run("Should use the password and db of a URL unless set explicitly", func(t *testing.T) {
+		opts, err := redisOptions(&RedisAuthConfig{Addr: "rediss://:secret@Cache.internal:6380/3"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opts.Addr).To(Equal("cache.internal:6380"))
+		Expect(opts.Password).To(Equal("secret"))
+		Expect(opts.DB).To(Equal(3))
+		Expect(opts.TLSConfig).ToNot(BeNil())
+		Expect(opts.TLSConfig.ServerName).To(Equal("cache.internal"))
+
+		opts, err = redisOptions(&RedisAuthConfig{Addr: "redis://:secret@localhost/3", Password: "other", DB: 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opts.Password).To(Equal("other"))
+		Expect(opts.DB).To(Equal(1))
+	})
 }
 
 func TestRedisStatus(t *testing.T) {
@@ -334,3 +449,16 @@ func setupRedis(cfg *RedisConfig) (*Redis, *miniredis.Miniredis, error) {
 
 	return checker, server, nil
 }
+
+func TestRedisClose(t *testing.T) {
+	RegisterTestingT(t)
+
+	checker, server, err := setupRedis(&RedisConfig{Ping: true})
+	Expect(err).ToNot(HaveOccurred())
+	defer server.Close()
+
+	Expect(checker.Close()).To(Succeed())
+
+	_, err = checker.Status()
+	Expect(err).To(HaveOccurred())
+}
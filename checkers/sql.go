@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
+
+	"github.com/InVisionApp/go-health/describe"
 )
 
 //go:generate counterfeiter -o ../fakes/isqlpinger.go . SQLPinger
@@ -79,6 +82,12 @@ type SQLConfig struct {
 	// ExecerResultHandler handles the result of
 	// the ExecContext function
 	ExecerResultHandler SQLExecerResultHandler
+
+	// CloseDB closes the Pinger, Queryer and Execer
+	// (if they implement io.Closer, ie. sql.DB) when
+	// the checker is closed; only set it if the
+	// database is dedicated to the health check
+	CloseDB bool
 }
 
 // SQL implements the "ICheckable" interface
@@ -123,19 +132,39 @@ func DefaultExecHandler(result sql.Result) (bool, error) {
 
 // this makes sure the sql check is properly configured
 func validateSQLConfig(cfg *SQLConfig) error {
-	if cfg == nil {
-		return fmt.Errorf("config is required")
+	v := &validation{}
+
+	if v.check(cfg == nil, "", "config is required") {
+		return v.err()
 	}
 
-	if cfg.Execer == nil && cfg.Queryer == nil && cfg.Pinger == nil {
-		return fmt.Errorf("one of Execer, Queryer, or Pinger is required in SQLConfig")
+	v.check(cfg.Execer == nil && cfg.Queryer == nil && cfg.Pinger == nil, "", "one of Execer, Queryer, or Pinger is required in SQLConfig")
+	v.check((cfg.Execer != nil || cfg.Queryer != nil) && len(cfg.Query) == 0, "Query", "SQLConfig.Query is required")
+
+	return v.err()
+}
+
+// Describe returns the type and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (s *SQL) Describe() describe.Description {
+	desc := describe.Description{
+		Type:         "sql",
+		Capabilities: make([]string, 0),
 	}
 
-	if (cfg.Execer != nil || cfg.Queryer != nil) && len(cfg.Query) == 0 {
-		return fmt.Errorf("SQLConfig.Query is required")
+	if s.Config.Pinger != nil {
+		desc.Capabilities = append(desc.Capabilities, "ping")
 	}
 
-	return nil
+	if s.Config.Queryer != nil {
+		desc.Capabilities = append(desc.Capabilities, "query")
+	}
+
+	if s.Config.Execer != nil {
+		desc.Capabilities = append(desc.Capabilities, "exec")
+	}
+
+	return desc
 }
 
 // Status is used for performing a database ping against a dependency; it satisfies
@@ -210,3 +239,29 @@ func (s *SQL) runQueryer() (interface{}, error) {
 
 	return nil, nil
 }
+
+// Close closes the database if "CloseDB" is set (see
+// "health.StopWithContext()"); returns the first error.
+func (s *SQL) Close() error {
+	if s.Config == nil || !s.Config.CloseDB {
+		return nil
+	}
+
+	var firstErr error
+	closed := make(map[io.Closer]bool)
+
+	for _, value := range []interface{}{s.Config.Pinger, s.Config.Queryer, s.Config.Execer} {
+		closer, ok := value.(io.Closer)
+		if !ok || closed[closer] {
+			continue
+		}
+
+		closed[closer] = true
+
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
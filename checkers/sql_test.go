@@ -370,3 +370,39 @@ func TestRunQueryer(t *testing.T) {
 		Expect(err.Error()).To(Equal("userland query result handler returned false"))
 	})
 }
+
+func TestSQLClose(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should close the database if CloseDB is set", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+
+		mock.ExpectClose()
+
+		s, err := NewSQL(&SQLConfig{
+			Pinger:  db,
+			Queryer: db,
+			Query:   querySQL,
+			CloseDB: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(s.Close()).To(Succeed())
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	t.Run("Should not close the database by default", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+
+		s, err := NewSQL(&SQLConfig{
+			Pinger: db,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		// closing the database would fail as it is not expected
+		Expect(s.Close()).To(Succeed())
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+}
@@ -0,0 +1,203 @@
+package checkers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+
+	"github.com/InVisionApp/go-health/describe"
+)
+
+const (
+	defaultTLSMinValidity = time.Duration(24*7) * time.Hour
+)
+
+// TLSConfig is used for configuring a TLS listener certificate check. The only
+// required field is `Addr`.
+//
+// "Addr" is _required_; the `host:port` of the listener to check (typically
+// the service's own HTTPS or gRPC listener).
+//
+// "ServerName" is optional; it is used for SNI and hostname verification and
+// defaults to the host portion of "Addr". It must be set if "Addr" has no host
+// (ie. ":8443"), unless "InsecureSkipVerify" is set.
+//
+// "RootCAs" is optional; if undefined, the system root CA pool is used.
+//
+// "MinValidity" is optional and defaults to "168h"; the check fails if the
+// served certificate expires sooner than that.
+//
+// "CertFile" is optional; if set, the served leaf certificate is compared
+// against the (PEM encoded) certificate on disk, catching cases where the
+// certificate was rotated on disk but the server never reloaded it.
+//
+// "NextProtos" is optional; set it to `[]string{"h2"}` when checking a gRPC
+// listener that requires ALPN.
+//
+// "InsecureSkipVerify" is optional; if set, the certificate chain and the
+// hostname are not verified (the expiry and "CertFile" are still checked).
+//
+// "Timeout" is optional and defaults to "DefaultTimeout".
+type TLSConfig struct {
+	Addr        string         // Required
+	ServerName  string         // Optional (default: host of Addr)
+	RootCAs     *x509.CertPool // Optional (default: system roots)
+	MinValidity time.Duration  // Optional (default 168h)
+	CertFile    string         // Optional
+	NextProtos  []string       // Optional
+	Timeout     time.Duration  // Optional (default DefaultTimeout)
+
+	InsecureSkipVerify bool // Optional
+}
+
+// TLSDetails is returned as the details of a TLS check.
+type TLSDetails struct {
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	Serial      string    `json:"serial"`
+	NotAfter    time.Time `json:"not_after"`
+	ExpiresIn   string    `json:"expires_in"`
+	Fingerprint string    `json:"fingerprint_sha256"`
+}
+
+// TLS implements the "ICheckable" interface.
+type TLS struct {
+	Config *TLSConfig
+}
+
+// NewTLS creates a new TLS listener certificate checker that can be used for
+// ".AddCheck(s)".
+func NewTLS(cfg *TLSConfig) (*TLS, error) {
+	if err := validateTLSConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate tls config: %w", err)
+	}
+
+	return &TLS{
+		Config: cfg,
+	}, nil
+}
+
+// Describe returns the type, target and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (t *TLS) Describe() describe.Description {
+	return describe.Description{
+		Type:         "tls",
+		Target:       t.Config.Addr,
+		Capabilities: []string{"handshake", "certificate_expiry"},
+	}
+}
+
+// Status is used for performing a TLS handshake against the configured
+// listener and validating the served certificate chain; it satisfies the
+// "ICheckable" interface.
+func (t *TLS) Status() (interface{}, error) {
+	dialer := &net.Dialer{Timeout: t.Config.Timeout}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", t.Config.Addr, &tls.Config{
+		ServerName: t.Config.ServerName,
+		RootCAs:    t.Config.RootCAs,
+		NextProtos: t.Config.NextProtos,
+
+		InsecureSkipVerify: t.Config.InsecureSkipVerify,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to complete TLS handshake with '%v': %v", t.Config.Addr, err)
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("Listener '%v' did not present a certificate", t.Config.Addr)
+	}
+
+	leaf := certs[0]
+	fingerprint := sha256.Sum256(leaf.Raw)
+
+	details := &TLSDetails{
+		Subject:     leaf.Subject.String(),
+		Issuer:      leaf.Issuer.String(),
+		Serial:      leaf.SerialNumber.String(),
+		NotAfter:    leaf.NotAfter,
+		ExpiresIn:   time.Until(leaf.NotAfter).Round(time.Second).String(),
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+	}
+
+	if time.Until(leaf.NotAfter) < t.Config.MinValidity {
+		return details, fmt.Errorf("Certificate served by '%v' expires at %v (less than %v from now)",
+			t.Config.Addr, leaf.NotAfter, t.Config.MinValidity)
+	}
+
+	if t.Config.CertFile != "" {
+		onDisk, err := readLeafCertificate(t.Config.CertFile)
+		if err != nil {
+			return details, fmt.Errorf("Unable to read certificate file: %v", err)
+		}
+
+		if !bytes.Equal(onDisk.Raw, leaf.Raw) {
+			return details, fmt.Errorf("Certificate served by '%v' (serial %v) does not match certificate on disk (serial %v); the listener may need to be reloaded",
+				t.Config.Addr, leaf.SerialNumber, onDisk.SerialNumber)
+		}
+	}
+
+	return details, nil
+}
+
+func readLeafCertificate(path string) (*x509.Certificate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no PEM encoded certificate found")
+		}
+
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+func validateTLSConfig(cfg *TLSConfig) error {
+	v := &validation{}
+
+	if v.check(cfg == nil, "", "Main config cannot be nil") {
+		return v.err()
+	}
+
+	if !v.check(cfg.Addr == "", "Addr", "Addr string must be set") {
+		host, _, err := net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			v.fail("Addr", "Unable to parse Addr: %v", err)
+		} else if cfg.ServerName == "" {
+			cfg.ServerName = host
+
+			v.check(host == "" && !cfg.InsecureSkipVerify, "ServerName",
+				"ServerName must be set if Addr has no host, unless InsecureSkipVerify is set")
+		}
+	}
+
+	v.check(cfg.MinValidity < 0, "MinValidity", "MinValidity cannot be negative")
+	v.check(cfg.Timeout < 0, "Timeout", "Timeout cannot be negative")
+
+	if cfg.MinValidity == 0 {
+		cfg.MinValidity = defaultTLSMinValidity
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	return v.err()
+}
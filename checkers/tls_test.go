@@ -0,0 +1,124 @@
+package checkers
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewTLS(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Happy path", func(t *testing.T) {
+		c, err := NewTLS(&TLSConfig{Addr: "localhost:8443"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Config.ServerName).To(Equal("localhost"))
+		Expect(c.Config.MinValidity).To(Equal(defaultTLSMinValidity))
+		Expect(c.Config.Timeout).To(Equal(DefaultTimeout))
+	})
+
+	t.Run("Should error with a nil cfg", func(t *testing.T) {
+		_, err := NewTLS(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+	})
+
+	t.Run("Should error with a bad addr", func(t *testing.T) {
+		_, err := NewTLS(&TLSConfig{Addr: "localhost"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to parse Addr"))
+	})
+
+	t.Run("Should require a server name if the addr has no host", func(t *testing.T) {
+		_, err := NewTLS(&TLSConfig{Addr: ":8443"})
+
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields()).To(Equal([]string{"ServerName"}))
+
+		c, err := NewTLS(&TLSConfig{Addr: ":8443", ServerName: "api.example.com"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Config.ServerName).To(Equal("api.example.com"))
+
+		_, err = NewTLS(&TLSConfig{Addr: ":8443", InsecureSkipVerify: true})
+		Expect(err).ToNot(HaveOccurred())
+	})
+}
+
+func TestTLSStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	// httptest certificates are issued for "example.com"
+	newChecker := func(cfg *TLSConfig) *TLS {
+		cfg.Addr = server.Listener.Addr().String()
+		cfg.ServerName = "example.com"
+		cfg.RootCAs = pool
+
+		c, err := NewTLS(cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		return c
+	}
+
+	t.Run("Happy path", func(t *testing.T) {
+		data, err := newChecker(&TLSConfig{MinValidity: time.Hour}).Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data.(*TLSDetails).NotAfter).To(Equal(server.Certificate().NotAfter))
+	})
+
+	t.Run("Should fail if the certificate expires too soon", func(t *testing.T) {
+		_, err := newChecker(&TLSConfig{MinValidity: time.Duration(200*365*24) * time.Hour}).Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("expires at"))
+	})
+
+	t.Run("Should fail if the chain can't be verified", func(t *testing.T) {
+		c := newChecker(&TLSConfig{})
+		c.Config.RootCAs = x509.NewCertPool()
+
+		_, err := c.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to complete TLS handshake"))
+	})
+
+	t.Run("Should skip the verification if asked to", func(t *testing.T) {
+		c, err := NewTLS(&TLSConfig{Addr: server.Listener.Addr().String(), InsecureSkipVerify: true, MinValidity: time.Hour})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("Should compare against the certificate on disk", func(t *testing.T) {
+		f, err := ioutil.TempFile("", "go-health-cert")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(f.Name())
+
+		pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		f.Close()
+
+		_, err = newChecker(&TLSConfig{MinValidity: time.Hour, CertFile: f.Name()}).Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		// an unparsable certificate on disk should fail the check
+		Expect(ioutil.WriteFile(f.Name(), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}), 0644)).To(Succeed())
+
+		_, err = newChecker(&TLSConfig{MinValidity: time.Hour, CertFile: f.Name()}).Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to read certificate file"))
+	})
+}
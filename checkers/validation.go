@@ -0,0 +1,88 @@
+package checkers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a single problem with a checker config.
+type FieldError struct {
+	// Field is the path of the offending field, ie. "Auth.Url" or
+	// "Keys[0].Key"; empty if the problem concerns the config as a whole
+	// (ie. none of the check methods is enabled)
+	Field string
+
+	// Message describes the problem
+	Message string
+}
+
+// Error returns the message of the problem
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// ValidationError is returned (wrapped) by the checker constructors if their
+// config is invalid. It contains all problems with the config (ie. a missing
+// URL, a negative timeout and conflicting options), rather than only the first
+// one; use "errors.As()" to retrieve it.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error returns the messages of all problems, separated by "; "
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Fields returns the paths of the offending fields, in the order the problems
+// were found.
+func (e *ValidationError) Fields() []string {
+	fields := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		fields = append(fields, fe.Field)
+	}
+
+	return fields
+}
+
+// validation collects the problems with a config
+type validation struct {
+	errs []*FieldError
+}
+
+// records a problem with the given field
+func (v *validation) fail(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// records a problem with the given field if "failed" is true; returns "failed"
+func (v *validation) check(failed bool, field, format string, args ...interface{}) bool {
+	if failed {
+		v.fail(field, format, args...)
+	}
+
+	return failed
+}
+
+// records the problems of a nested validation (ie. of an options struct)
+func (v *validation) merge(err error) {
+	if verr, ok := err.(*ValidationError); ok {
+		v.errs = append(v.errs, verr.Errors...)
+	} else if err != nil {
+		v.fail("", "%v", err)
+	}
+}
+
+// returns a "*ValidationError" with all recorded problems; nil if there are none
+func (v *validation) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: v.errs}
+}
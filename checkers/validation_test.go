@@ -0,0 +1,64 @@
+package checkers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValidationError(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should report all problems at once along with their fields", func(t *testing.T) {
+		_, err := NewRedis(&RedisConfig{
+			Auth: &RedisAuthConfig{Addr: "redis://h1,h2"},
+			Set:  &RedisSetOptions{},
+			Keys: []*RedisKeyOptions{{Key: "foo"}, {}},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to validate redis config: "))
+
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields()).To(Equal([]string{"Auth.Addr", "Keys[1].Key", "Set.Key"}))
+		Expect(verr.Error()).To(Equal("Unable to parse Addr: A redis DSN cannot contain more than one host; " +
+			"cfg.Keys[1].Key must be set; If cfg.Set is used, cfg.Set.Key must be set"))
+	})
+
+	t.Run("Should not report problems of fields that depend on a missing field", func(t *testing.T) {
+		err := validateMongoConfig(&MongoConfig{
+			Pool:         &MongoPoolOptions{},
+			ChangeStream: &MongoChangeStreamOptions{DB: "db", Collection: "c"},
+		})
+		Expect(err).To(HaveOccurred())
+
+		verr, ok := err.(*ValidationError)
+		Expect(ok).To(BeTrue())
+		Expect(verr.Fields()).To(Equal([]string{"Auth", "Pool", "ChangeStream.MaxInterval"}))
+	})
+
+	t.Run("Should report negative timeouts", func(t *testing.T) {
+		_, err := NewTLS(&TLSConfig{Timeout: -time.Second})
+
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields()).To(Equal([]string{"Addr", "Timeout"}))
+		Expect(err.Error()).To(ContainSubstring("Addr string must be set; Timeout cannot be negative"))
+
+		_, err = NewReachableChecker(&ReachableConfig{Timeout: -time.Second})
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Fields()).To(Equal([]string{"URL", "Timeout"}))
+	})
+
+	t.Run("Should only report the missing main config", func(t *testing.T) {
+		_, err := NewVersion(nil)
+
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Errors).To(HaveLen(1))
+		Expect(verr.Errors[0].Field).To(BeEmpty())
+		Expect(verr.Error()).To(Equal("Passed in config cannot be nil"))
+	})
+}
@@ -0,0 +1,423 @@
+package checkers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/globalsign/mgo"
+
+	"github.com/InVisionApp/go-health/describe"
+)
+
+var versionRegexp = regexp.MustCompile(`\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?`)
+
+// VersionFetcher is the signature for a function that returns the version
+// string of a dependency. The version may contain surrounding text (such as
+// the output of Postgres' "SELECT version()"); the first semver-like
+// substring is used.
+type VersionFetcher func() (string, error)
+
+// VersionConfig is used for configuring a dependency version compatibility
+// check.
+//
+// "Fetcher" is _required_; refer to "MongoVersionFetcher", "SQLVersionFetcher"
+// and "HTTPVersionFetcher" for bundled fetchers.
+//
+// "Constraint" is _required_; the supported semver range of the dependency.
+// Comparisons (`=`, `!=`, `>`, `>=`, `<`, `<=`), tilde (`~1.2`) and caret
+// (`^1.2.3`) ranges are supported; separate comparisons with a comma to
+// require all of them and with `||` to require any of them
+// (ie. `>=3.6, <5.0 || ^6.0`).
+type VersionConfig struct {
+	Fetcher    VersionFetcher
+	Constraint string
+}
+
+// VersionDetails is returned as the details of a version check.
+type VersionDetails struct {
+	Version    string `json:"version"`
+	Constraint string `json:"constraint"`
+}
+
+// Version implements the "ICheckable" interface.
+type Version struct {
+	Config *VersionConfig
+
+	constraint versionConstraint
+}
+
+// NewVersion creates a new dependency version checker that can be used for
+// ".AddCheck(s)".
+func NewVersion(cfg *VersionConfig) (*Version, error) {
+	if err := validateVersionConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate version config: %w", err)
+	}
+
+	// the constraint has been validated above
+	constraint, _ := parseVersionConstraint(cfg.Constraint)
+
+	return &Version{
+		Config:     cfg,
+		constraint: constraint,
+	}, nil
+}
+
+func validateVersionConfig(cfg *VersionConfig) error {
+	v := &validation{}
+
+	if v.check(cfg == nil, "", "Passed in config cannot be nil") {
+		return v.err()
+	}
+
+	v.check(cfg.Fetcher == nil, "Fetcher", "Fetcher cannot be nil")
+
+	if _, err := parseVersionConstraint(cfg.Constraint); err != nil {
+		v.fail("Constraint", "Unable to parse constraint: %v", err)
+	}
+
+	return v.err()
+}
+
+// Describe returns the type and capabilities of the checker (see
+// "health.ICheckableDescriber").
+func (v *Version) Describe() describe.Description {
+	return describe.Description{
+		Type:         "version",
+		Capabilities: []string{"version_constraint"},
+	}
+}
+
+// Status is used for fetching the version of a dependency and verifying that
+// it satisfies the configured constraint; it satisfies the "ICheckable"
+// interface.
+func (v *Version) Status() (interface{}, error) {
+	raw, err := v.Config.Fetcher()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to fetch version: %v", err)
+	}
+
+	version, err := parseSemver(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &VersionDetails{
+		Version:    version.String(),
+		Constraint: v.Config.Constraint,
+	}
+
+	if !v.constraint.matches(version) {
+		return details, fmt.Errorf("Dependency version '%v' does not satisfy constraint '%v'",
+			version, v.Config.Constraint)
+	}
+
+	return details, nil
+}
+
+// MongoVersionFetcher returns a "VersionFetcher" that reads the server
+// version via the "buildInfo" command.
+func MongoVersionFetcher(session *mgo.Session) VersionFetcher {
+	return func() (string, error) {
+		info, err := session.BuildInfo()
+		if err != nil {
+			return "", err
+		}
+
+		return info.Version, nil
+	}
+}
+
+// SQLVersionFetcher returns a "VersionFetcher" that runs the given query and
+// reads the first column of the first row (ie. "SELECT version()" for
+// Postgres/MySQL).
+func SQLVersionFetcher(queryer SQLQueryer, query string) VersionFetcher {
+	return func() (string, error) {
+		rows, err := queryer.QueryContext(context.Background(), query)
+		if err != nil {
+			return "", err
+		}
+		defer rows.Close()
+
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return "", err
+			}
+			return "", sql.ErrNoRows
+		}
+
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return "", err
+		}
+
+		return version, nil
+	}
+}
+
+// HTTPVersionFetcher returns a "VersionFetcher" that performs a GET against
+// the given URL. If "field" is set, the response is decoded as JSON and the
+// (dot separated) field is used as the version; otherwise the entire body is
+// used. A nil client defaults to one with a "DefaultTimeout" timeout.
+func HTTPVersionFetcher(client *http.Client, url, field string) VersionFetcher {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	return func() (string, error) {
+		resp, err := client.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("received unexpected status code '%v'", resp.StatusCode)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+
+		if field == "" {
+			return string(body), nil
+		}
+
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return "", fmt.Errorf("unable to decode response: %v", err)
+		}
+
+		for _, key := range strings.Split(field, ".") {
+			m, ok := data.(map[string]interface{})
+			if !ok {
+				return "", fmt.Errorf("field '%v' not found in response", field)
+			}
+
+			if data, ok = m[key]; !ok {
+				return "", fmt.Errorf("field '%v' not found in response", field)
+			}
+		}
+
+		return fmt.Sprint(data), nil
+	}
+}
+
+type semver struct {
+	major, minor, patch int
+	pre                 string
+}
+
+func (s semver) String() string {
+	v := fmt.Sprintf("%d.%d.%d", s.major, s.minor, s.patch)
+	if s.pre != "" {
+		v += "-" + s.pre
+	}
+
+	return v
+}
+
+func (s semver) compare(o semver) int {
+	for _, d := range []int{s.major - o.major, s.minor - o.minor, s.patch - o.patch} {
+		if d != 0 {
+			return d
+		}
+	}
+
+	// a pre-release version has lower precedence than the release itself
+	switch {
+	case s.pre == o.pre:
+		return 0
+	case s.pre == "":
+		return 1
+	case o.pre == "":
+		return -1
+	default:
+		return comparePrerelease(s.pre, o.pre)
+	}
+}
+
+// compares pre-release versions as per semver: the dot separated identifiers
+// are compared from left to right, numerically if both are numeric (numeric
+// identifiers have a lower precedence than alphanumeric ones), lexically
+// otherwise; a shorter set of identifiers has a lower precedence if all
+// preceding identifiers are equal
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return len(as) - len(bs)
+}
+
+func parseSemver(raw string) (semver, error) {
+	match := versionRegexp.FindString(raw)
+	if match == "" {
+		return semver{}, fmt.Errorf("Unable to find a version in '%v'", strings.TrimSpace(raw))
+	}
+
+	v := semver{}
+	if idx := strings.Index(match, "-"); idx >= 0 {
+		v.pre = match[idx+1:]
+		match = match[:idx]
+	}
+
+	parts := strings.Split(match, ".")
+	nums := []*int{&v.major, &v.minor, &v.patch}
+
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return semver{}, fmt.Errorf("Unable to parse version '%v': %v", raw, err)
+		}
+		*nums[i] = n
+	}
+
+	return v, nil
+}
+
+type versionComparison struct {
+	op      string
+	version semver
+}
+
+func (c versionComparison) matches(v semver) bool {
+	cmp := v.compare(c.version)
+
+	switch c.op {
+	case "=", "==", "":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+
+	return false
+}
+
+// a constraint is a list of alternatives ("||"), each of which is a list of
+// comparisons that must all match
+type versionConstraint [][]versionComparison
+
+func (c versionConstraint) matches(v semver) bool {
+	for _, all := range c {
+		ok := true
+		for _, cmp := range all {
+			if !cmp.matches(v) {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseVersionConstraint(raw string) (versionConstraint, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("constraint cannot be empty")
+	}
+
+	constraint := versionConstraint{}
+
+	for _, alt := range strings.Split(raw, "||") {
+		all := []versionComparison{}
+
+		for _, part := range strings.Split(alt, ",") {
+			comparisons, err := parseVersionComparison(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+
+			all = append(all, comparisons...)
+		}
+
+		constraint = append(constraint, all)
+	}
+
+	return constraint, nil
+}
+
+func parseVersionComparison(raw string) ([]versionComparison, error) {
+	op := strings.TrimRight(raw, "0123456789.-abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ ")
+	rest := strings.TrimSpace(raw[len(op):])
+
+	if rest == "" {
+		return nil, fmt.Errorf("missing version in '%v'", raw)
+	}
+
+	v, err := parseSemver(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "~":
+		// ~1.2.3 := >=1.2.3, <1.3.0 (or <2.0.0 if only the major is given)
+		upper := semver{major: v.major, minor: v.minor + 1}
+		if !strings.Contains(rest, ".") {
+			upper = semver{major: v.major + 1}
+		}
+		return []versionComparison{{">=", v}, {"<", upper}}, nil
+	case "^":
+		// ^1.2.3 := >=1.2.3, <2.0.0 (the left-most non-zero component is fixed),
+		// ie. ^0.2.3 := >=0.2.3, <0.3.0 and ^0.0.3 := >=0.0.3, <0.0.4; missing
+		// components are not fixed (^0.0 := >=0.0.0, <0.1.0)
+		components := strings.Count(strings.SplitN(rest, "-", 2)[0], ".") + 1
+
+		var upper semver
+		switch {
+		case v.major != 0 || components == 1:
+			upper = semver{major: v.major + 1}
+		case v.minor != 0 || components == 2:
+			upper = semver{minor: v.minor + 1}
+		default:
+			upper = semver{patch: v.patch + 1}
+		}
+		return []versionComparison{{">=", v}, {"<", upper}}, nil
+	case "", "=", "==", "!=", ">", ">=", "<", "<=":
+		return []versionComparison{{op, v}}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported operator '%v' in '%v'", op, raw)
+}
@@ -0,0 +1,170 @@
+package checkers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestNewVersion(t *testing.T) {
+	RegisterTestingT(t)
+
+	fetcher := func() (string, error) { return "1.0.0", nil }
+
+	t.Run("Happy path", func(t *testing.T) {
+		v, err := NewVersion(&VersionConfig{Fetcher: fetcher, Constraint: ">=1.0"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(v).ToNot(BeNil())
+	})
+
+	t.Run("Should error with a nil cfg", func(t *testing.T) {
+		_, err := NewVersion(nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("Should error without a fetcher", func(t *testing.T) {
+		_, err := NewVersion(&VersionConfig{Constraint: ">=1.0"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Fetcher cannot be nil"))
+	})
+
+	t.Run("Should error with a bad constraint", func(t *testing.T) {
+		_, err := NewVersion(&VersionConfig{Fetcher: fetcher, Constraint: "=>1.0"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unsupported operator"))
+
+		_, err = NewVersion(&VersionConfig{Fetcher: fetcher})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("constraint cannot be empty"))
+	})
+}
+
+func TestVersionStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	cases := []struct {
+		version    string
+		constraint string
+		ok         bool
+	}{
+		{"3.6.8", ">=3.6, <5.0", true},
+		{"5.0.1", ">=3.6, <5.0", false},
+		{"PostgreSQL 13.4 on x86_64-pc-linux-gnu", "^13", true},
+		{"PostgreSQL 12.1 on x86_64-pc-linux-gnu", "^13", false},
+		{"v1.4.2", "~1.4", true},
+		{"1.5.0", "~1.4", false},
+		{"0.3.1", "^0.3.0", true},
+		{"0.4.0", "^0.3.0", false},
+		{"0.2.5", "^0.2.3", true},
+		{"0.2.2", "^0.2.3", false},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.3", "^0.0.3", true},
+		{"0.0.4", "^0.0.3", false},
+		{"0.0.9", "^0.0", true},
+		{"0.1.0", "^0.0", false},
+		{"4.2.0", "<4.0 || >=4.2", true},
+		{"2.0.0-rc1", ">=2.0.0", false},
+		{"2.0.0", "!=2.0.0", false},
+		{"2.0.0-rc.10", ">2.0.0-rc.2", true},
+		{"2.0.0-rc.2", ">=2.0.0-rc.10", false},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(fmt.Sprintf("%v %v", c.version, c.constraint), func(t *testing.T) {
+			v, err := NewVersion(&VersionConfig{
+				Fetcher:    func() (string, error) { return c.version, nil },
+				Constraint: c.constraint,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = v.Status()
+			if c.ok {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("does not satisfy constraint"))
+			}
+		})
+	}
+
+	t.Run("Should error if the fetcher fails", func(t *testing.T) {
+		v, _ := NewVersion(&VersionConfig{
+			Fetcher:    func() (string, error) { return "", fmt.Errorf("boom") },
+			Constraint: ">=1",
+		})
+
+		_, err := v.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to fetch version: boom"))
+	})
+
+	t.Run("Should error if there is no version", func(t *testing.T) {
+		v, _ := NewVersion(&VersionConfig{
+			Fetcher:    func() (string, error) { return "unknown", nil },
+			Constraint: ">=1",
+		})
+
+		_, err := v.Status()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to find a version"))
+	})
+}
+
+func TestComparePrerelease(t *testing.T) {
+	RegisterTestingT(t)
+
+	// in ascending order, as per the semver spec
+	ordered := []string{"alpha", "alpha.1", "alpha.beta", "beta", "beta.2", "beta.11", "rc.1", "rc.2", "rc.10"}
+
+	for i := range ordered {
+		for j := range ordered {
+			c := comparePrerelease(ordered[i], ordered[j])
+
+			switch {
+			case i < j:
+				Expect(c).To(BeNumerically("<", 0), ordered[i]+" < "+ordered[j])
+			case i > j:
+				Expect(c).To(BeNumerically(">", 0), ordered[i]+" > "+ordered[j])
+			default:
+				Expect(c).To(BeZero())
+			}
+		}
+	}
+}
+
+func TestVersionFetchers(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("HTTP fetcher should read a JSON field", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.Write([]byte(`{"build": {"version": "2.3.4"}}`))
+		}))
+		defer server.Close()
+
+		version, err := HTTPVersionFetcher(nil, server.URL, "build.version")()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version).To(Equal("2.3.4"))
+
+		_, err = HTTPVersionFetcher(nil, server.URL, "build.missing")()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("field 'build.missing' not found"))
+	})
+
+	t.Run("SQL fetcher should read the first column", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT version()").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 13.4"))
+
+		version, err := SQLVersionFetcher(db, "SELECT version()")()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version).To(Equal("PostgreSQL 13.4"))
+	})
+}
@@ -0,0 +1,209 @@
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time and the tickers that drive the periodic
+// executions of the checks; set "Health.Clock" (before "h.Start()") to drive
+// the intervals from unit tests instead of sleeping (see "NewFakeClock()").
+// Check timeouts ("Config.Timeout") are always enforced in real time.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the subset of "time.Ticker" used by the schedulers; the interval
+// passed to "Reset()" must be positive.
+type Ticker interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+// realClock is the default clock, backed by the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{ticker: time.NewTicker(d)}
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t realTicker) Reset(d time.Duration) {
+	t.ticker.Reset(d)
+}
+
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// returns "h.Clock" or the real clock if none is set
+func (h *Health) clock() Clock {
+	if h.Clock != nil {
+		return h.Clock
+	}
+
+	return realClock{}
+}
+
+// FakeClock is a "Clock" that only moves forward when it is advanced; it is
+// meant for unit tests.
+type FakeClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+
+	// serializes the calls to "Advance()"
+	advanceLock sync.Mutex
+}
+
+// NewFakeClock creates a fake clock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, tickers: make([]*fakeTicker, 0)}
+}
+
+// Now returns the current (fake) time.
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// NewTicker creates a ticker that ticks whenever the clock is advanced past
+// its next tick.
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t := &fakeTicker{
+		clock:    c,
+		c:        make(chan time.Time),
+		interval: d,
+		next:     c.now.Add(d),
+		stopped:  make(chan struct{}),
+	}
+
+	c.tickers = append(c.tickers, t)
+
+	return t
+}
+
+// Advance moves the clock forward by "d" and delivers the ticks that are due
+// on the way, in order. Unlike "time.Ticker", no ticks are dropped: Advance
+// blocks until every tick has been received (or its ticker has been stopped),
+// so once it returns, every due execution of the checks has been triggered
+// (the executions themselves still complete asynchronously).
+func (c *FakeClock) Advance(d time.Duration) {
+	c.advanceLock.Lock()
+	defer c.advanceLock.Unlock()
+
+	c.lock.Lock()
+	target := c.now.Add(d)
+	c.lock.Unlock()
+
+	for {
+		c.lock.Lock()
+
+		due := c.nextDue(target)
+		if due == nil {
+			c.now = target
+			c.lock.Unlock()
+
+			return
+		}
+
+		c.now = due.next
+		now := c.now
+		due.next = due.next.Add(due.interval)
+
+		c.lock.Unlock()
+
+		// the receiver may call back into the clock
+		select {
+		case due.c <- now:
+		case <-due.stopped:
+		}
+	}
+}
+
+// returns the ticker with the earliest tick that is due by "target"; the
+// caller must hold the lock
+func (c *FakeClock) nextDue(target time.Time) *fakeTicker {
+	due := make([]*fakeTicker, 0)
+	for _, t := range c.tickers {
+		if !t.next.After(target) {
+			due = append(due, t)
+		}
+	}
+
+	if len(due) == 0 {
+		return nil
+	}
+
+	// ticks that are due at the same time are delivered in creation order
+	sort.SliceStable(due, func(i, j int) bool { return due[i].next.Before(due[j].next) })
+
+	return due[0]
+}
+
+type fakeTicker struct {
+	clock *FakeClock
+	c     chan time.Time
+
+	// guarded by the lock of the clock
+	interval time.Duration
+	next     time.Time
+
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for fakeTicker.Reset")
+	}
+
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	t.interval = d
+	t.next = t.clock.now.Add(d)
+}
+
+func (t *fakeTicker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stopped)
+
+		t.clock.lock.Lock()
+		defer t.clock.lock.Unlock()
+
+		for i, other := range t.clock.tickers {
+			if other == t {
+				t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+				break
+			}
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestFakeClock(t *testing.T) {
+	RegisterTestingT(t)
+
+	start := time.Date(2017, 12, 5, 19, 0, 0, 0, time.UTC)
+
+	t.Run("Should deliver the due ticks in order", func(t *testing.T) {
+		clock := NewFakeClock(start)
+
+		fast := clock.NewTicker(time.Minute)
+		slow := clock.NewTicker(90 * time.Second)
+
+		ticks := make(chan string, 10)
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+
+			for i := 0; i < 3; i++ {
+				select {
+				case now := <-fast.C():
+					ticks <- "fast " + now.Sub(start).String()
+				case now := <-slow.C():
+					ticks <- "slow " + now.Sub(start).String()
+				}
+			}
+		}()
+
+		clock.Advance(2 * time.Minute)
+		<-done
+
+		Expect(ticks).To(Receive(Equal("fast 1m0s")))
+		Expect(ticks).To(Receive(Equal("slow 1m30s")))
+		Expect(ticks).To(Receive(Equal("fast 2m0s")))
+		Expect(clock.Now()).To(Equal(start.Add(2 * time.Minute)))
+	})
+
+	t.Run("Should reset and stop tickers", func(t *testing.T) {
+		clock := NewFakeClock(start)
+
+		ticker := clock.NewTicker(time.Minute)
+		ticker.Reset(time.Hour)
+
+		// nobody is receiving, but no tick is due yet
+		clock.Advance(time.Minute)
+
+		ticker.Stop()
+
+		// ticks of stopped tickers are not delivered
+		clock.Advance(2 * time.Hour)
+		Expect(ticker.C()).ToNot(Receive())
+	})
+}
+
+func TestClock(t *testing.T) {
+	RegisterTestingT(t)
+
+	start := time.Date(2017, 12, 5, 19, 0, 0, 0, time.UTC)
+
+	configs := []struct {
+		name      string
+		scheduler Scheduler
+		cfg       Config
+	}{
+		{"ticker", SchedulerTicker, Config{Interval: time.Hour}},
+		{"timing wheel", SchedulerTimingWheel, Config{Interval: time.Hour}},
+		{"cron", SchedulerTicker, Config{Schedule: "0 * * * *"}},
+	}
+
+	for _, c := range configs {
+		c := c
+
+		t.Run("Should drive the executions of "+c.name+" checks", func(t *testing.T) {
+			clock := NewFakeClock(start)
+			checker := &fakes.FakeICheckable{}
+
+			cfg := c.cfg
+			cfg.Name = "foo"
+			cfg.Checker = checker
+
+			h := setupNewTestHealth()
+			h.Clock = clock
+			h.Scheduler = c.scheduler
+			h.WaitOnStart = true
+			h.AddCheck(&cfg)
+
+			Expect(h.Start()).To(Succeed())
+			defer h.Stop()
+
+			Expect(checker.StatusCallCount()).To(Equal(1))
+
+			clock.Advance(59 * time.Minute)
+			Consistently(checker.StatusCallCount, 50*time.Millisecond).Should(Equal(1))
+
+			clock.Advance(time.Minute)
+			Eventually(checker.StatusCallCount).Should(Equal(2))
+
+			Eventually(func() time.Time {
+				states, _, _ := h.State()
+				return states["foo"].CheckTime
+			}).Should(Equal(start.Add(time.Hour)))
+		})
+	}
+}
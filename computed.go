@@ -0,0 +1,168 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/InVisionApp/go-health/expr"
+)
+
+// computedChecksVar is the variable of computed checks that contains the
+// states of all checks, ie. for names that are not valid identifiers
+// (`checks["redis-cache"].ok`)
+const computedChecksVar = "checks"
+
+// ComputedCheck is a checker whose outcome is an expression over the states
+// of the other checks, ie. to report business-level health without custom
+// code:
+//
+//	checkout, err := h.Computed(`db.ok && (cache.ok || degraded_mode_enabled)`, map[string]interface{}{
+//		"degraded_mode_enabled": func() bool { return flags.Enabled("degraded-mode") },
+//	})
+//
+//	h.AddCheck(&health.Config{
+//		Name:     "checkout",
+//		Checker:  checkout,
+//		Interval: 5 * time.Second,
+//	})
+//
+// It is evaluated by the runner (on the interval of its config) against the
+// latest states and reported like any other check: it fails if the
+// expression is false (or cannot be evaluated, ie. because a referenced check
+// has not run yet).
+//
+// Every check is available as a variable (see the "expr" package for the
+// syntax of expressions) with the following fields: "ok" (the check is
+// healthy, see "h.IsHealthy()"), "failed", "degraded", "skipped", "paused",
+// "status", "error", "fatal", "duration" (in seconds),
+// "consecutive_errors", "consecutive_successes", "latency" (with "min",
+// "max", "avg" and "p95" in seconds and "samples"; if
+// "Health.LatencyWindow" is set) and "details" (the details of the check as
+// JSON). Checks whose names are not valid identifiers are available via the
+// "checks" variable (`checks["redis-cache"].ok`).
+type ComputedCheck struct {
+	health *Health
+	expr   *expr.Expr
+	vars   map[string]interface{}
+}
+
+// Computed creates a computed check from the expression. "vars" contains
+// additional variables (that take precedence over checks of the same name);
+// functions without arguments (ie. "func() bool") are called on every
+// evaluation.
+func (h *Health) Computed(expression string, vars map[string]interface{}) (*ComputedCheck, error) {
+	e, err := expr.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComputedCheck{
+		health: h,
+		expr:   e,
+		vars:   vars,
+	}, nil
+}
+
+// Status evaluates the expression; it satisfies the "ICheckable" interface.
+// The details contain the statuses of the referenced checks.
+func (c *ComputedCheck) Status() (interface{}, error) {
+	env := make(map[string]interface{})
+	statuses := make(map[string]string)
+
+	for _, id := range c.expr.Identifiers() {
+		if _, ok := c.vars[id]; ok {
+			continue
+		}
+
+		if id == computedChecksVar {
+			checks := make(map[string]interface{})
+			for name, state := range c.health.snapshotStates() {
+				checks[name] = computedState(state)
+			}
+
+			env[id] = checks
+
+			continue
+		}
+
+		state, ok := c.health.lookupState(id)
+		if !ok {
+			return nil, fmt.Errorf("Check '%v' has no state", id)
+		}
+
+		env[id] = computedState(state)
+		statuses[id] = state.Status
+	}
+
+	for k, v := range c.vars {
+		env[k] = v
+	}
+
+	ok, err := c.expr.EvalBool(env)
+	if err != nil {
+		return statuses, fmt.Errorf("Unable to evaluate expression: %v", err)
+	}
+
+	if !ok {
+		return statuses, fmt.Errorf("Expression '%v' is false", c.expr)
+	}
+
+	return statuses, nil
+}
+
+// Describe returns the description of the computed check; it satisfies the
+// "ICheckableDescriber" interface. The capabilities are the referenced
+// checks and variables.
+func (c *ComputedCheck) Describe() Description {
+	return Description{
+		Type:         "computed",
+		Target:       c.expr.String(),
+		Capabilities: c.expr.Identifiers(),
+	}
+}
+
+// returns the variable of the state for the expression
+func computedState(state State) map[string]interface{} {
+	v := map[string]interface{}{
+		"ok":                    !state.isFailure() && !state.isSkipped() && !state.isPaused(),
+		"failed":                state.isFailure(),
+		"degraded":              state.isDegraded(),
+		"skipped":               state.isSkipped(),
+		"paused":                state.isPaused(),
+		"status":                state.Status,
+		"error":                 state.Err,
+		"fatal":                 state.Fatal,
+		"duration":              state.Duration,
+		"consecutive_errors":    state.ConsecutiveErrors,
+		"consecutive_successes": state.ConsecutiveSuccesses,
+		"details":               jsonDetails(state.Details),
+	}
+
+	if state.Latency != nil {
+		v["latency"] = map[string]interface{}{
+			"samples": state.Latency.Samples,
+			"min":     state.Latency.Min,
+			"max":     state.Latency.Max,
+			"avg":     state.Latency.Avg,
+			"p95":     state.Latency.P95,
+		}
+	}
+
+	return v
+}
+
+// returns the details of a check as JSON (maps, slices, strings, numbers and
+// bools), so that expressions can access their fields by their JSON names;
+// nil if they cannot be marshaled
+func jsonDetails(details interface{}) interface{} {
+	if details == nil {
+		return nil
+	}
+
+	var v interface{}
+	if data, err := json.Marshal(details); err != nil || json.Unmarshal(data, &v) != nil {
+		return nil
+	}
+
+	return v
+}
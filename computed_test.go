@@ -0,0 +1,128 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestComputed(t *testing.T) {
+	RegisterTestingT(t)
+
+	setup := func(dbErr, cacheErr *error) *Health {
+		db := &fakes.FakeICheckable{}
+		db.StatusStub = func() (interface{}, error) {
+			return map[string]int{"connections": 12}, *dbErr
+		}
+
+		cache := &fakes.FakeICheckable{}
+		cache.StatusStub = func() (interface{}, error) {
+			return nil, *cacheErr
+		}
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddChecks([]*Config{
+			{Name: "db", Checker: db, Interval: time.Hour},
+			{Name: "redis-cache", Checker: cache, Interval: time.Hour},
+		})
+
+		return h
+	}
+
+	t.Run("Should error on invalid expressions", func(t *testing.T) {
+		_, err := New().Computed("db.ok &&", nil)
+		Expect(err).To(MatchError(ContainSubstring("Unable to parse expression")))
+	})
+
+	t.Run("Should report the expression like a normal check", func(t *testing.T) {
+		var dbErr, cacheErr error
+		degradedMode := false
+
+		h := setup(&dbErr, &cacheErr)
+
+		checkout, err := h.Computed(`db.ok && (checks["redis-cache"].ok || degraded_mode_enabled)`, map[string]interface{}{
+			"degraded_mode_enabled": func() bool { return degradedMode },
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(h.AddCheck(&Config{Name: "checkout", Checker: checkout, Interval: time.Hour})).To(Succeed())
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		state, err := h.RunCheck("checkout")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("ok"))
+
+		cacheErr = errors.New("connection refused")
+		_, err = h.RunCheck("redis-cache")
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err = h.RunCheck("checkout")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("failed"))
+		Expect(state.Err).To(Equal(`Expression 'db.ok && (checks["redis-cache"].ok || degraded_mode_enabled)' is false`))
+		Expect(state.Details).To(Equal(map[string]string{"db": "ok"}))
+
+		degradedMode = true
+
+		state, err = h.RunCheck("checkout")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("ok"))
+	})
+
+	t.Run("Should expose the stats and details of the checks", func(t *testing.T) {
+		var dbErr, cacheErr error
+
+		h := setup(&dbErr, &cacheErr)
+
+		pool, err := h.Computed(`db.details.connections < 10 || db.consecutive_successes > 1`, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(h.AddCheck(&Config{Name: "pool", Checker: pool, Interval: time.Hour})).To(Succeed())
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		state, err := h.RunCheck("pool")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("failed"))
+
+		_, err = h.RunCheck("db")
+		Expect(err).ToNot(HaveOccurred())
+
+		state, err = h.RunCheck("pool")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("ok"))
+	})
+
+	t.Run("Should fail if a referenced check has no state", func(t *testing.T) {
+		c, err := New().Computed(`db.ok`, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).To(MatchError("Check 'db' has no state"))
+	})
+
+	t.Run("Should fail if the expression cannot be evaluated", func(t *testing.T) {
+		c, err := New().Computed(`flag && 1`, map[string]interface{}{"flag": true})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).To(MatchError(ContainSubstring("Unable to evaluate expression: Operator '&&' requires bools")))
+	})
+
+	t.Run("Should describe the expression", func(t *testing.T) {
+		c, err := New().Computed(`db.ok && cache.ok`, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(c.Describe()).To(Equal(Description{
+			Type:         "computed",
+			Target:       "db.ok && cache.ok",
+			Capabilities: []string{"cache", "db"},
+		}))
+	})
+}
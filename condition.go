@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/InVisionApp/go-health/expr"
+)
+
+// ConditionCheck wraps a checker, so that the check also fails unless an
+// expression over the details returned by the checker holds, ie. to express
+// thresholds without writing Go:
+//
+//	checker, err := health.Condition(mongoChecker, `details.lag_seconds < 30 && details.members_healthy >= 2`)
+//
+// The details are available as the "details" variable (by their JSON names,
+// see the "expr" package for the syntax of expressions); errors of the
+// wrapped checker are returned as is.
+type ConditionCheck struct {
+	checker ICheckable
+	expr    *expr.Expr
+}
+
+// Condition creates a condition check that wraps the checker.
+func Condition(checker ICheckable, expression string) (*ConditionCheck, error) {
+	if checker == nil {
+		return nil, fmt.Errorf("Checker cannot be nil")
+	}
+
+	e, err := expr.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConditionCheck{
+		checker: checker,
+		expr:    e,
+	}, nil
+}
+
+// Status calls the wrapped checker and evaluates the condition over its
+// details; it satisfies the "ICheckable" interface.
+func (c *ConditionCheck) Status() (interface{}, error) {
+	return c.StatusContext(context.Background())
+}
+
+// StatusContext is like "Status()"; the context is passed on to wrapped
+// checkers that implement "ICheckableContext".
+func (c *ConditionCheck) StatusContext(ctx context.Context) (interface{}, error) {
+	var data interface{}
+	var err error
+
+	if cc, ok := c.checker.(ICheckableContext); ok {
+		data, err = cc.StatusContext(ctx)
+	} else {
+		data, err = c.checker.Status()
+	}
+
+	if err != nil {
+		return data, err
+	}
+
+	ok, err := c.expr.EvalBool(map[string]interface{}{"details": jsonDetails(data)})
+	if err != nil {
+		return data, fmt.Errorf("Unable to evaluate condition: %v", err)
+	}
+
+	if !ok {
+		return data, fmt.Errorf("Condition '%v' is not met", c.expr)
+	}
+
+	return data, nil
+}
+
+// Describe returns the description of the wrapped checker (if it implements
+// "ICheckableDescriber") with the additional "condition" capability.
+func (c *ConditionCheck) Describe() Description {
+	desc := Description{Type: fmt.Sprintf("%T", c.checker)}
+	if d, ok := c.checker.(ICheckableDescriber); ok {
+		desc = d.Describe()
+	}
+
+	desc.Capabilities = append(append([]string{}, desc.Capabilities...), "condition")
+
+	return desc
+}
+
+// Close closes the wrapped checker if it implements "io.Closer" (see
+// "h.StopWithContext()").
+func (c *ConditionCheck) Close() error {
+	if closer, ok := c.checker.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
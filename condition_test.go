@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type lagDetails struct {
+	LagSeconds     float64 `json:"lag_seconds"`
+	MembersHealthy int     `json:"members_healthy"`
+}
+
+func TestCondition(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error on invalid arguments", func(t *testing.T) {
+		_, err := Condition(nil, "true")
+		Expect(err).To(MatchError("Checker cannot be nil"))
+
+		_, err = Condition(&fakes.FakeICheckable{}, "details.lag_seconds <")
+		Expect(err).To(MatchError(ContainSubstring("Unable to parse expression")))
+	})
+
+	t.Run("Should fail unless the condition over the details holds", func(t *testing.T) {
+		details := &lagDetails{LagSeconds: 12, MembersHealthy: 3}
+
+		checker := &fakes.FakeICheckable{}
+		checker.StatusStub = func() (interface{}, error) {
+			return details, nil
+		}
+
+		c, err := Condition(checker, `details.lag_seconds < 30 && details.members_healthy >= 2`)
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := c.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(details))
+
+		details.LagSeconds = 45
+
+		data, err = c.Status()
+		Expect(err).To(MatchError("Condition 'details.lag_seconds < 30 && details.members_healthy >= 2' is not met"))
+		Expect(data).To(Equal(details))
+	})
+
+	t.Run("Should return errors of the checker as is", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, Degraded("replica lagging"))
+
+		c, err := Condition(checker, `details.lag_seconds < 30`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(isDegradedError(err)).To(BeTrue())
+	})
+
+	t.Run("Should fail if the condition cannot be evaluated", func(t *testing.T) {
+		c, err := Condition(&fakes.FakeICheckable{}, `details.lag_seconds < 30`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.Status()
+		Expect(err).To(MatchError("Unable to evaluate condition: Unable to access field 'lag_seconds' of nil"))
+	})
+
+	t.Run("Should pass on the context, description and closing", func(t *testing.T) {
+		type ctxKey struct{}
+
+		c, err := Condition(CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			return map[string]interface{}{"value": ctx.Value(ctxKey{})}, nil
+		}), `details.value == "passed"`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.StatusContext(context.WithValue(context.Background(), ctxKey{}, "passed"))
+		Expect(err).ToNot(HaveOccurred())
+
+		inner := &closingChecker{}
+
+		c, err = Condition(inner, `true`)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(c.Describe()).To(Equal(Description{Type: "*health.closingChecker", Capabilities: []string{"condition"}}))
+
+		Expect(c.Close()).To(Succeed())
+		Expect(inner.closeCount()).To(Equal(int32(1)))
+	})
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/checkers"
+	"github.com/InVisionApp/go-health/checkers/plugins"
+)
+
+// HTTP contains the settings of the `http` type (see "checkers.HTTPConfig").
+type HTTP struct {
+	URL        string            `yaml:"url"` // Required
+	Method     string            `yaml:"method"`
+	Body       string            `yaml:"body"`
+	Headers    map[string]string `yaml:"headers"`
+	StatusCode int               `yaml:"status_code"`
+	Expect     string            `yaml:"expect"`
+	Timeout    time.Duration     `yaml:"timeout"`
+}
+
+// TCP contains the settings of the `tcp` type (see
+// "checkers.ReachableConfig").
+type TCP struct {
+	Address string        `yaml:"address"` // Required; `host:port`
+	Network string        `yaml:"network"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Redis contains the settings of the `redis` type (see
+// "checkers.RedisConfig"); at least one of "Ping", "Set" or "Get" must be set.
+type Redis struct {
+	Addr     string `yaml:"addr"` // Required; `host:port` or `redis://` URL
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+	Ping     bool   `yaml:"ping"`
+
+	Set *struct {
+		Key        string        `yaml:"key"`
+		Value      string        `yaml:"value"`
+		Expiration time.Duration `yaml:"expiration"`
+	} `yaml:"set"`
+
+	Get *struct {
+		Key               string `yaml:"key"`
+		Expect            string `yaml:"expect"`
+		NoErrorMissingKey bool   `yaml:"no_error_missing_key"`
+	} `yaml:"get"`
+}
+
+// Mongo contains the settings of the `mongo` type (see
+// "checkers.MongoConfig"); at least one of "Ping", "Collection" or "Pool"
+// must be set.
+type Mongo struct {
+	URL        string `yaml:"url"` // Required
+	DB         string `yaml:"db"`
+	Collection string `yaml:"collection"`
+	Ping       bool   `yaml:"ping"`
+
+	Pool *struct {
+		MaxAvgWaitTime  time.Duration `yaml:"max_avg_wait_time"`
+		MaxPoolTimeouts int           `yaml:"max_pool_timeouts"`
+	} `yaml:"pool"`
+}
+
+// SQL contains the settings of the `sql` type (see "checkers.SQLConfig").
+// The database is opened via "sql.Open()", so the driver must be registered
+// by the application (ie. `import _ "github.com/lib/pq"`). The database is
+// pinged or, if "Query" is set, the query must return a single row.
+type SQL struct {
+	Driver string `yaml:"driver"` // Required
+	DSN    string `yaml:"dsn"`    // Required
+	Query  string `yaml:"query"`
+}
+
+// Computed contains the settings of the `computed` type (see
+// "health.ComputedCheck").
+type Computed struct {
+	Expression string                 `yaml:"expression"` // Required
+	Vars       map[string]interface{} `yaml:"vars"`
+}
+
+// Plugin contains the settings of the `plugin` type (see "plugins.Open()"):
+// the checker is loaded from a Go plugin (`.so`) or a WASM module (`.wasm`,
+// which requires "plugins.DefaultRuntime" to be set by the application).
+type Plugin struct {
+	Path     string                 `yaml:"path"` // Required
+	Settings map[string]interface{} `yaml:"settings"`
+}
+
+// factory creates the checker of a check of a given type
+type factory func(h *health.Health, c *Check) (health.ICheckable, error)
+
+// factories of the built-in types
+var factories = map[string]factory{
+	"http":     newHTTP,
+	"tcp":      newTCP,
+	"redis":    newRedis,
+	"mongo":    newMongo,
+	"sql":      newSQL,
+	"computed": newComputed,
+	"plugin":   newPlugin,
+}
+
+// indicates that the settings block of the type of the check is set
+func (c *Check) hasSettings() bool {
+	switch c.Type {
+	case "http":
+		return c.HTTP != nil
+	case "tcp":
+		return c.TCP != nil
+	case "redis":
+		return c.Redis != nil
+	case "mongo":
+		return c.Mongo != nil
+	case "sql":
+		return c.SQL != nil
+	case "computed":
+		return c.Computed != nil
+	case "plugin":
+		return c.Plugin != nil
+	}
+
+	return false
+}
+
+func newHTTP(h *health.Health, c *Check) (health.ICheckable, error) {
+	s := c.HTTP
+
+	u, err := url.Parse(s.URL)
+	if err != nil || s.URL == "" {
+		return nil, fmt.Errorf("Unable to parse URL '%v'", s.URL)
+	}
+
+	cfg := &checkers.HTTPConfig{
+		URL:        u,
+		Method:     s.Method,
+		StatusCode: s.StatusCode,
+		Expect:     s.Expect,
+		Timeout:    s.Timeout,
+	}
+
+	if s.Body != "" {
+		cfg.Payload = s.Body
+	}
+
+	if len(s.Headers) > 0 {
+		cfg.Header = make(http.Header)
+		for k, v := range s.Headers {
+			cfg.Header.Set(k, v)
+		}
+	}
+
+	return checkers.NewHTTP(cfg)
+}
+
+func newTCP(h *health.Health, c *Check) (health.ICheckable, error) {
+	s := c.TCP
+
+	if s.Address == "" {
+		return nil, errors.New("Address must be set")
+	}
+
+	return checkers.NewReachableChecker(&checkers.ReachableConfig{
+		URL:     &url.URL{Host: s.Address},
+		Network: s.Network,
+		Timeout: s.Timeout,
+	})
+}
+
+func newRedis(h *health.Health, c *Check) (health.ICheckable, error) {
+	s := c.Redis
+
+	cfg := &checkers.RedisConfig{
+		Auth: &checkers.RedisAuthConfig{
+			Addr:     s.Addr,
+			Password: s.Password,
+			DB:       s.DB,
+		},
+		Ping: s.Ping,
+	}
+
+	if s.Set != nil {
+		cfg.Set = &checkers.RedisSetOptions{Key: s.Set.Key, Value: s.Set.Value, Expiration: s.Set.Expiration}
+	}
+
+	if s.Get != nil {
+		cfg.Get = &checkers.RedisGetOptions{Key: s.Get.Key, Expect: s.Get.Expect, NoErrorMissingKey: s.Get.NoErrorMissingKey}
+	}
+
+	return checkers.NewRedis(cfg)
+}
+
+func newMongo(h *health.Health, c *Check) (health.ICheckable, error) {
+	s := c.Mongo
+
+	cfg := &checkers.MongoConfig{
+		Auth:       &checkers.MongoAuthConfig{Url: s.URL},
+		DB:         s.DB,
+		Collection: s.Collection,
+		Ping:       s.Ping,
+	}
+
+	if s.Pool != nil {
+		cfg.Pool = &checkers.MongoPoolOptions{MaxAvgWaitTime: s.Pool.MaxAvgWaitTime, MaxPoolTimeouts: s.Pool.MaxPoolTimeouts}
+	}
+
+	return checkers.NewMongo(cfg)
+}
+
+func newSQL(h *health.Health, c *Check) (health.ICheckable, error) {
+	s := c.SQL
+
+	if s.Driver == "" || s.DSN == "" {
+		return nil, errors.New("Driver and DSN must be set")
+	}
+
+	db, err := sql.Open(s.Driver, s.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open database: %v", err)
+	}
+
+	// the database is dedicated to the check
+	cfg := &checkers.SQLConfig{Pinger: db, CloseDB: true}
+	if s.Query != "" {
+		cfg.Queryer = db
+		cfg.Query = s.Query
+	}
+
+	checker, err := checkers.NewSQL(cfg)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return checker, nil
+}
+
+func newComputed(h *health.Health, c *Check) (health.ICheckable, error) {
+	return h.Computed(c.Computed.Expression, c.Computed.Vars)
+}
+
+func newPlugin(h *health.Health, c *Check) (health.ICheckable, error) {
+	if c.Plugin.Path == "" {
+		return nil, errors.New("Path must be set")
+	}
+
+	return plugins.Open(c.Plugin.Path, c.Plugin.Settings)
+}
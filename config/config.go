@@ -0,0 +1,378 @@
+// Package config builds a fully wired health instance from a declarative YAML
+// (or JSON) file, so that operators can add or tune checks without
+// recompiling:
+//
+//	h, err := config.Load("/etc/myapp/health.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	h.Start()
+//
+// Refer to the README of the package for the format of the file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/expr"
+	"github.com/InVisionApp/go-health/retry"
+)
+
+// references to environment variables, ie. `${REDIS_PASSWORD}`
+var envVarRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Config is the content of a config file; the settings of the health
+// instance are optional and default to the ones of "health.New()".
+type Config struct {
+	WaitOnStart     bool          `yaml:"wait_on_start"`
+	StartTimeout    time.Duration `yaml:"start_timeout"`
+	DefaultInterval time.Duration `yaml:"default_interval"`
+	DefaultTimeout  time.Duration `yaml:"default_timeout"`
+	StateTTL        time.Duration `yaml:"state_ttl"`
+	LatencyWindow   int           `yaml:"latency_window"`
+	HistorySize     int           `yaml:"history_size"`
+
+	Checks []*Check `yaml:"checks"`
+
+	secrets map[string]SecretResolver // by scheme; see "WithSecretResolver()"
+}
+
+// Check is the config of a single check. "Name" and "Type" are _required_;
+// the settings of the checker are read from the block that matches the type
+// (ie. "HTTP" for the `http` type).
+//
+// "Condition" is optional; an expression over the details of the checker
+// that must hold for the check to succeed, ie. `details.lag_seconds < 30`
+// (see "health.Condition()").
+//
+// The remaining fields correspond to the fields of "health.Config".
+type Check struct {
+	Name      string `yaml:"name"`
+	Type      string `yaml:"type"`
+	Condition string `yaml:"condition"`
+
+	Interval         time.Duration `yaml:"interval"`
+	Schedule         string        `yaml:"schedule"`
+	InitialDelay     time.Duration `yaml:"initial_delay"`
+	Timeout          time.Duration `yaml:"timeout"`
+	Fatal            bool          `yaml:"fatal"`
+	Critical         bool          `yaml:"critical"`
+	Tags             []string      `yaml:"tags"`
+	DependsOn        []string      `yaml:"depends_on"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	SuccessThreshold int           `yaml:"success_threshold"`
+	Jitter           float64       `yaml:"jitter"`
+	CacheTTL         time.Duration `yaml:"cache_ttl"`
+	Priority         string        `yaml:"priority"` // `normal` (default), `high` or `low`
+	Retry            *Retry        `yaml:"retry"`
+	Startup          bool          `yaml:"startup"`
+	Weight           float64       `yaml:"weight"`
+
+	// Disabled drops the check from the config, ie. in the profile of an
+	// environment without the dependency
+	Disabled bool `yaml:"disabled"`
+
+	HTTP     *HTTP     `yaml:"http"`
+	TCP      *TCP      `yaml:"tcp"`
+	Redis    *Redis    `yaml:"redis"`
+	Mongo    *Mongo    `yaml:"mongo"`
+	SQL      *SQL      `yaml:"sql"`
+	Computed *Computed `yaml:"computed"`
+	Plugin   *Plugin   `yaml:"plugin"`
+}
+
+// Retry is the retry policy of a check ("health.Config.Retry"); the delay
+// doubles on every retry (up to "MaxDelay") if "Exponential" is set.
+type Retry struct {
+	Retries     int           `yaml:"retries"`
+	Delay       time.Duration `yaml:"delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+	Exponential bool          `yaml:"exponential"`
+	Jitter      float64       `yaml:"jitter"`
+}
+
+// Load reads the config file and builds the health instance (see
+// "Config.Build()").
+func Load(path string, opts ...Option) (*health.Health, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read config file: %v", err)
+	}
+
+	cfg, err := Parse(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Build()
+}
+
+// Parse parses the config (YAML or JSON). References to environment
+// variables (`${NAME}`, ie. for passwords) are replaced by their values;
+// unknown fields are rejected, so that typos do not go unnoticed. The
+// overrides of the selected profile (see "WithProfile()") are applied before
+// the config is validated.
+func Parse(data []byte, opts ...Option) (*Config, error) {
+	data, err := expandEnv(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is re-encoded as YAML, so that both formats are decoded alike
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var v interface{}
+		if err := json.Unmarshal(trimmed, &v); err != nil {
+			return nil, fmt.Errorf("Unable to parse config: %v", err)
+		}
+
+		if data, err = yaml.Marshal(v); err != nil {
+			return nil, fmt.Errorf("Unable to parse config: %v", err)
+		}
+	}
+
+	o := newOptions(opts)
+
+	data, err = applyProfile(data, o)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse config: %v", err)
+	}
+
+	cfg.secrets = o.secrets
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("Unable to validate config: %v", err)
+	}
+
+	return cfg, nil
+}
+
+// decodes the config, rejecting unknown fields; disabled checks are dropped
+func decode(data []byte) (*Config, error) {
+	cfg := &Config{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(cfg); err != nil {
+		return nil, err
+	}
+
+	checks := cfg.Checks[:0]
+	for _, check := range cfg.Checks {
+		if check == nil || !check.Disabled {
+			checks = append(checks, check)
+		}
+	}
+
+	cfg.Checks = checks
+
+	return cfg, nil
+}
+
+// Build creates a new health instance (see "health.New()") with the settings
+// and checks of the config; the instance is not started yet, so that hooks
+// and listeners can be added first.
+func (c *Config) Build() (*health.Health, error) {
+	h, _, _, err := c.build()
+	return h, err
+}
+
+// returns the health instance along with the checks (with their secrets
+// resolved) and the configs that have been built for them
+func (c *Config) build() (*health.Health, []*Check, []*health.Config, error) {
+	h := health.New()
+	h.WaitOnStart = c.WaitOnStart
+	h.StartTimeout = c.StartTimeout
+	h.DefaultInterval = c.DefaultInterval
+	h.DefaultTimeout = c.DefaultTimeout
+	h.StateTTL = c.StateTTL
+	h.LatencyWindow = c.LatencyWindow
+	h.HistorySize = c.HistorySize
+
+	checks, cfgs, err := c.buildChecks(h)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := h.AddChecks(cfgs); err != nil {
+		return nil, nil, nil, fmt.Errorf("Unable to add checks: %v", err)
+	}
+
+	return h, checks, cfgs, nil
+}
+
+// BuildChecks creates the checkers of the checks for the given health
+// instance (which is only used by computed checks), ie. to add them to an
+// existing instance. References to secrets in the settings of the checks are
+// resolved first (see "WithSecretResolver()").
+func (c *Config) BuildChecks(h *health.Health) ([]*health.Config, error) {
+	_, cfgs, err := c.buildChecks(h)
+	return cfgs, err
+}
+
+// returns the checks with their secrets resolved along with their configs
+func (c *Config) buildChecks(h *health.Health) ([]*Check, []*health.Config, error) {
+	checks := make([]*Check, 0, len(c.Checks))
+	cfgs := make([]*health.Config, 0, len(c.Checks))
+
+	for _, check := range c.Checks {
+		resolved, err := c.resolveSecrets(check)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to build check '%v': %v", check.Name, err)
+		}
+
+		cfg, err := resolved.build(h)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to build check '%v': %v", check.Name, err)
+		}
+
+		checks = append(checks, resolved)
+		cfgs = append(cfgs, cfg)
+	}
+
+	return checks, cfgs, nil
+}
+
+func (c *Config) validate() error {
+	names := make(map[string]bool)
+
+	for i, check := range c.Checks {
+		if check == nil || check.Name == "" {
+			return fmt.Errorf("Check #%d has no name", i+1)
+		}
+
+		if names[check.Name] {
+			return fmt.Errorf("Duplicate check '%v'", check.Name)
+		}
+		names[check.Name] = true
+
+		if err := check.validate(); err != nil {
+			return fmt.Errorf("Invalid check '%v': %v", check.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Check) validate() error {
+	if c.Type == "" {
+		return errors.New("Type must be set")
+	}
+
+	if _, ok := factories[c.Type]; !ok {
+		return fmt.Errorf("Unknown type '%v'", c.Type)
+	}
+
+	if !c.hasSettings() {
+		return fmt.Errorf("Settings of type '%v' must be set", c.Type)
+	}
+
+	if _, ok := priorities[c.Priority]; !ok {
+		return fmt.Errorf("Unknown priority '%v'", c.Priority)
+	}
+
+	if c.Condition != "" {
+		if _, err := expr.Compile(c.Condition); err != nil {
+			return fmt.Errorf("Invalid condition: %v", err)
+		}
+	}
+
+	return nil
+}
+
+var priorities = map[string]health.Priority{
+	"":       health.PriorityNormal,
+	"normal": health.PriorityNormal,
+	"high":   health.PriorityHigh,
+	"low":    health.PriorityLow,
+}
+
+// returns the config of the check, including its checker
+func (c *Check) build(h *health.Health) (*health.Config, error) {
+	checker, err := factories[c.Type](h, c)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Condition != "" {
+		// the condition has been validated by "Parse()"
+		checker, _ = health.Condition(checker, c.Condition)
+	}
+
+	cfg := &health.Config{
+		Name:             c.Name,
+		Checker:          checker,
+		Interval:         c.Interval,
+		Schedule:         c.Schedule,
+		InitialDelay:     c.InitialDelay,
+		Timeout:          c.Timeout,
+		Fatal:            c.Fatal,
+		Critical:         c.Critical,
+		Tags:             c.Tags,
+		DependsOn:        c.DependsOn,
+		FailureThreshold: c.FailureThreshold,
+		SuccessThreshold: c.SuccessThreshold,
+		Jitter:           c.Jitter,
+		CacheTTL:         c.CacheTTL,
+		Priority:         priorities[c.Priority],
+		Startup:          c.Startup,
+		Weight:           c.Weight,
+	}
+
+	if c.Retry != nil {
+		cfg.Retry = c.Retry.policy()
+	}
+
+	return cfg, nil
+}
+
+// returns the retry policy
+func (r *Retry) policy() retry.Policy {
+	var p retry.Policy = retry.Constant{Delay: r.Delay, Retries: r.Retries}
+	if r.Exponential {
+		p = retry.Exponential{Initial: r.Delay, Max: r.MaxDelay, Retries: r.Retries}
+	}
+
+	if r.Jitter > 0 {
+		p = retry.Jittered{Policy: p, Jitter: r.Jitter}
+	}
+
+	return p
+}
+
+// replaces the references to environment variables
+func expandEnv(data []byte) ([]byte, error) {
+	var missing string
+
+	expanded := envVarRegexp.ReplaceAllFunc(data, func(ref []byte) []byte {
+		name := string(envVarRegexp.FindSubmatch(ref)[1])
+
+		value, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+
+		return []byte(value)
+	})
+
+	if missing != "" {
+		return nil, fmt.Errorf("Environment variable '%v' is not set", missing)
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	. "github.com/onsi/gomega"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/retry"
+)
+
+const testYAML = `
+wait_on_start: true
+start_timeout: 5s
+latency_window: 10
+
+checks:
+  - name: api
+    type: http
+    interval: 10s
+    timeout: 2s
+    fatal: true
+    tags: [readiness]
+    retry:
+      retries: 2
+      delay: 100ms
+      exponential: true
+      max_delay: 1s
+    http:
+      url: ${CONFIG_TEST_URL}/health
+      headers:
+        X-Token: secret
+      expect: ok
+
+  - name: port
+    type: tcp
+    condition: details == nil
+    priority: high
+    tcp:
+      address: localhost:5432
+
+  - name: checkout
+    type: computed
+    depends_on: [api]
+    computed:
+      expression: api.ok && !maintenance
+      vars:
+        maintenance: false
+`
+
+func TestParse(t *testing.T) {
+	RegisterTestingT(t)
+
+	os.Setenv("CONFIG_TEST_URL", "http://localhost:8080")
+	defer os.Unsetenv("CONFIG_TEST_URL")
+
+	t.Run("Should parse YAML", func(t *testing.T) {
+		cfg, err := Parse([]byte(testYAML))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cfg.WaitOnStart).To(BeTrue())
+		Expect(cfg.StartTimeout).To(Equal(5 * time.Second))
+		Expect(cfg.LatencyWindow).To(Equal(10))
+		Expect(cfg.Checks).To(HaveLen(3))
+
+		api := cfg.Checks[0]
+		Expect(api.Name).To(Equal("api"))
+		Expect(api.Interval).To(Equal(10 * time.Second))
+		Expect(api.Tags).To(Equal([]string{"readiness"}))
+		Expect(api.HTTP.URL).To(Equal("http://localhost:8080/health"))
+		Expect(api.HTTP.Headers).To(Equal(map[string]string{"X-Token": "secret"}))
+		Expect(api.Retry.policy()).To(Equal(retry.Exponential{Initial: 100 * time.Millisecond, Max: time.Second, Retries: 2}))
+
+		Expect(cfg.Checks[1].TCP.Address).To(Equal("localhost:5432"))
+		Expect(cfg.Checks[2].Computed.Vars).To(Equal(map[string]interface{}{"maintenance": false}))
+	})
+
+	t.Run("Should parse JSON", func(t *testing.T) {
+		cfg, err := Parse([]byte(`{
+	"default_interval": "30s",
+	"checks": [
+		{"name": "port", "type": "tcp", "failure_threshold": 3, "tcp": {"address": "localhost:6379"}}
+	]
+}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cfg.DefaultInterval).To(Equal(30 * time.Second))
+		Expect(cfg.Checks[0].FailureThreshold).To(Equal(3))
+		Expect(cfg.Checks[0].TCP.Address).To(Equal("localhost:6379"))
+	})
+
+	t.Run("Should error on invalid configs", func(t *testing.T) {
+		for src, msg := range map[string]string{
+			"checks: [{name: a, type: tcp, tcp: {adress: x}}]":                "field adress not found",
+			"checks: [{name: a, type: tcp, interval: 10, tcp: {address: x}}]": "cannot unmarshal",
+			`{"checks": [}`:         "invalid character",
+			"checks: [{type: tcp}]": "Check #1 has no name",
+			"checks: [{name: a, type: tcp, tcp: {}}, {name: a, type: tcp}]": "Duplicate check 'a'",
+			"checks: [{name: a}]":                                                         "Invalid check 'a': Type must be set",
+			"checks: [{name: a, type: ftp}]":                                              "Unknown type 'ftp'",
+			"checks: [{name: a, type: http, tcp: {address: x}}]":                          "Settings of type 'http' must be set",
+			"checks: [{name: a, type: tcp, priority: urgent, tcp: {address: x}}]":         "Unknown priority 'urgent'",
+			"checks: [{name: a, type: tcp, condition: 'details.x <', tcp: {address: x}}]": "Invalid condition: Unable to parse expression",
+			"checks: [{name: a, type: redis, redis: {password: ${CONFIG_TEST_MISSING}}}]": "Environment variable 'CONFIG_TEST_MISSING' is not set",
+		} {
+			_, err := Parse([]byte(src))
+			Expect(err).To(MatchError(ContainSubstring(msg)), src)
+		}
+	})
+}
+
+func TestBuild(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should build a wired health instance", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("X-Token")).To(Equal("secret"))
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		os.Setenv("CONFIG_TEST_URL", server.URL)
+		defer os.Unsetenv("CONFIG_TEST_URL")
+
+		path := filepath.Join(t.TempDir(), "health.yaml")
+		Expect(os.WriteFile(path, []byte(testYAML), 0600)).To(Succeed())
+
+		h, err := Load(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(h.WaitOnStart).To(BeTrue())
+		Expect(h.LatencyWindow).To(Equal(10))
+
+		descs := h.Descriptions()
+		Expect(descs).To(HaveLen(3))
+		Expect(descs[0].Type).To(Equal("http"))
+		Expect(descs[1].Type).To(Equal("computed"))
+		Expect(descs[2].Type).To(Equal("reachable"))
+		Expect(descs[2].Capabilities).To(ContainElement("condition"))
+
+		h.DisableLogging()
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		state, err := h.RunCheck("api")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("ok"))
+		Expect(state.Fatal).To(BeTrue())
+
+		state, err = h.RunCheck("checkout")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("ok"))
+	})
+
+	t.Run("Should build redis and sql checks", func(t *testing.T) {
+		server, err := miniredis.Run()
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		_, mock, err := sqlmock.NewWithDSN("config_test_db")
+		Expect(err).ToNot(HaveOccurred())
+		mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+		cfg, err := Parse([]byte(`
+checks:
+  - name: cache
+    type: redis
+    redis:
+      addr: ` + server.Addr() + `
+      ping: true
+      set: {key: health, value: ok}
+  - name: db
+    type: sql
+    sql:
+      driver: sqlmock
+      dsn: config_test_db
+      query: SELECT 1
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		h := health.New()
+
+		cfgs, err := cfg.BuildChecks(h)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfgs).To(HaveLen(2))
+
+		_, err = cfgs[0].Checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.Get("health")).To(Equal("ok"))
+
+		_, err = cfgs[1].Checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	t.Run("Should error if a checker cannot be created", func(t *testing.T) {
+		for src, msg := range map[string]string{
+			"checks: [{name: a, type: http, http: {url: ''}}]":                  "Unable to build check 'a': Unable to parse URL ''",
+			"checks: [{name: a, type: tcp, tcp: {address: ''}}]":                "Address must be set",
+			"checks: [{name: a, type: redis, redis: {addr: 'x:1'}}]":            "At minimum, either cfg.Ping",
+			"checks: [{name: a, type: sql, sql: {driver: sqlmock}}]":            "Driver and DSN must be set",
+			"checks: [{name: a, type: sql, sql: {driver: nope, dsn: x}}]":       "Unable to open database",
+			"checks: [{name: a, type: computed, computed: {expression: '&&'}}]": "Unable to parse expression",
+			"checks: [{name: a, type: plugin, plugin: {settings: {a: 1}}}]":     "Path must be set",
+			"checks: [{name: a, type: plugin, plugin: {path: checks.jar}}]":     "Unknown plugin type '.jar'",
+			"checks: [{name: a, type: plugin, plugin: {path: checks.wasm}}]":    "Unable to read module",
+		} {
+			cfg, err := Parse([]byte(src))
+			Expect(err).ToNot(HaveOccurred(), src)
+
+			_, err = cfg.Build()
+			Expect(err).To(MatchError(ContainSubstring(msg)), src)
+		}
+	})
+
+	t.Run("Should error if the file cannot be read", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+		Expect(err).To(MatchError(ContainSubstring("Unable to read config file")))
+	})
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultProfileEnv is the environment variable that selects the profile
+	// of the config, unless "WithProfile()" or "WithProfileEnv()" is passed.
+	DefaultProfileEnv = "HEALTH_PROFILE"
+)
+
+// Option alters how a config is parsed (see "Parse()"), ie. which profile is
+// applied or how secrets are resolved.
+type Option func(*options)
+
+type options struct {
+	profile    string
+	profileSet bool
+	profileEnv string
+	secrets    map[string]SecretResolver // by scheme
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{profileEnv: DefaultProfileEnv, secrets: defaultSecretResolvers()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithProfile selects the profile (ie. `prod`) whose overrides are applied to
+// the config; an empty name applies none, regardless of the environment.
+func WithProfile(name string) Option {
+	return func(o *options) {
+		o.profile = name
+		o.profileSet = true
+	}
+}
+
+// WithProfileEnv selects the profile via the given environment variable
+// instead of "DefaultProfileEnv".
+func WithProfileEnv(name string) Option {
+	return func(o *options) {
+		o.profileEnv = name
+	}
+}
+
+// returns the name of the selected profile, if any
+func (o *options) selectedProfile() string {
+	if o.profileSet {
+		return o.profile
+	}
+
+	return os.Getenv(o.profileEnv)
+}
+
+// applies the overrides of the selected profile (see "profiles" in the README)
+// to the document; returns the document without its profiles. Every profile
+// is validated, so that a typo in the overrides of one environment is caught
+// in all of them.
+func applyProfile(data []byte, o *options) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("Unable to parse config: %v", err)
+	}
+
+	raw, hasProfiles := doc["profiles"]
+	selected := o.selectedProfile()
+
+	if !hasProfiles && selected == "" {
+		return data, nil
+	}
+
+	delete(doc, "profiles")
+
+	profiles, ok := raw.(map[string]interface{})
+	if hasProfiles && !ok {
+		return nil, fmt.Errorf("Unable to parse config: profiles must be a map of profile names to overrides")
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var merged []byte
+
+	for _, name := range names {
+		overrides, ok := profiles[name].(map[string]interface{})
+		if !ok && profiles[name] != nil {
+			return nil, fmt.Errorf("Invalid profile '%v': overrides must be a map", name)
+		}
+
+		result, err := mergeProfile(doc, overrides)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid profile '%v': %v", name, err)
+		}
+
+		data, err := yaml.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid profile '%v': %v", name, err)
+		}
+
+		cfg, err := decode(data)
+		if err == nil {
+			err = cfg.validate()
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid profile '%v': %v", name, err)
+		}
+
+		if name == selected {
+			merged = data
+		}
+	}
+
+	if selected == "" {
+		return yaml.Marshal(doc)
+	}
+
+	if merged == nil {
+		return nil, fmt.Errorf("Unknown profile '%v'", selected)
+	}
+
+	return merged, nil
+}
+
+// returns the document with the overrides of a profile applied: the settings
+// of the instance are replaced, while the checks are merged by name (nested
+// blocks, ie. `http`, field by field); checks that do not exist in the
+// document are added
+func mergeProfile(doc, overrides map[string]interface{}) (map[string]interface{}, error) {
+	result := mergeMaps(doc, overrides, "checks")
+
+	raw, ok := overrides["checks"]
+	if !ok {
+		return result, nil
+	}
+
+	checks, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("checks must be a list")
+	}
+
+	base, _ := doc["checks"].([]interface{})
+	merged := make([]interface{}, len(base), len(base)+len(checks))
+	copy(merged, base)
+
+	for i, c := range checks {
+		check, ok := c.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Check #%d has no name", i+1)
+		}
+
+		if _, ok := check["name"].(string); !ok {
+			return nil, fmt.Errorf("Check #%d has no name", i+1)
+		}
+
+		found := false
+		for j, b := range merged {
+			if prev, ok := b.(map[string]interface{}); ok && prev["name"] == check["name"] {
+				merged[j] = mergeMaps(prev, check)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			merged = append(merged, check)
+		}
+	}
+
+	result["checks"] = merged
+
+	return result, nil
+}
+
+// returns a copy of "dst" with the values of "src" merged into it; nested
+// maps are merged recursively, other values are replaced. The given keys of
+// "src" are skipped.
+func mergeMaps(dst, src map[string]interface{}, skip ...string) map[string]interface{} {
+	result := make(map[string]interface{}, len(dst)+len(src))
+	for k, v := range dst {
+		result[k] = v
+	}
+
+	for k, v := range src {
+		if contains(skip, k) {
+			continue
+		}
+
+		prev, prevOK := result[k].(map[string]interface{})
+		next, nextOK := v.(map[string]interface{})
+
+		if prevOK && nextOK {
+			result[k] = mergeMaps(prev, next)
+			continue
+		}
+
+		result[k] = v
+	}
+
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+const testProfilesYAML = `
+default_interval: 30s
+
+checks:
+  - name: api
+    type: http
+    fatal: true
+    tags: [readiness]
+    http:
+      url: https://api.example.com/health
+      expect: ok
+
+  - name: cache
+    type: tcp
+    tcp:
+      address: redis.example.com:6379
+
+profiles:
+  dev:
+    default_interval: 5s
+    checks:
+      - name: api
+        fatal: false
+        http: {url: "http://localhost:8080/health"}
+      - name: cache
+        disabled: true
+
+  prod:
+    checks:
+      - name: db-port
+        type: tcp
+        tcp: {address: "db.example.com:5432"}
+`
+
+func TestProfiles(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should use the base config without a profile", func(t *testing.T) {
+		cfg, err := Parse([]byte(testProfilesYAML), WithProfile(""))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cfg.DefaultInterval).To(Equal(30 * time.Second))
+		Expect(cfg.Checks).To(HaveLen(2))
+		Expect(cfg.Checks[0].Fatal).To(BeTrue())
+		Expect(cfg.Checks[0].HTTP.URL).To(Equal("https://api.example.com/health"))
+	})
+
+	t.Run("Should merge the overrides of the profile", func(t *testing.T) {
+		cfg, err := Parse([]byte(testProfilesYAML), WithProfile("dev"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cfg.DefaultInterval).To(Equal(5 * time.Second))
+		Expect(cfg.Checks).To(HaveLen(1))
+
+		api := cfg.Checks[0]
+		Expect(api.Name).To(Equal("api"))
+		Expect(api.Fatal).To(BeFalse())
+		Expect(api.Tags).To(Equal([]string{"readiness"}))
+		Expect(api.HTTP.URL).To(Equal("http://localhost:8080/health"))
+		Expect(api.HTTP.Expect).To(Equal("ok"))
+	})
+
+	t.Run("Should add the checks of the profile", func(t *testing.T) {
+		cfg, err := Parse([]byte(testProfilesYAML), WithProfile("prod"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cfg.DefaultInterval).To(Equal(30 * time.Second))
+		Expect(cfg.Checks).To(HaveLen(3))
+		Expect(cfg.Checks[2].Name).To(Equal("db-port"))
+		Expect(cfg.Checks[2].TCP.Address).To(Equal("db.example.com:5432"))
+	})
+
+	t.Run("Should select the profile via the environment", func(t *testing.T) {
+		os.Setenv(DefaultProfileEnv, "dev")
+		defer os.Unsetenv(DefaultProfileEnv)
+
+		cfg, err := Parse([]byte(testProfilesYAML))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Checks).To(HaveLen(1))
+
+		os.Setenv("CONFIG_TEST_PROFILE", "prod")
+		defer os.Unsetenv("CONFIG_TEST_PROFILE")
+
+		cfg, err = Parse([]byte(testProfilesYAML), WithProfileEnv("CONFIG_TEST_PROFILE"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Checks).To(HaveLen(3))
+
+		// an explicit profile takes precedence over the environment
+		cfg, err = Parse([]byte(testProfilesYAML), WithProfile(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Checks).To(HaveLen(2))
+	})
+
+	t.Run("Should merge the profiles of JSON configs", func(t *testing.T) {
+		cfg, err := Parse([]byte(`{
+			"checks": [{"name": "port", "type": "tcp", "tcp": {"address": "localhost:5432"}}],
+			"profiles": {"dev": {"checks": [{"name": "port", "tcp": {"address": "localhost:5433"}}]}}
+		}`), WithProfile("dev"))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Checks[0].TCP.Address).To(Equal("localhost:5433"))
+	})
+
+	t.Run("Should error on an unknown profile", func(t *testing.T) {
+		_, err := Parse([]byte(testProfilesYAML), WithProfile("staging"))
+		Expect(err).To(MatchError("Unknown profile 'staging'"))
+
+		_, err = Parse([]byte("checks: []\n"), WithProfile("dev"))
+		Expect(err).To(MatchError("Unknown profile 'dev'"))
+	})
+
+	t.Run("Should validate every profile", func(t *testing.T) {
+		invalid := map[string]string{
+			"unknown field": `
+checks:
+  - name: port
+    type: tcp
+    tcp: {address: "localhost:5432"}
+profiles:
+  dev:
+    checks:
+      - name: port
+        tcp: {adress: "localhost:5433"}
+`,
+			"invalid check": `
+checks: []
+profiles:
+  dev:
+    checks:
+      - name: port
+        type: tcp
+`,
+			"check without a name": `
+checks: []
+profiles:
+  dev:
+    checks:
+      - type: tcp
+`,
+		}
+
+		for desc, data := range invalid {
+			_, err := Parse([]byte(data), WithProfile(""))
+			Expect(err).To(HaveOccurred(), desc)
+			Expect(err.Error()).To(HavePrefix("Invalid profile 'dev': "), desc)
+		}
+
+		_, err := Parse([]byte("checks: []\nprofiles: [dev]\n"), WithProfile(""))
+		Expect(err).To(HaveOccurred())
+	})
+}
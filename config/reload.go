@@ -0,0 +1,185 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// Reloader keeps the checks of a health instance in sync with a config file,
+// so that checks can be added, tuned or removed without restarting the
+// process:
+//
+//	r := config.NewReloader("/etc/myapp/health.yaml")
+//
+//	h, err := r.Load()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	h.Start()
+//	defer r.Watch(10 * time.Second)()
+//
+// Only the checks are reloaded (see "Syncer"); the settings of the health
+// instance (ie. "wait_on_start") are applied by "Load()" only.
+type Reloader struct {
+	// OnReload is optional; it is called after every reload triggered by
+	// "Watch()" with the checks that have changed, or the error if the
+	// reload has failed (the running checks are left untouched in that case).
+	OnReload func(diff health.ChecksDiff, err error)
+
+	path   string
+	opts   []Option
+	lock   sync.Mutex
+	syncer *Syncer
+	data   []byte // content of the last loaded file
+}
+
+// NewReloader returns a reloader for the config file; "Load()" must be
+// called first. The options are applied on every reload (see "Parse()").
+func NewReloader(path string, opts ...Option) *Reloader {
+	return &Reloader{path: path, opts: opts}
+}
+
+// Load reads the config file and builds the health instance (see
+// "config.Load()"), which is then kept in sync with the file by "Reload()".
+func (r *Reloader) Load() (*health.Health, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data, cfg, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	h, checks, cfgs, err := cfg.build()
+	if err != nil {
+		return nil, err
+	}
+
+	r.syncer = NewSyncer(h)
+	for i, check := range checks {
+		r.syncer.checks[check.Name] = &builtCheck{check: check, cfg: cfgs[i]}
+	}
+
+	r.data = data
+
+	return h, nil
+}
+
+// Reload reads the config file again and applies the difference to the
+// health instance (see "Syncer"): only the checks whose config has changed
+// are rebuilt (ie. reconnected) and restarted. The checkers of the removed
+// and updated checks are closed if they implement "io.Closer".
+//
+// If the file is invalid or a checker cannot be created, an error is
+// returned and the running checks are left untouched.
+func (r *Reloader) Reload() (health.ChecksDiff, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.syncer == nil {
+		return health.ChecksDiff{}, errors.New("Config has not been loaded")
+	}
+
+	data, cfg, err := r.read()
+	if err != nil {
+		return health.ChecksDiff{}, err
+	}
+
+	diff, err := r.syncer.Sync(cfg)
+	if err != nil {
+		return health.ChecksDiff{}, err
+	}
+
+	r.data = data
+
+	return diff, nil
+}
+
+// Watch checks the config file for changes at the given interval and reloads
+// it (see "Reload()") when its content has changed; the file is polled
+// rather than watched, so that editors that replace the file and the
+// symlinks of mounted ConfigMaps are handled alike. An error (ie. an invalid
+// file) is reported once, until the content of the file changes again. The
+// returned func stops watching.
+func (r *Reloader) Watch(interval time.Duration) func() {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	r.lock.Lock()
+	seen := r.data // content of the last reloaded (or loaded) file
+	r.lock.Unlock()
+
+	go func() {
+		defer ticker.Stop()
+
+		unreadable := false
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			// the tick may have raced with the stop
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			data, err := os.ReadFile(r.path)
+			if err != nil {
+				if !unreadable {
+					r.notify(health.ChecksDiff{}, fmt.Errorf("Unable to read config file: %v", err))
+				}
+
+				unreadable = true
+				continue
+			}
+
+			unreadable = false
+
+			if bytes.Equal(data, seen) {
+				continue
+			}
+
+			seen = data
+			r.notify(r.Reload())
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func (r *Reloader) notify(diff health.ChecksDiff, err error) {
+	if r.OnReload != nil {
+		r.OnReload(diff, err)
+	}
+}
+
+// reads and parses the config file
+func (r *Reloader) read() ([]byte, *Config, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to read config file: %v", err)
+	}
+
+	cfg, err := Parse(data, r.opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, cfg, nil
+}
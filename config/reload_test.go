@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+const reloadYAML = `
+checks:
+  - name: cache
+    type: redis
+    redis: {addr: REDIS_ADDR, ping: true}
+  - name: port
+    type: tcp
+    interval: 1m
+    tcp: {address: "localhost:5432"}
+  - name: ssh
+    type: tcp
+    interval: 1m
+    tcp: {address: "localhost:22"}
+`
+
+func TestReload(t *testing.T) {
+	RegisterTestingT(t)
+
+	server, err := miniredis.Run()
+	Expect(err).ToNot(HaveOccurred())
+	defer server.Close()
+
+	src := strings.Replace(reloadYAML, "REDIS_ADDR", server.Addr(), 1)
+
+	setup := func() (*Reloader, *health.Health, string) {
+		path := filepath.Join(t.TempDir(), "health.yaml")
+		Expect(os.WriteFile(path, []byte(src), 0600)).To(Succeed())
+
+		r := NewReloader(path)
+
+		h, err := r.Load()
+		Expect(err).ToNot(HaveOccurred())
+
+		h.DisableLogging()
+		Expect(h.Start()).To(Succeed())
+
+		return r, h, path
+	}
+
+	names := func(h *health.Health) []string {
+		var names []string
+		for _, desc := range h.Descriptions() {
+			names = append(names, desc.Name)
+		}
+
+		return names
+	}
+
+	t.Run("Should apply the changed checks", func(t *testing.T) {
+		r, h, path := setup()
+		defer h.Stop()
+
+		cache := r.syncer.checks["cache"].cfg.Checker
+
+		changed := strings.Replace(src, "interval: 1m\n    tcp: {address: \"localhost:5432\"}", "interval: 2m\n    tcp: {address: \"localhost:5432\"}", 1)
+		changed = strings.Replace(changed, "name: ssh", "name: sshd", 1)
+		Expect(os.WriteFile(path, []byte(changed), 0600)).To(Succeed())
+
+		diff, err := r.Reload()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(health.ChecksDiff{Added: []string{"sshd"}, Updated: []string{"port"}, Removed: []string{"ssh"}}))
+		Expect(names(h)).To(Equal([]string{"cache", "port", "sshd"}))
+
+		timing, err := h.Timing("port")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timing.Interval).To(Equal(2 * time.Minute))
+
+		// the unchanged check keeps its checker (and connection)
+		Expect(r.syncer.checks["cache"].cfg.Checker).To(BeIdenticalTo(cache))
+		_, err = cache.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		// the checker of an updated check is closed
+		Expect(os.WriteFile(path, []byte(strings.Replace(changed, "ping: true", "ping: true, db: 1", 1)), 0600)).To(Succeed())
+
+		diff, err = r.Reload()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(health.ChecksDiff{Updated: []string{"cache"}}))
+
+		_, err = cache.Status()
+		Expect(err).To(MatchError(ContainSubstring("closed")))
+
+		// reloading an unchanged file changes nothing
+		diff, err = r.Reload()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.Empty()).To(BeTrue())
+	})
+
+	t.Run("Should leave the running checks untouched on errors", func(t *testing.T) {
+		r, h, path := setup()
+		defer h.Stop()
+
+		for content, msg := range map[string]string{
+			"checks: [{name: a}]": "Type must be set",
+			src + "  - name: api\n    type: http\n    http: {url: ''}\n":                          "Unable to build check 'api'",
+			src + "  - name: api\n    type: tcp\n    depends_on: [nope]\n    tcp: {address: x}\n": "Unable to sync checks",
+		} {
+			Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+
+			_, err := r.Reload()
+			Expect(err).To(MatchError(ContainSubstring(msg)), content)
+			Expect(names(h)).To(Equal([]string{"cache", "port", "ssh"}))
+		}
+
+		_, err := r.syncer.checks["cache"].cfg.Checker.Status()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.Remove(path)).To(Succeed())
+
+		_, err = r.Reload()
+		Expect(err).To(MatchError(ContainSubstring("Unable to read config file")))
+	})
+
+	t.Run("Should keep the checks that have not been loaded from the file", func(t *testing.T) {
+		r, h, path := setup()
+		defer h.Stop()
+
+		Expect(h.AddCheck(&health.Config{Name: "own", Checker: &fakes.FakeICheckable{}, Interval: time.Minute})).To(Succeed())
+
+		Expect(os.WriteFile(path, []byte(strings.Replace(src, "name: ssh", "name: sshd", 1)), 0600)).To(Succeed())
+
+		diff, err := r.Reload()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.Removed).To(Equal([]string{"ssh"}))
+		Expect(names(h)).To(Equal([]string{"cache", "own", "port", "sshd"}))
+	})
+
+	t.Run("Should error if the config has not been loaded", func(t *testing.T) {
+		_, err := NewReloader("health.yaml").Reload()
+		Expect(err).To(MatchError("Config has not been loaded"))
+	})
+
+	t.Run("Watch should reload the file when it changes", func(t *testing.T) {
+		r, h, path := setup()
+		defer h.Stop()
+
+		type reload struct {
+			diff health.ChecksDiff
+			err  error
+		}
+
+		reloads := make(chan reload, 10)
+		r.OnReload = func(diff health.ChecksDiff, err error) { reloads <- reload{diff, err} }
+
+		// replaced atomically, so that a partially written file is never read
+		write := func(content string) {
+			tmp := path + ".tmp"
+			Expect(os.WriteFile(tmp, []byte(content), 0600)).To(Succeed())
+			Expect(os.Rename(tmp, path)).To(Succeed())
+		}
+
+		stop := r.Watch(5 * time.Millisecond)
+		defer stop()
+
+		Consistently(reloads, 25*time.Millisecond).ShouldNot(Receive())
+
+		write(strings.Replace(src, "name: ssh", "name: sshd", 1))
+
+		var rl reload
+		Eventually(reloads).Should(Receive(&rl))
+		Expect(rl.err).ToNot(HaveOccurred())
+		Expect(rl.diff).To(Equal(health.ChecksDiff{Added: []string{"sshd"}, Removed: []string{"ssh"}}))
+
+		// errors are reported once
+		write("checks: [{name: a}]")
+
+		Eventually(reloads).Should(Receive(&rl))
+		Expect(rl.err).To(MatchError(ContainSubstring("Type must be set")))
+		Consistently(reloads, 25*time.Millisecond).ShouldNot(Receive())
+
+		stop()
+		write(src)
+		Consistently(reloads, 25*time.Millisecond).ShouldNot(Receive())
+		Expect(names(h)).To(Equal([]string{"cache", "port", "sshd"}))
+	})
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// maximum time to resolve the secrets of a check
+	secretTimeout = 30 * time.Second
+)
+
+var (
+	// references to secrets within a value, ie. `Bearer ${vault:secret/api#token}`
+	secretRefRegexp = regexp.MustCompile(`\$\{([A-Za-z][A-Za-z0-9+.-]*):([^}]+)\}`)
+
+	// values that are references to secrets as a whole, ie. `awsssm:/prod/db`;
+	// only resolved if the scheme has a resolver, so that values such as
+	// `localhost:5432` are left as is
+	secretValueRegexp = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*):(.+)$`)
+)
+
+// SecretResolver resolves references to secrets in the settings of the checks
+// (ie. `vault:secret/db#password`), so that secrets do not have to be stored
+// in the config or the environment; "ref" is the reference without its scheme
+// (ie. `secret/db#password`). Resolvers are registered per scheme via
+// "WithSecretResolver()".
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc is an adapter to use a func as a "SecretResolver".
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f(ctx, ref).
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// WithSecretResolver registers the resolver of the secrets of the given
+// scheme (ie. `vault`). The `env` scheme (ie. `env:DB_PASSWORD`) is
+// registered by default; it can be replaced as well.
+func WithSecretResolver(scheme string, r SecretResolver) Option {
+	return func(o *options) {
+		o.secrets[scheme] = r
+	}
+}
+
+// resolves `env:NAME` references
+var envSecretResolver = SecretResolverFunc(func(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("Environment variable '%v' is not set", name)
+	}
+
+	return value, nil
+})
+
+func defaultSecretResolvers() map[string]SecretResolver {
+	return map[string]SecretResolver{"env": envSecretResolver}
+}
+
+// CacheSecrets wraps the resolver, so that every secret is only resolved
+// once per "ttl" (ie. on every reload of the config); errors are not cached.
+func CacheSecrets(r SecretResolver, ttl time.Duration) SecretResolver {
+	return &cachedSecrets{resolver: r, ttl: ttl, cache: make(map[string]cachedSecret)}
+}
+
+type cachedSecrets struct {
+	resolver SecretResolver
+	ttl      time.Duration
+
+	lock  sync.Mutex
+	cache map[string]cachedSecret // by ref
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+func (c *cachedSecrets) Resolve(ctx context.Context, ref string) (string, error) {
+	c.lock.Lock()
+	secret, ok := c.cache[ref]
+	c.lock.Unlock()
+
+	if ok && time.Now().Before(secret.expires) {
+		return secret.value, nil
+	}
+
+	value, err := c.resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	c.lock.Lock()
+	c.cache[ref] = cachedSecret{value: value, expires: time.Now().Add(c.ttl)}
+	c.lock.Unlock()
+
+	return value, nil
+}
+
+// returns the resolvers of the secrets of the config
+func (c *Config) secretResolvers() map[string]SecretResolver {
+	if c.secrets == nil {
+		return defaultSecretResolvers()
+	}
+
+	return c.secrets
+}
+
+// returns a copy of the check with the references to secrets in its settings
+// resolved; the check itself is left as is, so that secrets are resolved
+// again on every build (ie. after a rotation)
+func (c *Config) resolveSecrets(check *Check) (*Check, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+
+	r := &secretResolution{ctx: ctx, resolvers: c.secretResolvers()}
+	resolved := *check
+
+	for _, settings := range []interface{}{
+		&resolved.HTTP, &resolved.TCP, &resolved.Redis, &resolved.Mongo,
+		&resolved.SQL, &resolved.Computed, &resolved.Plugin,
+	} {
+		v := reflect.ValueOf(settings).Elem()
+
+		copied, err := r.resolve(v)
+		if err != nil {
+			return nil, err
+		}
+
+		v.Set(copied)
+	}
+
+	return &resolved, nil
+}
+
+type secretResolution struct {
+	ctx       context.Context
+	resolvers map[string]SecretResolver
+}
+
+// returns a copy of the value with the references to secrets in its strings
+// resolved
+func (r *secretResolution) resolve(v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := r.resolveString(v.String())
+		if err != nil {
+			return v, err
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.SetString(s)
+
+		return c, nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		e, err := r.resolve(v.Elem())
+		if err != nil {
+			return v, err
+		}
+
+		if v.Kind() == reflect.Ptr {
+			c := reflect.New(v.Type().Elem())
+			c.Elem().Set(e)
+
+			return c, nil
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(e)
+
+		return c, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			e, err := r.resolve(iter.Value())
+			if err != nil {
+				return v, err
+			}
+
+			c.SetMapIndex(iter.Key(), e)
+		}
+
+		return c, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			e, err := r.resolve(v.Index(i))
+			if err != nil {
+				return v, err
+			}
+
+			c.Index(i).Set(e)
+		}
+
+		return c, nil
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			if !c.Field(i).CanSet() {
+				continue
+			}
+
+			e, err := r.resolve(v.Field(i))
+			if err != nil {
+				return v, err
+			}
+
+			c.Field(i).Set(e)
+		}
+
+		return c, nil
+	default:
+		return v, nil
+	}
+}
+
+// resolves a value that is a reference as a whole, or the references within
+// the value; the error does not contain the value, only the reference
+func (r *secretResolution) resolveString(s string) (string, error) {
+	if m := secretValueRegexp.FindStringSubmatch(s); m != nil {
+		if resolver, ok := r.resolvers[m[1]]; ok && !secretRefRegexp.MatchString(s) {
+			return r.resolveRef(resolver, s, m[2])
+		}
+	}
+
+	var err error
+
+	resolved := secretRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		m := secretRefRegexp.FindStringSubmatch(ref)
+
+		resolver, ok := r.resolvers[m[1]]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("Unable to resolve secret '%v:%v': no resolver for scheme '%v'", m[1], m[2], m[1])
+			}
+
+			return ref
+		}
+
+		value, rerr := r.resolveRef(resolver, m[1]+":"+m[2], m[2])
+		if rerr != nil && err == nil {
+			err = rerr
+		}
+
+		return value
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return resolved, nil
+}
+
+func (r *secretResolution) resolveRef(resolver SecretResolver, ref, name string) (string, error) {
+	value, err := resolver.Resolve(r.ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("Unable to resolve secret '%v': %v", ref, err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const testSecretsYAML = `
+checks:
+  - name: api
+    type: http
+    http:
+      url: http://localhost:8080/health
+      headers:
+        Authorization: "Bearer ${vault:secret/api#token}"
+        X-Env: "${env:CONFIG_TEST_SECRET}"
+  - name: port
+    type: tcp
+    tcp:
+      address: vault:secret/db#address
+  - name: plugin
+    type: plugin
+    plugin:
+      path: /plugins/check.so
+      settings:
+        auth: {password: "env:CONFIG_TEST_SECRET"}
+        hosts: [localhost:5432, "${vault:secret/db#address}"]
+`
+
+func TestSecrets(t *testing.T) {
+	RegisterTestingT(t)
+
+	os.Setenv("CONFIG_TEST_SECRET", "from-env")
+	defer os.Unsetenv("CONFIG_TEST_SECRET")
+
+	vault := map[string]string{
+		"secret/api#token":  "t0ken",
+		"secret/db#address": "db.example.com:5432",
+	}
+
+	resolver := SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		if value, ok := vault[ref]; ok {
+			return value, nil
+		}
+
+		return "", errors.New("permission denied")
+	})
+
+	t.Run("Should resolve references to secrets", func(t *testing.T) {
+		cfg, err := Parse([]byte(testSecretsYAML), WithSecretResolver("vault", resolver))
+		Expect(err).ToNot(HaveOccurred())
+
+		api, err := cfg.resolveSecrets(cfg.Checks[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(api.HTTP.URL).To(Equal("http://localhost:8080/health"))
+		Expect(api.HTTP.Headers).To(Equal(map[string]string{"Authorization": "Bearer t0ken", "X-Env": "from-env"}))
+
+		port, err := cfg.resolveSecrets(cfg.Checks[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(port.TCP.Address).To(Equal("db.example.com:5432"))
+
+		plugin, err := cfg.resolveSecrets(cfg.Checks[2])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plugin.Plugin.Settings).To(Equal(map[string]interface{}{
+			"auth":  map[string]interface{}{"password": "from-env"},
+			"hosts": []interface{}{"localhost:5432", "db.example.com:5432"},
+		}))
+
+		// the references are kept in the config
+		Expect(cfg.Checks[0].HTTP.Headers["Authorization"]).To(Equal("Bearer ${vault:secret/api#token}"))
+		Expect(cfg.Checks[1].TCP.Address).To(Equal("vault:secret/db#address"))
+		Expect(cfg.Checks[2].Plugin.Settings["auth"]).To(Equal(map[string]interface{}{"password": "env:CONFIG_TEST_SECRET"}))
+	})
+
+	t.Run("Should resolve the secrets before building the checks", func(t *testing.T) {
+		cfg, err := Parse([]byte(testSecretsYAML), WithSecretResolver("vault", resolver))
+		Expect(err).ToNot(HaveOccurred())
+
+		cfg.Checks = cfg.Checks[1:2]
+
+		cfgs, err := cfg.BuildChecks(health.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfgs).To(HaveLen(1))
+	})
+
+	t.Run("Should error on unresolvable secrets", func(t *testing.T) {
+		// without a resolver for the scheme
+		cfg, err := Parse([]byte(testSecretsYAML))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = cfg.BuildChecks(health.New())
+		Expect(err).To(MatchError("Unable to build check 'api': Unable to resolve secret 'vault:secret/api#token': no resolver for scheme 'vault'"))
+
+		// the value of the whole reference is left as is, since it may not be
+		// a reference at all
+		port, err := cfg.resolveSecrets(cfg.Checks[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(port.TCP.Address).To(Equal("vault:secret/db#address"))
+
+		delete(vault, "secret/api#token")
+		defer func() { vault["secret/api#token"] = "t0ken" }()
+
+		cfg, err = Parse([]byte(testSecretsYAML), WithSecretResolver("vault", resolver))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = cfg.BuildChecks(health.New())
+		Expect(err).To(MatchError("Unable to build check 'api': Unable to resolve secret 'vault:secret/api#token': permission denied"))
+
+		os.Unsetenv("CONFIG_TEST_SECRET")
+		defer os.Setenv("CONFIG_TEST_SECRET", "from-env")
+
+		_, err = cfg.resolveSecrets(cfg.Checks[2])
+		Expect(err).To(MatchError("Unable to resolve secret 'env:CONFIG_TEST_SECRET': Environment variable 'CONFIG_TEST_SECRET' is not set"))
+	})
+
+	t.Run("Should rebuild the checks whose secrets have changed", func(t *testing.T) {
+		cfg, err := Parse([]byte(testSecretsYAML), WithSecretResolver("vault", resolver))
+		Expect(err).ToNot(HaveOccurred())
+
+		cfg.Checks = cfg.Checks[:2]
+
+		s := NewSyncer(health.New())
+
+		diff, err := s.Sync(cfg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.Added).To(Equal([]string{"api", "port"}))
+
+		diff, err = s.Sync(cfg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(health.ChecksDiff{}))
+
+		vault["secret/api#token"] = "r0tated"
+		defer func() { vault["secret/api#token"] = "t0ken" }()
+
+		diff, err = s.Sync(cfg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(health.ChecksDiff{Updated: []string{"api"}}))
+		Expect(s.checks["api"].check.HTTP.Headers["Authorization"]).To(Equal("Bearer r0tated"))
+	})
+
+	t.Run("Should cache secrets", func(t *testing.T) {
+		calls := 0
+		failing := false
+
+		cached := CacheSecrets(SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+			if failing {
+				return "", errors.New("unavailable")
+			}
+
+			calls++
+			return ref + "-value", nil
+		}), 50*time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			value, err := cached.Resolve(context.Background(), "foo")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal("foo-value"))
+		}
+
+		Expect(calls).To(Equal(1))
+
+		_, err := cached.Resolve(context.Background(), "bar")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal(2))
+
+		time.Sleep(60 * time.Millisecond)
+
+		// errors are not cached
+		failing = true
+		_, err = cached.Resolve(context.Background(), "foo")
+		Expect(err).To(MatchError("unavailable"))
+
+		failing = false
+		_, err = cached.Resolve(context.Background(), "foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal(3))
+	})
+}
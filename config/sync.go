@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// Syncer applies configs to a health instance, ie. configs that are loaded
+// again from a file (see "Reloader") or fetched from a control plane: only
+// the checks whose config has changed are rebuilt (ie. reconnected) and
+// restarted (see "h.SyncChecks()"). The checkers of the removed and updated
+// checks are closed if they implement "io.Closer".
+//
+// The syncer only manages the checks it has added; other checks of the
+// instance (ie. added via "h.AddCheck()") are kept. Only the checks of the
+// configs are applied, the settings of the instance are ignored.
+type Syncer struct {
+	health *health.Health
+	lock   sync.Mutex
+	checks map[string]*builtCheck // by name
+}
+
+// a check of the config (with its secrets resolved) along with the config
+// that has been built for it
+type builtCheck struct {
+	check *Check
+	cfg   *health.Config
+}
+
+// NewSyncer returns a syncer for the health instance.
+func NewSyncer(h *health.Health) *Syncer {
+	return &Syncer{health: h, checks: make(map[string]*builtCheck)}
+}
+
+// Sync applies the checks of the config to the health instance. If a checker
+// cannot be created or the resulting checks are invalid (ie. a dependency is
+// missing), an error is returned and the running checks are left untouched.
+func (s *Syncer) Sync(cfg *Config) (health.ChecksDiff, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	checks := make(map[string]*builtCheck, len(cfg.Checks))
+	cfgs := make([]*health.Config, 0, len(cfg.Checks))
+	var built []*health.Config
+
+	for _, check := range cfg.Checks {
+		// secrets are resolved on every sync, so that rotated secrets are
+		// picked up
+		resolved, err := cfg.resolveSecrets(check)
+		if err != nil {
+			closeCheckers(built)
+			return health.ChecksDiff{}, fmt.Errorf("Unable to build check '%v': %v", check.Name, err)
+		}
+
+		// unchanged checks keep their config (and checker)
+		if prev, ok := s.checks[check.Name]; ok && reflect.DeepEqual(prev.check, resolved) {
+			checks[check.Name] = prev
+			cfgs = append(cfgs, prev.cfg)
+			continue
+		}
+
+		c, err := resolved.build(s.health)
+		if err != nil {
+			closeCheckers(built)
+			return health.ChecksDiff{}, fmt.Errorf("Unable to build check '%v': %v", check.Name, err)
+		}
+
+		built = append(built, c)
+		checks[check.Name] = &builtCheck{check: resolved, cfg: c}
+		cfgs = append(cfgs, c)
+	}
+
+	managed := make([]string, 0, len(s.checks))
+	for name := range s.checks {
+		managed = append(managed, name)
+	}
+
+	diff, err := s.health.SyncChecks(cfgs, health.SyncOnly(managed...))
+	if err != nil {
+		closeCheckers(built)
+		return health.ChecksDiff{}, err
+	}
+
+	var retired []*health.Config
+	for name, prev := range s.checks {
+		if cur, ok := checks[name]; !ok || cur != prev {
+			retired = append(retired, prev.cfg)
+		}
+	}
+
+	closeCheckers(retired)
+
+	s.checks = checks
+
+	return diff, nil
+}
+
+// closes the checkers that implement "io.Closer"; the checkers are not
+// shared between checks, since every check builds its own
+func closeCheckers(cfgs []*health.Config) {
+	for _, cfg := range cfgs {
+		if closer, ok := cfg.Checker.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Package controlplane pulls check definitions from a central control plane,
+// so that SRE teams can roll out fleet-wide probes without changing the code
+// of every service:
+//
+//	client, err := controlplane.New(h, &controlplane.Config{
+//		URL:        "https://health-control.example.com/v1/checks/my-service",
+//		PublicKeys: []ed25519.PublicKey{key},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	defer client.Watch(time.Minute)()
+//
+// Refer to the README of the package for the protocol.
+package controlplane
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/config"
+)
+
+const (
+	// DefaultTimeout is used for requests if "Config.HTTPClient" is not set
+	DefaultTimeout = 10 * time.Second
+
+	// MaxDocumentSize is the maximum size of a document (1 MiB)
+	MaxDocumentSize = 1 << 20
+)
+
+// Document is the response of the control plane. "Config" contains the
+// checks in the format of the "config" package (YAML or JSON); the settings
+// of the health instance are ignored.
+//
+// "Signature" is the ed25519 signature (base64) of "Version", a newline and
+// "Config" (see "Sign()"); it is required if "Config.PublicKeys" is set.
+type Document struct {
+	Version   string `json:"version"`
+	Config    string `json:"config"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// Sign signs the document with the private key, ie. for the control plane
+// (or a CI pipeline that publishes documents to it).
+func Sign(doc *Document, key ed25519.PrivateKey) {
+	doc.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, doc.message()))
+}
+
+// the signed message; the version is signed too, so that the config of one
+// version cannot be passed off as another
+func (d *Document) message() []byte {
+	return []byte(d.Version + "\n" + d.Config)
+}
+
+// verifies the signature against any of the keys
+func (d *Document) verify(keys []ed25519.PublicKey) error {
+	if d.Signature == "" {
+		return errors.New("Document is not signed")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(d.Signature)
+	if err != nil {
+		return fmt.Errorf("Unable to decode signature: %v", err)
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, d.message(), sig) {
+			return nil
+		}
+	}
+
+	return errors.New("Signature of document is invalid")
+}
+
+// Config is used for configuring the client.
+//
+// "URL" is _required_; the documents are fetched via `GET`.
+//
+// "PublicKeys" is optional; if set, documents must be signed by one of the
+// keys (ie. the current and the next key during a rotation). Unsigned
+// documents are accepted otherwise; always set it if the checks are not
+// fetched from a trusted network.
+//
+// "Version" is optional; pins the version of the checks: it is sent as the
+// `version` query parameter and documents of other versions are rejected.
+// Defaults to the latest version.
+//
+// "Header" is optional; sent with every request (ie. `Authorization`).
+//
+// "HTTPClient" is optional; defaults to a client with a "DefaultTimeout".
+//
+// "ParseOptions" is optional; passed to "config.Parse()" for every document,
+// ie. to resolve secrets (see "config.WithSecretResolver()").
+//
+// "OnSync" is optional; it is called after every sync triggered by "Watch()"
+// with the version and the checks that have changed, or the error if the
+// sync has failed (the running checks are left untouched in that case).
+type Config struct {
+	URL          string
+	PublicKeys   []ed25519.PublicKey
+	Version      string
+	Header       http.Header
+	HTTPClient   *http.Client
+	ParseOptions []config.Option
+	OnSync       func(version string, diff health.ChecksDiff, err error)
+}
+
+// Client keeps the checks of a health instance in sync with the control
+// plane. Only the checks it has added are managed; other checks of the
+// instance (ie. added via "h.AddCheck()") are kept.
+type Client struct {
+	Config *Config
+
+	url    *url.URL
+	syncer *config.Syncer
+
+	lock    sync.Mutex
+	version string // of the applied document
+	etag    string
+}
+
+// New creates a client for the health instance; the checks are only fetched
+// once "Sync()" or "Watch()" is called.
+func New(h *health.Health, cfg *Config) (*Client, error) {
+	if h == nil {
+		return nil, errors.New("Health instance must be set")
+	}
+
+	if cfg == nil || cfg.URL == "" {
+		return nil, errors.New("URL must be set")
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse URL: %v", err)
+	}
+
+	if cfg.Version != "" {
+		query := u.Query()
+		query.Set("version", cfg.Version)
+		u.RawQuery = query.Encode()
+	}
+
+	return &Client{Config: cfg, url: u, syncer: config.NewSyncer(h)}, nil
+}
+
+// Version returns the version of the applied checks; empty if none have been
+// applied yet.
+func (c *Client) Version() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.version
+}
+
+// Sync fetches the checks from the control plane and applies them to the
+// health instance (see "config.Syncer"); a document that has already been
+// applied (same version) changes nothing. If the document cannot be fetched,
+// is not signed correctly, does not match the pinned version or the checks
+// cannot be built, an error is returned and the running checks are left
+// untouched.
+func (c *Client) Sync(ctx context.Context) (health.ChecksDiff, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	doc, etag, err := c.fetch(ctx)
+	if err != nil {
+		return health.ChecksDiff{}, err
+	}
+
+	// not modified
+	if doc == nil {
+		return health.ChecksDiff{}, nil
+	}
+
+	if doc.Version != "" && doc.Version == c.version {
+		c.etag = etag
+		return health.ChecksDiff{}, nil
+	}
+
+	if len(c.Config.PublicKeys) > 0 {
+		if err := doc.verify(c.Config.PublicKeys); err != nil {
+			return health.ChecksDiff{}, err
+		}
+	}
+
+	if c.Config.Version != "" && doc.Version != c.Config.Version {
+		return health.ChecksDiff{}, fmt.Errorf("Control plane has returned version '%v', but version '%v' is pinned", doc.Version, c.Config.Version)
+	}
+
+	cfg, err := config.Parse([]byte(doc.Config), c.Config.ParseOptions...)
+	if err != nil {
+		return health.ChecksDiff{}, err
+	}
+
+	diff, err := c.syncer.Sync(cfg)
+	if err != nil {
+		return health.ChecksDiff{}, err
+	}
+
+	c.version, c.etag = doc.Version, etag
+
+	return diff, nil
+}
+
+// Watch syncs the checks right away and then at the given interval (see
+// "Sync()"), until the returned func is called. The results are passed to
+// "OnSync".
+func (c *Client) Watch(interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			diff, err := c.Sync(ctx)
+
+			// the sync may have been canceled by the stop
+			if ctx.Err() != nil {
+				return
+			}
+
+			if c.Config.OnSync != nil {
+				c.Config.OnSync(c.Version(), diff, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// fetches the document; returns a nil document if it has not been modified
+func (c *Client) fetch(ctx context.Context) (*Document, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("Unable to create request: %v", err)
+	}
+
+	for k, values := range c.Config.Header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if c.etag != "" {
+		req.Header.Set("If-None-Match", c.etag)
+	}
+
+	client := c.Config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("Unable to fetch checks: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, "", nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("Unable to fetch checks: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("Unable to read checks: %v", err)
+	}
+
+	if len(body) > MaxDocumentSize {
+		return nil, "", fmt.Errorf("Document exceeds %d bytes", MaxDocumentSize)
+	}
+
+	doc := &Document{}
+	if err := json.Unmarshal(body, doc); err != nil {
+		return nil, "", fmt.Errorf("Unable to parse document: %v", err)
+	}
+
+	return doc, resp.Header.Get("ETag"), nil
+}
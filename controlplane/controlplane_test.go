@@ -0,0 +1,281 @@
+package controlplane
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+const (
+	checksV1 = `
+checks:
+  - name: port
+    type: tcp
+    interval: 1m
+    tcp: {address: "localhost:5432"}
+`
+
+	checksV2 = `
+checks:
+  - name: ssh
+    type: tcp
+    interval: 1m
+    tcp: {address: "localhost:22"}
+`
+)
+
+// fakeControlPlane serves the current document
+type fakeControlPlane struct {
+	lock     sync.Mutex
+	doc      *Document
+	etag     string
+	requests []*http.Request
+}
+
+func (f *fakeControlPlane) set(doc *Document, etag string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.doc, f.etag = doc, etag
+}
+
+func (f *fakeControlPlane) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.requests = append(f.requests, r)
+
+	if f.etag != "" {
+		if r.Header.Get("If-None-Match") == f.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", f.etag)
+	}
+
+	json.NewEncoder(w).Encode(f.doc)
+}
+
+func (f *fakeControlPlane) lastRequest() *http.Request {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.requests[len(f.requests)-1]
+}
+
+func signed(version, checks string, key ed25519.PrivateKey) *Document {
+	doc := &Document{Version: version, Config: checks}
+	Sign(doc, key)
+
+	return doc
+}
+
+func names(h *health.Health) []string {
+	var names []string
+	for _, desc := range h.Descriptions() {
+		names = append(names, desc.Name)
+	}
+
+	return names
+}
+
+func TestNew(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error on invalid configs", func(t *testing.T) {
+		_, err := New(nil, &Config{URL: "http://localhost"})
+		Expect(err).To(MatchError("Health instance must be set"))
+
+		_, err = New(health.New(), &Config{})
+		Expect(err).To(MatchError("URL must be set"))
+
+		_, err = New(health.New(), &Config{URL: "http://[::1"})
+		Expect(err).To(MatchError(ContainSubstring("Unable to parse URL")))
+	})
+}
+
+func TestSync(t *testing.T) {
+	RegisterTestingT(t)
+
+	pub, key, err := ed25519.GenerateKey(nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	setup := func(cfg *Config) (*Client, *health.Health, *fakeControlPlane) {
+		cp := &fakeControlPlane{doc: signed("v1", checksV1, key)}
+		server := httptest.NewServer(cp)
+		t.Cleanup(server.Close)
+
+		h := health.New()
+		h.DisableLogging()
+		Expect(h.AddCheck(&health.Config{Name: "own", Checker: &fakes.FakeICheckable{}, Interval: time.Minute})).To(Succeed())
+		Expect(h.Start()).To(Succeed())
+		t.Cleanup(func() { h.Stop() })
+
+		cfg.URL = server.URL + "/v1/checks/my-service"
+		if cfg.PublicKeys == nil {
+			cfg.PublicKeys = []ed25519.PublicKey{pub}
+		}
+
+		client, err := New(h, cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		return client, h, cp
+	}
+
+	t.Run("Should apply the checks of the control plane", func(t *testing.T) {
+		client, h, cp := setup(&Config{Header: http.Header{"Authorization": {"Bearer token"}}})
+
+		diff, err := client.Sync(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(health.ChecksDiff{Added: []string{"port"}}))
+		Expect(client.Version()).To(Equal("v1"))
+		Expect(names(h)).To(Equal([]string{"own", "port"}))
+		Expect(cp.lastRequest().Header.Get("Authorization")).To(Equal("Bearer token"))
+
+		cp.set(signed("v2", checksV2, key), "")
+
+		diff, err = client.Sync(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(health.ChecksDiff{Added: []string{"ssh"}, Removed: []string{"port"}}))
+		Expect(client.Version()).To(Equal("v2"))
+
+		// checks that have not been added by the client are kept
+		Expect(names(h)).To(Equal([]string{"own", "ssh"}))
+	})
+
+	t.Run("Should not apply the same version again", func(t *testing.T) {
+		client, _, cp := setup(&Config{})
+		cp.set(signed("v1", checksV1, key), `"v1"`)
+
+		_, err := client.Sync(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		diff, err := client.Sync(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.Empty()).To(BeTrue())
+		Expect(cp.lastRequest().Header.Get("If-None-Match")).To(Equal(`"v1"`))
+	})
+
+	t.Run("Should pin the version", func(t *testing.T) {
+		client, h, cp := setup(&Config{Version: "v1"})
+
+		_, err := client.Sync(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cp.lastRequest().URL.Query().Get("version")).To(Equal("v1"))
+
+		cp.set(signed("v2", checksV2, key), "")
+
+		_, err = client.Sync(context.Background())
+		Expect(err).To(MatchError("Control plane has returned version 'v2', but version 'v1' is pinned"))
+		Expect(names(h)).To(Equal([]string{"own", "port"}))
+	})
+
+	t.Run("Should reject documents that are not signed correctly", func(t *testing.T) {
+		client, h, cp := setup(&Config{})
+
+		tampered := signed("v1", checksV1, key)
+		tampered.Config = checksV2
+
+		for doc, msg := range map[*Document]string{
+			{Version: "v1", Config: checksV1}:                            "Document is not signed",
+			{Version: "v1", Config: checksV1, Signature: "%%"}:           "Unable to decode signature",
+			signed("v1", checksV1, otherKey):                             "Signature of document is invalid",
+			tampered:                                                     "Signature of document is invalid",
+			signed("v1", "checks: [{name: a}]", key):                     "Type must be set",
+			signed("v1", "checks: [{name: a, type: tcp, tcp: {}}]", key): "Unable to build check 'a'",
+		} {
+			cp.set(doc, "")
+
+			_, err := client.Sync(context.Background())
+			Expect(err).To(MatchError(ContainSubstring(msg)), doc.Config)
+			Expect(names(h)).To(Equal([]string{"own"}))
+			Expect(client.Version()).To(BeEmpty())
+		}
+	})
+
+	t.Run("Should accept unsigned documents without public keys", func(t *testing.T) {
+		client, _, cp := setup(&Config{PublicKeys: []ed25519.PublicKey{}})
+		cp.set(&Document{Version: "v1", Config: checksV1}, "")
+
+		_, err := client.Sync(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("Should error if the checks cannot be fetched", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/unavailable":
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case "/invalid":
+				w.Write([]byte("<html>"))
+			case "/large":
+				w.Write(make([]byte, MaxDocumentSize+1))
+			}
+		}))
+		defer server.Close()
+
+		for path, msg := range map[string]string{
+			"/unavailable": "Unable to fetch checks: unexpected status code 503",
+			"/invalid":     "Unable to parse document",
+			"/large":       "Document exceeds 1048576 bytes",
+		} {
+			client, err := New(health.New(), &Config{URL: server.URL + path})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.Sync(context.Background())
+			Expect(err).To(MatchError(ContainSubstring(msg)), path)
+		}
+	})
+
+	t.Run("Watch should sync the checks periodically", func(t *testing.T) {
+		type result struct {
+			version string
+			diff    health.ChecksDiff
+			err     error
+		}
+
+		syncs := make(chan result, 10)
+
+		client, h, cp := setup(&Config{
+			OnSync: func(version string, diff health.ChecksDiff, err error) { syncs <- result{version, diff, err} },
+		})
+
+		stop := client.Watch(5 * time.Millisecond)
+		defer stop()
+
+		var s result
+		Eventually(syncs).Should(Receive(&s))
+		Expect(s.err).ToNot(HaveOccurred())
+		Expect(s.version).To(Equal("v1"))
+		Expect(s.diff.Added).To(Equal([]string{"port"}))
+
+		cp.set(signed("v2", checksV2, key), "")
+
+		Eventually(func() string { return client.Version() }).Should(Equal("v2"))
+		Expect(names(h)).To(Equal([]string{"own", "ssh"}))
+
+		stop()
+		time.Sleep(10 * time.Millisecond)
+
+		// drain the syncs that completed before the stop
+		for len(syncs) > 0 {
+			<-syncs
+		}
+
+		Consistently(syncs, 25*time.Millisecond).ShouldNot(Receive())
+	})
+}
@@ -0,0 +1,155 @@
+package health
+
+import (
+	"reflect"
+)
+
+// returns a deep copy of the state, so that it can be modified (or
+// serialized) without affecting the recorded state
+func (s State) copy() State {
+	if s.Tags != nil {
+		s.Tags = append([]string{}, s.Tags...)
+	}
+
+	if s.Latency != nil {
+		latency := *s.Latency
+		s.Latency = &latency
+	}
+
+	s.Details = copyDetails(s.Details)
+
+	return s
+}
+
+// replaces the states with deep copies (see "State.copy()"); the tags and
+// latency stats of all states share a single allocation, since "h.State()"
+// copies every state on every call
+func copyStates(states map[string]State) {
+	numTags, numLatencies := 0, 0
+	for _, state := range states {
+		numTags += len(state.Tags)
+		if state.Latency != nil {
+			numLatencies++
+		}
+	}
+
+	tags := make([]string, 0, numTags)
+	latencies := make([]LatencyStats, 0, numLatencies)
+
+	for name, state := range states {
+		if state.Tags != nil {
+			start := len(tags)
+			tags = append(tags, state.Tags...)
+			state.Tags = tags[start:len(tags):len(tags)]
+		}
+
+		if state.Latency != nil {
+			latencies = append(latencies, *state.Latency)
+			state.Latency = &latencies[len(latencies)-1]
+		}
+
+		state.Details = copyDetails(state.Details)
+
+		states[name] = state
+	}
+}
+
+// returns a deep copy of the details of a check; pointers, maps, slices and
+// the exported fields of structs are copied recursively, everything else
+// (ie. unexported fields, funcs or channels) is shared
+func copyDetails(details interface{}) interface{} {
+	if details == nil {
+		return nil
+	}
+
+	return deepCopy(reflect.ValueOf(details), make(map[copiedPointer]reflect.Value)).Interface()
+}
+
+// copiedPointer identifies a pointer that has already been copied, so that
+// shared (or cyclic) references are preserved
+type copiedPointer struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func deepCopy(v reflect.Value, seen map[copiedPointer]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		key := copiedPointer{addr: v.Pointer(), typ: v.Type()}
+		if c, ok := seen[key]; ok {
+			return c
+		}
+
+		c := reflect.New(v.Type().Elem())
+		seen[key] = c
+		c.Elem().Set(deepCopy(v.Elem(), seen))
+
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), seen))
+
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value(), seen))
+		}
+
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copyElems(c, v, seen)
+
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		copyElems(c, v, seen)
+
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i), seen))
+			}
+		}
+
+		return c
+	default:
+		return v
+	}
+}
+
+// copies the elements of a slice or array
+func copyElems(dst, src reflect.Value, seen map[copiedPointer]reflect.Value) {
+	switch src.Type().Elem().Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		reflect.Copy(dst, src)
+		return
+	}
+
+	for i := 0; i < src.Len(); i++ {
+		dst.Index(i).Set(deepCopy(src.Index(i), seen))
+	}
+}
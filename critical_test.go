@@ -0,0 +1,50 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestCritical(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Non-critical checks should not flip the overall failed state", func(t *testing.T) {
+		failing := &fakes.FakeICheckable{}
+		failing.StatusReturns(nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "cache", Checker: failing, Interval: time.Hour},
+			{Name: "db", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Critical: true},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(2))
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states["cache"].Status).To(Equal("failed"))
+
+	})
+
+	t.Run("Critical checks should flip the overall failed state", func(t *testing.T) {
+		dbChecker := &fakes.FakeICheckable{}
+		dbChecker.StatusReturns(nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "db", Checker: dbChecker, Interval: time.Hour, Critical: true},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(h.Failed).Should(BeTrue())
+
+		states, _, _ := h.State()
+		Expect(states["db"].Fatal).To(BeTrue())
+	})
+}
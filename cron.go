@@ -0,0 +1,282 @@
+package health
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cron descriptors and their equivalent expressions
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes the bounds (and names) of a field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	// 7 is an alias of sunday
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// cronExpr is a parsed (standard, five-field) cron expression; every field is
+// a bit set of the matching values
+type cronExpr struct {
+	minute, hour, dom, month, dow uint64
+
+	// whether the day fields are unrestricted ("*"); if both are restricted,
+	// a day matches if either field matches
+	domStar, dowStar bool
+}
+
+// parses a cron expression ("minute hour day-of-month month day-of-week", ie.
+// "5 * * * *" for every hour at :05) or one of the "@hourly", "@daily",
+// "@weekly", "@monthly" and "@yearly" descriptors
+func parseCron(spec string) (*cronExpr, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@") {
+		expr, ok := cronDescriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown descriptor '%v'", spec)
+		}
+
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields (minute hour day-of-month month day-of-week), got %d",
+			len(cronFields), len(fields))
+	}
+
+	bits := make([]uint64, len(fields))
+
+	for i, f := range fields {
+		b, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+
+		bits[i] = b
+	}
+
+	c := &cronExpr{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+
+	// sunday may be given as 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	return c, nil
+}
+
+// parses a comma separated list of values, ranges ("1-5") and steps ("*/15"
+// or "10-50/20") into a bit set
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step '%v' in %v field '%v'", part[i+1:], spec.name, field)
+			}
+
+			rng, step = part[:i], n
+		}
+
+		lo, hi := spec.min, spec.max
+
+		if rng != "*" {
+			var err error
+
+			bounds := strings.SplitN(rng, "-", 2)
+			if lo, err = parseCronValue(bounds[0], spec); err != nil {
+				return 0, fmt.Errorf("%v in %v field '%v'", err, spec.name, field)
+			}
+
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = parseCronValue(bounds[1], spec); err != nil {
+					return 0, fmt.Errorf("%v in %v field '%v'", err, spec.name, field)
+				}
+			} else if step > 1 {
+				// "10/20" is short for "10-max/20"
+				hi = spec.max
+			}
+
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range '%v' in %v field '%v'", rng, spec.name, field)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseCronValue(s string, spec cronField) (int, error) {
+	if v, ok := spec.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("value '%v' is out of range [%d-%d]", s, spec.min, spec.max)
+	}
+
+	return v, nil
+}
+
+// returns the first time after "t" that matches the expression; the zero
+// time if there is none within five years (ie. "0 0 30 2 *")
+func (c *cronExpr) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c *cronExpr) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// validates the schedules of the checks
+func validateSchedules(cfgs []*Config) error {
+	for _, c := range cfgs {
+		if c.Schedule == "" {
+			continue
+		}
+
+		expr, err := parseCron(c.Schedule)
+		if err != nil {
+			return fmt.Errorf("Check '%v' has an invalid schedule: %v", c.Name, err)
+		}
+
+		if expr.next(time.Now()).IsZero() {
+			return fmt.Errorf("Check '%v' has a schedule that never fires", c.Name)
+		}
+
+		if c.AdaptiveInterval || c.Backoff || c.Jitter > 0 {
+			return fmt.Errorf("Check '%v' cannot combine a schedule with an adaptive interval, backoff or jitter", c.Name)
+		}
+	}
+
+	return nil
+}
+
+// cronSchedule delivers the ticks of a cron expression from its own goroutine
+type cronSchedule struct {
+	expr   *cronExpr
+	ticker Ticker // reset to the next matching time on every tick
+	done   chan struct{}
+	once   sync.Once
+}
+
+// the first tick is delivered at the first matching time after "delay"
+func newCronSchedule(clock Clock, expr *cronExpr, delay time.Duration, fire func()) *cronSchedule {
+	now := clock.Now()
+
+	c := &cronSchedule{
+		expr:   expr,
+		ticker: clock.NewTicker(expr.next(now.Add(delay)).Sub(now)),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer c.ticker.Stop()
+
+		for {
+			select {
+			case <-c.ticker.C():
+				fire()
+
+				// the next matching time is always in the future
+				now := clock.Now()
+				next := c.expr.next(now)
+				if next.IsZero() {
+					return
+				}
+
+				c.ticker.Reset(next.Sub(now))
+			case <-c.done:
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+// the ticks are determined by the cron expression, the interval is ignored
+func (c *cronSchedule) reset(d time.Duration) {}
+
+func (c *cronSchedule) stop() {
+	c.once.Do(func() { close(c.done) })
+}
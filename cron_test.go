@@ -0,0 +1,125 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestParseCron(t *testing.T) {
+	RegisterTestingT(t)
+
+	// a wednesday
+	now := time.Date(2024, time.January, 10, 12, 34, 56, 0, time.UTC)
+
+	t.Run("Should return the next matching time", func(t *testing.T) {
+		cases := map[string]time.Time{
+			"* * * * *":           time.Date(2024, time.January, 10, 12, 35, 0, 0, time.UTC),
+			"5 * * * *":           time.Date(2024, time.January, 10, 13, 5, 0, 0, time.UTC),
+			"*/15 * * * *":        time.Date(2024, time.January, 10, 12, 45, 0, 0, time.UTC),
+			"0 9-17/4 * * *":      time.Date(2024, time.January, 10, 13, 0, 0, 0, time.UTC),
+			"30 2 * * mon-fri":    time.Date(2024, time.January, 11, 2, 30, 0, 0, time.UTC),
+			"0 0 * * 7":           time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC),
+			"0 0 1,15 * *":        time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+			"0 0 29 feb *":        time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			"0 0 1 * sat":         time.Date(2024, time.January, 13, 0, 0, 0, 0, time.UTC),
+			"@hourly":             time.Date(2024, time.January, 10, 13, 0, 0, 0, time.UTC),
+			"@daily":              time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC),
+			"@weekly":             time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC),
+			"@monthly":            time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+			"@YEARLY":             time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			"  0   12  10  1  * ": time.Date(2025, time.January, 10, 12, 0, 0, 0, time.UTC),
+		}
+
+		for spec, expected := range cases {
+			expr, err := parseCron(spec)
+			Expect(err).ToNot(HaveOccurred(), spec)
+			Expect(expr.next(now)).To(Equal(expected), spec)
+		}
+	})
+
+	t.Run("Should return the zero time if the expression never matches", func(t *testing.T) {
+		expr, err := parseCron("0 0 30 2 *")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expr.next(now).IsZero()).To(BeTrue())
+	})
+
+	t.Run("Should error with invalid expressions", func(t *testing.T) {
+		cases := map[string]string{
+			"* * * *":      "expected 5 fields",
+			"@often":       "unknown descriptor '@often'",
+			"60 * * * *":   "value '60' is out of range [0-59] in minute field '60'",
+			"* * 0 * *":    "value '0' is out of range [1-31] in day of month field '0'",
+			"* * * foo *":  "value 'foo' is out of range [1-12] in month field 'foo'",
+			"*/0 * * * *":  "invalid step '0' in minute field '*/0'",
+			"* 10-5 * * *": "invalid range '10-5' in hour field '10-5'",
+			"* * * * 1-":   "value '' is out of range [0-7] in day of week field '1-'",
+		}
+
+		for spec, msg := range cases {
+			_, err := parseCron(spec)
+			Expect(err).To(HaveOccurred(), spec)
+			Expect(err.Error()).To(ContainSubstring(msg), spec)
+		}
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should execute a scheduled check right away, then only on its schedule", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:     "foo",
+				Checker:  checker,
+				Schedule: "@yearly",
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(checker.StatusCallCount).Should(Equal(1))
+		Consistently(checker.StatusCallCount, 50*time.Millisecond).Should(Equal(1))
+	})
+
+	t.Run("Should not execute a scheduled check with an initial delay right away", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:         "foo",
+				Checker:      checker,
+				Schedule:     "@yearly",
+				InitialDelay: time.Millisecond,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Consistently(checker.StatusCallCount, 50*time.Millisecond).Should(Equal(0))
+	})
+
+	t.Run("Should error with an invalid schedule", func(t *testing.T) {
+		cases := map[*Config]string{
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Schedule: "every hour"}:                      "Check 'foo' has an invalid schedule: expected 5 fields",
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Schedule: "0 0 31 2 *"}:                      "Check 'foo' has a schedule that never fires",
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Schedule: "@hourly", Backoff: true}:          "Check 'foo' cannot combine a schedule with an adaptive interval, backoff or jitter",
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Schedule: "@hourly", Jitter: 0.1}:            "cannot combine a schedule",
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Schedule: "@hourly", AdaptiveInterval: true}: "cannot combine a schedule",
+		}
+
+		for cfg, msg := range cases {
+			h := setupNewTestHealth()
+			h.AddCheck(cfg)
+
+			err := h.Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(msg))
+		}
+	})
+}
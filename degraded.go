@@ -0,0 +1,42 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DegradedError is returned by a checker to signal that its dependency is
+// degraded but still usable (ie. high latency or replica lag). The check is
+// then reported as "degraded" instead of "failed": it does not flip the
+// overall failed state, does not count towards "Config.FailureThreshold" and
+// does not open an incident.
+//
+// Errors that implement "Degraded() bool" are reported as degraded as well,
+// ie. "checkers.DegradedError" (the "checkers" package cannot depend on this
+// one).
+type DegradedError struct {
+	Reason string
+}
+
+// Error satisfies the "error" interface.
+func (e *DegradedError) Error() string {
+	return e.Reason
+}
+
+// Degraded returns a "DegradedError" with the given (formatted) reason.
+func Degraded(format string, args ...interface{}) error {
+	return &DegradedError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// indicates whether the error (or any error it wraps) is a "DegradedError"
+// or reports itself as degraded
+func isDegradedError(err error) bool {
+	var degraded *DegradedError
+	if errors.As(err, &degraded) {
+		return true
+	}
+
+	var d interface{ Degraded() bool }
+
+	return errors.As(err, &d) && d.Degraded()
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/checkers"
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestDegraded(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should report checks returning a DegradedError as degraded", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, Degraded("replica lag is %v", 5*time.Second))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "db", Checker: checker, Interval: time.Hour, Fatal: true, Tags: []string{"db"}},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(1))
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states["db"].Status).To(Equal("degraded"))
+		Expect(states["db"].Err).To(Equal("replica lag is 5s"))
+		Expect(states["db"].ConsecutiveErrors).To(BeZero())
+
+		Expect(h.IsHealthy("db")).To(BeTrue())
+		Expect(h.Groups()["db"].Status).To(Equal("degraded"))
+		Expect(h.Incidents()).To(BeEmpty())
+
+		// wrapped degraded errors are detected as well
+		checker.StatusReturns(nil, fmt.Errorf("db: %w", Degraded("high latency")))
+
+		state, _ := h.RunCheck("db")
+		Expect(state.Status).To(Equal("degraded"))
+		Expect(state.Err).To(Equal("db: high latency"))
+
+		// as are the degraded errors of the checkers package
+		checker.StatusReturns(nil, &checkers.DegradedError{Reason: "usage exceeds 80% of the limit"})
+
+		state, _ = h.RunCheck("db")
+		Expect(state.Status).To(Equal("degraded"))
+
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		state, _ = h.RunCheck("db")
+		Expect(state.Status).To(Equal("failed"))
+		Expect(h.Failed()).To(BeTrue())
+	})
+
+	t.Run("A failed check should stay failed until the success threshold is reached", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "db", Checker: checker, Interval: time.Hour, SuccessThreshold: 2},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(1))
+
+		checker.StatusReturns(nil, Degraded("high latency"))
+
+		state, _ := h.RunCheck("db")
+		Expect(state.Status).To(Equal("failed"))
+
+		state, _ = h.RunCheck("db")
+		Expect(state.Status).To(Equal("degraded"))
+	})
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"fmt"
+)
+
+// validates that all dependencies exist and that there are no cycles
+func validateDependencies(cfgs []*Config) error {
+	byName := make(map[string]*Config, len(cfgs))
+	for _, c := range cfgs {
+		byName[c.Name] = c
+	}
+
+	for _, c := range cfgs {
+		for _, dep := range c.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("Check '%v' depends on unknown check '%v'", c.Name, dep)
+			}
+		}
+	}
+
+	// depth-first search for cycles
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	marks := make(map[string]int, len(cfgs))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case visiting:
+			return fmt.Errorf("Check '%v' has a circular dependency", name)
+		case visited:
+			return nil
+		}
+
+		marks[name] = visiting
+		for _, dep := range byName[name].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		marks[name] = visited
+
+		return nil
+	}
+
+	for _, c := range cfgs {
+		if err := visit(c.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns the name of the first dependency of the check that is currently
+// failing (or skipped itself); empty if all dependencies are healthy
+func (h *Health) failedDependency(cfg *Config) string {
+	if len(cfg.DependsOn) == 0 {
+		return ""
+	}
+
+	for _, dep := range cfg.DependsOn {
+		if state, ok := h.lookupState(dep); ok && (state.isFailure() || state.isSkipped()) {
+			return dep
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error on unknown dependencies", func(t *testing.T) {
+		err := validateDependencies([]*Config{
+			{Name: "a"},
+			{Name: "b", DependsOn: []string{"c"}},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Check 'b' depends on unknown check 'c'"))
+	})
+
+	t.Run("Should error on circular dependencies", func(t *testing.T) {
+		err := validateDependencies([]*Config{
+			{Name: "a", DependsOn: []string{"c"}},
+			{Name: "b", DependsOn: []string{"a"}},
+			{Name: "c", DependsOn: []string{"b"}},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("has a circular dependency"))
+	})
+
+	t.Run("Should accept valid dependencies", func(t *testing.T) {
+		err := validateDependencies([]*Config{
+			{Name: "a"},
+			{Name: "b", DependsOn: []string{"a"}},
+			{Name: "c", DependsOn: []string{"a", "b"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("Start should fail with invalid dependencies", func(t *testing.T) {
+		h := New()
+		h.AddCheck(&Config{Name: "a", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, DependsOn: []string{"a"}})
+
+		err := h.Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to start healthcheck"))
+	})
+}
+
+func TestDependsOn(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should skip a check while its dependency is failing", func(t *testing.T) {
+		checkerA := &fakes.FakeICheckable{}
+		checkerB := &fakes.FakeICheckable{}
+		checkerC := &fakes.FakeICheckable{}
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "a", Checker: checkerA, Interval: time.Hour, Fatal: true},
+			{Name: "b", Checker: checkerB, Interval: time.Hour, Fatal: true, DependsOn: []string{"a"}},
+			{Name: "c", Checker: checkerC, Interval: time.Hour, DependsOn: []string{"b"}},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(3))
+
+		// b fails on its own
+		checkerB.StatusReturns(nil, errors.New("b broke"))
+
+		state, _ := h.RunCheck("b")
+		Expect(state.Status).To(Equal("failed"))
+		incidentID := state.IncidentID
+
+		// a fails, b and (transitively) c are skipped
+		checkerA.StatusReturns(nil, errors.New("a broke"))
+		h.RunCheck("a")
+
+		calls := checkerB.StatusCallCount()
+
+		state, _ = h.RunCheck("b")
+		Expect(state.Status).To(Equal("skipped"))
+		Expect(state.Err).To(Equal("skipped (dependency 'a' failed)"))
+		Expect(state.IncidentID).To(Equal(incidentID))
+		Expect(checkerB.StatusCallCount()).To(Equal(calls))
+
+		state, _ = h.RunCheck("c")
+		Expect(state.Status).To(Equal("skipped"))
+		Expect(state.Err).To(Equal("skipped (dependency 'b' failed)"))
+
+		// only a counts as failure
+		states, failed, _ := h.State()
+		Expect(failed).To(BeTrue())
+		Expect(states["b"].Status).To(Equal("skipped"))
+
+		// a and b recover
+		checkerA.StatusReturns(nil, nil)
+		checkerB.StatusReturns(nil, nil)
+		h.RunCheck("a")
+
+		state, _ = h.RunCheck("b")
+		Expect(state.Status).To(Equal("ok"))
+		Expect(state.IncidentID).To(Equal(incidentID))
+		Expect(checkerB.StatusCallCount()).To(Equal(calls + 1))
+
+		// the incident of b has been resolved
+		incident, ok := h.Incident(incidentID)
+		Expect(ok).To(BeTrue())
+		Expect(incident.Open()).To(BeFalse())
+	})
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+// DeployPhase is the phase of a rollout the instance is in (see
+// "h.SetDeployPhase()").
+type DeployPhase string
+
+const (
+	// DeployPhaseStable is the default phase: the instance is not part of a
+	// rollout
+	DeployPhaseStable DeployPhase = ""
+
+	// DeployPhaseCanary marks the instance as a canary of a new version
+	DeployPhaseCanary DeployPhase = "canary"
+
+	// DeployPhaseDraining marks the instance as being drained ahead of its
+	// replacement
+	DeployPhaseDraining DeployPhase = "draining"
+)
+
+// Deploy describes the active deploy phase (see "h.SetDeployPhase()").
+type Deploy struct {
+	// Phase is either "canary" or "draining"
+	Phase DeployPhase `json:"phase"`
+
+	// Since is the time the instance has entered the phase
+	Since time.Time `json:"since"`
+}
+
+// SetDeployPhase marks the instance as a canary or as draining
+// ("DeployPhaseStable" resets it), ie. from the rollout tooling through
+// "handlers.NewDeployHandlerFunc()". The bundled handlers annotate their
+// output with the phase (see "h.Deploy()"), and the failures of the checks
+// that set "Config.DowngradeDuringDeploy" are reported as degraded instead of
+// failing the overall status until the instance is stable again. Downgraded
+// failures neither open incidents nor notify the status (and state) listeners;
+// a check that is still failing is reported on its first execution once the
+// instance is stable again.
+func (h *Health) SetDeployPhase(phase DeployPhase) error {
+	switch phase {
+	case DeployPhaseStable, DeployPhaseCanary, DeployPhaseDraining:
+	default:
+		return fmt.Errorf("Unknown deploy phase %q", phase)
+	}
+
+	h.statesLock.Lock()
+	defer h.statesLock.Unlock()
+
+	prevStatus := h.overallStatus()
+
+	if phase == DeployPhaseStable {
+		h.deploy.Store((*Deploy)(nil))
+	} else if prev, ok := h.Deploy(); !ok || prev.Phase != phase {
+		h.deploy.Store(&Deploy{Phase: phase, Since: h.clock().Now()})
+	}
+
+	// re-evaluate the failures that are already recorded
+	for _, state := range h.states {
+		if downgraded := h.isDowngraded(&state); downgraded != state.Downgraded {
+			state.Downgraded = downgraded
+			h.putState(state)
+		}
+	}
+
+	// cached responses must be re-rendered
+	h.invalidateSnapshot()
+
+	if status := h.overallStatus(); status != prevStatus {
+		h.dispatchStateEvent("overall_state_change", func(l IStateListener) { l.OnOverallStateChange(prevStatus, status) })
+	}
+
+	return nil
+}
+
+// Deploy returns the active deploy phase; false if the instance is stable.
+func (h *Health) Deploy() (Deploy, bool) {
+	d, _ := h.deploy.Load().(*Deploy)
+	if d == nil {
+		return Deploy{}, false
+	}
+
+	return *d, true
+}
+
+// indicates whether the failure of the state is downgraded by the active
+// deploy phase
+func (h *Health) isDowngraded(state *State) bool {
+	if !state.downgradable || !state.isFailure() {
+		return false
+	}
+
+	_, ok := h.Deploy()
+
+	return ok
+}
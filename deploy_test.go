@@ -0,0 +1,181 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestSetDeployPhase(t *testing.T) {
+	RegisterTestingT(t)
+
+	failing := func() *fakes.FakeICheckable {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("connection refused"))
+		return checker
+	}
+
+	t.Run("Should downgrade the recorded failures of the opted-in checks", func(t *testing.T) {
+		events := make(chan string, 100)
+
+		h := setupNewTestHealth()
+		h.StateListeners = []IStateListener{&StateListenerFuncs{
+			OverallStateChange: func(prevStatus, status string) {
+				events <- fmt.Sprintf("overall %v -> %v", prevStatus, status)
+			},
+		}}
+		h.WaitOnStart = true
+		h.AddChecks([]*Config{
+			{Name: "db", Checker: failing(), Interval: testCheckInterval, Fatal: true, DowngradeDuringDeploy: true},
+			{Name: "api", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval, Fatal: true},
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Failed()).To(BeTrue())
+
+		_, ok := h.Deploy()
+		Expect(ok).To(BeFalse())
+
+		version := h.StateVersion()
+		Expect(h.SetDeployPhase(DeployPhaseCanary)).To(Succeed())
+
+		d, ok := h.Deploy()
+		Expect(ok).To(BeTrue())
+		Expect(d.Phase).To(Equal(DeployPhaseCanary))
+		Expect(d.Since).ToNot(BeZero())
+
+		Expect(h.Failed()).To(BeFalse())
+		Expect(h.StateVersion()).ToNot(Equal(version))
+
+		states, failed, err := h.State()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed).To(BeFalse())
+		Expect(states["db"].Status).To(Equal("failed"))
+		Expect(states["db"].Downgraded).To(BeTrue())
+		Expect(states["api"].Downgraded).To(BeFalse())
+
+		Eventually(events).Should(Receive(Equal("overall failed -> degraded")))
+
+		// new executions of the check stay downgraded
+		Consistently(h.Failed, 3*testCheckInterval).Should(BeFalse())
+
+		// moving on to draining keeps the phase annotated
+		Expect(h.SetDeployPhase(DeployPhaseDraining)).To(Succeed())
+
+		d, ok = h.Deploy()
+		Expect(ok).To(BeTrue())
+		Expect(d.Phase).To(Equal(DeployPhaseDraining))
+		Expect(h.Failed()).To(BeFalse())
+
+		Expect(h.SetDeployPhase(DeployPhaseStable)).To(Succeed())
+
+		_, ok = h.Deploy()
+		Expect(ok).To(BeFalse())
+		Expect(h.Failed()).To(BeTrue())
+
+		states, _, _ = h.State()
+		Expect(states["db"].Downgraded).To(BeFalse())
+
+		Eventually(events).Should(Receive(Equal("overall degraded -> failed")))
+	})
+
+	t.Run("Should downgrade the failures recorded during a deploy", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "db", Checker: failing(), Interval: testCheckInterval, Fatal: true, Tags: []string{"storage"},
+			DowngradeDuringDeploy: true})
+
+		Expect(h.SetDeployPhase(DeployPhaseDraining)).To(Succeed())
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Failed()).To(BeFalse())
+
+		Expect(h.Groups()["storage"].Status).To(Equal("degraded"))
+
+		states, _, _ := h.State()
+		Expect(states["db"].Downgraded).To(BeTrue())
+	})
+
+	t.Run("Should not report the failures recorded during a deploy", func(t *testing.T) {
+		var healthy int32
+		checker := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			if atomic.LoadInt32(&healthy) == 1 {
+				return nil, nil
+			}
+
+			return nil, errors.New("connection refused")
+		})
+
+		var failed, recovered int32
+
+		h := setupNewTestHealth()
+		h.StateListeners = []IStateListener{&StateListenerFuncs{
+			CheckFailed:    func(state *State) { atomic.AddInt32(&failed, 1) },
+			CheckRecovered: func(state *State, recordedFailures int64, failureDurationSeconds float64) { atomic.AddInt32(&recovered, 1) },
+		}}
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "db", Checker: checker, Interval: testCheckInterval, Fatal: true, DowngradeDuringDeploy: true})
+
+		Expect(h.SetDeployPhase(DeployPhaseCanary)).To(Succeed())
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Consistently(h.Failed, 3*testCheckInterval).Should(BeFalse())
+
+		states, _, _ := h.State()
+		Expect(states["db"].Downgraded).To(BeTrue())
+		Expect(states["db"].IncidentID).To(BeEmpty())
+		Expect(h.Incidents()).To(BeEmpty())
+		Expect(atomic.LoadInt32(&failed)).To(BeZero())
+
+		// recovering from a downgraded failure is not a recovery either
+		atomic.StoreInt32(&healthy, 1)
+		Eventually(func() string { s, _ := h.lookupState("db"); return s.Status }).Should(Equal("ok"))
+		Consistently(func() int32 { return atomic.LoadInt32(&recovered) }, 3*testCheckInterval).Should(BeZero())
+
+		// a failure is reported once the instance is stable again
+		atomic.StoreInt32(&healthy, 0)
+		Eventually(func() string { s, _ := h.lookupState("db"); return s.Status }).Should(Equal("failed"))
+
+		Expect(h.SetDeployPhase(DeployPhaseStable)).To(Succeed())
+
+		Eventually(func() int32 { return atomic.LoadInt32(&failed) }).Should(Equal(int32(1)))
+		Expect(h.Incidents()).To(HaveLen(1))
+	})
+
+	t.Run("Should not downgrade the checks that did not opt in", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "db", Checker: failing(), Interval: testCheckInterval, Fatal: true})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.SetDeployPhase(DeployPhaseCanary)).To(Succeed())
+		Expect(h.Failed()).To(BeTrue())
+
+		states, _, _ := h.State()
+		Expect(states["db"].Downgraded).To(BeFalse())
+	})
+
+	t.Run("Should reject unknown phases", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		err := h.SetDeployPhase("rolling")
+		Expect(err).To(MatchError(`Unknown deploy phase "rolling"`))
+
+		_, ok := h.Deploy()
+		Expect(ok).To(BeFalse())
+	})
+}
@@ -0,0 +1,82 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/InVisionApp/go-health/describe"
+)
+
+// Description describes what a check actually does; refer to
+// "describe.Description".
+type Description = describe.Description
+
+// ICheckableDescriber can optionally be implemented by checkers to describe
+// what they do; all bundled checkers implement it.
+type ICheckableDescriber interface {
+	ICheckable
+
+	// Describe returns the type, target and capabilities of the checker; the
+	// name and expected interval are filled in from the config of the check
+	Describe() Description
+}
+
+// Describe returns the description of the check with the given name.
+func (h *Health) Describe(name string) (Description, error) {
+	h.configsLock.Lock()
+	defer h.configsLock.Unlock()
+
+	for _, c := range h.configs {
+		if c.Name == name {
+			return h.describe(c), nil
+		}
+	}
+
+	return Description{}, ErrCheckNotFound
+}
+
+// Descriptions returns the descriptions of all checks, sorted by name.
+func (h *Health) Descriptions() []Description {
+	h.configsLock.Lock()
+	defer h.configsLock.Unlock()
+
+	descs := make([]Description, 0, len(h.configs))
+	for _, c := range h.configs {
+		descs = append(descs, h.describe(c))
+	}
+
+	sort.Slice(descs, func(i, j int) bool {
+		return descs[i].Name < descs[j].Name
+	})
+
+	return descs
+}
+
+// returns the description of the check; the caller must hold "configsLock"
+func (h *Health) describe(cfg *Config) Description {
+	var desc Description
+	if d, ok := cfg.Checker.(ICheckableDescriber); ok {
+		desc = d.Describe()
+	}
+
+	desc.Name = cfg.Name
+
+	if desc.Type == "" {
+		desc.Type = fmt.Sprintf("%T", cfg.Checker)
+	}
+
+	if cfg.Schedule == "" && cfg.CacheTTL <= 0 {
+		timing := h.resolveTiming(cfg)
+
+		// the timing of a running check is resolved when the check is started
+		h.runnersLock.Lock()
+		if r, ok := h.runners[cfg.Name]; ok {
+			timing = r.timing
+		}
+		h.runnersLock.Unlock()
+
+		desc.ExpectedInterval = timing.Interval
+	}
+
+	return desc
+}
@@ -0,0 +1,30 @@
+// Package describe contains the description of a check, shared by the health
+// package and the bundled checkers (which cannot import the health package).
+package describe
+
+import (
+	"time"
+)
+
+// Description describes what a check actually does (as opposed to only its
+// registered name), ie. for admin endpoints, config dumps and dashboards.
+type Description struct {
+	// Name of the check ("health.Config.Name")
+	Name string `json:"name"`
+
+	// Type of the checker, ie. "http", "redis" or "mongo"; defaults to the Go
+	// type of checkers that do not implement "health.ICheckableDescriber"
+	Type string `json:"type"`
+
+	// Target is the (redacted) address of the checked dependency, ie. an URL
+	// or a "host:port"; empty if the check has no single target
+	Target string `json:"target,omitempty"`
+
+	// Capabilities lists what the check verifies, ie. "ping", "set" and "get"
+	Capabilities []string `json:"capabilities,omitempty"`
+
+	// ExpectedInterval is the effective interval of the check (see
+	// "health.Timing"); zero for scheduled ("health.Config.Schedule") and
+	// lazy ("health.Config.CacheTTL") checks, which do not run on an interval
+	ExpectedInterval time.Duration `json:"expected_interval,omitempty"`
+}
@@ -0,0 +1,82 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type describedChecker struct {
+	fakes.FakeICheckable
+}
+
+func (d *describedChecker) Describe() Description {
+	return Description{
+		Name:         "ignored",
+		Type:         "fake",
+		Target:       "localhost:1234",
+		Capabilities: []string{"ping"},
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should describe the checks", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.DefaultInterval = time.Hour
+		h.AddChecks([]*Config{
+			{Name: "foo", Checker: &describedChecker{}, Interval: time.Minute},
+			{Name: "bar", Checker: &fakes.FakeICheckable{}},
+			{Name: "lazy", Checker: &fakes.FakeICheckable{}, CacheTTL: time.Minute},
+			{Name: "scheduled", Checker: &fakes.FakeICheckable{}, Schedule: "@daily"},
+		})
+
+		desc, err := h.Describe("foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc).To(Equal(Description{
+			Name:             "foo",
+			Type:             "fake",
+			Target:           "localhost:1234",
+			Capabilities:     []string{"ping"},
+			ExpectedInterval: time.Minute,
+		}))
+
+		descs := h.Descriptions()
+		Expect(descs).To(HaveLen(4))
+		Expect(descs[0]).To(Equal(Description{
+			Name:             "bar",
+			Type:             "*fakes.FakeICheckable",
+			ExpectedInterval: time.Hour,
+		}))
+		Expect(descs[1].Name).To(Equal("foo"))
+		Expect(descs[2].Name).To(Equal("lazy"))
+		Expect(descs[2].ExpectedInterval).To(BeZero())
+		Expect(descs[3].Name).To(Equal("scheduled"))
+		Expect(descs[3].ExpectedInterval).To(BeZero())
+	})
+
+	t.Run("Should use the timing of running checks", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Minute, InitialDelay: time.Minute})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		h.DefaultInterval = time.Hour
+
+		desc, err := h.Describe("foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc.ExpectedInterval).To(Equal(time.Minute))
+	})
+
+	t.Run("Should error for unknown checks", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		_, err := h.Describe("foo")
+		Expect(err).To(Equal(ErrCheckNotFound))
+	})
+}
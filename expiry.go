@@ -0,0 +1,146 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// the minimum period of the sweeper that expires the states
+	minExpiryPeriod = 10 * time.Millisecond
+)
+
+// IExpiryHook can optionally be implemented by result hooks
+// ("Health.ResultHooks") to be notified when the state of a check expires
+// (see "Health.StateTTL").
+type IExpiryHook interface {
+	// CheckExpired is called (in its own goroutine) when the state of a check
+	// has expired.
+	// 	* state - The expired state of the check
+	CheckExpired(state *State)
+}
+
+// indicates the state has expired (see "Health.StateTTL")
+func (s *State) isExpired() bool {
+	return s.Status == "expired"
+}
+
+// starts the sweeper that expires the states (unless "h.StateTTL" is unset);
+// the caller must hold "configsLock"
+func (h *Health) startExpiry() {
+	if h.StateTTL <= 0 {
+		return
+	}
+
+	period := h.StateTTL / 4
+	if period < minExpiryPeriod {
+		period = minExpiryPeriod
+	}
+
+	ticker := h.clock().NewTicker(period)
+	done := make(chan struct{})
+	h.expiryDone = done
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C():
+				h.expireStates(now)
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// stops the sweeper; the caller must hold "configsLock"
+func (h *Health) stopExpiry() {
+	if h.expiryDone != nil {
+		close(h.expiryDone)
+		h.expiryDone = nil
+	}
+}
+
+// marks the states of the running checks that have not reported for
+// "h.StateTTL" as expired, and deletes the states of removed checks once
+// "h.StateTTL" has elapsed since their removal
+func (h *Health) expireStates(now time.Time) {
+	// lazy checks only report on demand and passed startup checks no longer
+	// report, so neither are expired
+	scheduled := make(map[string]bool)
+
+	h.runnersLock.Lock()
+	for name, r := range h.runners {
+		scheduled[name] = r.refresh == nil && !r.hasPassed()
+	}
+	h.runnersLock.Unlock()
+
+	h.statesLock.Lock()
+
+	prevStatus := h.overallStatus()
+	expired := make([]State, 0)
+
+	for name, state := range h.states {
+		if removedAt, ok := h.removed[name]; ok {
+			if now.Sub(removedAt) >= h.StateTTL {
+				h.deleteState(name)
+				delete(h.removed, name)
+			}
+
+			continue
+		}
+
+		if !scheduled[name] || state.isExpired() || state.isPaused() || now.Sub(state.CheckTime) < h.StateTTL {
+			continue
+		}
+
+		expired = append(expired, h.expireState(state, now, fmt.Sprintf("No result for %v", h.StateTTL)))
+	}
+
+	if status := h.overallStatus(); status != prevStatus {
+		h.dispatchStateEvent("overall_state_change", func(l IStateListener) { l.OnOverallStateChange(prevStatus, status) })
+	}
+
+	h.statesLock.Unlock()
+
+	for i := range expired {
+		h.handleExpiryHooks(&expired[i])
+	}
+}
+
+// stores the state as expired and publishes the change; returns the expired
+// state. The caller must hold "statesLock".
+func (h *Health) expireState(prevState State, now time.Time, reason string) State {
+	// the old state is published, so it must not share its details with the
+	// recorded state
+	oldState := prevState.copy()
+
+	state := prevState
+	state.Status = "expired"
+	state.Err = reason
+	state.ChangedAt = now
+
+	h.putState(state)
+
+	h.publishStateChange(StateChange{
+		Name:      state.Name,
+		OldState:  &oldState,
+		NewState:  state,
+		Err:       state.Err,
+		Timestamp: now,
+	})
+
+	return state
+}
+
+// notifies the result hooks that implement "IExpiryHook"
+func (h *Health) handleExpiryHooks(stateEntry *State) {
+	for _, hook := range h.ResultHooks {
+		if expiryHook, ok := hook.(IExpiryHook); ok {
+			state := *stateEntry
+			go expiryHook.CheckExpired(&state)
+		}
+	}
+}
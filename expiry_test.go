@@ -0,0 +1,148 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type testExpiryHook struct {
+	expired chan *State
+}
+
+func (h *testExpiryHook) CheckCompleted(state *State) {}
+
+func (h *testExpiryHook) CheckExpired(state *State) {
+	h.expired <- state
+}
+
+func TestStateTTL(t *testing.T) {
+	RegisterTestingT(t)
+
+	status := func(h *Health, name string) func() string {
+		return func() string {
+			state, _ := h.lookupState(name)
+			return state.Status
+		}
+	}
+
+	setup := func(cfgs ...*Config) (*Health, *FakeClock, *testExpiryHook) {
+		hook := &testExpiryHook{expired: make(chan *State, 10)}
+		clock := NewFakeClock(time.Now())
+
+		h := setupNewTestHealth()
+		h.Clock = clock
+		h.StateTTL = time.Minute
+		h.ResultHooks = []IResultHook{hook}
+		h.WaitOnStart = true
+		h.AddChecks(cfgs)
+
+		Expect(h.Start()).To(Succeed())
+
+		return h, clock, hook
+	}
+
+	t.Run("Should expire the state of a check that has not reported for the TTL", func(t *testing.T) {
+		h, clock, hook := setup(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Hour})
+		defer h.Stop()
+
+		changes, cancel := h.Subscribe()
+		defer cancel()
+
+		clock.Advance(59 * time.Second)
+		Expect(status(h, "foo")()).To(Equal("ok"))
+
+		// the ticks are handled asynchronously
+		clock.Advance(time.Second)
+		Eventually(status(h, "foo")).Should(Equal("expired"))
+
+		var expired *State
+		Eventually(hook.expired).Should(Receive(&expired))
+		Expect(expired.Err).To(Equal("No result for 1m0s"))
+
+		var change StateChange
+		Expect(changes).To(Receive(&change))
+		Expect(change.OldState.Status).To(Equal("ok"))
+		Expect(change.NewState.Status).To(Equal("expired"))
+
+		// a fresh result replaces the expired state
+		clock.Advance(time.Hour)
+		Eventually(status(h, "foo")).Should(Equal("ok"))
+	})
+
+	t.Run("Should not share the details of the old state with the expired state", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(map[string]interface{}{"foo": "bar"}, nil)
+
+		h, clock, _ := setup(&Config{Name: "foo", Checker: checker, Interval: time.Hour})
+		defer h.Stop()
+
+		changes, cancel := h.Subscribe()
+		defer cancel()
+
+		clock.Advance(time.Minute)
+		Eventually(status(h, "foo")).Should(Equal("expired"))
+
+		var change StateChange
+		Expect(changes).To(Receive(&change))
+
+		change.OldState.Details.(map[string]interface{})["foo"] = "baz"
+
+		state, _ := h.lookupState("foo")
+		Expect(state.Details).To(Equal(map[string]interface{}{"foo": "bar"}))
+		Expect(change.NewState.Details).To(Equal(map[string]interface{}{"foo": "bar"}))
+	})
+
+	t.Run("Should keep the state of a removed check as expired until the TTL has elapsed", func(t *testing.T) {
+		h, clock, hook := setup(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Hour})
+		defer h.Stop()
+
+		Expect(h.RemoveCheck("foo")).To(Succeed())
+
+		state, ok := h.lookupState("foo")
+		Expect(ok).To(BeTrue())
+		Expect(state.Status).To(Equal("expired"))
+		Expect(state.Err).To(Equal("Check has been removed"))
+		Eventually(hook.expired).Should(Receive())
+
+		clock.Advance(time.Minute)
+
+		Eventually(func() bool {
+			_, ok := h.lookupState("foo")
+			return ok
+		}).Should(BeFalse())
+	})
+
+	t.Run("Should not expire lazy or paused checks", func(t *testing.T) {
+		h, clock, _ := setup(
+			&Config{Name: "lazy", Checker: &fakes.FakeICheckable{}, CacheTTL: time.Second},
+			&Config{Name: "paused", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+		)
+		defer h.Stop()
+
+		h.refreshLazyCheck("lazy")
+		Expect(h.PauseCheck("paused")).To(Succeed())
+
+		clock.Advance(2 * time.Minute)
+
+		Consistently(status(h, "lazy"), 50*time.Millisecond).Should(Equal("ok"))
+		Expect(status(h, "paused")()).To(Equal("paused"))
+	})
+
+	t.Run("Should not expire anything without a TTL", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.RemoveCheck("foo")).To(Succeed())
+
+		_, ok := h.lookupState("foo")
+		Expect(ok).To(BeFalse())
+	})
+}
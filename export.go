@@ -0,0 +1,167 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ExportVersion is the version of the format produced by "ExportConfig()"
+const ExportVersion = 1
+
+// ExportedConfig is a portable, driver-agnostic description of the checks
+// registered with a health instance (see "ExportConfig()"), ie. for fleet
+// inventory and drift detection. It does not contain the checkers themselves
+// and thus cannot be used to register checks.
+type ExportedConfig struct {
+	Version int             `json:"version"`
+	Checks  []ExportedCheck `json:"checks"`
+}
+
+// ExportedCheck is the portable description of a single check; the target is
+// redacted by the checker (see "ICheckableDescriber").
+type ExportedCheck struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Target       string   `json:"target,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+
+	// Interval and Timeout are the effective ones (see "Timing"); the interval
+	// is zero for scheduled and lazy checks
+	Interval time.Duration `json:"interval,omitempty"`
+	Timeout  time.Duration `json:"timeout,omitempty"`
+	Schedule string        `json:"schedule,omitempty"`
+	CacheTTL time.Duration `json:"cache_ttl,omitempty"`
+
+	Fatal            bool     `json:"fatal,omitempty"`
+	Critical         bool     `json:"critical,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	DependsOn        []string `json:"depends_on,omitempty"`
+	FailureThreshold int      `json:"failure_threshold,omitempty"`
+	SuccessThreshold int      `json:"success_threshold,omitempty"`
+	Startup          bool     `json:"startup,omitempty"`
+	Chained          bool     `json:"chained,omitempty"`
+	Weight           float64  `json:"weight,omitempty"`
+}
+
+// ExportConfig returns the JSON description of the registered checks, sorted
+// by name (see "ExportedConfig").
+func (h *Health) ExportConfig() ([]byte, error) {
+	h.configsLock.Lock()
+
+	exported := &ExportedConfig{
+		Version: ExportVersion,
+		Checks:  make([]ExportedCheck, 0, len(h.configs)),
+	}
+
+	for _, c := range h.configs {
+		exported.Checks = append(exported.Checks, h.export(c))
+	}
+
+	h.configsLock.Unlock()
+
+	sort.Slice(exported.Checks, func(i, j int) bool {
+		return exported.Checks[i].Name < exported.Checks[j].Name
+	})
+
+	data, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to marshal config: %v", err)
+	}
+
+	return data, nil
+}
+
+// returns the portable description of the check; the caller must hold
+// "configsLock"
+func (h *Health) export(cfg *Config) ExportedCheck {
+	desc := h.describe(cfg)
+
+	return ExportedCheck{
+		Name:             desc.Name,
+		Type:             desc.Type,
+		Target:           desc.Target,
+		Capabilities:     desc.Capabilities,
+		Interval:         desc.ExpectedInterval,
+		Timeout:          h.resolveTiming(cfg).Timeout,
+		Schedule:         cfg.Schedule,
+		CacheTTL:         cfg.CacheTTL,
+		Fatal:            cfg.Fatal,
+		Critical:         cfg.Critical,
+		Tags:             cfg.Tags,
+		DependsOn:        cfg.DependsOn,
+		FailureThreshold: cfg.FailureThreshold,
+		SuccessThreshold: cfg.SuccessThreshold,
+		Startup:          cfg.Startup,
+		Chained:          cfg.Chained,
+		Weight:           cfg.Weight,
+	}
+}
+
+// ImportConfig parses a config produced by "ExportConfig()", ie. of another
+// instance of the fleet, so that it can be compared (see
+// "ExportedConfig.Diff()").
+func ImportConfig(data []byte) (*ExportedConfig, error) {
+	imported := &ExportedConfig{}
+	if err := json.Unmarshal(data, imported); err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal config: %v", err)
+	}
+
+	if imported.Version != ExportVersion {
+		return nil, fmt.Errorf("Unsupported config version %v", imported.Version)
+	}
+
+	names := make(map[string]bool, len(imported.Checks))
+	for _, c := range imported.Checks {
+		if c.Name == "" {
+			return nil, errors.New("Config contains a check without a name")
+		}
+
+		if names[c.Name] {
+			return nil, fmt.Errorf("Config contains duplicate check '%v'", c.Name)
+		}
+
+		names[c.Name] = true
+	}
+
+	return imported, nil
+}
+
+// Diff returns the (sorted) names of the checks that have been added, removed
+// or changed in the other config.
+func (c *ExportedConfig) Diff(other *ExportedConfig) []string {
+	checks := make(map[string]ExportedCheck, len(c.Checks))
+	for _, check := range c.Checks {
+		checks[check.Name] = check
+	}
+
+	drifted := make([]string, 0)
+
+	for _, check := range other.Checks {
+		existing, ok := checks[check.Name]
+		if !ok || !existing.equal(check) {
+			drifted = append(drifted, check.Name)
+		}
+
+		delete(checks, check.Name)
+	}
+
+	for name := range checks {
+		drifted = append(drifted, name)
+	}
+
+	sort.Strings(drifted)
+
+	return drifted
+}
+
+// compares the JSON representations, so that ie. nil and empty tags are equal
+func (c ExportedCheck) equal(other ExportedCheck) bool {
+	a, errA := json.Marshal(c)
+	b, errB := json.Marshal(other)
+
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestExportConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should export the checks", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.DefaultInterval = time.Hour
+		h.AddChecks([]*Config{
+			{Name: "foo", Checker: &describedChecker{}, Interval: time.Minute, Timeout: time.Second, Fatal: true, Tags: []string{"storage"}},
+			{Name: "bar", Checker: &fakes.FakeICheckable{}, Schedule: "@daily", DependsOn: []string{"foo"}},
+		})
+
+		data, err := h.ExportConfig()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"version": 1,
+			"checks": [
+				{"name": "bar", "type": "*fakes.FakeICheckable", "schedule": "@daily", "depends_on": ["foo"]},
+				{
+					"name": "foo", "type": "fake", "target": "localhost:1234", "capabilities": ["ping"],
+					"interval": 60000000000, "timeout": 1000000000, "fatal": true, "tags": ["storage"]
+				}
+			]
+		}`))
+
+		imported, err := ImportConfig(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(imported.Checks).To(HaveLen(2))
+		Expect(imported.Checks[1].Interval).To(Equal(time.Minute))
+	})
+
+	t.Run("Should not import invalid configs", func(t *testing.T) {
+		_, err := ImportConfig([]byte(`{`))
+		Expect(err).To(HaveOccurred())
+
+		_, err = ImportConfig([]byte(`{"version": 2}`))
+		Expect(err).To(MatchError("Unsupported config version 2"))
+
+		_, err = ImportConfig([]byte(`{"version": 1, "checks": [{"type": "redis"}]}`))
+		Expect(err).To(MatchError("Config contains a check without a name"))
+
+		_, err = ImportConfig([]byte(`{"version": 1, "checks": [{"name": "redis"}, {"name": "redis"}]}`))
+		Expect(err).To(MatchError("Config contains duplicate check 'redis'"))
+	})
+
+	t.Run("Should detect drift", func(t *testing.T) {
+		a := &ExportedConfig{Version: 1, Checks: []ExportedCheck{
+			{Name: "mongo", Type: "mongo", Interval: time.Minute},
+			{Name: "redis", Type: "redis", Tags: []string{}},
+			{Name: "sql", Type: "sql"},
+		}}
+		b := &ExportedConfig{Version: 1, Checks: []ExportedCheck{
+			{Name: "http", Type: "http"},
+			{Name: "mongo", Type: "mongo", Interval: time.Hour},
+			{Name: "redis", Type: "redis"},
+		}}
+
+		Expect(a.Diff(b)).To(Equal([]string{"http", "mongo", "sql"}))
+		Expect(a.Diff(a)).To(BeEmpty())
+	})
+}
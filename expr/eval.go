@@ -0,0 +1,311 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+type node interface {
+	eval(env map[string]interface{}) (interface{}, error)
+	identifiers(seen map[string]bool)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(env map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *literalNode) identifiers(seen map[string]bool) {}
+
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(env map[string]interface{}) (interface{}, error) {
+	v, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown identifier '%v'", n.name)
+	}
+
+	return normalize(v), nil
+}
+
+func (n *identNode) identifiers(seen map[string]bool) {
+	seen[n.name] = true
+}
+
+type memberNode struct {
+	object node
+	key    node
+}
+
+func (n *memberNode) eval(env map[string]interface{}) (interface{}, error) {
+	object, err := n.object.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := n.key.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// lists are indexed by number
+	if list, ok := object.([]interface{}); ok {
+		i, ok := key.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, fmt.Errorf("Unable to index a list by %v", typeName(key))
+		}
+
+		if i < 0 || int(i) >= len(list) {
+			return nil, fmt.Errorf("Index %v out of range (length %d)", i, len(list))
+		}
+
+		return normalize(list[int(i)]), nil
+	}
+
+	name, ok := key.(string)
+	if !ok {
+		return nil, fmt.Errorf("Unable to access a field by %v", typeName(key))
+	}
+
+	m, ok := object.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Unable to access field '%v' of %v", name, typeName(object))
+	}
+
+	v, ok := m[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown field '%v'", name)
+	}
+
+	return normalize(v), nil
+}
+
+func (n *memberNode) identifiers(seen map[string]bool) {
+	n.object.identifiers(seen)
+	n.key.identifiers(seen)
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "!":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("Operator '!' requires a bool, got %v", typeName(v))
+		}
+
+		return !b, nil
+	default:
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("Operator '-' requires a number, got %v", typeName(v))
+		}
+
+		return -f, nil
+	}
+}
+
+func (n *unaryNode) identifiers(seen map[string]bool) {
+	n.operand.identifiers(seen)
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// the logical operators short-circuit
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("Operator '%v' requires bools, got %v", n.op, typeName(left))
+		}
+
+		if l == (n.op == "||") {
+			return l, nil
+		}
+
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("Operator '%v' requires bools, got %v", n.op, typeName(right))
+		}
+
+		return r, nil
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	if n.op == "+" {
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		}
+	}
+
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			switch n.op {
+			case "<":
+				return l < r, nil
+			case "<=":
+				return l <= r, nil
+			case ">":
+				return l > r, nil
+			case ">=":
+				return l >= r, nil
+			}
+		}
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("Operator '%v' requires numbers, got %v and %v", n.op, typeName(left), typeName(right))
+	}
+
+	switch n.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	}
+
+	if r == 0 {
+		return nil, fmt.Errorf("Division by zero")
+	}
+
+	if n.op == "%" {
+		return math.Mod(l, r), nil
+	}
+
+	return l / r, nil
+}
+
+func (n *binaryNode) identifiers(seen map[string]bool) {
+	n.left.identifiers(seen)
+	n.right.identifiers(seen)
+}
+
+// converts the value of the environment to the types of the language: all
+// numbers become float64 (durations are converted to seconds), maps with
+// string keys become "map[string]interface{}", slices become "[]interface{}"
+// and functions are called
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil, bool, string, float64, map[string]interface{}, []interface{}:
+		return v
+	case func() interface{}:
+		return normalize(t())
+	case time.Duration:
+		return t.Seconds()
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String:
+		return rv.String()
+	case reflect.Func:
+		if rv.Type().NumIn() == 0 && rv.Type().NumOut() == 1 {
+			return normalize(rv.Call(nil)[0].Interface())
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			m := make(map[string]interface{}, rv.Len())
+			for _, k := range rv.MapKeys() {
+				m[k.String()] = rv.MapIndex(k).Interface()
+			}
+
+			return m
+		}
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = rv.Index(i).Interface()
+		}
+
+		return list
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+	}
+
+	return v
+}
+
+// returns the name of the type of the value in the language
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "map"
+	case []interface{}:
+		return "list"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,101 @@
+// Package expr contains a small expression language, ie. for computed checks
+// over the states of other checks (`db.ok && (cache.ok || degraded_mode)`) or
+// conditions over the details of a check (`details.lag_seconds < 30`).
+//
+// Expressions support number, string (double or single quoted), boolean and
+// nil literals, identifiers, member access (`a.b` or `a["b-c"]`), indexing of
+// lists (`a[0]`), parentheses
+// and the following operators, from the lowest to the highest precedence:
+//
+//	||
+//	&&
+//	==  !=
+//	<  <=  >  >=
+//	+  -
+//	*  /  %
+//	!  - (unary)
+//
+// "&&" and "||" short-circuit and require booleans; "+" also concatenates
+// strings. All numbers are evaluated as float64.
+package expr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Expr is a compiled expression; it is safe for concurrent use.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses the expression.
+func Compile(src string) (*Expr, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse expression: %v", err)
+	}
+
+	root, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse expression: %v", err)
+	}
+
+	return &Expr{src: src, root: root}, nil
+}
+
+// MustCompile is like "Compile()", but panics if the expression cannot be
+// parsed.
+func MustCompile(src string) *Expr {
+	e, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression against the environment. Values of the
+// environment may be nested maps (with string keys), slices, numbers, strings, bools,
+// nil or functions without arguments that return one of those (ie.
+// "func() bool" for feature flags), which are called on every evaluation.
+func (e *Expr) Eval(env map[string]interface{}) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+// EvalBool evaluates the expression, which must result in a bool.
+func (e *Expr) EvalBool(env map[string]interface{}) (bool, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("Expression '%v' does not result in a bool, but %v", e.src, typeName(v))
+	}
+
+	return b, nil
+}
+
+// Identifiers returns the (sorted) root identifiers that are referenced by the
+// expression, ie. "db" and "cache" for `db.ok && cache.ok`.
+func (e *Expr) Identifiers() []string {
+	seen := make(map[string]bool)
+	e.root.identifiers(seen)
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
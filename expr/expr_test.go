@@ -0,0 +1,119 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCompile(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should compile valid expressions", func(t *testing.T) {
+		e, err := Compile(`db.ok && (cache.ok || !degraded_mode) && checks["redis-cache"].latency.p95 <= 0.5`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.String()).To(Equal(`db.ok && (cache.ok || !degraded_mode) && checks["redis-cache"].latency.p95 <= 0.5`))
+		Expect(e.Identifiers()).To(Equal([]string{"cache", "checks", "db", "degraded_mode"}))
+	})
+
+	t.Run("Should error on invalid expressions", func(t *testing.T) {
+		for src, msg := range map[string]string{
+			"":                 "unexpected end of expression",
+			"db.ok &&":         "unexpected end of expression",
+			"(db.ok":           "expected ')', got end of expression",
+			"db.ok)":           "unexpected ')' at 5",
+			"db.":              "expected a field name",
+			`db.status == "ok`: "unterminated string at 13",
+			"db.ok # cache":    "unexpected character '#' at 6",
+			"1.2.3 > 1":        "invalid number '1.2.3' at 0",
+		} {
+			_, err := Compile(src)
+			Expect(err).To(MatchError(ContainSubstring(msg)), src)
+			Expect(err.Error()).To(HavePrefix("Unable to parse expression"))
+		}
+	})
+
+	t.Run("MustCompile should panic on invalid expressions", func(t *testing.T) {
+		Expect(func() { MustCompile("&&") }).To(Panic())
+		Expect(MustCompile("true")).ToNot(BeNil())
+	})
+}
+
+func TestEval(t *testing.T) {
+	RegisterTestingT(t)
+
+	env := map[string]interface{}{
+		"db": map[string]interface{}{
+			"ok":       true,
+			"status":   "ok",
+			"duration": 250 * time.Millisecond,
+			"details":  map[string]string{"region": "eu-west-1"},
+			"replicas": []map[string]interface{}{{"lag": 3}, {"lag": 45}},
+		},
+		"cache":   map[string]interface{}{"ok": false, "consecutive_errors": int64(3)},
+		"flag":    func() bool { return true },
+		"lazy":    func() interface{} { return 42 },
+		"missing": nil,
+	}
+
+	t.Run("Should evaluate expressions", func(t *testing.T) {
+		for src, expected := range map[string]interface{}{
+			`db.ok && (cache.ok || flag)`:                true,
+			`db.ok && cache.ok`:                          false,
+			`!cache.ok`:                                  true,
+			`db.status == "ok" && db.status != 'failed'`: true,
+			`db.duration < 0.3`:                          true,
+			`cache.consecutive_errors >= 3`:              true,
+			`db["details"].region == "eu-west-1"`:        true,
+			`db.replicas[1].lag > db.replicas[0].lag`:    true,
+			`lazy + 1`:       float64(43),
+			`-lazy * 2 / 4`:  float64(-21),
+			`lazy % 5`:       float64(2),
+			`1 + 2 * 3 == 7`: true,
+			`"eu" + "-west"`: "eu-west",
+			`"a" < "b"`:      true,
+			`missing == nil`: true,
+			`db.ok == 1`:     false,
+		} {
+			v, err := MustCompile(src).Eval(env)
+			Expect(err).ToNot(HaveOccurred(), src)
+			Expect(v).To(Equal(expected), src)
+		}
+	})
+
+	t.Run("Should short-circuit logical operators", func(t *testing.T) {
+		ok, err := MustCompile(`cache.ok && unknown.ok`).EvalBool(env)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		ok, err = MustCompile(`db.ok || unknown.ok`).EvalBool(env)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	t.Run("Should error on invalid evaluations", func(t *testing.T) {
+		for src, msg := range map[string]string{
+			`unknown.ok`:       "Unknown identifier 'unknown'",
+			`db.unknown`:       "Unknown field 'unknown'",
+			`db.ok.value`:      "Unable to access field 'value' of bool",
+			`db[1]`:            "Unable to access a field by number",
+			`db.replicas[2]`:   "Index 2 out of range (length 2)",
+			`db.replicas[0.5]`: "Unable to index a list by number",
+			`db.replicas["a"]`: "Unable to index a list by string",
+			`db.ok && 1`:       "Operator '&&' requires bools, got number",
+			`!db.status`:       "Operator '!' requires a bool, got string",
+			`-db.status`:       "Operator '-' requires a number, got string",
+			`db.status > 1`:    "Operator '>' requires numbers, got string and number",
+			`lazy / (lazy-42)`: "Division by zero",
+		} {
+			_, err := MustCompile(src).Eval(env)
+			Expect(err).To(MatchError(ContainSubstring(msg)), src)
+		}
+	})
+
+	t.Run("EvalBool should error if the result is not a bool", func(t *testing.T) {
+		_, err := MustCompile(`db.status`).EvalBool(env)
+		Expect(err).To(MatchError("Expression 'db.status' does not result in a bool, but string"))
+	})
+}
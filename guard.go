@@ -0,0 +1,67 @@
+package health
+
+// IsHealthy returns whether the check with the given name is currently
+// healthy (or degraded, ie. still usable); a check that has failed, has been
+// skipped (because a dependency has failed), is paused or has not run yet is
+// not healthy. Use it (or "h.Guard()") to disable optional features while
+// their dependencies are down.
+func (h *Health) IsHealthy(name string) bool {
+	h.refreshLazyCheck(name)
+
+	state, ok := h.lookupState(name)
+
+	return ok && !state.isFailure() && !state.isSkipped() && !state.isPaused()
+}
+
+// Guard formalizes graceful degradation: application code consults it to
+// disable an optional feature (ie. skip recommendations while Redis is down)
+// while any of the guarded checks is unhealthy.
+type Guard struct {
+	health IHealth
+	checks []string
+}
+
+// Guard returns a guard for the checks with the given names.
+func (h *Health) Guard(names ...string) *Guard {
+	return &Guard{
+		health: h,
+		checks: names,
+	}
+}
+
+// Healthy returns whether all guarded checks are healthy (see "h.IsHealthy()").
+func (g *Guard) Healthy() bool {
+	for _, name := range g.checks {
+		if !g.health.IsHealthy(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Unhealthy returns the names of the guarded checks that are not healthy.
+func (g *Guard) Unhealthy() []string {
+	unhealthy := make([]string, 0)
+
+	for _, name := range g.checks {
+		if !g.health.IsHealthy(name) {
+			unhealthy = append(unhealthy, name)
+		}
+	}
+
+	return unhealthy
+}
+
+// Run calls "feature" if all guarded checks are healthy and "fallback"
+// (if non-nil) otherwise.
+func (g *Guard) Run(feature func(), fallback func()) {
+	if g.Healthy() {
+		feature()
+		return
+	}
+
+	if fallback != nil {
+		fallback()
+	}
+}
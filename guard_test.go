@@ -0,0 +1,53 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestGuard(t *testing.T) {
+	RegisterTestingT(t)
+
+	redis := &fakes.FakeICheckable{}
+	redis.StatusReturns(nil, errors.New("things broke"))
+
+	h, _, err := setupRunners([]*Config{
+		{Name: "redis", Checker: redis, Interval: time.Hour},
+		{Name: "db", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+		{Name: "search", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, DependsOn: []string{"redis"}},
+	}, nil)
+	Expect(err).ToNot(HaveOccurred())
+	defer h.Stop()
+
+	Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(3))
+	h.RunCheck("search")
+
+	t.Run("IsHealthy should reflect the state of the check", func(t *testing.T) {
+		Expect(h.IsHealthy("db")).To(BeTrue())
+		Expect(h.IsHealthy("redis")).To(BeFalse())
+		Expect(h.IsHealthy("search")).To(BeFalse())
+		Expect(h.IsHealthy("unknown")).To(BeFalse())
+	})
+
+	t.Run("Guard should only run the feature if all checks are healthy", func(t *testing.T) {
+		var ran []string
+
+		g := h.Guard("db")
+		Expect(g.Healthy()).To(BeTrue())
+		Expect(g.Unhealthy()).To(BeEmpty())
+		g.Run(func() { ran = append(ran, "feature") }, func() { ran = append(ran, "fallback") })
+
+		g = h.Guard("db", "redis", "search")
+		Expect(g.Healthy()).To(BeFalse())
+		Expect(g.Unhealthy()).To(Equal([]string{"redis", "search"}))
+		g.Run(func() { ran = append(ran, "feature") }, func() { ran = append(ran, "fallback") })
+		g.Run(func() { ran = append(ran, "feature") }, nil)
+
+		Expect(ran).To(Equal([]string{"feature", "fallback"}))
+	})
+}
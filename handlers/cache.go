@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// stateVersioner is implemented by `health.Health`; see `h.StateVersion()`
+type stateVersioner interface {
+	StateVersion() uint64
+}
+
+// cachedResponse is a pre-encoded response for a given version of the states
+type cachedResponse struct {
+	version    uint64
+	statusCode int
+	data       []byte
+}
+
+// NewCachedJSONHandlerFunc works like `NewJSONHandlerFunc`, but serves the
+// pre-encoded response from a cache that is only invalidated once the states
+// change (see `h.StateVersion()`), instead of re-marshalling all states on
+// every request; ie. for endpoints that are hit by load balancers many times
+// per second.
+//
+// Requests with query parameters (ie. `since` or `tags`) are not cached. If `h`
+// does not implement `StateVersion()` (ie. a mock), nothing is cached.
+func NewCachedJSONHandlerFunc(h health.IHealth, custom map[string]interface{}) http.HandlerFunc {
+	versioner, ok := h.(stateVersioner)
+	if !ok {
+		return NewJSONHandlerFunc(h, custom)
+	}
+
+	var (
+		cache *cachedResponse
+		lock  sync.RWMutex
+	)
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "" {
+			statusCode, data := renderJSON(h, r, custom)
+			writeJSONResponse(rw, statusCode, data)
+			return
+		}
+
+		// read the version first: a response rendered from newer states is
+		// merely re-rendered once more, but never served stale
+		version := versioner.StateVersion()
+
+		lock.RLock()
+		cached := cache
+		lock.RUnlock()
+
+		if cached == nil || cached.version != version {
+			statusCode, data := renderJSON(h, r, custom)
+			cached = &cachedResponse{version: version, statusCode: statusCode, data: data}
+
+			lock.Lock()
+			cache = cached
+			lock.Unlock()
+		}
+
+		writeJSONResponse(rw, cached.statusCode, cached.data)
+	})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// NewDeployHandlerFunc will return an `http.HandlerFunc` for rollout tooling
+// that will write the deploy phase of the instance (see `h.Deploy()`) to `rw`.
+// On `POST` or `PUT`, the phase is first set to the `phase` query parameter
+// (`canary`, `draining` or empty for stable; see `h.SetDeployPhase()`);
+// unknown phases are rejected with `http.StatusBadRequest`. The handler
+// changes the state of the instance; do not expose it publicly.
+func NewDeployHandlerFunc(h health.IHealth) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost, http.MethodPut:
+			phase := health.DeployPhase(r.URL.Query().Get("phase"))
+			if err := h.SetDeployPhase(phase); err != nil {
+				writeJSONStatus(rw, "error", fmt.Sprintf("Unable to set deploy phase: %v", err), http.StatusBadRequest)
+				return
+			}
+		default:
+			rw.Header().Set("Allow", "GET, HEAD, POST, PUT")
+			writeJSONStatus(rw, "error", fmt.Sprintf("Method '%v' not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body := map[string]interface{}{"phase": health.DeployPhaseStable}
+		if deploy, ok := h.Deploy(); ok {
+			body["phase"] = deploy.Phase
+			body["since"] = deploy.Since
+		}
+
+		data, err := json.Marshal(body)
+		if err != nil {
+			writeJSONStatus(rw, "error", fmt.Sprintf("Failed to marshal deploy phase: %v", err), http.StatusOK)
+			return
+		}
+
+		writeJSONResponse(rw, http.StatusOK, data)
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// NewDescriptionsHandlerFunc will return an `http.HandlerFunc` that will
+// marshal and write the descriptions of all checks (see `h.Descriptions()`)
+// to `rw`, ie. the type, target and capabilities of every check along with
+// its expected interval.
+func NewDescriptionsHandlerFunc(h health.IHealth) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, err := json.Marshal(map[string]interface{}{
+			"checks": h.Descriptions(),
+		})
+		if err != nil {
+			writeJSONStatus(rw, "error", fmt.Sprintf("Failed to marshal descriptions: %v", err), http.StatusOK)
+			return
+		}
+
+		writeJSONResponse(rw, http.StatusOK, data)
+	})
+}
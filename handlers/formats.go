@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const (
+	statusUp   = "UP"
+	statusDown = "DOWN"
+)
+
+// MicroProfilePayload is the Eclipse MicroProfile Health response body.
+type MicroProfilePayload struct {
+	Status string              `json:"status"`
+	Checks []MicroProfileCheck `json:"checks"`
+}
+
+// MicroProfileCheck is a single check of a MicroProfile Health response; as
+// mandated by the spec, "Data" only contains flat (string, number or boolean) values.
+type MicroProfileCheck struct {
+	Name   string                 `json:"name"`
+	Status string                 `json:"status"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+}
+
+// ActuatorPayload is the Spring Boot Actuator `/health` response body.
+type ActuatorPayload struct {
+	Status     string                       `json:"status"`
+	Components map[string]ActuatorComponent `json:"components,omitempty"`
+}
+
+// ActuatorComponent is a single (check) component of a Spring Boot Actuator
+// `/health` response.
+type ActuatorComponent struct {
+	Status  string                 `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// NewMicroProfileHandlerFunc will return an `http.HandlerFunc` that will write
+// the current health in the Eclipse MicroProfile Health JSON format to `rw`.
+// The status code is `http.StatusServiceUnavailable` if `h.Failed()` is `true`
+// and `http.StatusOK` otherwise.
+func NewMicroProfileHandlerFunc(h health.IHealth) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		states, failed, err := h.State()
+		if err != nil {
+			writeFormattedResponse(rw, http.StatusServiceUnavailable, &MicroProfilePayload{
+				Status: statusDown,
+				Checks: []MicroProfileCheck{},
+			})
+			return
+		}
+
+		payload := &MicroProfilePayload{
+			Status: upOrDown(failed),
+			Checks: make([]MicroProfileCheck, 0, len(states)),
+		}
+
+		for _, name := range sortedStateNames(states) {
+			state := states[name]
+
+			data := map[string]interface{}{
+				"check_time": state.CheckTime,
+				"fatal":      state.Fatal,
+			}
+
+			if state.Err != "" {
+				data["error"] = state.Err
+			}
+
+			if state.Warning != "" {
+				data["warning"] = state.Warning
+			}
+
+			payload.Checks = append(payload.Checks, MicroProfileCheck{
+				Name:   name,
+				Status: upOrDown(state.Status == "failed"),
+				Data:   data,
+			})
+		}
+
+		writeFormattedResponse(rw, statusCodeFor(failed), payload)
+	})
+}
+
+// NewActuatorHandlerFunc will return an `http.HandlerFunc` that will write the
+// current health in the Spring Boot Actuator `/health` JSON format to `rw`
+// (with every check as a component). The status code is
+// `http.StatusServiceUnavailable` if `h.Failed()` is `true` and
+// `http.StatusOK` otherwise.
+func NewActuatorHandlerFunc(h health.IHealth) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		states, failed, err := h.State()
+		if err != nil {
+			writeFormattedResponse(rw, http.StatusServiceUnavailable, &ActuatorPayload{
+				Status: statusDown,
+			})
+			return
+		}
+
+		payload := &ActuatorPayload{
+			Status:     upOrDown(failed),
+			Components: make(map[string]ActuatorComponent, len(states)),
+		}
+
+		for name, state := range states {
+			details := map[string]interface{}{
+				"check_time": state.CheckTime,
+			}
+
+			if state.Err != "" {
+				details["error"] = state.Err
+			}
+
+			if state.Details != nil {
+				details["details"] = state.Details
+			}
+
+			payload.Components[name] = ActuatorComponent{
+				Status:  upOrDown(state.Status == "failed"),
+				Details: details,
+			}
+		}
+
+		writeFormattedResponse(rw, statusCodeFor(failed), payload)
+	})
+}
+
+func upOrDown(failed bool) string {
+	if failed {
+		return statusDown
+	}
+
+	return statusUp
+}
+
+func statusCodeFor(failed bool) int {
+	if failed {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
+
+func sortedStateNames(states map[string]health.State) []string {
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func writeFormattedResponse(rw http.ResponseWriter, statusCode int, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		writeJSONStatus(rw, "error", fmt.Sprintf("Failed to marshal state data: %v", err), http.StatusOK)
+		return
+	}
+
+	writeJSONResponse(rw, statusCode, data)
+}
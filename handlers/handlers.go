@@ -4,11 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/InVisionApp/go-health"
 )
 
+// DegradedStatusCode is the status code written by `NewBasicHandlerFunc` and
+// `NewJSONHandlerFunc` if none of the fatal checks has failed but any check is
+// degraded (see `health.DegradedError`).
+var DegradedStatusCode = http.StatusOK
+
 type jsonStatus struct {
 	Message string `json:"message"`
 	Status  string `json:"status"`
@@ -21,7 +29,9 @@ type mutexMap struct {
 
 // NewBasicHandlerFunc will return an `http.HandlerFunc` that will write `ok`
 // string + `http.StatusOK` to `rw`` if `h.Failed()` returns `false`;
-// returns `error` + `http.StatusInternalServerError` if `h.Failed()` returns `true`.
+// returns `error` + `http.StatusInternalServerError` if `h.Failed()` returns `true`;
+// returns `degraded` + `DegradedStatusCode` if any check is degraded (or the
+// maintenance mode is on with the `degraded` status).
 func NewBasicHandlerFunc(h health.IHealth) http.HandlerFunc {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 		status := http.StatusOK
@@ -30,6 +40,12 @@ func NewBasicHandlerFunc(h health.IHealth) http.HandlerFunc {
 		if h.Failed() {
 			status = http.StatusInternalServerError
 			body = "failed"
+		} else if m, ok := h.Maintenance(); ok {
+			status = DegradedStatusCode
+			body = m.Status
+		} else if states, _, _ := h.State(); anyDegraded(states) {
+			status = DegradedStatusCode
+			body = "degraded"
 		}
 
 		rw.WriteHeader(status)
@@ -41,61 +57,194 @@ func NewBasicHandlerFunc(h health.IHealth) http.HandlerFunc {
 // write the contents of `h.StateMapInterface()` to `rw` and set status code to
 //  `http.StatusOK` if `h.Failed()` is `false` OR set status code to
 // `http.StatusInternalServerError` if `h.Failed` is `true`.
-// It also accepts a set of optional custom fields to be added to the final JSON body
+// It also accepts a set of optional custom fields to be added to the final JSON body.
+//
+// If the `since` query parameter is set (RFC3339 or unix timestamp), `details`
+// only contains the checks whose state has changed after the given time (see
+// `h.Changes()`).
+//
+// If the `tags` query parameter is set (comma separated), only the checks with
+// at least one of the given tags are included (see `health.WithTags()`).
+//
+// `score` is the weighted health score of the included checks (see
+// `h.Score()`).
+//
+// If the `history` query parameter is `true`, `history` contains the latest
+// results of every included check (see `h.History()`); if it is `sampled`, it
+// contains their downsampled results instead (see `h.SampledHistory()`).
 func NewJSONHandlerFunc(h health.IHealth, custom map[string]interface{}) http.HandlerFunc {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		states, failed, err := h.State()
+		statusCode, data := renderJSON(h, r, custom)
+
+		writeJSONResponse(rw, statusCode, data)
+	})
+}
+
+// renders the response of `NewJSONHandlerFunc`; returns the status code and the body
+func renderJSON(h health.IHealth, r *http.Request, custom map[string]interface{}) (int, []byte) {
+	opts := make([]health.StateOption, 0)
+	if param := r.URL.Query().Get("tags"); param != "" {
+		opts = append(opts, health.WithTags(strings.Split(param, ",")...))
+	}
+
+	states, failed, err := h.State(opts...)
+	if err != nil {
+		return http.StatusOK, marshalJSONStatus("error", fmt.Sprintf("Unable to fetch states: %v", err))
+	}
+
+	var details interface{} = states
+
+	if param := r.URL.Query().Get("since"); param != "" {
+		since, err := parseSince(param)
 		if err != nil {
-			writeJSONStatus(rw, "error", fmt.Sprintf("Unable to fetch states: %v", err), http.StatusOK)
-			return
+			return http.StatusBadRequest, marshalJSONStatus("error", fmt.Sprintf("Unable to parse 'since': %v", err))
 		}
 
-		msg := "ok"
-		statusCode := http.StatusOK
+		changes := h.Changes(since)
 
-		// There may be an _initial_ delay in display healthcheck data as the
-		// healthchecks will only begin firing at "initialTime + checkIntervalTime"
-		if len(states) == 0 {
-			writeJSONStatus(rw, msg, "Healthcheck spinning up", statusCode)
-			return
+		// only include the changes of the (filtered) checks
+		for name := range changes {
+			if _, ok := states[name]; !ok {
+				delete(changes, name)
+			}
 		}
 
-		if failed {
-			msg = "failed"
-			statusCode = http.StatusInternalServerError
+		details = changes
+	}
+
+	msg := "ok"
+	statusCode := http.StatusOK
+	maintenance, inMaintenance := h.Maintenance()
+
+	// There may be an _initial_ delay in display healthcheck data as the
+	// healthchecks will only begin firing at "initialTime + checkIntervalTime"
+	if len(states) == 0 && !inMaintenance {
+		return statusCode, marshalJSONStatus(msg, "Healthcheck spinning up")
+	}
+
+	if failed {
+		msg = "failed"
+		statusCode = http.StatusInternalServerError
+	} else if inMaintenance {
+		msg = maintenance.Status
+		statusCode = DegradedStatusCode
+	} else if anyDegraded(states) {
+		msg = "degraded"
+		statusCode = DegradedStatusCode
+	}
+
+	fullBody := mutexMap{}
+	fullBody.Lock()
+	fullBody.data = map[string]interface{}{
+		"status":  msg,
+		"score":   h.Score(opts...),
+		"details": details,
+	}
+
+	if inMaintenance {
+		fullBody.data["maintenance"] = maintenance
+	}
+
+	if deploy, ok := h.Deploy(); ok {
+		fullBody.data["deploy"] = deploy
+	}
+
+	if withHistory, _ := strconv.ParseBool(r.URL.Query().Get("history")); withHistory {
+		history := make(map[string][]health.HistoryEntry, len(states))
+		for name := range states {
+			history[name] = h.History(name)
 		}
 
-		fullBody := mutexMap{}
-		fullBody.Lock()
-		fullBody.data = map[string]interface{}{
-			"status":  msg,
-			"details": states,
+		fullBody.data["history"] = history
+	} else if r.URL.Query().Get("history") == "sampled" {
+		history := make(map[string][]health.HistoryEntry, len(states))
+		for name := range states {
+			history[name] = h.SampledHistory(name)
 		}
 
-		for k, v := range custom {
-			if k != "status" && k != "details" {
-				fullBody.data[k] = v
+		fullBody.data["history"] = history
+	}
+
+	for k, v := range custom {
+		if _, ok := fullBody.data[k]; !ok {
+			fullBody.data[k] = v
+		}
+	}
+
+	data, err := json.Marshal(fullBody.data)
+	fullBody.Unlock()
+	if err != nil {
+		return http.StatusOK, marshalJSONStatus("error", fmt.Sprintf("Failed to marshal state data: %v", err))
+	}
+
+	return statusCode, data
+}
+
+// NewIncidentsHandlerFunc will return an `http.HandlerFunc` that will marshal
+// and write the incidents retained by `h.Incidents()` to `rw`. If the `id`
+// query parameter is set, only the incident with the given ID is written (or
+// `http.StatusNotFound` if it does not exist).
+func NewIncidentsHandlerFunc(h health.IHealth) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body interface{}
+
+		if id := r.URL.Query().Get("id"); id != "" {
+			incident, ok := h.Incident(id)
+			if !ok {
+				writeJSONStatus(rw, "error", fmt.Sprintf("Incident '%v' not found", id), http.StatusNotFound)
+				return
+			}
+
+			body = incident
+		} else {
+			body = map[string]interface{}{
+				"incidents": h.Incidents(),
 			}
 		}
 
-		data, err := json.Marshal(fullBody.data)
-		fullBody.Unlock()
+		data, err := json.Marshal(body)
 		if err != nil {
-			writeJSONStatus(rw, "error", fmt.Sprintf("Failed to marshal state data: %v", err), http.StatusOK)
+			writeJSONStatus(rw, "error", fmt.Sprintf("Failed to marshal incident data: %v", err), http.StatusOK)
 			return
 		}
 
-		writeJSONResponse(rw, statusCode, data)
+		writeJSONResponse(rw, http.StatusOK, data)
 	})
 }
 
+// indicates whether any of the checks is degraded (or its failure has been
+// downgraded during a deploy, or tolerated by "Health.Aggregation"); only
+// called if the healthcheck has not failed
+func anyDegraded(states map[string]health.State) bool {
+	for _, state := range states {
+		if state.Status == "degraded" || state.Downgraded || (state.Fatal && state.Status == "failed") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parses a RFC3339 or unix timestamp
+func parseSince(param string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(param, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, param)
+}
+
 func writeJSONStatus(rw http.ResponseWriter, status, message string, statusCode int) {
+	writeJSONResponse(rw, statusCode, marshalJSONStatus(status, message))
+}
+
+func marshalJSONStatus(status, message string) []byte {
 	jsonData, _ := json.Marshal(&jsonStatus{
 		Message: message,
 		Status:  status,
 	})
 
-	writeJSONResponse(rw, statusCode, jsonData)
+	return jsonData
 }
 
 func writeJSONResponse(rw http.ResponseWriter, statusCode int, content []byte) {
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// NewInternalHandlerFunc will return an `http.HandlerFunc` that will marshal
+// and write the internals of the runner (see `h.RunnerStats()`) to `rw`, ie.
+// to be mounted on `/healthz/internal` so that operators can monitor the
+// monitor.
+func NewInternalHandlerFunc(h health.IHealth) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, err := json.Marshal(h.RunnerStats())
+		if err != nil {
+			writeJSONStatus(rw, "error", fmt.Sprintf("Failed to marshal runner stats: %v", err), http.StatusOK)
+			return
+		}
+
+		writeJSONResponse(rw, http.StatusOK, data)
+	})
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// Nagios plugin exit codes
+const (
+	NagiosOK       = 0
+	NagiosWarning  = 1
+	NagiosCritical = 2
+	NagiosUnknown  = 3
+)
+
+var nagiosStatuses = map[int]string{
+	NagiosOK:       "OK",
+	NagiosWarning:  "WARNING",
+	NagiosCritical: "CRITICAL",
+	NagiosUnknown:  "UNKNOWN",
+}
+
+// NagiosDefaultTemplate produces the status line (with perfdata) followed by
+// one line for each failing check.
+const NagiosDefaultTemplate = `{{.Status}} - {{.Summary}}{{if .Perfdata}} | {{.Perfdata}}{{end}}
+{{range .Failed}}{{.Name}}: {{.Err}}
+{{end}}`
+
+var nagiosDefaultTemplate = template.Must(template.New("nagios").Parse(NagiosDefaultTemplate))
+
+// NagiosData contains the variables available to the Nagios output template.
+type NagiosData struct {
+	Status   string         // "OK", "WARNING", "CRITICAL" or "UNKNOWN"
+	ExitCode int            // the plugin exit code (0, 1, 2 or 3)
+	Summary  string         // ie. "1 of 3 checks failing: foo"
+	Perfdata string         // perfdata for all checks
+	States   []health.State // all states, sorted by name
+	Failed   []health.State // failed states, sorted by name
+	Warnings []health.State // degraded states and states with warnings, sorted by name
+}
+
+// NagiosOutput renders the current health state in the Nagios plugin format
+// and returns it along with the plugin exit code; this allows go-health
+// services to integrate with Nagios, Icinga or Zabbix agents (ie. via a small
+// command that prints the output and exits with the exit code).
+//
+// A failing fatal check is "CRITICAL", a failing non-fatal check or a check
+// with a warning (or degraded) is "WARNING" and a lack of check results is "UNKNOWN".
+//
+// If `tmpl` is nil, `NagiosDefaultTemplate` is used.
+func NagiosOutput(h health.IHealth, tmpl *template.Template) (string, int, error) {
+	if tmpl == nil {
+		tmpl = nagiosDefaultTemplate
+	}
+
+	states, _, err := h.State()
+	if err != nil {
+		return fmt.Sprintf("UNKNOWN - Unable to fetch states: %v\n", err), NagiosUnknown, nil
+	}
+
+	data := newNagiosData(states)
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", NagiosUnknown, fmt.Errorf("Unable to render nagios template: %v", err)
+	}
+
+	return buf.String(), data.ExitCode, nil
+}
+
+// NewNagiosHandlerFunc will return an `http.HandlerFunc` that will write the
+// output of `NagiosOutput()` to `rw` as plain text. The plugin exit code is
+// set in the `X-Nagios-Exit-Code` header; the status code is
+// `http.StatusInternalServerError` for "CRITICAL", `http.StatusServiceUnavailable`
+// for "UNKNOWN" and `http.StatusOK` otherwise.
+func NewNagiosHandlerFunc(h health.IHealth, tmpl *template.Template) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		output, exitCode, err := NagiosOutput(h, tmpl)
+		if err != nil {
+			output = fmt.Sprintf("UNKNOWN - %v\n", err)
+		}
+
+		statusCode := http.StatusOK
+
+		switch exitCode {
+		case NagiosCritical:
+			statusCode = http.StatusInternalServerError
+		case NagiosUnknown:
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.Header().Set("X-Nagios-Exit-Code", fmt.Sprintf("%d", exitCode))
+		rw.WriteHeader(statusCode)
+		rw.Write([]byte(output))
+	})
+}
+
+func newNagiosData(states map[string]health.State) *NagiosData {
+	data := &NagiosData{
+		ExitCode: NagiosOK,
+		States:   make([]health.State, 0, len(states)),
+		Failed:   make([]health.State, 0),
+		Warnings: make([]health.State, 0),
+	}
+
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	perfdata := make([]string, 0, len(names))
+	failedNames := make([]string, 0)
+
+	for _, name := range names {
+		state := states[name]
+		data.States = append(data.States, state)
+
+		value := 1
+
+		if state.Status == "failed" {
+			value = 0
+			data.Failed = append(data.Failed, state)
+			failedNames = append(failedNames, name)
+
+			if state.Fatal && !state.Downgraded {
+				data.ExitCode = NagiosCritical
+			} else if data.ExitCode < NagiosWarning {
+				data.ExitCode = NagiosWarning
+			}
+		}
+
+		if state.Warning != "" || state.Status == "degraded" {
+			data.Warnings = append(data.Warnings, state)
+
+			if data.ExitCode < NagiosWarning {
+				data.ExitCode = NagiosWarning
+			}
+		}
+
+		perfdata = append(perfdata,
+			fmt.Sprintf("'%v'=%d;;1:;0;1", name, value),
+			fmt.Sprintf("'%v_failures'=%d;;;0", name, state.ContiguousFailures))
+	}
+
+	data.Perfdata = strings.Join(perfdata, " ")
+
+	switch {
+	case len(states) == 0:
+		data.ExitCode = NagiosUnknown
+		data.Summary = "no check results yet"
+	case len(data.Failed) > 0:
+		data.Summary = fmt.Sprintf("%d of %d checks failing: %v", len(data.Failed), len(states),
+			strings.Join(failedNames, ", "))
+	case len(data.Warnings) > 0:
+		data.Summary = fmt.Sprintf("all %d checks ok, %d with warnings", len(states), len(data.Warnings))
+	default:
+		data.Summary = fmt.Sprintf("all %d checks ok", len(states))
+	}
+
+	data.Status = nagiosStatuses[data.ExitCode]
+
+	return data
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// OpenAPIConfig describes where the handlers are mounted; it is used for
+// generating the OpenAPI document of the health endpoints.
+//
+// "Title" and "Version" are optional; they default to "go-health" and "1.0.0".
+//
+// The paths are optional; only the endpoints with a path set are documented.
+// If none of the paths are set, `JSONPath` defaults to "/healthz".
+type OpenAPIConfig struct {
+	Title   string
+	Version string
+
+	JSONPath      string // `NewJSONHandlerFunc`
+	BasicPath     string // `NewBasicHandlerFunc`
+	IncidentsPath string // `NewIncidentsHandlerFunc`
+	NagiosPath    string // `NewNagiosHandlerFunc`
+	SchemaPath    string // `NewSchemaHandlerFunc`
+	OpenAPIPath   string // `NewOpenAPIHandlerFunc`
+
+	MicroProfilePath string // `NewMicroProfileHandlerFunc`
+	ActuatorPath     string // `NewActuatorHandlerFunc`
+}
+
+// OpenAPISpec returns an OpenAPI 3 document describing the health endpoints
+// mounted at the paths set in `cfg` (including the payload schema returned by
+// `PayloadSchema()`); `cfg` may be nil.
+func OpenAPISpec(cfg *OpenAPIConfig) map[string]interface{} {
+	c := OpenAPIConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if c.Title == "" {
+		c.Title = "go-health"
+	}
+
+	if c.Version == "" {
+		c.Version = "1.0.0"
+	}
+
+	if c.JSONPath == "" && c.BasicPath == "" && c.IncidentsPath == "" &&
+		c.NagiosPath == "" && c.SchemaPath == "" && c.OpenAPIPath == "" &&
+		c.MicroProfilePath == "" && c.ActuatorPath == "" {
+		c.JSONPath = "/healthz"
+	}
+
+	paths := map[string]interface{}{}
+
+	if c.JSONPath != "" {
+		paths[c.JSONPath] = openAPIGet("Aggregated health of all checks", []interface{}{
+			openAPIQueryParam("since", "Only include the checks whose state has changed after the given time (RFC3339 or unix timestamp)"),
+		}, map[string]interface{}{
+			"200": openAPIJSONResponse("Healthy", "HealthPayload"),
+			"400": openAPIJSONResponse("Invalid query parameter", "Status"),
+			"500": openAPIJSONResponse("A fatal check has failed", "HealthPayload"),
+		})
+	}
+
+	if c.BasicPath != "" {
+		paths[c.BasicPath] = openAPIGet("Aggregated health as plain text", nil, map[string]interface{}{
+			"200": openAPITextResponse("Healthy (`ok`)", nil),
+			"500": openAPITextResponse("A fatal check has failed (`failed`)", nil),
+		})
+	}
+
+	if c.IncidentsPath != "" {
+		paths[c.IncidentsPath] = openAPIGet("Retained incidents", []interface{}{
+			openAPIQueryParam("id", "Only return the incident with the given ID"),
+		}, map[string]interface{}{
+			"200": openAPIJSONResponse("The incidents (or the incident with the given ID)", "IncidentsPayload"),
+			"404": openAPIJSONResponse("Incident not found", "Status"),
+		})
+	}
+
+	if c.NagiosPath != "" {
+		headers := map[string]interface{}{
+			"X-Nagios-Exit-Code": map[string]interface{}{
+				"description": "The Nagios plugin exit code",
+				"schema":      map[string]interface{}{"type": "integer", "enum": []interface{}{NagiosOK, NagiosWarning, NagiosCritical, NagiosUnknown}},
+			},
+		}
+
+		paths[c.NagiosPath] = openAPIGet("Health in the Nagios plugin output format", nil, map[string]interface{}{
+			"200": openAPITextResponse("OK or WARNING", headers),
+			"500": openAPITextResponse("CRITICAL", headers),
+			"503": openAPITextResponse("UNKNOWN", headers),
+		})
+	}
+
+	if c.MicroProfilePath != "" {
+		paths[c.MicroProfilePath] = openAPIGet("Aggregated health in the MicroProfile Health format", nil, map[string]interface{}{
+			"200": openAPIJSONResponse("Healthy", "MicroProfilePayload"),
+			"503": openAPIJSONResponse("A fatal check has failed", "MicroProfilePayload"),
+		})
+	}
+
+	if c.ActuatorPath != "" {
+		paths[c.ActuatorPath] = openAPIGet("Aggregated health in the Spring Boot Actuator format", nil, map[string]interface{}{
+			"200": openAPIJSONResponse("Healthy", "ActuatorPayload"),
+			"503": openAPIJSONResponse("A fatal check has failed", "ActuatorPayload"),
+		})
+	}
+
+	if c.SchemaPath != "" {
+		paths[c.SchemaPath] = openAPIGet("JSON Schema of the health payload", nil, map[string]interface{}{
+			"200": openAPIJSONResponse("The JSON Schema", ""),
+		})
+	}
+
+	if c.OpenAPIPath != "" {
+		paths[c.OpenAPIPath] = openAPIGet("This OpenAPI document", nil, map[string]interface{}{
+			"200": openAPIJSONResponse("The OpenAPI document", ""),
+		})
+	}
+
+	// OpenAPI 3.0 does not support these JSON Schema keywords
+	payload := PayloadSchema()
+	delete(payload, "$schema")
+	delete(payload, "$id")
+
+	incident := schemaFor(reflect.TypeOf(health.Incident{}))
+	incident["properties"].(map[string]interface{})["duration"] = map[string]interface{}{"type": "string"}
+	incident["required"] = append(incident["required"].([]interface{}), "duration")
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   c.Title,
+			"version": c.Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"HealthPayload": payload,
+				"Status":        schemaFor(reflect.TypeOf(jsonStatus{})),
+				"Incident":      incident,
+
+				"MicroProfilePayload": schemaFor(reflect.TypeOf(MicroProfilePayload{})),
+				"ActuatorPayload":     schemaFor(reflect.TypeOf(ActuatorPayload{})),
+
+				"IncidentsPayload": map[string]interface{}{
+					"oneOf": []interface{}{
+						map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"incidents": map[string]interface{}{
+									"type":  "array",
+									"items": openAPIRef("Incident"),
+								},
+							},
+						},
+						openAPIRef("Incident"),
+					},
+				},
+			},
+		},
+	}
+}
+
+// NewOpenAPIHandlerFunc will return an `http.HandlerFunc` that will write the
+// OpenAPI document returned by `OpenAPISpec(cfg)` to `rw` (ie. mounted at
+// `/healthz/openapi.json`).
+func NewOpenAPIHandlerFunc(cfg *OpenAPIConfig) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, err := json.MarshalIndent(OpenAPISpec(cfg), "", "  ")
+		if err != nil {
+			writeJSONStatus(rw, "error", fmt.Sprintf("Failed to marshal OpenAPI document: %v", err), http.StatusOK)
+			return
+		}
+
+		writeJSONResponse(rw, http.StatusOK, data)
+	})
+}
+
+func openAPIGet(summary string, params []interface{}, responses map[string]interface{}) map[string]interface{} {
+	op := map[string]interface{}{
+		"summary":   summary,
+		"responses": responses,
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	return map[string]interface{}{"get": op}
+}
+
+func openAPIQueryParam(name, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "query",
+		"required":    false,
+		"description": description,
+		"schema":      map[string]interface{}{"type": "string"},
+	}
+}
+
+func openAPIJSONResponse(description, schema string) map[string]interface{} {
+	s := map[string]interface{}{"type": "object"}
+	if schema != "" {
+		s = openAPIRef(schema)
+	}
+
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": s},
+		},
+	}
+}
+
+func openAPITextResponse(description string, headers map[string]interface{}) map[string]interface{} {
+	response := map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+		},
+	}
+
+	if headers != nil {
+		response["headers"] = headers
+	}
+
+	return response
+}
+
+func openAPIRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// PayloadSchemaID is the "$id" of the JSON Schema returned by `PayloadSchema()`.
+const PayloadSchemaID = "https://github.com/InVisionApp/go-health/handlers/payload.schema.json"
+
+// JSONPayload describes the body written by `NewJSONHandlerFunc`; the JSON
+// Schema returned by `PayloadSchema()` is generated from it.
+//
+// "Message" is only set while the healthcheck is spinning up or on errors;
+// "Details" is set otherwise. "Maintenance" is only set while the maintenance
+// mode is on (see `h.SetMaintenanceMode()`), "Deploy" while the instance is a
+// canary or draining (see `h.SetDeployPhase()`), "History" if requested with
+// the `history` query parameter (see `h.History()`). Custom fields passed to
+// `NewJSONHandlerFunc` are permitted as additional top-level properties.
+type JSONPayload struct {
+	Status      string                           `json:"status"`
+	Message     string                           `json:"message,omitempty"`
+	Details     map[string]health.State          `json:"details,omitempty"`
+	Maintenance *health.Maintenance              `json:"maintenance,omitempty"`
+	Deploy      *health.Deploy                   `json:"deploy,omitempty"`
+	History     map[string][]health.HistoryEntry `json:"history,omitempty"`
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// PayloadSchema returns the JSON Schema (draft-07) of the `NewJSONHandlerFunc`
+// payload, generated from the `JSONPayload` and `health.State` structs.
+func PayloadSchema() map[string]interface{} {
+	schema := schemaFor(reflect.TypeOf(JSONPayload{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = PayloadSchemaID
+	schema["title"] = "go-health JSON handler payload"
+	schema["additionalProperties"] = true
+
+	props := schema["properties"].(map[string]interface{})
+	props["status"].(map[string]interface{})["enum"] = []interface{}{"ok", "degraded", "failed", "error"}
+	props["maintenance"].(map[string]interface{})["properties"].(map[string]interface{})["status"].(map[string]interface{})["enum"] = []interface{}{"degraded", "failed"}
+	props["deploy"].(map[string]interface{})["properties"].(map[string]interface{})["phase"].(map[string]interface{})["enum"] = []interface{}{"canary", "draining"}
+
+	state := props["details"].(map[string]interface{})["additionalProperties"].(map[string]interface{})
+	state["properties"].(map[string]interface{})["status"].(map[string]interface{})["enum"] = []interface{}{"ok", "degraded", "failed", "skipped", "paused", "expired"}
+
+	return schema
+}
+
+// NewSchemaHandlerFunc will return an `http.HandlerFunc` that will write the
+// JSON Schema returned by `PayloadSchema()` to `rw`.
+func NewSchemaHandlerFunc() http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, err := json.MarshalIndent(PayloadSchema(), "", "  ")
+		if err != nil {
+			writeJSONStatus(rw, "error", fmt.Sprintf("Failed to marshal schema: %v", err), http.StatusOK)
+			return
+		}
+
+		writeJSONResponse(rw, http.StatusOK, data)
+	})
+}
+
+// ValidatePayload validates the given `NewJSONHandlerFunc` payload against the
+// schema returned by `PayloadSchema()`; useful for verifying (ie. in CI) that
+// consumers are compatible with the payload of a given `go-health` version.
+func ValidatePayload(data []byte) error {
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("Unable to unmarshal payload: %v", err)
+	}
+
+	return validateSchema(PayloadSchema(), payload, "$")
+}
+
+// generates the schema of the given type; only the subset of types used by
+// the payload structs is supported
+func schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]interface{}{"type": "integer", "description": "nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+
+	// interface{} and anything else may contain arbitrary JSON
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	required := []interface{}{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, opts := field.Name, ""
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if len(parts) > 1 {
+				opts = parts[1]
+			}
+		}
+
+		props[name] = schemaFor(field.Type)
+
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// validates the value against the subset of JSON Schema produced by schemaFor
+func validateSchema(schema map[string]interface{}, value interface{}, path string) error {
+	if typ, ok := schema["type"].(string); ok {
+		if err := validateType(typ, value, path); err != nil {
+			return err
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, v := range enum {
+			if v == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%v: value '%v' is not one of %v", path, value, enum)
+		}
+	}
+
+	if schema["format"] == "date-time" {
+		if _, err := time.Parse(time.RFC3339Nano, value.(string)); err != nil {
+			return fmt.Errorf("%v: invalid date-time: %v", path, err)
+		}
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateSchema(items, item, fmt.Sprintf("%v[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		return validateObject(schema, v, path)
+	}
+
+	return nil
+}
+
+func validateObject(schema map[string]interface{}, obj map[string]interface{}, path string) error {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				return fmt.Errorf("%v: missing required property '%v'", path, name)
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+
+	// sort the keys to report errors deterministically
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if prop, ok := props[k].(map[string]interface{}); ok {
+			if err := validateSchema(prop, obj[k], path+"."+k); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return fmt.Errorf("%v: unexpected property '%v'", path, k)
+			}
+		case map[string]interface{}:
+			if err := validateSchema(additional, obj[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateType(typ string, value interface{}, path string) error {
+	valid := false
+
+	switch typ {
+	case "object":
+		_, valid = value.(map[string]interface{})
+	case "array":
+		_, valid = value.([]interface{})
+	case "string":
+		_, valid = value.(string)
+	case "boolean":
+		_, valid = value.(bool)
+	case "number":
+		_, valid = value.(float64)
+	case "integer":
+		f, ok := value.(float64)
+		valid = ok && f == float64(int64(f))
+	}
+
+	if !valid {
+		return fmt.Errorf("%v: expected %v, got %T", path, typ, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// NewShedderMiddleware returns an HTTP middleware that sheds a fraction of the
+// requests (with `http.StatusServiceUnavailable` and a `Retry-After` header)
+// according to the admission probability of the given `health.Shedder`.
+func NewShedderMiddleware(s *health.Shedder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if !s.Admit() {
+				rw.Header().Set("Retry-After", "1")
+				writeJSONStatus(rw, "error", "Service is shedding load", http.StatusServiceUnavailable)
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
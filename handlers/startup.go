@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// NewStartupHandlerFunc will return an `http.HandlerFunc` for a Kubernetes
+// startup probe that will write `ok` with `http.StatusOK` once all startup
+// checks have passed (see `h.Started()`), and `starting` with
+// `http.StatusServiceUnavailable` until then.
+func NewStartupHandlerFunc(h health.IHealth) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		body := "ok"
+
+		if !h.Started() {
+			status = http.StatusServiceUnavailable
+			body = "starting"
+		}
+
+		rw.WriteHeader(status)
+		rw.Write([]byte(body))
+	})
+}
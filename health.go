@@ -6,8 +6,12 @@
 package health
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/InVisionApp/go-logger"
@@ -16,7 +20,9 @@ import (
 //go:generate counterfeiter -o ./fakes/icheckable.go . ICheckable
 
 var (
-	// ErrNoAddCfgWhenActive is returned when you attempt to add check(s) to an already active healthcheck instance
+	// ErrNoAddCfgWhenActive was returned when you attempted to add check(s) to an already active healthcheck instance.
+	//
+	// Deprecated: checks can be added to (and removed from) an active healthcheck instance; no longer returned.
 	ErrNoAddCfgWhenActive = errors.New("Unable to add new check configuration(s) while healthcheck is active")
 
 	// ErrAlreadyRunning is returned when you attempt to "h.Start()" an already running healthcheck
@@ -25,6 +31,10 @@ var (
 	// ErrAlreadyStopped is returned when you attempt to "h.Stop()" a non-running healthcheck instance
 	ErrAlreadyStopped = errors.New("Healthcheck is not running - nothing to stop")
 
+	// ErrStartTimeout is returned when the checks have not completed their first
+	// execution within "StartTimeout" (see "WaitOnStart"); the checks keep running
+	ErrStartTimeout = errors.New("Timed out waiting for the first execution of the checks")
+
 	// ErrEmptyConfigs is returned when you attempt to add an empty slice of configs via "h.AddChecks()"
 	ErrEmptyConfigs = errors.New("Configs appears to be empty - nothing to add")
 )
@@ -35,10 +45,37 @@ var (
 type IHealth interface {
 	AddChecks(cfgs []*Config) error
 	AddCheck(cfg *Config) error
+	RemoveCheck(name string) error
+	SyncChecks(cfgs []*Config, opts ...SyncOption) (ChecksDiff, error)
+	PauseCheck(name string) error
+	ResumeCheck(name string) error
+	Started() bool
+	Score(opts ...StateOption) int
 	Start() error
 	Stop() error
-	State() (map[string]State, bool, error)
+	StopWithContext(ctx context.Context) error
+	State(opts ...StateOption) (map[string]State, bool, error)
 	Failed() bool
+	IsHealthy(name string) bool
+	Groups() map[string]GroupState
+	Changes(since time.Time) map[string]State
+	Incidents() []Incident
+	Incident(id string) (Incident, bool)
+	History(name string) []HistoryEntry
+	SampledHistory(name string) []HistoryEntry
+	RunCheck(name string) (State, error)
+	RunCheckContext(ctx context.Context, name string) (State, error)
+	RunAll(ctx context.Context) (map[string]State, bool, error)
+	Timing(name string) (Timing, error)
+	Describe(name string) (Description, error)
+	Descriptions() []Description
+	RunnerStats() RunnerStats
+	ExportConfig() ([]byte, error)
+	SetMaintenanceMode(on bool, reason string)
+	Maintenance() (Maintenance, bool)
+	SetDeployPhase(phase DeployPhase) error
+	Deploy() (Deploy, bool)
+	Subscribe() (<-chan StateChange, func())
 }
 
 // ICheckable is an interface implemented by a number of bundled checkers such
@@ -69,6 +106,16 @@ type IStatusListener interface {
 	HealthCheckRecovered(entry *State, recordedFailures int64, failureDurationSeconds float64)
 }
 
+// IResultHook is an interface that receives the result of every completed
+// check (as opposed to "IStatusListener", which is only notified about failures
+// and recoveries), ie. for pushing check results to a monitoring system.
+type IResultHook interface {
+	// CheckCompleted is called (in its own goroutine) after every execution
+	// of a check.
+	// 	* state - The recorded state of the check
+	CheckCompleted(state *State)
+}
+
 // Config is a struct used for defining and configuring checks.
 type Config struct {
 	// Name of the check
@@ -77,29 +124,189 @@ type Config struct {
 	// Checker instance used to perform health check
 	Checker ICheckable
 
-	// Interval between health checks
+	// Interval between health checks; defaults to "Health.DefaultInterval"
+	// (or the package-level "DefaultInterval"), refer to "Timing" for the
+	// precedence
 	Interval time.Duration
 
+	// Schedule is optional; a cron expression ("minute hour day-of-month
+	// month day-of-week", ie. "5 * * * *" for every hour at :05, or one of
+	// "@hourly", "@daily", "@weekly", "@monthly" and "@yearly") that
+	// schedules the check instead of "Interval", ie. for expensive checks
+	// that should run at a fixed time. The check is still executed right
+	// away on start (unless it has an "InitialDelay", in which case the first
+	// execution happens at the first scheduled time after the delay). The
+	// times are in the local time zone; scheduled checks always run off their
+	// own timer and cannot be combined with "AdaptiveInterval", "Backoff" or
+	// "Jitter".
+	Schedule string
+
+	// InitialDelay is optional; if set, the first execution of the check
+	// happens after the delay instead of immediately on start (ie. for
+	// expensive checks that should not fire before connection pools are
+	// warm); subsequent executions happen on the normal interval. Until then,
+	// the check has no state.
+	InitialDelay time.Duration
+
+	// Timeout is optional (defaults to "Health.DefaultTimeout" or the
+	// package-level "DefaultTimeout"); if a check (or its recovery probe) takes longer,
+	// it is aborted and recorded as failed with "ErrCheckTimeout". Checkers
+	// that implement "ICheckableContext" are notified via context
+	// cancellation; others keep running in the background - in either case,
+	// the next execution does not start before the aborted one has returned.
+	Timeout time.Duration
+
 	// Fatal marks a failing health check so that the
 	// entire health check request fails with a 500 error
 	Fatal bool
+
+	// Critical is an alias of Fatal: only critical checks can flip the overall
+	// failed state, while non-critical checks (ie. an optional cache) are
+	// still run and reported but never fail the healthcheck
+	Critical bool
+
+	// Tags group checks (ie. "readiness", "liveness" or "db"); use
+	// "WithTags()" to only fetch the state of a subset of the checks and
+	// "h.Groups()" for the aggregated status of every tag
+	Tags []string
+
+	// DependsOn contains the names of the checks this check depends on; while
+	// any of them is failing (or skipped), the check is not executed and
+	// reported as "skipped" instead, avoiding noise and wasted connections
+	DependsOn []string
+
+	// FailureThreshold is the number of consecutive errors after which the
+	// check is marked as failed; defaults to 1 (fail on the first error).
+	// Until then, the check stays "ok" and the error is only recorded in
+	// "State.Err", avoiding flapping on single transient errors.
+	FailureThreshold int
+
+	// SuccessThreshold is the number of consecutive successes after which a
+	// failed check is reported healthy again; defaults to 1 (recover on the
+	// first success). Until then, the check stays "failed".
+	SuccessThreshold int
+
+	// AdaptiveInterval stretches the interval (up to MaxInterval) when the
+	// check consistently takes close to or longer than its interval, instead of
+	// running back-to-back against a slow dependency. The interval is shrunk
+	// back once the check becomes fast again.
+	AdaptiveInterval bool
+
+	// MaxInterval caps the stretched interval; defaults to 10x Interval
+	MaxInterval time.Duration
+
+	// Backoff doubles the interval on every execution of a failed check (up
+	// to MaxBackoff), instead of hammering a struggling dependency; the
+	// interval is restored once the check recovers.
+	Backoff bool
+
+	// MaxBackoff caps the backed off interval; defaults to 10x Interval
+	MaxBackoff time.Duration
+
+	// Jitter randomizes every interval of the check by up to the given
+	// fraction (ie. 0.1 for +/- 10%), so that many instances checking the
+	// same dependency do not all fire at the same time; capped at 1.
+	Jitter float64
+
+	// Retry retries a failed execution of the check (ie. "retry.Exponential")
+	// before its failure is recorded, so that a single dropped packet does not
+	// count as a failure; every attempt is bounded by the timeout of the
+	// check. Degraded results are not retried. Defaults to no retries.
+	Retry RetryPolicy
+
+	// RecoveryProbe is an optional, stricter checker (ie. a write canary
+	// instead of a ping) that a failing check must additionally pass before it
+	// is marked healthy again, preventing premature re-admission to load
+	// balancing.
+	RecoveryProbe ICheckable
+
+	// OverlapPolicy determines what happens with a tick that fires while the
+	// previous execution of the check is still in flight; defaults to
+	// "OverlapSkip". Either way, at most one execution of a check runs at a
+	// time and the tick is counted in "State.MissedTicks".
+	OverlapPolicy OverlapPolicy
+
+	// Priority determines which worker pool ("Health.Workers") executes the
+	// check; defaults to "PriorityNormal"
+	Priority Priority
+
+	// CacheTTL makes the check lazy: instead of running on a timer, it is
+	// executed on demand when its state is requested ("h.State()",
+	// "h.Failed()", "h.Groups()" or "h.IsHealthy()") and the result is cached
+	// for the TTL, ie. for low-traffic services that do not want to keep
+	// background connections alive. Until it is first requested, the check
+	// has no state. Lazy checks cannot be combined with "Schedule",
+	// "InitialDelay", "AdaptiveInterval", "Backoff" or "Jitter".
+	CacheTTL time.Duration
+
+	// DowngradeDuringDeploy reports a failure of the check as degraded
+	// (instead of failing the overall status) while the instance is a canary
+	// or draining (see "h.SetDeployPhase()"), ie. for dependencies that are
+	// expected to flap during a rollout. The state is marked as
+	// "State.Downgraded".
+	DowngradeDuringDeploy bool
+
+	// Startup makes the check a startup check (ie. "migrations applied" or
+	// "cache warmed"): it runs on its schedule only until its first success
+	// and is considered permanently passed afterwards, ie. it is no longer
+	// executed and its last state is kept. Use "h.Started()" to find out
+	// whether all startup checks have passed (ie. for a Kubernetes startup
+	// probe). Startup checks cannot be combined with "CacheTTL".
+	Startup bool
+
+	// Chained executes the check right after every execution of its
+	// dependencies ("DependsOn") instead of on its own timer, so that it
+	// consumes the values they have just published (see "Publish()" and
+	// "Lookup()") in the same tick, ie. "list the shards, then ping each".
+	// Chained checks cannot be combined with "Schedule", "InitialDelay" or
+	// "CacheTTL".
+	Chained bool
+
+	// Weight is the weight of the check in the health score (see
+	// "h.Score()"), ie. 3 for the primary database and 0.5 for an optional
+	// recommendation service; defaults to 1.
+	Weight float64
 }
 
+// indicates whether a failure of the check flips the overall failed state
+func (c *Config) isCritical() bool {
+	return c.Fatal || c.Critical
+}
+
+// OverlapPolicy determines how ticks that fire while a check is still in
+// flight are handled.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the tick; the check runs again on the next tick
+	OverlapSkip OverlapPolicy = iota
+
+	// OverlapQueue runs the check again as soon as the in-flight execution
+	// completes (at most one execution is queued)
+	OverlapQueue
+)
+
 // State is a struct that contains the results of the latest
 // run of a particular check.
 type State struct {
 	// Name of the health check
 	Name string `json:"name"`
 
-	// Status of the health check state ("ok" or "failed")
+	// Status of the health check state ("ok", "degraded", "failed",
+	// "skipped" if a dependency has failed, "paused" or "expired" (see
+	// "Health.StateTTL"))
 	Status string `json:"status"`
 
-	// Err is the error returned from a failed health check
+	// Err is the error returned from a failed (or degraded) health check
 	Err string `json:"error,omitempty"`
 
-	// Fatal shows if the check will affect global result
+	// Fatal shows if the check will affect global result ("Config.Fatal"
+	// or "Config.Critical")
 	Fatal bool `json:"fatal,omitempty"`
 
+	// Tags of the check ("Config.Tags")
+	Tags []string `json:"tags,omitempty"`
+
 	// Details contains more contextual detail about a
 	// failing health check.
 	Details interface{} `json:"details,omitempty"` // contains JSON message (that can be marshaled)
@@ -107,8 +314,51 @@ type State struct {
 	// CheckTime is the time of the last health check
 	CheckTime time.Time `json:"check_time"`
 
+	// Duration is how long the last execution of the check took
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// Latency contains the min/max/avg and p95 durations of the latest
+	// executions of the check (see "Health.LatencyWindow")
+	Latency *LatencyStats `json:"latency,omitempty"`
+
+	// ChangedAt is the time of the last check whose outcome (status, error or
+	// warning) differed from the previous one
+	ChangedAt time.Time `json:"changed_at"`
+
+	// ConsecutiveErrors is the number of consecutive executions of the check
+	// that returned an error (regardless of "Config.FailureThreshold")
+	ConsecutiveErrors int64 `json:"consecutive_errors,omitempty"`
+
+	// ConsecutiveSuccesses is the number of consecutive executions of the
+	// check that did not return an error (regardless of "Config.SuccessThreshold")
+	ConsecutiveSuccesses int64 `json:"consecutive_successes,omitempty"`
+
+	// TimedOut indicates that the check has been aborted because it
+	// exceeded its timeout ("Err" is set to "ErrCheckTimeout")
+	TimedOut bool `json:"timed_out,omitempty"`
+
+	// Warning contains a non-fatal warning about the check (ie. its
+	// interval has been stretched because it is slow)
+	Warning string `json:"warning,omitempty"`
+
+	// MissedTicks is the number of ticks that fired while the previous
+	// execution of the check was still in flight
+	MissedTicks int64 `json:"missed_ticks,omitempty"`
+
+	// IncidentID is the ID of the open incident of a failing check; on
+	// recovery, it is the ID of the incident that has been resolved
+	IncidentID string `json:"incident_id,omitempty"`
+
+	// Downgraded indicates that the failure of the check is reported as
+	// degraded because of the deploy phase of the instance (see
+	// "Config.DowngradeDuringDeploy")
+	Downgraded bool `json:"downgraded,omitempty"`
+
 	ContiguousFailures int64     `json:"num_failures"`     // the number of failures that occurred in a row
 	TimeOfFirstFailure time.Time `json:"first_failure_at"` // the time of the initial transitional failure for any given health check
+
+	downgradable bool    // "Config.DowngradeDuringDeploy"
+	weight       float64 // "Config.Weight"
 }
 
 // indicates state is failure
@@ -116,6 +366,27 @@ func (s *State) isFailure() bool {
 	return s.Status == "failed"
 }
 
+// indicates the failure affects the global result
+func (s *State) isFatalFailure() bool {
+	return s.Fatal && s.isFailure() && !s.Downgraded
+}
+
+// indicates the check has returned a "DegradedError" (or its failure has
+// been downgraded)
+func (s *State) isDegraded() bool {
+	return s.Status == "degraded" || (s.isFailure() && s.Downgraded)
+}
+
+// indicates the check has been skipped because a dependency has failed
+func (s *State) isSkipped() bool {
+	return s.Status == "skipped"
+}
+
+// indicates the check has been paused via "h.PauseCheck()"
+func (s *State) isPaused() bool {
+	return s.Status == "paused"
+}
+
 // Health contains internal go-health internal structures.
 type Health struct {
 	Logger log.Logger
@@ -123,11 +394,160 @@ type Health struct {
 	// StatusListener will report failures and recoveries
 	StatusListener IStatusListener
 
-	active     *sBool // indicates whether the healthcheck is actively running
-	configs    []*Config
-	states     map[string]State
-	statesLock sync.Mutex
-	runners    map[string]chan struct{} // contains map of active runners w/ a stop channel
+	// ResultHooks will receive the result of every completed check
+	ResultHooks []IResultHook
+
+	// StateListeners will (asynchronously) receive completed checks, failures,
+	// recoveries and changes of the overall status
+	StateListeners []IStateListener
+
+	// Workers limits the number of concurrent executions per check priority
+	// ("Config.Priority"); each priority has its own worker pool, so that cheap
+	// local checks are not starved behind slow network checks during a
+	// partial outage. Priorities without (or with 0) workers are unbounded.
+	Workers map[Priority]int
+
+	// Scheduler determines how the periodic executions of the checks are
+	// scheduled; defaults to "SchedulerTicker" (one goroutine and ticker per
+	// check). Use "SchedulerTimingWheel" or "SchedulerWorkerPool" for hundreds
+	// or thousands of checks.
+	Scheduler Scheduler
+
+	// Concurrency is the number of workers of "SchedulerWorkerPool"; defaults
+	// to 10
+	Concurrency int
+
+	// WaitOnStart makes "h.Start()" block until every check has completed its
+	// first execution, so that the states are populated before the service
+	// begins accepting traffic
+	WaitOnStart bool
+
+	// StartTimeout limits how long "h.Start()" blocks (see "WaitOnStart");
+	// no limit by default
+	StartTimeout time.Duration
+
+	// DefaultInterval and DefaultTimeout are the interval and timeout of the
+	// checks that do not set "Config.Interval" or "Config.Timeout"; if unset,
+	// the package-level "DefaultInterval" and "DefaultTimeout" apply. Refer
+	// to "h.Timing()" for the effective values of a check.
+	DefaultInterval time.Duration
+	DefaultTimeout  time.Duration
+
+	// DegradedWeight is how much a degraded check (or a check with a warning,
+	// or a failure downgraded during a deploy) lowers the health score (see
+	// "h.Score()") compared to a failed check; defaults to 0.5.
+	DegradedWeight float64
+
+	// Aggregation is the policy that determines whether the healthcheck has
+	// failed as a whole (see "AggregationPolicy"); defaults to
+	// "AggregateAny", ie. any failed critical check fails the healthcheck.
+	// Critical checks whose failure is tolerated by the policy report the
+	// overall status as "degraded".
+	Aggregation AggregationPolicy
+
+	// MaintenanceStatus is the overall status that is forced while the
+	// maintenance mode is on (see "h.SetMaintenanceMode()"); either "failed"
+	// (default) or "degraded"
+	MaintenanceStatus string
+
+	// WheelResolution is the tick of the timing wheel ("SchedulerTimingWheel"
+	// and "SchedulerWorkerPool");
+	// intervals are rounded up to it. Defaults to 100ms.
+	WheelResolution time.Duration
+
+	// StateTTL expires the state of a check that has not reported for the
+	// given duration (ie. because it hangs without a "Config.Timeout"): its
+	// status becomes "expired" (which is neither ok nor failed) instead of
+	// serving a stale result forever. The state of a removed check is marked
+	// as expired right away and deleted once the TTL has elapsed. Result hooks
+	// that implement "IExpiryHook" are notified. Must exceed the longest
+	// interval of the checks; lazy and paused checks never expire. Defaults to
+	// no expiry (the state of a removed check is deleted right away).
+	StateTTL time.Duration
+
+	// LatencyWindow is the number of executions per check that the latency
+	// stats of its state ("State.Latency") are computed from, ie. to detect a
+	// dependency that is slowly degrading before it starts timing out;
+	// defaults to 0 (no stats)
+	LatencyWindow int
+
+	// HistorySize is the number of results that are retained per check (see
+	// "h.History()"); defaults to 0 (no history)
+	HistorySize int
+
+	// HistorySampling downsamples the results of every check into one sample
+	// per interval, ie. "time.Minute" (see "h.SampledHistory()"); defaults to
+	// 0 (no sampled history)
+	HistorySampling time.Duration
+
+	// HistoryRetention is how long samples are retained; defaults to
+	// "DefaultHistoryRetention"
+	HistoryRetention time.Duration
+
+	// HistoryStore persists the sampled history, so that it survives restarts
+	// (ie. "NewFileHistoryStore()"); defaults to nil (in memory only)
+	HistoryStore IHistoryStore
+
+	// PanicHandler is called (in addition to logging) whenever a checker
+	// panics; the panic is recovered and the check is marked as failed (see
+	// "PanicError")
+	PanicHandler func(name string, err *PanicError)
+
+	// Tracer creates a span per check execution (ie. an OpenTelemetry tracer,
+	// see "ITracer"); defaults to no tracing
+	Tracer ITracer
+
+	// Slog receives structured records of check executions and of the
+	// lifecycle of the healthcheck (see "LogEvent"), in addition to "Logger";
+	// defaults to nil (disabled)
+	Slog *slog.Logger
+
+	// SlogLevels overrides the levels of the events logged to "Slog" (see
+	// "DefaultSlogLevels")
+	SlogLevels map[LogEvent]slog.Level
+
+	// Clock provides the current time and drives the intervals of the checks
+	// (see "NewFakeClock()" for unit tests); defaults to the real time
+	Clock Clock
+
+	active      *sBool // indicates whether the healthcheck is actively running
+	configs     []*Config
+	configsLock sync.Mutex
+	states      map[string]State
+	statesLock  sync.Mutex
+	snapshot    atomic.Value // read-only *stateSnapshot of the states; nil after every update
+	maintenance atomic.Value // *Maintenance; nil unless the maintenance mode is on
+	deploy      atomic.Value // *Deploy; nil while the instance is stable
+	executions  executions   // in-flight executions, see "h.StopWithContext()"
+
+	fatalFailures  int64  // number of failed fatal checks (atomic)
+	degradedChecks int64  // number of degraded checks (atomic)
+	stateVersion   uint64 // incremented on every update of the states (atomic)
+	lazyChecks     int64  // number of running lazy checks (atomic)
+	pendingHooks   int64  // number of result hook calls in flight (atomic)
+	droppedEvents  int64  // number of dropped listener and subscriber events (atomic)
+
+	runners     map[string]*runner // contains map of active runners
+	runnersLock sync.Mutex
+
+	incidents     []*Incident          // open and resolved incidents, oldest first
+	openIncidents map[string]*Incident // open incidents by check name
+	incidentsLock sync.Mutex
+
+	pools map[Priority]*workerPool // worker pools by priority, created on "h.Start()"
+	wheel *timingWheel             // shared by all checks unless "SchedulerTicker" is used
+	queue *workQueue               // executes all checks if "SchedulerWorkerPool" is used
+
+	dispatchers     []*listenerDispatcher // dispatchers of the state listeners
+	dispatchersLock sync.Mutex
+
+	subscribers     map[*subscription]struct{} // see "h.Subscribe()"
+	subscribersLock sync.Mutex
+
+	removed    map[string]time.Time // removal time of expired checks by name; guarded by statesLock
+	histories  map[string]*history  // latest results by check name; guarded by statesLock
+	sampler    sampler              // sampled history, see "h.SampledHistory()"
+	expiryDone chan struct{}        // stops the sweeper of "StateTTL"; guarded by configsLock
 }
 
 // New returns a new instance of the Health struct.
@@ -136,9 +556,12 @@ func New() *Health {
 		Logger:     log.NewSimple(),
 		configs:    make([]*Config, 0),
 		states:     make(map[string]State, 0),
-		runners:    make(map[string]chan struct{}, 0),
+		runners:    make(map[string]*runner, 0),
 		active:     newBool(),
 		statesLock: sync.Mutex{},
+
+		incidents:     make([]*Incident, 0),
+		openIncidents: make(map[string]*Incident, 0),
 	}
 }
 
@@ -149,53 +572,259 @@ func (h *Health) DisableLogging() {
 
 // AddChecks is used for adding multiple check definitions at once (as opposed
 // to adding them sequentially via "AddCheck()").
+//
+// If the healthcheck is already running, the checks are validated and started
+// right away, ie. for connections that are opened after "h.Start()".
 func (h *Health) AddChecks(cfgs []*Config) error {
-	if h.active.val() {
-		return ErrNoAddCfgWhenActive
+	h.configsLock.Lock()
+	defer h.configsLock.Unlock()
+
+	if !h.active.val() {
+		h.configs = append(h.configs, cfgs...)
+		return nil
+	}
+
+	configs := make([]*Config, 0, len(h.configs)+len(cfgs))
+	configs = append(configs, h.configs...)
+	configs = append(configs, cfgs...)
+
+	if err := validateConfigs(configs, h.Workers); err != nil {
+		return fmt.Errorf("Unable to add check(s): %v", err)
 	}
 
-	h.configs = append(h.configs, cfgs...)
+	h.configs = configs
+
+	for _, c := range cfgs {
+		h.startCheck(c)
+	}
 
 	return nil
 }
 
 // AddCheck is used for adding a single check definition to the current health
-// instance.
+// instance (see "AddChecks()").
 func (h *Health) AddCheck(cfg *Config) error {
-	if h.active.val() {
-		return ErrNoAddCfgWhenActive
+	return h.AddChecks([]*Config{cfg})
+}
+
+// RemoveCheck removes the check with the given name; if the healthcheck is
+// running, the check is stopped and its state is discarded (an execution that
+// is still in flight is not recorded). A check cannot be removed while other
+// checks depend on it.
+func (h *Health) RemoveCheck(name string) error {
+	h.configsLock.Lock()
+	defer h.configsLock.Unlock()
+
+	configs := make([]*Config, 0, len(h.configs))
+	found := false
+
+	for _, c := range h.configs {
+		if c.Name == name {
+			found = true
+			continue
+		}
+
+		for _, dep := range c.DependsOn {
+			if dep == name {
+				return fmt.Errorf("Unable to remove check: check '%v' depends on '%v'", c.Name, name)
+			}
+		}
+
+		configs = append(configs, c)
+	}
+
+	if !found {
+		return ErrCheckNotFound
+	}
+
+	h.configs = configs
+
+	if h.stopCheck(name) {
+		h.discardState(name)
 	}
 
-	h.configs = append(h.configs, cfg)
 	return nil
 }
 
+// stops the runner of the check; returns false if the check was not running
+func (h *Health) stopCheck(name string) bool {
+	h.runnersLock.Lock()
+	r, ok := h.runners[name]
+	delete(h.runners, name)
+	h.runnersLock.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	if r.refresh != nil {
+		atomic.AddInt64(&h.lazyChecks, -1)
+	}
+
+	h.Logger.WithFields(log.Fields{"name": name}).Debug("Stopping checker")
+	h.logEvent(context.Background(), LogEventLifecycle, "Checker stopped", slog.String("check", name))
+
+	// wait for a paused state that is being recorded, so that it is not
+	// recorded after the state has been discarded
+	r.pauseLock.Lock()
+	r.halt()
+	r.pauseLock.Unlock()
+
+	return true
+}
+
+// discards (or expires, see "StateTTL") the state of a removed check and
+// resolves its incident
+func (h *Health) discardState(name string) {
+	h.statesLock.Lock()
+	state, hasState := h.states[name]
+	if h.StateTTL > 0 && hasState {
+		// kept (as expired) until the TTL has elapsed
+		now := h.clock().Now()
+		prevStatus := h.overallStatus()
+
+		state = h.expireState(state, now, "Check has been removed")
+
+		if h.removed == nil {
+			h.removed = make(map[string]time.Time)
+		}
+		h.removed[name] = now
+
+		if status := h.overallStatus(); status != prevStatus {
+			h.dispatchStateEvent("overall_state_change", func(l IStateListener) { l.OnOverallStateChange(prevStatus, status) })
+		}
+	} else {
+		h.deleteState(name)
+	}
+	h.statesLock.Unlock()
+
+	if h.StateTTL > 0 && hasState {
+		h.handleExpiryHooks(&state)
+	}
+
+	h.resolveIncident(name, h.clock().Now())
+}
+
 // Start will start all of the defined health checks. Each of the checks run in
-// their own goroutines (as "time.Ticker").
+// their own goroutines (as "time.Ticker"); all checks are executed right away
+// (in parallel), unless they have an "InitialDelay" or are lazy ("CacheTTL").
+//
+// If "WaitOnStart" is set, Start blocks until every check has completed its
+// first execution (or "StartTimeout" has elapsed), so that the states are
+// populated before the service begins accepting traffic. Checks with an
+// "InitialDelay", lazy and chained checks are not waited for.
 func (h *Health) Start() error {
+	runners, err := h.start()
+	if err != nil || !h.WaitOnStart {
+		return err
+	}
+
+	var timeout <-chan time.Time
+	if h.StartTimeout > 0 {
+		timer := time.NewTimer(h.StartTimeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	for _, r := range runners {
+		// checks with an initial delay, lazy and chained checks are not
+		// executed on start
+		if r.cfg.InitialDelay > 0 || r.refresh != nil || r.trigger != nil {
+			continue
+		}
+
+		select {
+		case <-r.firstRun:
+		case <-timeout:
+			return ErrStartTimeout
+		}
+	}
+
+	return nil
+}
+
+// starts all checks; returns their runners
+func (h *Health) start() ([]*runner, error) {
+	h.configsLock.Lock()
+	defer h.configsLock.Unlock()
+
 	if h.active.val() {
-		return ErrAlreadyRunning
+		return nil, ErrAlreadyRunning
 	}
 
 	// if there are no check configs, this is a noop
 	if len(h.configs) < 1 {
-		return nil
+		return nil, nil
 	}
 
-	for _, c := range h.configs {
-		h.Logger.WithFields(log.Fields{"name": c.Name}).Debug("Starting checker")
-		ticker := time.NewTicker(c.Interval)
-		stop := make(chan struct{})
+	if err := validateConfigs(h.configs, h.Workers); err != nil {
+		return nil, fmt.Errorf("Unable to start healthcheck: %v", err)
+	}
+
+	h.pools = newWorkerPools(h.Workers)
+
+	h.startExpiry()
+	h.startSampler()
 
-		h.startRunner(c, ticker, stop)
+	if h.Scheduler == SchedulerTimingWheel || h.Scheduler == SchedulerWorkerPool {
+		h.wheel = newTimingWheel(h.clock(), h.WheelResolution)
+	}
+
+	if h.Scheduler == SchedulerWorkerPool {
+		h.queue = newWorkQueue(h.Concurrency)
+	}
 
-		h.runners[c.Name] = stop
+	h.startStateListeners()
+
+	runners := make([]*runner, 0, len(h.configs))
+	for _, c := range h.configs {
+		runners = append(runners, h.startCheck(c))
 	}
 
 	// Checkers are now actively running
 	h.active.setTrue()
 
-	return nil
+	h.logEvent(context.Background(), LogEventLifecycle, "Healthcheck started", slog.Int("checks", len(h.configs)))
+
+	return runners, nil
+}
+
+// validates the check configs as a whole
+func validateConfigs(cfgs []*Config, workers map[Priority]int) error {
+	names := make(map[string]bool, len(cfgs))
+	for _, c := range cfgs {
+		if names[c.Name] {
+			return fmt.Errorf("Check '%v' is defined more than once", c.Name)
+		}
+		names[c.Name] = true
+	}
+
+	if err := validateDependencies(cfgs); err != nil {
+		return err
+	}
+
+	if err := validateSchedules(cfgs); err != nil {
+		return err
+	}
+
+	if err := validateCacheTTLs(cfgs); err != nil {
+		return err
+	}
+
+	if err := validateStartupChecks(cfgs); err != nil {
+		return err
+	}
+
+	if err := validateChainedChecks(cfgs); err != nil {
+		return err
+	}
+
+	if err := validateWeights(cfgs); err != nil {
+		return err
+	}
+
+	return validatePriorities(cfgs, workers)
 }
 
 // Stop will cause all of the running health checks to be stopped. Additionally,
@@ -205,17 +834,44 @@ func (h *Health) Stop() error {
 		return ErrAlreadyStopped
 	}
 
-	for name, stop := range h.runners {
+	h.runnersLock.Lock()
+	for name, r := range h.runners {
 		h.Logger.WithFields(log.Fields{"name": name}).Debug("Stopping checker")
-		close(stop)
+		h.logEvent(context.Background(), LogEventLifecycle, "Checker stopped", slog.String("check", name))
+		r.halt()
 	}
 
 	// Reset runner map
-	h.runners = make(map[string]chan struct{}, 0)
+	h.runners = make(map[string]*runner, 0)
+	atomic.StoreInt64(&h.lazyChecks, 0)
+	h.runnersLock.Unlock()
+
+	h.configsLock.Lock()
+	if h.wheel != nil {
+		h.wheel.stop()
+		h.wheel = nil
+	}
+
+	if h.queue != nil {
+		h.queue.stop()
+		h.queue = nil
+	}
+
+	h.stopExpiry()
+	h.stopSampler()
+	h.configsLock.Unlock()
 
 	// Reset states
 	h.safeResetStates()
 
+	// Already queued events are still delivered
+	h.stopStateListeners()
+
+	// Incidents are retained, but can no longer be resolved by a recovery
+	h.resolveAllIncidents()
+
+	h.logEvent(context.Background(), LogEventLifecycle, "Healthcheck stopped")
+
 	return nil
 }
 
@@ -226,132 +882,640 @@ func (h *Health) Stop() error {
 // used for building your own status handler (as opposed to using the built-in
 // "hc.HandlerBasic" or "hc.HandlerJSON").
 //
-// The map key is the name of the check.
-func (h *Health) State() (map[string]State, bool, error) {
-	return h.safeGetStates(), h.Failed(), nil
+// The map key is the name of the check. Pass "WithTags()" to only include a
+// subset of the checks (the failure status is then based on that subset).
+// While the maintenance mode is on, the failure status is forced (see
+// "h.SetMaintenanceMode()").
+//
+// The states are deep copies (including their "Details"), so they can be
+// modified or serialized without racing with the execution of the checks.
+// Copying the details is not free; endpoints that are probed at a high rate
+// should cache their responses until the states change (see
+// "h.StateVersion()"), as the bundled handlers do.
+func (h *Health) State(opts ...StateOption) (map[string]State, bool, error) {
+	h.refreshLazyChecks(opts)
+
+	states := filterStates(h.snapshotStates(), opts)
+
+	failed := h.aggregate(states)
+	if m, ok := h.Maintenance(); ok {
+		failed = m.Status == "failed"
+	}
+
+	copyStates(states)
+
+	return states, failed, nil
 }
 
 // Failed will return the basic state of overall health. This should be used when
-// details about the failure are not needed
+// details about the failure are not needed; forced while the maintenance mode
+// is on (see "h.SetMaintenanceMode()")
 func (h *Health) Failed() bool {
-	for _, val := range h.safeGetStates() {
-		if val.Fatal && val.isFailure() {
-			return true
+	h.refreshLazyChecks(nil)
+
+	if m, ok := h.Maintenance(); ok {
+		return m.Status == "failed"
+	}
+
+	if h.Aggregation != nil {
+		return h.Aggregation.Failed(h.snapshotStates())
+	}
+
+	return atomic.LoadInt64(&h.fatalFailures) > 0
+}
+
+// Changes will return the states of the checks whose outcome (status, error or
+// warning) has changed after "since" (thread-safe). This allows pollers that
+// sync the state of many instances to only fetch what has changed.
+//
+// The map key is the name of the check; the states are deep copies (see
+// "h.State()").
+func (h *Health) Changes(since time.Time) map[string]State {
+	changes := make(map[string]State, 0)
+
+	for k, v := range h.snapshotStates() {
+		if v.ChangedAt.After(since) {
+			changes[k] = v
 		}
 	}
-	return false
+
+	copyStates(changes)
+
+	return changes
+}
+
+// starts the runner of the check
+func (h *Health) startCheck(cfg *Config) *runner {
+	h.Logger.WithFields(log.Fields{"name": cfg.Name}).Debug("Starting checker")
+	h.logEvent(context.Background(), LogEventLifecycle, "Checker started", slog.String("check", cfg.Name))
+
+	r := h.startRunner(cfg)
+
+	h.runnersLock.Lock()
+	h.runners[cfg.Name] = r
+	h.runnersLock.Unlock()
+
+	if r.refresh != nil {
+		atomic.AddInt64(&h.lazyChecks, 1)
+	}
+
+	return r
 }
 
-func (h *Health) startRunner(cfg *Config, ticker *time.Ticker, stop <-chan struct{}) {
+// startRunner starts the periodic execution of the check; the returned runner
+// also executes the check synchronously (serialized with the periodic executions)
+func (h *Health) startRunner(cfg *Config) *runner {
+	timing := h.resolveTiming(cfg)
+	clock := h.clock()
+
+	r := &runner{cfg: cfg, timing: timing, firstRun: make(chan struct{})}
+
+	// the config with the effective interval and timeout
+	resolved := *cfg
+	resolved.Interval = timing.Interval
+	resolved.Timeout = timing.Timeout
+
+	// delivers the ticks of the check; assigned before the first execution
+	var sched schedule
+
+	var adapter *intervalAdapter
+	if cfg.AdaptiveInterval {
+		adapter = newIntervalAdapter(&resolved)
+	}
+
+	var backoff *failureBackoff
+	if cfg.Backoff {
+		backoff = newFailureBackoff(&resolved)
+	}
+
+	// the (possibly adapted) interval before jitter is applied
+	baseInterval := int64(timing.Interval)
+
+	nextInterval := func() time.Duration {
+		return jitterInterval(time.Duration(atomic.LoadInt64(&baseInterval)), cfg.Jitter)
+	}
+
+	// the drift of the ticks is only tracked for checks that run on an interval
+	drifting := cfg.Schedule == "" && !cfg.Chained
+
+	// the next tick is delivered after "d"
+	resetSchedule := func(d time.Duration) {
+		sched.reset(d)
+
+		if drifting {
+			r.drift.expect(clock.Now(), d)
+		}
+	}
+
+	// whether the previous execution of the check has failed
+	failing := false
+
+	// number of consecutive executions that returned an error (or not)
+	var consecutiveErrors, consecutiveSuccesses int64
+
+	// durations of the latest executions, see "Health.LatencyWindow"
+	var latency *latencyWindow
+	if h.LatencyWindow > 0 {
+		latency = newLatencyWindow(h.LatencyWindow)
+	}
 
 	// function to execute and collect check data
-	checkFunc := func() {
-		data, err := cfg.Checker.Status()
+	checkFunc := func(ctx context.Context) State {
+		// do not execute the check while a dependency has failed
+		if dep := h.failedDependency(cfg); dep != "" {
+			stateEntry := acquireState()
+			defer releaseState(stateEntry)
+
+			*stateEntry = State{
+				Name:      cfg.Name,
+				Status:    "skipped",
+				Err:       fmt.Sprintf("skipped (dependency '%v' failed)", dep),
+				CheckTime: clock.Now(),
+				Fatal:     cfg.isCritical(),
+				Tags:      cfg.Tags,
+
+				MissedTicks:          atomic.LoadInt64(&r.missedTicks),
+				ConsecutiveErrors:    consecutiveErrors,
+				ConsecutiveSuccesses: consecutiveSuccesses,
+			}
+
+			r.setOutputs(nil)
+
+			r.record(func() {
+				h.safeUpdateState(stateEntry)
+				h.handleResultHooks(stateEntry)
+			})
+
+			h.triggerChained(cfg.Name)
+
+			return *stateEntry
+		}
+
+		ctx, span := h.startSpan(ctx, cfg)
+		ctx, outputs := h.withPipeline(ctx, cfg)
+
+		h.logEvent(ctx, LogEventCheckStart, "Check started", slog.String("check", cfg.Name))
+
+		start := clock.Now()
+		timeout := callTimeout(ctx, timing.Timeout)
+
+		data, err, done := callCheckerWithRetry(ctx, cfg, clock, timeout)
+
+		// do not start the next execution before an aborted one has returned
+		defer func() { <-done }()
+
+		// a panic of the checker is recorded as a failure, along with its stack
+		if p, ok := err.(*PanicError); ok {
+			h.handlePanic(cfg.Name, p)
+			data = map[string]string{"panic": fmt.Sprint(p.Value), "stack": string(p.Stack)}
+		}
+
+		// a degraded dependency is usable, ie. the check has not failed
+		var degradedErr error
+		if err != nil && isDegradedError(err) {
+			degradedErr, err = err, nil
+		}
+
+		// a failing check must also pass the recovery probe to be marked healthy
+		if err == nil && failing && cfg.RecoveryProbe != nil {
+			_, probeErr, probeDone := callChecker(ctx, cfg.RecoveryProbe, timeout)
+			defer func() { <-probeDone }()
+
+			if p, ok := probeErr.(*PanicError); ok {
+				h.handlePanic(cfg.Name, p)
+			}
+
+			if probeErr != nil {
+				err = fmt.Errorf("recovery probe failed: %v", probeErr)
+			}
+		}
+
+		stateEntry := acquireState()
+		defer releaseState(stateEntry)
 
-		stateEntry := &State{
+		*stateEntry = State{
 			Name:      cfg.Name,
 			Status:    "ok",
 			Details:   data,
-			CheckTime: time.Now(),
-			Fatal:     cfg.Fatal,
+			CheckTime: clock.Now(),
+			Fatal:     cfg.isCritical(),
+			Tags:      cfg.Tags,
+			Duration:  clock.Now().Sub(start),
+
+			MissedTicks: atomic.LoadInt64(&r.missedTicks),
+
+			downgradable: cfg.DowngradeDuringDeploy,
+			weight:       cfg.Weight,
 		}
 
 		if err != nil {
 			h.Logger.WithFields(log.Fields{
 				"check": cfg.Name,
-				"fatal": cfg.Fatal,
+				"fatal": cfg.isCritical(),
 				"err":   err,
 			}).Error("healthcheck has failed")
 
+			consecutiveErrors++
+			consecutiveSuccesses = 0
+
 			stateEntry.Err = err.Error()
-			stateEntry.Status = "failed"
+			stateEntry.TimedOut = err == ErrCheckTimeout
+
+			// only mark the check as failed once the failure threshold is reached
+			if failing || consecutiveErrors >= int64(cfg.FailureThreshold) {
+				stateEntry.Status = "failed"
+			}
+		} else {
+			consecutiveErrors = 0
+			consecutiveSuccesses++
+
+			// a failed check stays failed until the success threshold is reached
+			if failing && consecutiveSuccesses < int64(cfg.SuccessThreshold) {
+				stateEntry.Status = "failed"
+				stateEntry.Err = fmt.Sprintf("awaiting %d consecutive successes to recover", cfg.SuccessThreshold)
+			} else if degradedErr != nil {
+				stateEntry.Status = "degraded"
+				stateEntry.Err = degradedErr.Error()
+			}
+		}
+
+		stateEntry.ConsecutiveErrors = consecutiveErrors
+		stateEntry.ConsecutiveSuccesses = consecutiveSuccesses
+
+		// the values of a failed execution are discarded
+		if values := outputs.seal(); err == nil {
+			r.setOutputs(values)
+		} else {
+			r.setOutputs(nil)
+		}
+
+		if latency != nil {
+			latency.observe(stateEntry.Duration)
+			stateEntry.Latency = latency.stats()
+		}
+
+		endSpan(span, stateEntry)
+		h.logResult(ctx, stateEntry, err, failing)
+
+		failing = stateEntry.isFailure()
+
+		interval := timing.Interval
+
+		if adapter != nil {
+			if adapted, changed := adapter.observe(clock.Now().Sub(start)); changed {
+				h.Logger.WithFields(log.Fields{
+					"check":    cfg.Name,
+					"interval": adapted,
+				}).Warn("adapted check interval")
+			}
+
+			interval = adapter.current
+			stateEntry.Warning = adapter.warning()
+		}
+
+		if backoff != nil {
+			var changed bool
+			if interval, changed = backoff.observe(failing, interval); changed && failing {
+				h.Logger.WithFields(log.Fields{
+					"check":    cfg.Name,
+					"interval": interval,
+				}).Warn("backed off failing check interval")
+			}
+
+			if stateEntry.Warning == "" {
+				stateEntry.Warning = backoff.warning()
+			}
 		}
 
-		h.safeUpdateState(stateEntry)
+		if int64(interval) != atomic.LoadInt64(&baseInterval) {
+			atomic.StoreInt64(&baseInterval, int64(interval))
+			resetSchedule(nextInterval())
+		}
+
+		recorded := false
+		r.record(func() {
+			h.safeUpdateState(stateEntry)
+			h.handleResultHooks(stateEntry)
+			recorded = true
+		})
+
+		// a startup check is not executed again once it has succeeded
+		if cfg.Startup && recorded && err == nil && !stateEntry.isFailure() && r.pass() {
+			sched.stop()
+
+			h.Logger.WithFields(log.Fields{"name": cfg.Name}).Info("Startup check passed")
+			h.logEvent(ctx, LogEventLifecycle, "Startup check passed", slog.String("check", cfg.Name))
+		}
+
+		h.triggerChained(cfg.Name)
+
+		return *stateEntry
 	}
 
-	go func() {
-		defer ticker.Stop()
+	// at most one execution of the check is in flight; concurrent callers
+	// (ie. "h.RunCheck()" while the timer fires) share its state
+	var flightLock sync.Mutex
+	var inFlightExec *flight
+
+	pool := h.pools[cfg.Priority]
+
+	r.exec = func(ctx context.Context) State {
+		// a paused check (or a passed startup check) is not executed
+		if r.isPaused() || r.hasPassed() {
+			state, _ := h.lookupState(cfg.Name)
+			return state
+		}
+
+		flightLock.Lock()
+		if f := inFlightExec; f != nil {
+			flightLock.Unlock()
+			atomic.AddInt64(&r.coalesced, 1)
+
+			return f.wait(ctx, func() State {
+				state, _ := h.lookupState(cfg.Name)
+				return state
+			})
+		}
+
+		f := &flight{done: make(chan struct{})}
+		inFlightExec = f
+		flightLock.Unlock()
+
+		done := h.executions.begin()
+		defer done()
 
-		// execute once so that it is immediate
-		checkFunc()
+		atomic.AddInt64(&r.active, 1)
+		defer atomic.AddInt64(&r.active, -1)
 
-		// all following executions
-	RunLoop:
-		for {
-			select {
-			case <-ticker.C:
-				checkFunc()
-			case <-stop:
-				break RunLoop
+		// the execution has landed before it stops being active
+		defer func() {
+			flightLock.Lock()
+			inFlightExec = nil
+			flightLock.Unlock()
+
+			close(f.done)
+		}()
+
+		release := pool.acquire()
+		defer release()
+
+		f.state = checkFunc(ctx)
+
+		return f.state
+	}
+
+	// executions run in their own goroutine (or on the worker pool) so that
+	// the runner can keep track of ticks that fire while a check is in flight
+	queue := h.queue
+	var loopLock sync.Mutex
+	inFlight, queued := false, false
+
+	// the caller must hold loopLock
+	var run func()
+	run = func() {
+		inFlight = true
+		queue.dispatch(func() {
+			r.exec(context.Background())
+			r.firstRunOnce.Do(func() { close(r.firstRun) })
+
+			loopLock.Lock()
+			defer loopLock.Unlock()
+
+			inFlight = false
+			if queued && !r.isStopped() {
+				queued = false
+				run()
 			}
+		})
+	}
+
+	// whether the first tick is yet to be delivered after the initial delay;
+	// guarded by loopLock
+	delayed := cfg.InitialDelay > 0
+
+	// all following executions
+	fire := func() {
+		loopLock.Lock()
+		defer loopLock.Unlock()
+
+		if drifting {
+			r.drift.tick(clock.Now())
+		}
+
+		if cfg.Jitter > 0 || delayed {
+			delayed = false
+			resetSchedule(nextInterval())
+		}
+
+		if inFlight {
+			atomic.AddInt64(&r.missedTicks, 1)
+			queued = cfg.OverlapPolicy == OverlapQueue
+			return
 		}
+		run()
+	}
+
+	// lazy checks are only executed on demand, see "h.refreshLazyChecks()"
+	if cfg.CacheTTL > 0 {
+		sched = noopSchedule{}
+		r.refresh = h.newRefresh(r)
+		r.onStop = func() {
+			h.Logger.WithFields(log.Fields{"name": cfg.Name}).Debug("Checker exiting")
+		}
+
+		return r
+	}
 
+	// chained checks are executed by their dependencies, see
+	// "h.triggerChained()"
+	if cfg.Chained {
+		sched = noopSchedule{}
+		r.trigger = fire
+		r.onStop = func() {
+			h.Logger.WithFields(log.Fields{"name": cfg.Name}).Debug("Checker exiting")
+		}
+
+		return r
+	}
+
+	// the schedule may fire before it has been assigned
+	loopLock.Lock()
+
+	// the first tick is delivered after the initial delay, if any
+	if cfg.Schedule != "" {
+		// validated by "validateSchedules()"
+		expr, _ := parseCron(cfg.Schedule)
+		sched = newCronSchedule(h.clock(), expr, cfg.InitialDelay, fire)
+	} else if cfg.InitialDelay > 0 {
+		sched = h.newSchedule(cfg.InitialDelay, fire)
+		r.drift.expect(clock.Now(), cfg.InitialDelay)
+	} else {
+		sched = h.newSchedule(timing.Interval, fire)
+		r.drift.expect(clock.Now(), timing.Interval)
+	}
+
+	r.onStop = func() {
+		sched.stop()
 		h.Logger.WithFields(log.Fields{"name": cfg.Name}).Debug("Checker exiting")
-	}()
+	}
+
+	// otherwise, execute once so that it is immediate
+	if cfg.InitialDelay <= 0 {
+		run()
+
+		if cfg.Jitter > 0 {
+			resetSchedule(nextInterval())
+		}
+	}
+
+	loopLock.Unlock()
+
+	return r
 }
 
 // resets the states in a concurrency-safe manner
 func (h *Health) safeResetStates() {
 	h.statesLock.Lock()
 	defer h.statesLock.Unlock()
-	h.states = make(map[string]State, 0)
+	h.clearStates()
 }
 
 // updates the check state in a concurrency-safe manner
 func (h *Health) safeUpdateState(stateEntry *State) {
+	// a downgraded failure neither opens an incident nor notifies the status
+	// listeners (see "handleStatusListener()")
+	stateEntry.Downgraded = h.isDowngraded(stateEntry)
+
 	// dispatch any status listeners
 	h.handleStatusListener(stateEntry)
 
 	// update states here
 	h.statesLock.Lock()
-	defer h.statesLock.Unlock()
 
-	h.states[stateEntry.Name] = *stateEntry
-}
+	prevStatus := h.overallStatus()
 
-// get all states in a concurrency-safe manner
-func (h *Health) safeGetStates() map[string]State {
-	h.statesLock.Lock()
-	defer h.statesLock.Unlock()
+	// a check that has been removed and added again is no longer expired
+	delete(h.removed, stateEntry.Name)
+
+	h.recordHistory(stateEntry)
+	h.recordSample(stateEntry)
 
-	// deep copy h.states to avoid race
-	statesCopy := make(map[string]State, 0)
+	prevState, ok := h.states[stateEntry.Name]
+	changed := !ok || prevState.Status != stateEntry.Status || prevState.Err != stateEntry.Err ||
+		prevState.Warning != stateEntry.Warning
 
-	for k, v := range h.states {
-		statesCopy[k] = v
+	if changed {
+		stateEntry.ChangedAt = stateEntry.CheckTime
+	} else {
+		stateEntry.ChangedAt = prevState.ChangedAt
 	}
 
-	return statesCopy
+	// the checker may keep (and modify) its details, so a copy is recorded
+	h.putState(stateEntry.copy())
+
+	if changed {
+		change := StateChange{
+			Name:      stateEntry.Name,
+			NewState:  *stateEntry,
+			Err:       stateEntry.Err,
+			Timestamp: stateEntry.CheckTime,
+		}
+
+		if ok {
+			oldState := prevState.copy()
+			change.OldState = &oldState
+		}
+
+		h.publishStateChange(change)
+	}
+
+	status := h.overallStatus()
+
+	// dispatch while holding the lock so that events are queued in order
+	state := *stateEntry
+	h.dispatchStateEvent("check_completed", func(l IStateListener) { l.OnCheckCompleted(&state) })
+
+	if status != prevStatus {
+		h.dispatchStateEvent("overall_state_change", func(l IStateListener) { l.OnOverallStateChange(prevStatus, status) })
+	}
+
+	h.statesLock.Unlock()
 }
 
 // if a status listener is attached
 func (h *Health) handleStatusListener(stateEntry *State) {
 	// get the previous state
-	h.statesLock.Lock()
-	prevState := h.states[stateEntry.Name]
-	h.statesLock.Unlock()
+	prevState, _ := h.lookupState(stateEntry.Name)
+
+	// a skipped (or paused) check carries over the failure of its previous
+	// state, as does a failure that is downgraded during a deploy. The failures
+	// of checks that can be downgraded only count once they have been reported
+	// (ie. have opened an incident), since they may have been recorded during
+	// a deploy.
+	prevFailing := (prevState.isFailure() && (!prevState.downgradable || prevState.IncidentID != "")) ||
+		((prevState.isSkipped() || prevState.isPaused()) && prevState.IncidentID != "")
+
+	if stateEntry.isSkipped() || stateEntry.isPaused() || stateEntry.Downgraded {
+		if prevFailing {
+			stateEntry.IncidentID = prevState.IncidentID
+			stateEntry.TimeOfFirstFailure = prevState.TimeOfFirstFailure
+			stateEntry.ContiguousFailures = prevState.ContiguousFailures
+		}
+
+		return
+	}
 
 	// state is failure
 	if stateEntry.isFailure() {
-		if !prevState.isFailure() {
-			// new failure: previous state was ok
-			if h.StatusListener != nil {
-				go h.StatusListener.HealthCheckFailed(stateEntry)
-			}
+		stateEntry.IncidentID = h.recordIncidentFailure(stateEntry)
 
-			stateEntry.TimeOfFirstFailure = time.Now()
+		if !prevFailing {
+			// new failure: previous state was ok
+			stateEntry.TimeOfFirstFailure = h.clock().Now()
 		} else {
 			// carry the time of first failure from the previous state
 			stateEntry.TimeOfFirstFailure = prevState.TimeOfFirstFailure
 		}
 		stateEntry.ContiguousFailures = prevState.ContiguousFailures + 1
-	} else if prevState.isFailure() {
+
+		if !prevFailing {
+			// the listeners get copies, since the state is recycled (see
+			// "acquireState()")
+			if h.StatusListener != nil {
+				failed := *stateEntry
+				go h.StatusListener.HealthCheckFailed(&failed)
+			}
+
+			state := *stateEntry
+			h.dispatchStateEvent("check_failed", func(l IStateListener) { l.OnCheckFailed(&state) })
+		}
+	} else if prevFailing {
 		// recovery, previous state was failure
-		failureSeconds := time.Now().Sub(prevState.TimeOfFirstFailure).Seconds()
+		failureSeconds := h.clock().Now().Sub(prevState.TimeOfFirstFailure).Seconds()
+
+		stateEntry.IncidentID = h.resolveIncident(stateEntry.Name, stateEntry.CheckTime)
 
 		if h.StatusListener != nil {
-			go h.StatusListener.HealthCheckRecovered(stateEntry, prevState.ContiguousFailures, failureSeconds)
+			recovered := *stateEntry
+			go h.StatusListener.HealthCheckRecovered(&recovered, prevState.ContiguousFailures, failureSeconds)
 		}
+
+		state, recordedFailures := *stateEntry, prevState.ContiguousFailures
+		h.dispatchStateEvent("check_recovered", func(l IStateListener) {
+			l.OnCheckRecovered(&state, recordedFailures, failureSeconds)
+		})
+	}
+}
+
+// dispatches the state of a completed check to the result hooks (if any)
+func (h *Health) handleResultHooks(stateEntry *State) {
+	for _, hook := range h.ResultHooks {
+		state := *stateEntry
+		atomic.AddInt64(&h.pendingHooks, 1)
+
+		go func(hook IResultHook) {
+			defer atomic.AddInt64(&h.pendingHooks, -1)
+			hook.CheckCompleted(&state)
+		}(hook)
 	}
 }
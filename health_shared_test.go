@@ -2,12 +2,45 @@ package health
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/InVisionApp/go-health/fakes"
 	"github.com/InVisionApp/go-logger"
 )
 
+// toggleChecker is a checker whose result can be changed while its check is
+// running; the fakes must not be changed once the checks have been started
+type toggleChecker struct {
+	result atomic.Value // toggleResult
+	calls  int64
+}
+
+type toggleResult struct {
+	err error
+}
+
+func newToggleChecker(err error) *toggleChecker {
+	c := &toggleChecker{}
+	c.SetErr(err)
+
+	return c
+}
+
+func (c *toggleChecker) Status() (interface{}, error) {
+	atomic.AddInt64(&c.calls, 1)
+	return nil, c.result.Load().(toggleResult).err
+}
+
+// SetErr sets the error returned by the following executions (nil succeeds)
+func (c *toggleChecker) SetErr(err error) {
+	c.result.Store(toggleResult{err: err})
+}
+
+func (c *toggleChecker) StatusCallCount() int {
+	return int(atomic.LoadInt64(&c.calls))
+}
+
 func setupRunners(cfgs []*Config, logger log.Logger) (*Health, []*Config, error) {
 	h := New()
 	testCheckInterval := time.Duration(10) * time.Millisecond
@@ -53,3 +86,18 @@ func setupRunners(cfgs []*Config, logger log.Logger) (*Health, []*Config, error)
 
 	return h, cfgs, nil
 }
+
+// waits for the first execution of every check to complete, so that the
+// following executions are not coalesced with it
+func waitFirstRuns(h *Health) {
+	h.runnersLock.Lock()
+	runners := make([]*runner, 0, len(h.runners))
+	for _, r := range h.runners {
+		runners = append(runners, r)
+	}
+	h.runnersLock.Unlock()
+
+	for _, r := range runners {
+		<-r.firstRun
+	}
+}
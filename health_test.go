@@ -3,6 +3,7 @@ package health
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -68,13 +69,43 @@ func TestAddChecks(t *testing.T) {
 		Expect(len(h.configs)).To(Equal(1))
 	})
 
-	t.Run("Should error if healthcheck is already running", func(t *testing.T) {
-		h := setupNewTestHealth()
-		h.active.setTrue()
-		err := h.AddChecks([]*Config{})
+	t.Run("Should start the checks if healthcheck is already running", func(t *testing.T) {
+		h, _, err := setupRunners(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		checker := &fakes.FakeICheckable{}
+		err = h.AddChecks([]*Config{{Name: "baz", Checker: checker, Interval: testCheckInterval}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(checker.StatusCallCount).Should(BeNumerically(">=", 2))
+		Eventually(func() map[string]State { states, _, _ := h.State(); return states }).Should(HaveKey("baz"))
+		Expect(h.configs).To(HaveLen(3))
+	})
+
+	t.Run("Should error if the checks are invalid and healthcheck is already running", func(t *testing.T) {
+		h, _, err := setupRunners(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		err = h.AddChecks([]*Config{{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Check 'foo' is defined more than once"))
+
+		// a duplicate within the added checks
+		err = h.AddChecks([]*Config{
+			{Name: "baz", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+			{Name: "baz", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Check 'baz' is defined more than once"))
 
+		err = h.AddChecks([]*Config{{Name: "baz", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval, DependsOn: []string{"qux"}}})
 		Expect(err).To(HaveOccurred())
-		Expect(err).To(Equal(ErrNoAddCfgWhenActive))
+		Expect(err.Error()).To(ContainSubstring("Check 'baz' depends on unknown check 'qux'"))
+
+		Expect(h.configs).To(HaveLen(2))
+		Expect(h.runners).To(HaveLen(2))
 	})
 
 	t.Run("Should not error if passed in empty config slice", func(t *testing.T) {
@@ -104,13 +135,111 @@ func TestAddCheck(t *testing.T) {
 		Expect(len(h.configs)).To(Equal(1))
 	})
 
-	t.Run("Should error if healthcheck is already running", func(t *testing.T) {
+	t.Run("Should start the check if healthcheck is already running", func(t *testing.T) {
+		h, _, err := setupRunners(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		checker := &fakes.FakeICheckable{}
+		Expect(h.AddCheck(&Config{Name: "baz", Checker: checker, Interval: testCheckInterval})).To(Succeed())
+
+		Eventually(checker.StatusCallCount).Should(BeNumerically(">=", 2))
+
+		// the results of the check are reported
+		Eventually(func() string { states, _, _ := h.State(); return states["baz"].Status }).Should(Equal("ok"))
+
+		result, err := h.RunCheck("baz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Name).To(Equal("baz"))
+		Expect(h.configs).To(HaveLen(3))
+	})
+
+	t.Run("Should error on a duplicate name if healthcheck is already running", func(t *testing.T) {
+		h, _, err := setupRunners(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		checker := &fakes.FakeICheckable{}
+		err = h.AddCheck(&Config{Name: "foo", Checker: checker, Interval: testCheckInterval})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Check 'foo' is defined more than once"))
+
+		// the duplicate is neither scheduled nor does it replace the running check
+		Consistently(checker.StatusCallCount, 25*time.Millisecond).Should(Equal(0))
+		Expect(h.configs).To(HaveLen(2))
+		Expect(h.runners).To(HaveLen(2))
+	})
+}
+
+func TestRemoveCheck(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should remove the config of a non-running healthcheck", func(t *testing.T) {
 		h := setupNewTestHealth()
-		h.active.setTrue()
-		err := h.AddCheck(&Config{})
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval})
+
+		Expect(h.RemoveCheck("foo")).To(Succeed())
+		Expect(h.configs).To(BeEmpty())
+	})
+
+	t.Run("Should stop the check and discard its state", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker, Interval: testCheckInterval, Fatal: true},
+			{Name: "bar", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(h.Failed).Should(BeTrue())
+
+		Expect(h.RemoveCheck("foo")).To(Succeed())
+
+		// let an execution that was in flight complete
+		time.Sleep(5 * time.Millisecond)
+
+		calls := checker.StatusCallCount()
+		Consistently(checker.StatusCallCount, 25*time.Millisecond).Should(Equal(calls))
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states).ToNot(HaveKey("foo"))
+		Expect(states).To(HaveKey("bar"))
+
+		// the incident of the removed check is resolved
+		Expect(h.Incidents()).To(HaveLen(1))
+		Expect(h.Incidents()[0].Open()).To(BeFalse())
+
+		_, err = h.RunCheck("foo")
+		Expect(err).To(Equal(ErrCheckNotFound))
+
+		// the check can be added again
+		Expect(h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval})).To(Succeed())
+		Eventually(func() map[string]State { states, _, _ := h.State(); return states }).Should(HaveKey("foo"))
+	})
+
+	t.Run("Should error if the check does not exist", func(t *testing.T) {
+		h, _, err := setupRunners(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Expect(h.RemoveCheck("baz")).To(Equal(ErrCheckNotFound))
+	})
 
+	t.Run("Should error if other checks depend on the check", func(t *testing.T) {
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+			{Name: "bar", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval, DependsOn: []string{"foo"}},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		err = h.RemoveCheck("foo")
 		Expect(err).To(HaveOccurred())
-		Expect(err).To(Equal(ErrNoAddCfgWhenActive))
+		Expect(err.Error()).To(ContainSubstring("check 'bar' depends on 'foo'"))
+		Expect(h.runners).To(HaveKey("foo"))
 	})
 }
 
@@ -362,6 +491,52 @@ func TestStart(t *testing.T) {
 		err = h.Start()
 		Expect(err).To(Equal(ErrAlreadyRunning))
 	})
+
+	t.Run("Should block until the first execution of every check has completed", func(t *testing.T) {
+		var running, maxRunning int64
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+
+		for _, name := range []string{"foo", "bar", "baz"} {
+			h.AddCheck(&Config{
+				Name:     name,
+				Checker:  &slowChecker{delay: 20 * time.Millisecond, running: &running, maxRunning: &maxRunning},
+				Interval: time.Minute,
+			})
+		}
+
+		start := time.Now()
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		// the checks are executed in parallel
+		Expect(time.Since(start)).To(BeNumerically("<", 50*time.Millisecond))
+		Expect(atomic.LoadInt64(&maxRunning)).To(Equal(int64(3)))
+
+		states, _, _ := h.State()
+		Expect(states).To(HaveLen(3))
+	})
+
+	t.Run("Should error if the first execution does not complete in time", func(t *testing.T) {
+		var running, maxRunning int64
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.StartTimeout = 10 * time.Millisecond
+
+		h.AddCheck(&Config{
+			Name:     "foo",
+			Checker:  &slowChecker{delay: 50 * time.Millisecond, running: &running, maxRunning: &maxRunning},
+			Interval: time.Minute,
+		})
+
+		Expect(h.Start()).To(Equal(ErrStartTimeout))
+		defer h.Stop()
+
+		// the checks keep running
+		Eventually(func() map[string]State { states, _, _ := h.State(); return states }).Should(HaveKey("foo"))
+	})
 }
 
 func TestStop(t *testing.T) {
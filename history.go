@@ -0,0 +1,91 @@
+package health
+
+import (
+	"time"
+)
+
+// HistoryEntry is a past result of a check (see "h.History()").
+type HistoryEntry struct {
+	// Status of the check ("ok", "degraded", "failed", "skipped" or "paused")
+	Status string `json:"status"`
+
+	// Err is the error returned from a failed (or degraded) check
+	Err string `json:"error,omitempty"`
+
+	// Duration is how long the execution of the check took
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// CheckTime is the time of the execution of the check
+	CheckTime time.Time `json:"check_time"`
+}
+
+// history is a ring buffer of the latest results of a check
+type history struct {
+	entries []HistoryEntry
+	next    int // index of the next (and oldest, if full) entry
+	full    bool
+}
+
+func newHistory(size int) *history {
+	return &history{entries: make([]HistoryEntry, size)}
+}
+
+// adds the entry, overwriting the oldest one if the buffer is full
+func (r *history) add(entry HistoryEntry) {
+	r.entries[r.next] = entry
+
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// returns a copy of the entries, oldest first
+func (r *history) list() []HistoryEntry {
+	if !r.full {
+		return append([]HistoryEntry{}, r.entries[:r.next]...)
+	}
+
+	return append(append(make([]HistoryEntry, 0, len(r.entries)), r.entries[r.next:]...), r.entries[:r.next]...)
+}
+
+// History returns the latest "Health.HistorySize" results of the check with
+// the given name, oldest first (thread-safe), so operators can see whether a
+// failure is new or intermittent. Returns nil if the check has no results (or
+// the history is disabled).
+func (h *Health) History(name string) []HistoryEntry {
+	h.statesLock.Lock()
+	defer h.statesLock.Unlock()
+
+	r, ok := h.histories[name]
+	if !ok {
+		return nil
+	}
+
+	return r.list()
+}
+
+// records the state in the history of the check; the caller must hold
+// "statesLock"
+func (h *Health) recordHistory(state *State) {
+	if h.HistorySize <= 0 {
+		return
+	}
+
+	if h.histories == nil {
+		h.histories = make(map[string]*history)
+	}
+
+	r, ok := h.histories[state.Name]
+	if !ok {
+		r = newHistory(h.HistorySize)
+		h.histories[state.Name] = r
+	}
+
+	r.add(HistoryEntry{
+		Status:    state.Status,
+		Err:       state.Err,
+		Duration:  state.Duration,
+		CheckTime: state.CheckTime,
+	})
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestHistory(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should retain the latest results of a check, oldest first", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h := setupNewTestHealth()
+		h.HistorySize = 3
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: checker, Interval: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.History("foo")).To(HaveLen(1))
+
+		for _, err := range []error{errors.New("first"), nil, errors.New("second")} {
+			checker.StatusReturns(nil, err)
+
+			_, runErr := h.RunCheck("foo")
+			Expect(runErr).ToNot(HaveOccurred())
+		}
+
+		history := h.History("foo")
+		Expect(history).To(HaveLen(3))
+		Expect(history[0].Status).To(Equal("failed"))
+		Expect(history[0].Err).To(Equal("first"))
+		Expect(history[1].Status).To(Equal("ok"))
+		Expect(history[1].Err).To(BeEmpty())
+		Expect(history[2].Status).To(Equal("failed"))
+		Expect(history[2].Err).To(Equal("second"))
+		Expect(history[2].CheckTime).ToNot(BeZero())
+		Expect(history[1].CheckTime.After(history[2].CheckTime)).To(BeFalse())
+
+		// the returned history is a copy
+		history[0].Status = "modified"
+		Expect(h.History("foo")[0].Status).To(Equal("failed"))
+	})
+
+	t.Run("Should drop the history of removed checks", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.HistorySize = 3
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.History("foo")).To(HaveLen(1))
+
+		Expect(h.RemoveCheck("foo")).To(Succeed())
+		Expect(h.History("foo")).To(BeNil())
+	})
+
+	t.Run("Should not retain results by default", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.History("foo")).To(BeNil())
+	})
+}
+
+func TestHistoryRing(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := newHistory(2)
+	Expect(r.list()).To(BeEmpty())
+
+	r.add(HistoryEntry{Status: "a"})
+	Expect(r.list()).To(Equal([]HistoryEntry{{Status: "a"}}))
+
+	r.add(HistoryEntry{Status: "b"})
+	r.add(HistoryEntry{Status: "c"})
+	Expect(r.list()).To(Equal([]HistoryEntry{{Status: "b"}, {Status: "c"}}))
+
+	r.add(HistoryEntry{Status: "d"})
+	Expect(r.list()).To(Equal([]HistoryEntry{{Status: "c"}, {Status: "d"}}))
+}
@@ -0,0 +1,188 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const defaultAppDynamicsEventType = "GoHealth"
+
+// AppDynamicsConfig is used for configuring the AppDynamics event hook.
+//
+// "ControllerURL" is _required_; the URL of the AppDynamics controller (ie.
+// `https://example.saas.appdynamics.com`).
+//
+// "Application" is _required_; the name (or ID) of the business application.
+//
+// "Username" is _required_; the API user in `user@account` format.
+//
+// "Password" is _required_; the password of the API user.
+//
+// "Tier" and "Node" are optional; the tier and node the events are correlated with.
+//
+// "CustomEventType" is optional; defaults to `GoHealth`.
+//
+// "Properties" is optional; additional properties added to every event.
+//
+// "Client" is optional; if undefined, a new client will be created using "Timeout".
+//
+// "Timeout" is optional and defaults to "3s".
+//
+// "OnError" is optional; called if an event could not be sent.
+type AppDynamicsConfig struct {
+	ControllerURL   string
+	Application     string
+	Username        string
+	Password        string
+	Tier            string
+	Node            string
+	CustomEventType string
+	Properties      map[string]string
+	Client          *http.Client
+	Timeout         time.Duration
+	OnError         func(err error)
+}
+
+// AppDynamics implements the "health.IStatusListener" interface; it creates a
+// custom event via the AppDynamics controller REST API whenever a check fails
+// or recovers.
+type AppDynamics struct {
+	Config *AppDynamicsConfig
+}
+
+// NewAppDynamics creates a new AppDynamics event hook that can be used as (or
+// called from) a "health.IStatusListener".
+func NewAppDynamics(cfg *AppDynamicsConfig) (*AppDynamics, error) {
+	if err := validateAppDynamicsConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate appdynamics config: %v", err)
+	}
+
+	return &AppDynamics{
+		Config: cfg,
+	}, nil
+}
+
+// HealthCheckFailed creates an "ERROR" event; it satisfies the
+// "health.IStatusListener" interface.
+func (a *AppDynamics) HealthCheckFailed(entry *health.State) {
+	summary := fmt.Sprintf("Health check '%v' has failed: %v", entry.Name, entry.Err)
+	a.send(a.params(entry, "ERROR", summary, nil))
+}
+
+// HealthCheckRecovered creates an "INFO" event; it satisfies the
+// "health.IStatusListener" interface.
+func (a *AppDynamics) HealthCheckRecovered(entry *health.State, recordedFailures int64, failureDurationSeconds float64) {
+	summary := fmt.Sprintf("Health check '%v' has recovered after %v failure(s) (%.2fs)",
+		entry.Name, recordedFailures, failureDurationSeconds)
+
+	a.send(a.params(entry, "INFO", summary, map[string]string{
+		"recordedFailures":       fmt.Sprintf("%d", recordedFailures),
+		"failureDurationSeconds": fmt.Sprintf("%.2f", failureDurationSeconds),
+	}))
+}
+
+func (a *AppDynamics) params(entry *health.State, severity, summary string, extra map[string]string) url.Values {
+	params := url.Values{}
+	params.Set("eventtype", "CUSTOM")
+	params.Set("customeventtype", a.Config.CustomEventType)
+	params.Set("severity", severity)
+	params.Set("summary", summary)
+
+	if a.Config.Tier != "" {
+		params.Set("tier", a.Config.Tier)
+	}
+
+	if a.Config.Node != "" {
+		params.Set("node", a.Config.Node)
+	}
+
+	properties := map[string]string{
+		"check": entry.Name,
+		"fatal": fmt.Sprintf("%v", entry.Fatal),
+	}
+
+	if entry.IncidentID != "" {
+		properties["incidentId"] = entry.IncidentID
+	}
+
+	for _, m := range []map[string]string{a.Config.Properties, extra} {
+		for k, v := range m {
+			properties[k] = v
+		}
+	}
+
+	for k, v := range properties {
+		params.Add("propertynames", k)
+		params.Add("propertyvalues", v)
+	}
+
+	return params
+}
+
+func (a *AppDynamics) send(params url.Values) {
+	if err := a.post(params); err != nil && a.Config.OnError != nil {
+		a.Config.OnError(err)
+	}
+}
+
+func (a *AppDynamics) post(params url.Values) error {
+	endpoint := fmt.Sprintf("%v/controller/rest/applications/%v/events?%v",
+		strings.TrimRight(a.Config.ControllerURL, "/"), url.PathEscape(a.Config.Application), params.Encode())
+
+	req, err := http.NewRequest("POST", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("Unable to create appdynamics request: %v", err)
+	}
+
+	req.SetBasicAuth(a.Config.Username, a.Config.Password)
+
+	resp, err := a.Config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to send appdynamics event: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Appdynamics rejected event with status code '%v'", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func validateAppDynamicsConfig(cfg *AppDynamicsConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.ControllerURL == "" {
+		return errors.New("ControllerURL must be set")
+	}
+
+	if cfg.Application == "" {
+		return errors.New("Application must be set")
+	}
+
+	if cfg.Username == "" || cfg.Password == "" {
+		return errors.New("Username and Password must be set")
+	}
+
+	if cfg.CustomEventType == "" {
+		cfg.CustomEventType = defaultAppDynamicsEventType
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return nil
+}
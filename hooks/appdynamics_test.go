@@ -0,0 +1,106 @@
+package hooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+func TestNewAppDynamics(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error with nil config", func(t *testing.T) {
+		_, err := NewAppDynamics(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+	})
+
+	t.Run("Should error with missing attributes", func(t *testing.T) {
+		_, err := NewAppDynamics(&AppDynamicsConfig{Application: "app"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("ControllerURL must be set"))
+
+		_, err = NewAppDynamics(&AppDynamicsConfig{ControllerURL: "http://controller"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Application must be set"))
+
+		_, err = NewAppDynamics(&AppDynamicsConfig{ControllerURL: "http://controller", Application: "app"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Username and Password must be set"))
+	})
+}
+
+func TestAppDynamicsEvents(t *testing.T) {
+	RegisterTestingT(t)
+
+	type request struct {
+		path   string
+		params url.Values
+		user   string
+	}
+
+	requests := make(chan *request, 10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		requests <- &request{path: r.URL.Path, params: r.URL.Query(), user: user}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	a, err := NewAppDynamics(&AppDynamicsConfig{
+		ControllerURL: ts.URL + "/",
+		Application:   "my app",
+		Username:      "api@account",
+		Password:      "secret",
+		Tier:          "web",
+		Node:          "node-1",
+		Properties:    map[string]string{"env": "prod"},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	var listener health.IStatusListener = a
+
+	propertyValue := func(params url.Values, name string) string {
+		for i, n := range params["propertynames"] {
+			if n == name {
+				return params["propertyvalues"][i]
+			}
+		}
+		return ""
+	}
+
+	t.Run("Should create an error event on failure", func(t *testing.T) {
+		listener.HealthCheckFailed(&health.State{Name: "foo", Err: "things broke", IncidentID: "abc"})
+
+		var req *request
+		Eventually(requests).Should(Receive(&req))
+
+		Expect(req.path).To(Equal("/controller/rest/applications/my app/events"))
+		Expect(req.user).To(Equal("api@account"))
+		Expect(req.params.Get("eventtype")).To(Equal("CUSTOM"))
+		Expect(req.params.Get("customeventtype")).To(Equal("GoHealth"))
+		Expect(req.params.Get("severity")).To(Equal("ERROR"))
+		Expect(req.params.Get("summary")).To(Equal("Health check 'foo' has failed: things broke"))
+		Expect(req.params.Get("tier")).To(Equal("web"))
+		Expect(req.params.Get("node")).To(Equal("node-1"))
+		Expect(propertyValue(req.params, "incidentId")).To(Equal("abc"))
+		Expect(propertyValue(req.params, "env")).To(Equal("prod"))
+	})
+
+	t.Run("Should create an info event on recovery", func(t *testing.T) {
+		listener.HealthCheckRecovered(&health.State{Name: "foo"}, 3, 1.5)
+
+		var req *request
+		Eventually(requests).Should(Receive(&req))
+
+		Expect(req.params.Get("severity")).To(Equal("INFO"))
+		Expect(req.params.Get("summary")).To(Equal("Health check 'foo' has recovered after 3 failure(s) (1.50s)"))
+		Expect(propertyValue(req.params, "recordedFailures")).To(Equal("3"))
+	})
+}
@@ -0,0 +1,322 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const (
+	defaultConsulAddr              = "http://127.0.0.1:8500"
+	defaultConsulTTL               = time.Duration(15) * time.Second
+	defaultConsulHTTPCheckInterval = time.Duration(10) * time.Second
+
+	// Consul check statuses
+	ConsulStatusPassing  = "passing"
+	ConsulStatusWarning  = "warning"
+	ConsulStatusCritical = "critical"
+)
+
+// ConsulConfig is used for configuring the Consul integration.
+//
+// "Addr" is optional; the address of the local Consul agent; defaults to
+// `http://127.0.0.1:8500`.
+//
+// "Token" is optional; the ACL token sent as `X-Consul-Token`.
+//
+// "ServiceName" is _required_; the name of the service to register.
+//
+// "ServiceID" is optional; defaults to "ServiceName".
+//
+// "Address", "Port" and "Tags" are optional; registered along with the service.
+//
+// "TTL" is optional; the TTL of the check that is kept updated with the
+// aggregate health; defaults to "15s". The check is updated every "TTL/2".
+//
+// "HTTPCheckURL" is optional; if set, Consul polls the given URL (ie. the
+// `handlers.NewJSONHandlerFunc` endpoint) every "HTTPCheckInterval" (defaults
+// to "10s") instead of using a TTL check.
+//
+// "DeregisterCriticalAfter" is optional; if set, Consul deregisters the
+// service once its check has been critical for longer than this.
+//
+// "Client" is optional; if undefined, a new client will be created using "Timeout".
+//
+// "Timeout" is optional and defaults to "3s".
+//
+// "OnError" is optional; called if the TTL check could not be updated.
+type ConsulConfig struct {
+	Addr                    string
+	Token                   string
+	ServiceName             string
+	ServiceID               string
+	Address                 string
+	Port                    int
+	Tags                    []string
+	TTL                     time.Duration
+	HTTPCheckURL            string
+	HTTPCheckInterval       time.Duration
+	DeregisterCriticalAfter time.Duration
+	Client                  *http.Client
+	Timeout                 time.Duration
+	OnError                 func(err error)
+}
+
+// Consul registers a service in Consul along with a check reflecting the
+// aggregate health of a "health.IHealth" instance.
+type Consul struct {
+	Config *ConsulConfig
+
+	health health.IHealth
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopLock sync.Mutex
+}
+
+// NewConsul creates a new Consul integration for the given health instance.
+func NewConsul(h health.IHealth, cfg *ConsulConfig) (*Consul, error) {
+	if h == nil {
+		return nil, errors.New("Health instance cannot be nil")
+	}
+
+	if err := validateConsulConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate consul config: %v", err)
+	}
+
+	return &Consul{
+		Config: cfg,
+		health: h,
+	}, nil
+}
+
+// CheckID returns the ID of the Consul check registered along with the service.
+func (c *Consul) CheckID() string {
+	return "go-health:" + c.Config.ServiceID
+}
+
+// Register registers the service (and its check) in Consul. With a TTL check,
+// it also starts updating the check with the aggregate health until
+// "Deregister()" is called.
+func (c *Consul) Register() error {
+	check := map[string]interface{}{
+		"CheckID": c.CheckID(),
+		"Name":    "go-health",
+	}
+
+	if c.Config.HTTPCheckURL != "" {
+		check["HTTP"] = c.Config.HTTPCheckURL
+		check["Interval"] = c.Config.HTTPCheckInterval.String()
+	} else {
+		check["TTL"] = c.Config.TTL.String()
+	}
+
+	if c.Config.DeregisterCriticalAfter > 0 {
+		check["DeregisterCriticalServiceAfter"] = c.Config.DeregisterCriticalAfter.String()
+	}
+
+	service := map[string]interface{}{
+		"ID":    c.Config.ServiceID,
+		"Name":  c.Config.ServiceName,
+		"Check": check,
+	}
+
+	if c.Config.Address != "" {
+		service["Address"] = c.Config.Address
+	}
+
+	if c.Config.Port != 0 {
+		service["Port"] = c.Config.Port
+	}
+
+	if len(c.Config.Tags) > 0 {
+		service["Tags"] = c.Config.Tags
+	}
+
+	if err := c.put("/v1/agent/service/register", service); err != nil {
+		return fmt.Errorf("Unable to register service: %v", err)
+	}
+
+	if c.Config.HTTPCheckURL != "" {
+		return nil
+	}
+
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+
+	if c.stop == nil {
+		c.stop, c.done = make(chan struct{}), make(chan struct{})
+		go c.run(c.stop, c.done)
+	}
+
+	return nil
+}
+
+// Deregister stops updating the TTL check (if any) and deregisters the service
+// from Consul; call it on shutdown.
+func (c *Consul) Deregister() error {
+	c.stopLock.Lock()
+	if c.stop != nil {
+		close(c.stop)
+
+		// do not update the check after the service is deregistered
+		<-c.done
+
+		c.stop, c.done = nil, nil
+	}
+	c.stopLock.Unlock()
+
+	if err := c.put("/v1/agent/service/deregister/"+url.PathEscape(c.Config.ServiceID), nil); err != nil {
+		return fmt.Errorf("Unable to deregister service: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateTTL updates the TTL check with the current aggregate health; it is
+// called periodically after "Register()".
+func (c *Consul) UpdateTTL() error {
+	status, output := c.status()
+
+	err := c.put("/v1/agent/check/update/"+url.PathEscape(c.CheckID()), map[string]interface{}{
+		"Status": status,
+		"Output": output,
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to update TTL check: %v", err)
+	}
+
+	return nil
+}
+
+func (c *Consul) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.Config.TTL / 2)
+	defer ticker.Stop()
+
+	for {
+		if err := c.UpdateTTL(); err != nil && c.Config.OnError != nil {
+			c.Config.OnError(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// maps the aggregate health to a consul check status; failing non-fatal
+// (or degraded) checks result in a warning
+func (c *Consul) status() (string, string) {
+	states, failed, err := c.health.State()
+	if err != nil {
+		return ConsulStatusCritical, fmt.Sprintf("Unable to fetch states: %v", err)
+	}
+
+	failing, degraded := make([]string, 0), make([]string, 0)
+	for name, state := range states {
+		switch state.Status {
+		case "failed":
+			failing = append(failing, name)
+		case "degraded":
+			degraded = append(degraded, name)
+		}
+	}
+	sort.Strings(failing)
+	sort.Strings(degraded)
+
+	switch {
+	case failed:
+		return ConsulStatusCritical, "failing checks: " + strings.Join(failing, ", ")
+	case len(failing) > 0:
+		return ConsulStatusWarning, "failing non-fatal checks: " + strings.Join(failing, ", ")
+	case len(degraded) > 0:
+		return ConsulStatusWarning, "degraded checks: " + strings.Join(degraded, ", ")
+	}
+
+	return ConsulStatusPassing, fmt.Sprintf("%d checks ok", len(states))
+}
+
+func (c *Consul) put(path string, body interface{}) error {
+	var payload []byte
+
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("Unable to marshal request body: %v", err)
+		}
+	}
+
+	req, err := http.NewRequest("PUT", c.Config.Addr+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("Unable to create consul request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.Config.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Config.Token)
+	}
+
+	resp, err := c.Config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to send consul request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Consul rejected request with status code '%v'", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func validateConsulConfig(cfg *ConsulConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.ServiceName == "" {
+		return errors.New("ServiceName must be set")
+	}
+
+	if cfg.ServiceID == "" {
+		cfg.ServiceID = cfg.ServiceName
+	}
+
+	if cfg.Addr == "" {
+		cfg.Addr = defaultConsulAddr
+	}
+
+	cfg.Addr = strings.TrimSuffix(cfg.Addr, "/")
+
+	if cfg.TTL == 0 {
+		cfg.TTL = defaultConsulTTL
+	}
+
+	if cfg.HTTPCheckInterval == 0 {
+		cfg.HTTPCheckInterval = defaultConsulHTTPCheckInterval
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return nil
+}
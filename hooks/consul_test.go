@@ -0,0 +1,198 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type consulRequest struct {
+	Method string
+	Path   string
+	Token  string
+	Body   map[string]interface{}
+}
+
+func newConsulServer(requests chan consulRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		requests <- consulRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Token:  r.Header.Get("X-Consul-Token"),
+			Body:   body,
+		}
+	}))
+}
+
+func TestNewConsul(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewConsul(nil, &ConsulConfig{ServiceName: "foo"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Health instance cannot be nil"))
+
+	_, err = NewConsul(health.New(), nil)
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+
+	_, err = NewConsul(health.New(), &ConsulConfig{})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("ServiceName must be set"))
+
+	c, err := NewConsul(health.New(), &ConsulConfig{ServiceName: "foo"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(c.Config.ServiceID).To(Equal("foo"))
+	Expect(c.Config.Addr).To(Equal("http://127.0.0.1:8500"))
+	Expect(c.Config.TTL).To(Equal(15 * time.Second))
+	Expect(c.CheckID()).To(Equal("go-health:foo"))
+}
+
+func TestConsulRegister(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should register a TTL check and keep it updated until deregistered", func(t *testing.T) {
+		requests := make(chan consulRequest, 100)
+		ts := newConsulServer(requests)
+		defer ts.Close()
+
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h := health.New()
+		h.DisableLogging()
+		h.AddCheck(&health.Config{Name: "db", Checker: checker, Interval: time.Hour, Fatal: true})
+		h.Start()
+		defer h.Stop()
+
+		c, _ := NewConsul(h, &ConsulConfig{
+			Addr:        ts.URL,
+			Token:       "secret",
+			ServiceName: "my-service",
+			Port:        8080,
+			TTL:         20 * time.Millisecond,
+		})
+
+		// let the check run once
+		time.Sleep(5 * time.Millisecond)
+
+		Expect(c.Register()).To(Succeed())
+
+		var req consulRequest
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Method).To(Equal("PUT"))
+		Expect(req.Path).To(Equal("/v1/agent/service/register"))
+		Expect(req.Token).To(Equal("secret"))
+		Expect(req.Body["Name"]).To(Equal("my-service"))
+		Expect(req.Body["Port"]).To(BeNumerically("==", 8080))
+		Expect(req.Body["Check"]).To(HaveKeyWithValue("TTL", "20ms"))
+		Expect(req.Body["Check"]).To(HaveKeyWithValue("CheckID", "go-health:my-service"))
+
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Path).To(Equal("/v1/agent/check/update/go-health:my-service"))
+		Expect(req.Body["Status"]).To(Equal(ConsulStatusCritical))
+		Expect(req.Body["Output"]).To(Equal("failing checks: db"))
+
+		// updated every TTL/2
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Path).To(Equal("/v1/agent/check/update/go-health:my-service"))
+
+		Expect(c.Deregister()).To(Succeed())
+
+		for req.Path != "/v1/agent/service/deregister/my-service" {
+			Eventually(requests).Should(Receive(&req))
+		}
+
+		// no more updates once deregistered
+		Consistently(requests, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	t.Run("Should register an HTTP check", func(t *testing.T) {
+		requests := make(chan consulRequest, 10)
+		ts := newConsulServer(requests)
+		defer ts.Close()
+
+		c, _ := NewConsul(health.New(), &ConsulConfig{
+			Addr:                    ts.URL,
+			ServiceName:             "my-service",
+			HTTPCheckURL:            "http://10.0.0.1:8080/healthcheck",
+			DeregisterCriticalAfter: time.Minute,
+		})
+
+		Expect(c.Register()).To(Succeed())
+
+		var req consulRequest
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Body["Check"]).To(HaveKeyWithValue("HTTP", "http://10.0.0.1:8080/healthcheck"))
+		Expect(req.Body["Check"]).To(HaveKeyWithValue("Interval", "10s"))
+		Expect(req.Body["Check"]).To(HaveKeyWithValue("DeregisterCriticalServiceAfter", "1m0s"))
+		Expect(req.Body["Check"]).ToNot(HaveKey("TTL"))
+
+		Consistently(requests, 20*time.Millisecond).ShouldNot(Receive())
+	})
+
+	t.Run("Should error if consul rejects the registration", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer ts.Close()
+
+		c, _ := NewConsul(health.New(), &ConsulConfig{Addr: ts.URL, ServiceName: "my-service"})
+
+		err := c.Register()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("status code '403'"))
+	})
+}
+
+func TestConsulStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should report failing non-fatal checks as warning", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h := health.New()
+		h.DisableLogging()
+		h.AddChecks([]*health.Config{
+			{Name: "cache", Checker: checker, Interval: time.Hour},
+			{Name: "db", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Fatal: true},
+		})
+		h.Start()
+		defer h.Stop()
+
+		time.Sleep(5 * time.Millisecond)
+
+		c, _ := NewConsul(h, &ConsulConfig{ServiceName: "foo"})
+
+		status, output := c.status()
+		Expect(status).To(Equal(ConsulStatusWarning))
+		Expect(output).To(Equal("failing non-fatal checks: cache"))
+	})
+
+	t.Run("Should report passing when all checks are ok", func(t *testing.T) {
+		h := health.New()
+		h.DisableLogging()
+		h.AddCheck(&health.Config{Name: "db", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Fatal: true})
+		h.Start()
+		defer h.Stop()
+
+		time.Sleep(5 * time.Millisecond)
+
+		c, _ := NewConsul(h, &ConsulConfig{ServiceName: "foo"})
+
+		status, output := c.status()
+		Expect(status).To(Equal(ConsulStatusPassing))
+		Expect(output).To(Equal("1 checks ok"))
+	})
+}
@@ -0,0 +1,350 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const (
+	defaultEurekaRenewalInterval = time.Duration(30) * time.Second
+	defaultEurekaLeaseDuration   = time.Duration(90) * time.Second
+
+	// Eureka instance statuses
+	EurekaStatusUp           = "UP"
+	EurekaStatusDown         = "DOWN"
+	EurekaStatusOutOfService = "OUT_OF_SERVICE"
+)
+
+// EurekaConfig is used for configuring the Eureka integration.
+//
+// "URL" is _required_; the Eureka service URL (ie. `http://eureka:8761/eureka`).
+//
+// "App" is _required_; the name of the application to register.
+//
+// "HostName" is optional; defaults to the hostname of the machine.
+//
+// "InstanceID" is optional; defaults to `<hostname>:<app>:<port>`.
+//
+// "IPAddr", "Port", "VIPAddress", "HealthCheckURL", "StatusPageURL",
+// "HomePageURL" and "Metadata" are optional; registered along with the
+// instance. "VIPAddress" defaults to "App".
+//
+// "RenewalInterval" is optional; the interval of the heartbeats; defaults to "30s".
+//
+// "LeaseDuration" is optional; the time after which Eureka evicts the instance
+// without heartbeats; defaults to "90s".
+//
+// "Client" is optional; if undefined, a new client will be created using "Timeout".
+//
+// "Timeout" is optional and defaults to "3s".
+//
+// "OnError" is optional; called if a heartbeat or registration has failed.
+type EurekaConfig struct {
+	URL             string
+	App             string
+	HostName        string
+	InstanceID      string
+	IPAddr          string
+	Port            int
+	VIPAddress      string
+	HealthCheckURL  string
+	StatusPageURL   string
+	HomePageURL     string
+	Metadata        map[string]string
+	RenewalInterval time.Duration
+	LeaseDuration   time.Duration
+	Client          *http.Client
+	Timeout         time.Duration
+	OnError         func(err error)
+}
+
+// Eureka registers an instance in Eureka, sends heartbeats and keeps the
+// instance status (`UP` or `DOWN`) in sync with the aggregate health of a
+// "health.IHealth" instance.
+type Eureka struct {
+	Config *EurekaConfig
+
+	health health.IHealth
+
+	// the status of the last registration
+	status     string
+	statusLock sync.Mutex
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopLock sync.Mutex
+}
+
+// NewEureka creates a new Eureka integration for the given health instance.
+func NewEureka(h health.IHealth, cfg *EurekaConfig) (*Eureka, error) {
+	if h == nil {
+		return nil, errors.New("Health instance cannot be nil")
+	}
+
+	if err := validateEurekaConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate eureka config: %v", err)
+	}
+
+	return &Eureka{
+		Config: cfg,
+		health: h,
+	}, nil
+}
+
+// Register registers the instance in Eureka and starts sending heartbeats
+// until "Deregister()" is called. The instance is re-registered whenever its
+// status changes.
+func (e *Eureka) Register() error {
+	e.statusLock.Lock()
+	err := e.register(e.currentStatus())
+	e.statusLock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	e.stopLock.Lock()
+	defer e.stopLock.Unlock()
+
+	if e.stop == nil {
+		e.stop, e.done = make(chan struct{}), make(chan struct{})
+		go e.run(e.stop, e.done)
+	}
+
+	return nil
+}
+
+// Deregister stops sending heartbeats and removes the instance from Eureka;
+// call it on shutdown.
+func (e *Eureka) Deregister() error {
+	e.stopLock.Lock()
+	if e.stop != nil {
+		close(e.stop)
+
+		// do not send heartbeats after the instance is deregistered
+		<-e.done
+
+		e.stop, e.done = nil, nil
+	}
+	e.stopLock.Unlock()
+
+	if _, err := e.request("DELETE", e.instancePath(), nil); err != nil {
+		return fmt.Errorf("Unable to deregister instance: %v", err)
+	}
+
+	return nil
+}
+
+// SetOutOfService takes the instance out of service (`OUT_OF_SERVICE`)
+// regardless of its health, ie. during a deployment; passing false removes
+// the override.
+func (e *Eureka) SetOutOfService(outOfService bool) error {
+	method, value := "PUT", EurekaStatusOutOfService
+	if !outOfService {
+		method, value = "DELETE", EurekaStatusUp
+	}
+
+	if _, err := e.request(method, e.instancePath()+"/status?value="+value, nil); err != nil {
+		return fmt.Errorf("Unable to set status override: %v", err)
+	}
+
+	return nil
+}
+
+// Heartbeat renews the lease of the instance; if the status of the instance
+// has changed (or Eureka does not know the instance), it is re-registered
+// instead. It is called every "RenewalInterval" after "Register()".
+func (e *Eureka) Heartbeat() error {
+	e.statusLock.Lock()
+	defer e.statusLock.Unlock()
+
+	status := e.currentStatus()
+	if status != e.status {
+		return e.register(status)
+	}
+
+	code, err := e.request("PUT", e.instancePath(), nil)
+	if code == http.StatusNotFound {
+		return e.register(status)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Unable to send heartbeat: %v", err)
+	}
+
+	return nil
+}
+
+func (e *Eureka) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(e.Config.RenewalInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.Heartbeat(); err != nil && e.Config.OnError != nil {
+				e.Config.OnError(err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (e *Eureka) currentStatus() string {
+	if e.health.Failed() {
+		return EurekaStatusDown
+	}
+
+	return EurekaStatusUp
+}
+
+func (e *Eureka) register(status string) error {
+	instance := map[string]interface{}{
+		"instanceId": e.Config.InstanceID,
+		"hostName":   e.Config.HostName,
+		"app":        e.app(),
+		"ipAddr":     e.Config.IPAddr,
+		"status":     status,
+		"vipAddress": e.Config.VIPAddress,
+		"port": map[string]interface{}{
+			"$":        e.Config.Port,
+			"@enabled": fmt.Sprintf("%v", e.Config.Port != 0),
+		},
+		"dataCenterInfo": map[string]interface{}{
+			"@class": "com.netflix.appinfo.InstanceInfo$DefaultDataCenterInfo",
+			"name":   "MyOwn",
+		},
+		"leaseInfo": map[string]interface{}{
+			"renewalIntervalInSecs": int(e.Config.RenewalInterval.Seconds()),
+			"durationInSecs":        int(e.Config.LeaseDuration.Seconds()),
+		},
+	}
+
+	if e.Config.HealthCheckURL != "" {
+		instance["healthCheckUrl"] = e.Config.HealthCheckURL
+	}
+
+	if e.Config.StatusPageURL != "" {
+		instance["statusPageUrl"] = e.Config.StatusPageURL
+	}
+
+	if e.Config.HomePageURL != "" {
+		instance["homePageUrl"] = e.Config.HomePageURL
+	}
+
+	if len(e.Config.Metadata) > 0 {
+		instance["metadata"] = e.Config.Metadata
+	}
+
+	if _, err := e.request("POST", "/apps/"+url.PathEscape(e.app()), map[string]interface{}{"instance": instance}); err != nil {
+		return fmt.Errorf("Unable to register instance: %v", err)
+	}
+
+	e.status = status
+
+	return nil
+}
+
+// eureka uses upper case application names
+func (e *Eureka) app() string {
+	return strings.ToUpper(e.Config.App)
+}
+
+func (e *Eureka) instancePath() string {
+	return "/apps/" + url.PathEscape(e.app()) + "/" + url.PathEscape(e.Config.InstanceID)
+}
+
+func (e *Eureka) request(method, path string, body interface{}) (int, error) {
+	var payload []byte
+
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return 0, fmt.Errorf("Unable to marshal request body: %v", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, e.Config.URL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("Unable to create eureka request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := e.Config.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to send eureka request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("Eureka rejected request with status code '%v'", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func validateEurekaConfig(cfg *EurekaConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.URL == "" {
+		return errors.New("URL must be set")
+	}
+
+	if cfg.App == "" {
+		return errors.New("App must be set")
+	}
+
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+
+	if cfg.HostName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("Unable to determine hostname: %v", err)
+		}
+
+		cfg.HostName = hostname
+	}
+
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = fmt.Sprintf("%v:%v:%d", cfg.HostName, strings.ToLower(cfg.App), cfg.Port)
+	}
+
+	if cfg.VIPAddress == "" {
+		cfg.VIPAddress = cfg.App
+	}
+
+	if cfg.RenewalInterval == 0 {
+		cfg.RenewalInterval = defaultEurekaRenewalInterval
+	}
+
+	if cfg.LeaseDuration == 0 {
+		cfg.LeaseDuration = defaultEurekaLeaseDuration
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type eurekaRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   map[string]interface{}
+}
+
+func newEurekaServer(requests chan eurekaRequest, heartbeatStatus int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		requests <- eurekaRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Body:   body,
+		}
+
+		if r.Method == "PUT" && r.URL.RawQuery == "" {
+			w.WriteHeader(heartbeatStatus)
+		}
+	}))
+}
+
+func TestNewEureka(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewEureka(nil, &EurekaConfig{URL: "http://eureka", App: "foo"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Health instance cannot be nil"))
+
+	_, err = NewEureka(health.New(), nil)
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+
+	_, err = NewEureka(health.New(), &EurekaConfig{App: "foo"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("URL must be set"))
+
+	_, err = NewEureka(health.New(), &EurekaConfig{URL: "http://eureka"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("App must be set"))
+
+	e, err := NewEureka(health.New(), &EurekaConfig{URL: "http://eureka/", App: "Foo", HostName: "host1", Port: 8080})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(e.Config.URL).To(Equal("http://eureka"))
+	Expect(e.Config.InstanceID).To(Equal("host1:foo:8080"))
+	Expect(e.Config.VIPAddress).To(Equal("Foo"))
+	Expect(e.Config.RenewalInterval).To(Equal(30 * time.Second))
+	Expect(e.Config.LeaseDuration).To(Equal(90 * time.Second))
+}
+
+func TestEurekaRegister(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should register, send heartbeats and deregister", func(t *testing.T) {
+		requests := make(chan eurekaRequest, 100)
+		ts := newEurekaServer(requests, http.StatusOK)
+		defer ts.Close()
+
+		e, _ := NewEureka(health.New(), &EurekaConfig{
+			URL:             ts.URL,
+			App:             "my-service",
+			HostName:        "host1",
+			Port:            8080,
+			RenewalInterval: 10 * time.Millisecond,
+		})
+
+		Expect(e.Register()).To(Succeed())
+
+		var req eurekaRequest
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Method).To(Equal("POST"))
+		Expect(req.Path).To(Equal("/apps/MY-SERVICE"))
+
+		instance := req.Body["instance"].(map[string]interface{})
+		Expect(instance["instanceId"]).To(Equal("host1:my-service:8080"))
+		Expect(instance["app"]).To(Equal("MY-SERVICE"))
+		Expect(instance["status"]).To(Equal(EurekaStatusUp))
+		Expect(instance["port"]).To(HaveKeyWithValue("$", BeNumerically("==", 8080)))
+
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Method).To(Equal("PUT"))
+		Expect(req.Path).To(Equal("/apps/MY-SERVICE/host1:my-service:8080"))
+
+		Expect(e.Deregister()).To(Succeed())
+
+		for req.Method != "DELETE" {
+			Eventually(requests).Should(Receive(&req))
+		}
+		Expect(req.Path).To(Equal("/apps/MY-SERVICE/host1:my-service:8080"))
+
+		Consistently(requests, 30*time.Millisecond).ShouldNot(Receive())
+	})
+
+	t.Run("Should re-register when the status changes", func(t *testing.T) {
+		requests := make(chan eurekaRequest, 100)
+		ts := newEurekaServer(requests, http.StatusOK)
+		defer ts.Close()
+
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h := health.New()
+		h.DisableLogging()
+		h.AddCheck(&health.Config{Name: "db", Checker: checker, Interval: time.Hour, Fatal: true})
+
+		e, _ := NewEureka(h, &EurekaConfig{URL: ts.URL, App: "my-service", HostName: "host1"})
+
+		Expect(e.Register()).To(Succeed())
+		defer e.Deregister()
+
+		var req eurekaRequest
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Body["instance"]).To(HaveKeyWithValue("status", EurekaStatusUp))
+
+		h.Start()
+		defer h.Stop()
+
+		Eventually(h.Failed).Should(BeTrue())
+
+		Expect(e.Heartbeat()).To(Succeed())
+
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Method).To(Equal("POST"))
+		Expect(req.Body["instance"]).To(HaveKeyWithValue("status", EurekaStatusDown))
+	})
+
+	t.Run("Should re-register if eureka does not know the instance", func(t *testing.T) {
+		requests := make(chan eurekaRequest, 100)
+		ts := newEurekaServer(requests, http.StatusNotFound)
+		defer ts.Close()
+
+		e, _ := NewEureka(health.New(), &EurekaConfig{URL: ts.URL, App: "my-service", HostName: "host1"})
+
+		Expect(e.Heartbeat()).To(Succeed())
+
+		var req eurekaRequest
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Method).To(Equal("POST"))
+	})
+
+	t.Run("Should set and remove the out of service override", func(t *testing.T) {
+		requests := make(chan eurekaRequest, 100)
+		ts := newEurekaServer(requests, http.StatusOK)
+		defer ts.Close()
+
+		e, _ := NewEureka(health.New(), &EurekaConfig{URL: ts.URL, App: "my-service", InstanceID: "i-1"})
+
+		Expect(e.SetOutOfService(true)).To(Succeed())
+
+		var req eurekaRequest
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Method).To(Equal("PUT"))
+		Expect(req.Path).To(Equal("/apps/MY-SERVICE/i-1/status"))
+		Expect(req.Query).To(Equal("value=OUT_OF_SERVICE"))
+
+		Expect(e.SetOutOfService(false)).To(Succeed())
+
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Method).To(Equal("DELETE"))
+		Expect(req.Query).To(Equal("value=UP"))
+	})
+}
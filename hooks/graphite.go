@@ -0,0 +1,156 @@
+package hooks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const defaultGraphitePrefix = "health"
+
+// characters that are not allowed in a graphite metric path component
+var graphiteInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_\-]`)
+
+// GraphiteConfig is used for configuring the Graphite hook.
+//
+// "Addr" is _required_; the address of the Graphite (carbon) server in
+// `host:port` format (ie. `graphite:2003`).
+//
+// "Network" is optional; either `tcp` (default) or `udp`.
+//
+// "Prefix" is optional; the prefix of all metric paths; defaults to `health`.
+//
+// "Timeout" is optional and defaults to "3s".
+//
+// "OnError" is optional; called if the metrics could not be sent.
+//
+// For every check execution, two metrics are sent: `<prefix>.<check>.status`
+// (`1` if the check is ok, `0` if it has failed) and `<prefix>.<check>.latency_ms`.
+type GraphiteConfig struct {
+	Addr    string
+	Network string
+	Prefix  string
+	Timeout time.Duration
+	OnError func(err error)
+}
+
+// Graphite implements the "health.IResultHook" interface; it sends the status
+// and latency of every check execution using the Graphite plaintext protocol.
+type Graphite struct {
+	Config *GraphiteConfig
+
+	conn     net.Conn
+	connLock sync.Mutex
+}
+
+// NewGraphite creates a new Graphite hook that can be added to "health.ResultHooks".
+func NewGraphite(cfg *GraphiteConfig) (*Graphite, error) {
+	if err := validateGraphiteConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate graphite config: %v", err)
+	}
+
+	return &Graphite{
+		Config: cfg,
+	}, nil
+}
+
+// CheckCompleted sends the metrics of the check execution; it satisfies the
+// "health.IResultHook" interface.
+func (g *Graphite) CheckCompleted(state *health.State) {
+	if err := g.send(g.metrics(state)); err != nil && g.Config.OnError != nil {
+		g.Config.OnError(err)
+	}
+}
+
+// Close closes the connection to the Graphite server (if any).
+func (g *Graphite) Close() error {
+	g.connLock.Lock()
+	defer g.connLock.Unlock()
+
+	if g.conn == nil {
+		return nil
+	}
+
+	err := g.conn.Close()
+	g.conn = nil
+
+	return err
+}
+
+func (g *Graphite) metrics(state *health.State) []byte {
+	path := g.Config.Prefix + "." + graphiteInvalidChars.ReplaceAllString(state.Name, "_")
+	ts := state.CheckTime.Unix()
+
+	status := 1
+	if state.Status == "failed" {
+		status = 0
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%v.status %d %d\n", path, status, ts)
+	fmt.Fprintf(buf, "%v.latency_ms %.3f %d\n", path, float64(state.Duration.Nanoseconds())/1e6, ts)
+
+	return buf.Bytes()
+}
+
+func (g *Graphite) send(data []byte) error {
+	g.connLock.Lock()
+	defer g.connLock.Unlock()
+
+	if g.conn == nil {
+		conn, err := net.DialTimeout(g.Config.Network, g.Config.Addr, g.Config.Timeout)
+		if err != nil {
+			return fmt.Errorf("Unable to connect to graphite: %v", err)
+		}
+		g.conn = conn
+	}
+
+	g.conn.SetWriteDeadline(time.Now().Add(g.Config.Timeout))
+
+	if _, err := g.conn.Write(data); err != nil {
+		// reconnect on the next send
+		g.conn.Close()
+		g.conn = nil
+
+		return fmt.Errorf("Unable to send metrics to graphite: %v", err)
+	}
+
+	return nil
+}
+
+func validateGraphiteConfig(cfg *GraphiteConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.Addr == "" {
+		return errors.New("Addr must be set")
+	}
+
+	if cfg.Network == "" {
+		cfg.Network = "tcp"
+	}
+
+	if cfg.Network != "tcp" && cfg.Network != "udp" {
+		return fmt.Errorf("Unsupported network '%v'", cfg.Network)
+	}
+
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultGraphitePrefix
+	}
+
+	cfg.Prefix = strings.TrimSuffix(cfg.Prefix, ".")
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+
+	return nil
+}
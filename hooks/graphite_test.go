@@ -0,0 +1,100 @@
+package hooks
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+func TestNewGraphite(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewGraphite(nil)
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+
+	_, err = NewGraphite(&GraphiteConfig{})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Addr must be set"))
+
+	_, err = NewGraphite(&GraphiteConfig{Addr: "graphite:2003", Network: "unix"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Unsupported network 'unix'"))
+
+	g, err := NewGraphite(&GraphiteConfig{Addr: "graphite:2003", Prefix: "services.foo."})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(g.Config.Network).To(Equal("tcp"))
+	Expect(g.Config.Prefix).To(Equal("services.foo"))
+}
+
+func TestGraphiteCheckCompleted(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Unix(1500000000, 0)
+
+	t.Run("Should send metrics over TCP", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		lines := make(chan string, 10)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+
+		g, err := NewGraphite(&GraphiteConfig{Addr: ln.Addr().String()})
+		Expect(err).ToNot(HaveOccurred())
+		defer g.Close()
+
+		var hook health.IResultHook = g
+		hook.CheckCompleted(&health.State{Name: "my check", Status: "ok", CheckTime: now, Duration: 1500 * time.Microsecond})
+		hook.CheckCompleted(&health.State{Name: "other", Status: "failed", CheckTime: now})
+
+		Eventually(lines).Should(Receive(Equal("health.my_check.status 1 1500000000")))
+		Eventually(lines).Should(Receive(Equal("health.my_check.latency_ms 1.500 1500000000")))
+		Eventually(lines).Should(Receive(Equal("health.other.status 0 1500000000")))
+	})
+
+	t.Run("Should send metrics over UDP", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		g, err := NewGraphite(&GraphiteConfig{Addr: conn.LocalAddr().String(), Network: "udp", Prefix: "svc"})
+		Expect(err).ToNot(HaveOccurred())
+		defer g.Close()
+
+		g.CheckCompleted(&health.State{Name: "foo", Status: "ok", CheckTime: now})
+
+		buf := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(buf[:n])).To(Equal("svc.foo.status 1 1500000000\nsvc.foo.latency_ms 0.000 1500000000\n"))
+	})
+
+	t.Run("Should report connection errors via OnError", func(t *testing.T) {
+		errs := make(chan error, 1)
+
+		g, _ := NewGraphite(&GraphiteConfig{Addr: "127.0.0.1:1", OnError: func(err error) { errs <- err }})
+		g.CheckCompleted(&health.State{Name: "foo"})
+
+		var err error
+		Eventually(errs).Should(Receive(&err))
+		Expect(err.Error()).To(ContainSubstring("Unable to connect to graphite"))
+	})
+}
@@ -0,0 +1,130 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const defaultHoneycombAPIHost = "https://api.honeycomb.io"
+
+// HoneycombConfig is used for configuring the Honeycomb hook.
+//
+// "APIKey" is _required_; the Honeycomb API (ingest) key.
+//
+// "Dataset" is _required_; the dataset the events are sent to.
+//
+// "APIHost" is optional; defaults to `https://api.honeycomb.io`.
+//
+// "Fields" is optional; static fields added to every event (ie. `service.name`).
+//
+// "Client" is optional; if undefined, a new client will be created using "Timeout".
+//
+// "Timeout" is optional and defaults to "3s".
+//
+// "OnError" is optional; called if an event could not be sent.
+type HoneycombConfig struct {
+	APIKey  string
+	Dataset string
+	APIHost string
+	Fields  map[string]interface{}
+	Client  *http.Client
+	Timeout time.Duration
+	OnError func(err error)
+}
+
+// Honeycomb implements the "health.IResultHook" interface; it sends one wide
+// event (see "WideEvent()") per check execution to Honeycomb.
+type Honeycomb struct {
+	Config *HoneycombConfig
+}
+
+// NewHoneycomb creates a new Honeycomb hook that can be added to "health.ResultHooks".
+func NewHoneycomb(cfg *HoneycombConfig) (*Honeycomb, error) {
+	if err := validateHoneycombConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate honeycomb config: %v", err)
+	}
+
+	return &Honeycomb{
+		Config: cfg,
+	}, nil
+}
+
+// CheckCompleted sends a wide event for the check execution; it satisfies the
+// "health.IResultHook" interface.
+func (hc *Honeycomb) CheckCompleted(state *health.State) {
+	if err := hc.send(state); err != nil && hc.Config.OnError != nil {
+		hc.Config.OnError(err)
+	}
+}
+
+func (hc *Honeycomb) send(state *health.State) error {
+	event := WideEvent(state)
+	for k, v := range hc.Config.Fields {
+		event[k] = v
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal honeycomb event: %v", err)
+	}
+
+	endpoint := fmt.Sprintf("%v/1/events/%v", strings.TrimRight(hc.Config.APIHost, "/"),
+		url.PathEscape(hc.Config.Dataset))
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("Unable to create honeycomb request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Honeycomb-Team", hc.Config.APIKey)
+	req.Header.Set("X-Honeycomb-Event-Time", state.CheckTime.Format(time.RFC3339Nano))
+
+	resp, err := hc.Config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to send honeycomb event: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Honeycomb rejected event with status code '%v'", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func validateHoneycombConfig(cfg *HoneycombConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.APIKey == "" {
+		return errors.New("APIKey must be set")
+	}
+
+	if cfg.Dataset == "" {
+		return errors.New("Dataset must be set")
+	}
+
+	if cfg.APIHost == "" {
+		cfg.APIHost = defaultHoneycombAPIHost
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+func TestNewHoneycomb(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewHoneycomb(nil)
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+
+	_, err = NewHoneycomb(&HoneycombConfig{Dataset: "health"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("APIKey must be set"))
+
+	_, err = NewHoneycomb(&HoneycombConfig{APIKey: "key"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Dataset must be set"))
+
+	hc, err := NewHoneycomb(&HoneycombConfig{APIKey: "key", Dataset: "health"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(hc.Config.APIHost).To(Equal(defaultHoneycombAPIHost))
+}
+
+func TestHoneycombCheckCompleted(t *testing.T) {
+	RegisterTestingT(t)
+
+	type request struct {
+		path  string
+		team  string
+		event map[string]interface{}
+	}
+
+	requests := make(chan *request, 10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &request{path: r.URL.Path, team: r.Header.Get("X-Honeycomb-Team")}
+		json.NewDecoder(r.Body).Decode(&req.event)
+		requests <- req
+	}))
+	defer ts.Close()
+
+	hc, err := NewHoneycomb(&HoneycombConfig{
+		APIKey:  "key",
+		Dataset: "health checks",
+		APIHost: ts.URL,
+		Fields:  map[string]interface{}{"service.name": "my-service"},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	var hook health.IResultHook = hc
+	hook.CheckCompleted(&health.State{Name: "foo", Status: "ok", CheckTime: time.Now()})
+
+	var req *request
+	Eventually(requests).Should(Receive(&req))
+
+	Expect(req.path).To(Equal("/1/events/health checks"))
+	Expect(req.team).To(Equal("key"))
+	Expect(req.event).To(HaveKeyWithValue("name", "foo"))
+	Expect(req.event).To(HaveKeyWithValue("service.name", "my-service"))
+}
@@ -0,0 +1,192 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const (
+	defaultNewRelicEventType = "HealthCheck"
+	defaultHTTPTimeout       = time.Duration(3) * time.Second
+
+	// NewRelicRegionUS is the (default) US data center of New Relic
+	NewRelicRegionUS = "US"
+
+	// NewRelicRegionEU is the EU data center of New Relic
+	NewRelicRegionEU = "EU"
+)
+
+var newRelicCollectors = map[string]string{
+	NewRelicRegionUS: "https://insights-collector.newrelic.com",
+	NewRelicRegionEU: "https://insights-collector.eu01.nr-data.net",
+}
+
+// NewRelicConfig is used for configuring the New Relic event hook.
+//
+// "AccountID" is _required_; the New Relic account ID.
+//
+// "InsertKey" is _required_; the insert (or license) key used for the Events API.
+//
+// "Region" is optional; either "NewRelicRegionUS" (default) or "NewRelicRegionEU".
+//
+// "URL" is optional; overrides the Events API endpoint entirely.
+//
+// "EventType" is optional; the custom event type; defaults to `HealthCheck`.
+//
+// "Attributes" is optional; additional attributes added to every event; use it
+// for entity correlation (ie. `entity.guid`, `service.name` or `host`).
+//
+// "Client" is optional; if undefined, a new client will be created using "Timeout".
+//
+// "Timeout" is optional and defaults to "3s".
+//
+// "OnError" is optional; called if an event could not be sent.
+type NewRelicConfig struct {
+	AccountID  string
+	InsertKey  string
+	Region     string
+	URL        string
+	EventType  string
+	Attributes map[string]interface{}
+	Client     *http.Client
+	Timeout    time.Duration
+	OnError    func(err error)
+}
+
+// NewRelic implements the "health.IStatusListener" interface; it sends a
+// custom event to the New Relic Events API whenever a check fails or recovers.
+type NewRelic struct {
+	Config *NewRelicConfig
+}
+
+// NewNewRelic creates a new New Relic event hook that can be used as (or
+// called from) a "health.IStatusListener".
+func NewNewRelic(cfg *NewRelicConfig) (*NewRelic, error) {
+	if err := validateNewRelicConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate new relic config: %v", err)
+	}
+
+	return &NewRelic{
+		Config: cfg,
+	}, nil
+}
+
+// HealthCheckFailed sends a "failed" event; it satisfies the
+// "health.IStatusListener" interface.
+func (n *NewRelic) HealthCheckFailed(entry *health.State) {
+	n.send(n.event(entry, "failed"))
+}
+
+// HealthCheckRecovered sends a "recovered" event; it satisfies the
+// "health.IStatusListener" interface.
+func (n *NewRelic) HealthCheckRecovered(entry *health.State, recordedFailures int64, failureDurationSeconds float64) {
+	event := n.event(entry, "recovered")
+	event["recordedFailures"] = recordedFailures
+	event["failureDurationSeconds"] = failureDurationSeconds
+
+	n.send(event)
+}
+
+func (n *NewRelic) event(entry *health.State, status string) map[string]interface{} {
+	event := make(map[string]interface{}, len(n.Config.Attributes)+7)
+
+	for k, v := range n.Config.Attributes {
+		event[k] = v
+	}
+
+	event["eventType"] = n.Config.EventType
+	event["timestamp"] = entry.CheckTime.Unix()
+	event["check"] = entry.Name
+	event["status"] = status
+	event["fatal"] = entry.Fatal
+
+	if entry.Err != "" {
+		event["error"] = entry.Err
+	}
+
+	if entry.IncidentID != "" {
+		event["incidentId"] = entry.IncidentID
+	}
+
+	return event
+}
+
+func (n *NewRelic) send(event map[string]interface{}) {
+	if err := n.post(event); err != nil && n.Config.OnError != nil {
+		n.Config.OnError(err)
+	}
+}
+
+func (n *NewRelic) post(event map[string]interface{}) error {
+	payload, err := json.Marshal([]map[string]interface{}{event})
+	if err != nil {
+		return fmt.Errorf("Unable to marshal new relic event: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", n.Config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("Unable to create new relic request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Insert-Key", n.Config.InsertKey)
+
+	resp, err := n.Config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to send new relic event: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("New relic rejected event with status code '%v'", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func validateNewRelicConfig(cfg *NewRelicConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.AccountID == "" {
+		return errors.New("AccountID must be set")
+	}
+
+	if cfg.InsertKey == "" {
+		return errors.New("InsertKey must be set")
+	}
+
+	if cfg.URL == "" {
+		if cfg.Region == "" {
+			cfg.Region = NewRelicRegionUS
+		}
+
+		collector, ok := newRelicCollectors[cfg.Region]
+		if !ok {
+			return fmt.Errorf("Unsupported region '%v'", cfg.Region)
+		}
+
+		cfg.URL = fmt.Sprintf("%v/v1/accounts/%v/events", collector, cfg.AccountID)
+	}
+
+	if cfg.EventType == "" {
+		cfg.EventType = defaultNewRelicEventType
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return nil
+}
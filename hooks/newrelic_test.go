@@ -0,0 +1,124 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+func TestNewNewRelic(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error with nil config", func(t *testing.T) {
+		_, err := NewNewRelic(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+	})
+
+	t.Run("Should error without account ID or insert key", func(t *testing.T) {
+		_, err := NewNewRelic(&NewRelicConfig{InsertKey: "key"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("AccountID must be set"))
+
+		_, err = NewNewRelic(&NewRelicConfig{AccountID: "123"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("InsertKey must be set"))
+	})
+
+	t.Run("Should error with an unsupported region", func(t *testing.T) {
+		_, err := NewNewRelic(&NewRelicConfig{AccountID: "123", InsertKey: "key", Region: "MARS"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unsupported region 'MARS'"))
+	})
+
+	t.Run("Should set defaults", func(t *testing.T) {
+		n, err := NewNewRelic(&NewRelicConfig{AccountID: "123", InsertKey: "key", Region: NewRelicRegionEU})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n.Config.URL).To(Equal("https://insights-collector.eu01.nr-data.net/v1/accounts/123/events"))
+		Expect(n.Config.EventType).To(Equal("HealthCheck"))
+		Expect(n.Config.Client).ToNot(BeNil())
+	})
+}
+
+func TestNewRelicEvents(t *testing.T) {
+	RegisterTestingT(t)
+
+	events := make(chan map[string]interface{}, 10)
+	keys := make(chan string, 10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := []map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		keys <- r.Header.Get("X-Insert-Key")
+		for _, e := range payload {
+			events <- e
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	n, err := NewNewRelic(&NewRelicConfig{
+		AccountID:  "123",
+		InsertKey:  "secret",
+		URL:        ts.URL,
+		Attributes: map[string]interface{}{"service.name": "my-service"},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	var listener health.IStatusListener = n
+
+	t.Run("Should send a failed event", func(t *testing.T) {
+		listener.HealthCheckFailed(&health.State{
+			Name: "foo", Err: "things broke", Fatal: true, IncidentID: "abc", CheckTime: time.Now(),
+		})
+
+		var event map[string]interface{}
+		Eventually(events).Should(Receive(&event))
+		Expect(<-keys).To(Equal("secret"))
+
+		Expect(event).To(HaveKeyWithValue("eventType", "HealthCheck"))
+		Expect(event).To(HaveKeyWithValue("check", "foo"))
+		Expect(event).To(HaveKeyWithValue("status", "failed"))
+		Expect(event).To(HaveKeyWithValue("error", "things broke"))
+		Expect(event).To(HaveKeyWithValue("incidentId", "abc"))
+		Expect(event).To(HaveKeyWithValue("service.name", "my-service"))
+	})
+
+	t.Run("Should send a recovered event", func(t *testing.T) {
+		listener.HealthCheckRecovered(&health.State{Name: "foo", IncidentID: "abc", CheckTime: time.Now()}, 3, 1.5)
+
+		var event map[string]interface{}
+		Eventually(events).Should(Receive(&event))
+
+		Expect(event).To(HaveKeyWithValue("status", "recovered"))
+		Expect(event).To(HaveKeyWithValue("recordedFailures", float64(3)))
+		Expect(event).To(HaveKeyWithValue("failureDurationSeconds", 1.5))
+	})
+
+	t.Run("Should report rejected events via OnError", func(t *testing.T) {
+		rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer rejecting.Close()
+
+		errs := make(chan error, 1)
+		n, _ := NewNewRelic(&NewRelicConfig{
+			AccountID: "123", InsertKey: "secret", URL: rejecting.URL,
+			OnError: func(err error) { errs <- err },
+		})
+
+		n.HealthCheckFailed(&health.State{Name: "foo"})
+
+		var err error
+		Eventually(errs).Should(Receive(&err))
+		Expect(err.Error()).To(ContainSubstring("status code '403'"))
+	})
+}
@@ -0,0 +1,235 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// OTLP severity numbers
+const (
+	otlpSeverityInfo  = 9
+	otlpSeverityError = 17
+)
+
+// OTLPLogsConfig is used for configuring the OTLP logs hook.
+//
+// "Endpoint" is _required_; the full URL of the OTLP/HTTP logs endpoint (ie.
+// `http://otel-collector:4318/v1/logs`).
+//
+// "Headers" is optional; additional headers sent with every request (ie. for
+// authentication).
+//
+// "ServiceName" is optional; sets the `service.name` resource attribute.
+//
+// "ResourceAttributes" is optional; additional resource attributes.
+//
+// "Client" is optional; if undefined, a new client will be created using "Timeout".
+//
+// "Timeout" is optional and defaults to "3s".
+//
+// "OnError" is optional; called if a log record could not be sent.
+type OTLPLogsConfig struct {
+	Endpoint           string
+	Headers            map[string]string
+	ServiceName        string
+	ResourceAttributes map[string]string
+	Client             *http.Client
+	Timeout            time.Duration
+	OnError            func(err error)
+}
+
+// OTLPLogs implements the "health.IResultHook" interface; it sends one wide
+// event (see "WideEvent()") per check execution as an OTLP log record (using
+// the OTLP/HTTP JSON encoding).
+type OTLPLogs struct {
+	Config *OTLPLogsConfig
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      map[string]string `json:"scope"`
+	LogRecords []otlpLogRecord   `json:"logRecords"`
+}
+
+type otlpResourceLogs struct {
+	Resource  map[string][]otlpAttribute `json:"resource"`
+	ScopeLogs []otlpScopeLogs            `json:"scopeLogs"`
+}
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+// NewOTLPLogs creates a new OTLP logs hook that can be added to "health.ResultHooks".
+func NewOTLPLogs(cfg *OTLPLogsConfig) (*OTLPLogs, error) {
+	if err := validateOTLPLogsConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate otlp logs config: %v", err)
+	}
+
+	return &OTLPLogs{
+		Config: cfg,
+	}, nil
+}
+
+// CheckCompleted sends a log record for the check execution; it satisfies the
+// "health.IResultHook" interface.
+func (o *OTLPLogs) CheckCompleted(state *health.State) {
+	if err := o.send(state); err != nil && o.Config.OnError != nil {
+		o.Config.OnError(err)
+	}
+}
+
+func (o *OTLPLogs) send(state *health.State) error {
+	payload, err := json.Marshal(o.request(state))
+	if err != nil {
+		return fmt.Errorf("Unable to marshal otlp log record: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", o.Config.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("Unable to create otlp request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range o.Config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := o.Config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to send otlp log record: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("OTLP endpoint rejected log record with status code '%v'", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (o *OTLPLogs) request(state *health.State) *otlpLogsRequest {
+	record := otlpLogRecord{
+		TimeUnixNano:   strconv.FormatInt(state.CheckTime.UnixNano(), 10),
+		SeverityNumber: otlpSeverityInfo,
+		SeverityText:   "INFO",
+		Body:           otlpStringValue(fmt.Sprintf("health check '%v' is %v", state.Name, state.Status)),
+		Attributes:     otlpAttributes(WideEvent(state)),
+	}
+
+	if state.Status == "failed" {
+		record.SeverityNumber = otlpSeverityError
+		record.SeverityText = "ERROR"
+	}
+
+	resource := map[string]interface{}{}
+	for k, v := range o.Config.ResourceAttributes {
+		resource[k] = v
+	}
+
+	if o.Config.ServiceName != "" {
+		resource["service.name"] = o.Config.ServiceName
+	}
+
+	return &otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{
+			{
+				Resource: map[string][]otlpAttribute{"attributes": otlpAttributes(resource)},
+				ScopeLogs: []otlpScopeLogs{
+					{
+						Scope:      map[string]string{"name": "github.com/InVisionApp/go-health"},
+						LogRecords: []otlpLogRecord{record},
+					},
+				},
+			},
+		},
+	}
+}
+
+// converts a flat map into (sorted) OTLP attributes
+func otlpAttributes(m map[string]interface{}) []otlpAttribute {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attributes := make([]otlpAttribute, 0, len(keys))
+	for _, k := range keys {
+		attributes = append(attributes, otlpAttribute{Key: k, Value: newOTLPValue(m[k])})
+	}
+
+	return attributes
+}
+
+func newOTLPValue(v interface{}) otlpValue {
+	switch val := v.(type) {
+	case string:
+		return otlpStringValue(val)
+	case bool:
+		return otlpValue{BoolValue: &val}
+	case int:
+		s := strconv.Itoa(val)
+		return otlpValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(val, 10)
+		return otlpValue{IntValue: &s}
+	case float64:
+		return otlpValue{DoubleValue: &val}
+	case time.Time:
+		return otlpStringValue(val.Format(time.RFC3339Nano))
+	default:
+		return otlpStringValue(fmt.Sprint(val))
+	}
+}
+
+func otlpStringValue(s string) otlpValue {
+	return otlpValue{StringValue: &s}
+}
+
+func validateOTLPLogsConfig(cfg *OTLPLogsConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.Endpoint == "" {
+		return errors.New("Endpoint must be set")
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+func TestNewOTLPLogs(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewOTLPLogs(nil)
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+
+	_, err = NewOTLPLogs(&OTLPLogsConfig{})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Endpoint must be set"))
+}
+
+func TestOTLPLogsCheckCompleted(t *testing.T) {
+	RegisterTestingT(t)
+
+	requests := make(chan *otlpLogsRequest, 10)
+	auth := make(chan string, 10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &otlpLogsRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		auth <- r.Header.Get("Authorization")
+		requests <- req
+	}))
+	defer ts.Close()
+
+	o, err := NewOTLPLogs(&OTLPLogsConfig{
+		Endpoint:    ts.URL + "/v1/logs",
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		ServiceName: "my-service",
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	now := time.Now()
+	o.CheckCompleted(&health.State{Name: "foo", Status: "failed", Err: "i/o timeout", CheckTime: now})
+
+	var req *otlpLogsRequest
+	Eventually(requests).Should(Receive(&req))
+	Expect(<-auth).To(Equal("Bearer token"))
+
+	Expect(req.ResourceLogs).To(HaveLen(1))
+
+	resource := req.ResourceLogs[0].Resource["attributes"]
+	Expect(resource).To(HaveLen(1))
+	Expect(resource[0].Key).To(Equal("service.name"))
+	Expect(*resource[0].Value.StringValue).To(Equal("my-service"))
+
+	record := req.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	Expect(record.SeverityText).To(Equal("ERROR"))
+	Expect(*record.Body.StringValue).To(Equal("health check 'foo' is failed"))
+
+	attributes := map[string]otlpValue{}
+	for _, a := range record.Attributes {
+		attributes[a.Key] = a.Value
+	}
+
+	Expect(*attributes["error.category"].StringValue).To(Equal(ErrorCategoryTimeout))
+	Expect(*attributes["fatal"].BoolValue).To(BeFalse())
+	Expect(*attributes["num_failures"].IntValue).To(Equal("0"))
+}
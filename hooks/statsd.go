@@ -0,0 +1,227 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const defaultMetricsPrefix = "health"
+
+// characters that are not allowed in a statsd metric name or tag
+var statsdInvalidChars = strings.NewReplacer(":", "_", "|", "_", "@", "_", ",", "_", "#", "_", "\n", "_")
+
+// MetricsSink receives the metrics of the check executions. It is satisfied by
+// the Datadog statsd client ("*statsd.Client" of
+// "github.com/DataDog/datadog-go/statsd") as well as by "StatsD".
+type MetricsSink interface {
+	// Incr increments the counter with the given name by one
+	Incr(name string, tags []string, rate float64) error
+
+	// Timing records the duration with the given name
+	Timing(name string, value time.Duration, tags []string, rate float64) error
+}
+
+// MetricsConfig is used for configuring the Metrics hook.
+//
+// "Sink" is _required_; ie. "NewStatsD()" or a Datadog statsd client.
+//
+// "Prefix" is optional; the prefix of all metric names; defaults to `health`.
+//
+// "Tags" is optional; tags that are added to every metric (ie. `env:prod`).
+//
+// "OnError" is optional; called if the metrics could not be sent.
+//
+// For every check execution, a counter (`<prefix>.check.success` or
+// `<prefix>.check.failure`) is incremented and its duration is recorded as a
+// timer (`<prefix>.check.duration`); all of them are tagged with
+// `check:<name>`. Degraded checks count as successes; skipped and paused
+// checks are not recorded.
+type MetricsConfig struct {
+	Sink    MetricsSink
+	Prefix  string
+	Tags    []string
+	OnError func(err error)
+}
+
+// Metrics implements the "health.IResultHook" interface; it emits counters and
+// timers for the check executions to a metrics sink.
+type Metrics struct {
+	Config *MetricsConfig
+}
+
+// NewMetrics creates a new Metrics hook that can be added to "health.ResultHooks".
+func NewMetrics(cfg *MetricsConfig) (*Metrics, error) {
+	if err := validateMetricsConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate metrics config: %v", err)
+	}
+
+	return &Metrics{
+		Config: cfg,
+	}, nil
+}
+
+// CheckCompleted emits the metrics of the check execution; it satisfies the
+// "health.IResultHook" interface.
+func (m *Metrics) CheckCompleted(state *health.State) {
+	if state.Status == "skipped" || state.Status == "paused" {
+		return
+	}
+
+	tags := append(append(make([]string, 0, len(m.Config.Tags)+1), m.Config.Tags...), "check:"+state.Name)
+
+	counter := "check.success"
+	if state.Status == "failed" {
+		counter = "check.failure"
+	}
+
+	if err := m.Config.Sink.Incr(m.Config.Prefix+"."+counter, tags, 1); err != nil {
+		m.onError(err)
+	}
+
+	if err := m.Config.Sink.Timing(m.Config.Prefix+".check.duration", state.Duration, tags, 1); err != nil {
+		m.onError(err)
+	}
+}
+
+func (m *Metrics) onError(err error) {
+	if m.Config.OnError != nil {
+		m.Config.OnError(err)
+	}
+}
+
+func validateMetricsConfig(cfg *MetricsConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.Sink == nil {
+		return errors.New("Sink must be set")
+	}
+
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultMetricsPrefix
+	}
+
+	cfg.Prefix = strings.TrimSuffix(cfg.Prefix, ".")
+
+	return nil
+}
+
+// StatsDConfig is used for configuring the StatsD sink.
+//
+// "Addr" is _required_; the address of the StatsD server (or Datadog agent)
+// in `host:port` format (ie. `localhost:8125`).
+//
+// Tags are sent using the DogStatsD extension of the protocol (`|#tag:value`),
+// which is understood by the Datadog agent, Telegraf and the Prometheus
+// statsd_exporter among others.
+type StatsDConfig struct {
+	Addr string
+}
+
+// StatsD implements the "MetricsSink" interface; it sends the metrics over UDP
+// using the StatsD protocol.
+type StatsD struct {
+	Config *StatsDConfig
+
+	conn     net.Conn
+	connLock sync.Mutex
+}
+
+// NewStatsD creates a new StatsD sink that can be used as "MetricsConfig.Sink".
+func NewStatsD(cfg *StatsDConfig) (*StatsD, error) {
+	if err := validateStatsDConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate statsd config: %v", err)
+	}
+
+	return &StatsD{
+		Config: cfg,
+	}, nil
+}
+
+// Incr sends a counter increment; it satisfies the "MetricsSink" interface.
+func (s *StatsD) Incr(name string, tags []string, rate float64) error {
+	return s.send(name, "1", "c", tags, rate)
+}
+
+// Timing sends a timer in milliseconds; it satisfies the "MetricsSink"
+// interface.
+func (s *StatsD) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	ms := strconv.FormatFloat(float64(value.Nanoseconds())/1e6, 'f', -1, 64)
+	return s.send(name, ms, "ms", tags, rate)
+}
+
+// Close closes the socket (if any).
+func (s *StatsD) Close() error {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+func (s *StatsD) send(name, value, kind string, tags []string, rate float64) error {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "%v:%v|%v", statsdInvalidChars.Replace(name), value, kind)
+
+	if rate > 0 && rate < 1 {
+		fmt.Fprintf(buf, "|@%v", strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+
+	if len(tags) > 0 {
+		escaped := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			// the first colon separates the name of the tag from its value
+			parts := strings.SplitN(tag, ":", 2)
+			for i := range parts {
+				parts[i] = statsdInvalidChars.Replace(parts[i])
+			}
+
+			escaped = append(escaped, strings.Join(parts, ":"))
+		}
+
+		buf.WriteString("|#" + strings.Join(escaped, ","))
+	}
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.Dial("udp", s.Config.Addr)
+		if err != nil {
+			return fmt.Errorf("Unable to connect to statsd: %v", err)
+		}
+		s.conn = conn
+	}
+
+	if _, err := s.conn.Write([]byte(buf.String())); err != nil {
+		return fmt.Errorf("Unable to send metrics to statsd: %v", err)
+	}
+
+	return nil
+}
+
+func validateStatsDConfig(cfg *StatsDConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.Addr == "" {
+		return errors.New("Addr must be set")
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package hooks
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+type metric struct {
+	name  string
+	value time.Duration
+	tags  []string
+}
+
+type fakeMetricsSink struct {
+	counters []metric
+	timers   []metric
+	err      error
+}
+
+func (f *fakeMetricsSink) Incr(name string, tags []string, rate float64) error {
+	f.counters = append(f.counters, metric{name: name, tags: tags})
+	return f.err
+}
+
+func (f *fakeMetricsSink) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	f.timers = append(f.timers, metric{name: name, value: value, tags: tags})
+	return f.err
+}
+
+func TestNewMetrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewMetrics(nil)
+	Expect(err).To(MatchError(ContainSubstring("Main config cannot be nil")))
+
+	_, err = NewMetrics(&MetricsConfig{})
+	Expect(err).To(MatchError(ContainSubstring("Sink must be set")))
+
+	m, err := NewMetrics(&MetricsConfig{Sink: &fakeMetricsSink{}, Prefix: "services.foo."})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(m.Config.Prefix).To(Equal("services.foo"))
+}
+
+func TestMetricsCheckCompleted(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should emit counters and timers tagged with the check name", func(t *testing.T) {
+		sink := &fakeMetricsSink{}
+
+		m, err := NewMetrics(&MetricsConfig{Sink: sink, Tags: []string{"env:prod"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		m.CheckCompleted(&health.State{Name: "redis", Status: "ok", Duration: time.Millisecond})
+		m.CheckCompleted(&health.State{Name: "mongo", Status: "failed", Duration: time.Second})
+		m.CheckCompleted(&health.State{Name: "sql", Status: "skipped"})
+
+		Expect(sink.counters).To(Equal([]metric{
+			{name: "health.check.success", tags: []string{"env:prod", "check:redis"}},
+			{name: "health.check.failure", tags: []string{"env:prod", "check:mongo"}},
+		}))
+		Expect(sink.timers).To(Equal([]metric{
+			{name: "health.check.duration", value: time.Millisecond, tags: []string{"env:prod", "check:redis"}},
+			{name: "health.check.duration", value: time.Second, tags: []string{"env:prod", "check:mongo"}},
+		}))
+	})
+
+	t.Run("Should report errors of the sink", func(t *testing.T) {
+		errs := make([]error, 0)
+
+		m, err := NewMetrics(&MetricsConfig{
+			Sink:    &fakeMetricsSink{err: errors.New("no route to host")},
+			OnError: func(err error) { errs = append(errs, err) },
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		m.CheckCompleted(&health.State{Name: "redis", Status: "ok"})
+		Expect(errs).To(HaveLen(2))
+	})
+}
+
+func TestStatsD(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := NewStatsD(&StatsDConfig{})
+	Expect(err).To(MatchError(ContainSubstring("Addr must be set")))
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	defer conn.Close()
+
+	s, err := NewStatsD(&StatsDConfig{Addr: conn.LocalAddr().String()})
+	Expect(err).ToNot(HaveOccurred())
+	defer s.Close()
+
+	read := func() string {
+		buf := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+
+		n, _, err := conn.ReadFrom(buf)
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(buf[:n])
+	}
+
+	Expect(s.Incr("health.check.failure", []string{"check:s3|eu", "env:prod"}, 1)).To(Succeed())
+	Expect(read()).To(Equal("health.check.failure:1|c|#check:s3_eu,env:prod"))
+
+	Expect(s.Timing("health.check.duration", 1500*time.Microsecond, nil, 0.5)).To(Succeed())
+	Expect(read()).To(Equal("health.check.duration:1.5|ms|@0.5"))
+}
@@ -0,0 +1,244 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const (
+	defaultTargetGroupFailureDuration    = time.Duration(30) * time.Second
+	defaultTargetGroupMinHealthyFraction = 0.5
+)
+
+// TargetGroupClient is used for (de)registering a target with AWS ALB/NLB
+// target groups; implement it with a thin wrapper around the AWS SDK
+// (`elbv2.RegisterTargets`, `elbv2.DeregisterTargets` and
+// `elbv2.DescribeTargetHealth`).
+type TargetGroupClient interface {
+	// RegisterTarget registers the target with the target group
+	RegisterTarget(ctx context.Context, targetGroupARN, targetID string, port int) error
+
+	// DeregisterTarget deregisters the target from the target group
+	DeregisterTarget(ctx context.Context, targetGroupARN, targetID string, port int) error
+
+	// TargetHealth returns whether each target of the target group is healthy,
+	// keyed by target ID
+	TargetHealth(ctx context.Context, targetGroupARN string) (map[string]bool, error)
+}
+
+// TargetGroupConfig is used for configuring the AWS target group integration.
+//
+// "Client" is _required_; refer to the "TargetGroupClient" docs for details.
+//
+// "TargetGroupARNs" is _required_; the target groups the target belongs to.
+//
+// "TargetID" is _required_; the ID of the target (instance ID or IP address).
+//
+// "Port" is optional; the port of the target.
+//
+// "FailureDuration" is optional; the target is only deregistered once the
+// health has been failing for this long (see "h.Failed()"); defaults to
+// "30s".
+//
+// "MinHealthyFraction" is optional; the target is not deregistered from a
+// target group if that would leave less than this fraction of its targets
+// healthy (ie. when the whole fleet fails because of a shared dependency);
+// defaults to "0.5".
+//
+// "Timeout" is optional; the timeout of the client calls; defaults to "3s".
+//
+// "OnError" is optional; called if the target could not be (de)registered or
+// if the deregistration was refused by the safeguard.
+type TargetGroupConfig struct {
+	Client             TargetGroupClient
+	TargetGroupARNs    []string
+	TargetID           string
+	Port               int
+	FailureDuration    time.Duration
+	MinHealthyFraction float64
+	Timeout            time.Duration
+	OnError            func(err error)
+}
+
+// TargetGroup implements the "health.IResultHook" interface; it deregisters the
+// target from AWS ALB/NLB target groups when the fatal checks fail
+// persistently and re-registers it once they recover. Intended for deployments
+// that are not fronted by Kubernetes.
+type TargetGroup struct {
+	Config *TargetGroupConfig
+
+	health health.IHealth
+
+	// target groups the target has been deregistered from
+	deregistered map[string]bool
+
+	// time since which the health has been failing
+	since time.Time
+
+	lock sync.Mutex
+}
+
+// NewTargetGroup creates a new AWS target group integration for the given
+// health instance; add it to "health.ResultHooks".
+func NewTargetGroup(h health.IHealth, cfg *TargetGroupConfig) (*TargetGroup, error) {
+	if h == nil {
+		return nil, errors.New("Health instance cannot be nil")
+	}
+
+	if err := validateTargetGroupConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate target group config: %v", err)
+	}
+
+	return &TargetGroup{
+		Config:       cfg,
+		health:       h,
+		deregistered: make(map[string]bool, 0),
+	}, nil
+}
+
+// CheckCompleted re-evaluates the aggregate health and (de)registers the
+// target if needed; it satisfies the "health.IResultHook" interface.
+func (t *TargetGroup) CheckCompleted(state *health.State) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	failingSince, failing := t.failingSince(state)
+
+	for _, arn := range t.Config.TargetGroupARNs {
+		var err error
+
+		switch {
+		case failing && !t.deregistered[arn] && time.Since(failingSince) >= t.Config.FailureDuration:
+			err = t.deregister(arn)
+		case !failing && t.deregistered[arn]:
+			err = t.register(arn)
+		}
+
+		if err != nil && t.Config.OnError != nil {
+			t.Config.OnError(err)
+		}
+	}
+}
+
+// Deregistered returns the target groups the target is currently deregistered from.
+func (t *TargetGroup) Deregistered() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	arns := make([]string, 0)
+	for _, arn := range t.Config.TargetGroupARNs {
+		if t.deregistered[arn] {
+			arns = append(arns, arn)
+		}
+	}
+
+	return arns
+}
+
+// returns the time since which the health has been failing; that is the
+// first time the failure has been seen, unless a completed fatal check has
+// been failing for longer. The health may fail without any failed fatal check
+// (ie. in maintenance mode or because of "Health.Aggregation"), so the
+// failure is tracked here rather than derived from the states.
+func (t *TargetGroup) failingSince(state *health.State) (time.Time, bool) {
+	if !t.health.Failed() {
+		t.since = time.Time{}
+		return t.since, false
+	}
+
+	if t.since.IsZero() {
+		t.since = time.Now()
+	}
+
+	if state != nil && state.Fatal && state.Status == "failed" &&
+		!state.TimeOfFirstFailure.IsZero() && state.TimeOfFirstFailure.Before(t.since) {
+		t.since = state.TimeOfFirstFailure
+	}
+
+	return t.since, true
+}
+
+func (t *TargetGroup) deregister(arn string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.Config.Timeout)
+	defer cancel()
+
+	targets, err := t.Config.Client.TargetHealth(ctx, arn)
+	if err != nil {
+		return fmt.Errorf("Unable to describe target health of '%v': %v", arn, err)
+	}
+
+	// the remaining healthy targets, excluding this one
+	healthy := 0
+	for id, ok := range targets {
+		if ok && id != t.Config.TargetID {
+			healthy++
+		}
+	}
+
+	if len(targets) > 0 && float64(healthy)/float64(len(targets)) < t.Config.MinHealthyFraction {
+		return fmt.Errorf("Refusing to deregister target from '%v': only %d of %d targets would remain healthy",
+			arn, healthy, len(targets))
+	}
+
+	if err := t.Config.Client.DeregisterTarget(ctx, arn, t.Config.TargetID, t.Config.Port); err != nil {
+		return fmt.Errorf("Unable to deregister target from '%v': %v", arn, err)
+	}
+
+	t.deregistered[arn] = true
+
+	return nil
+}
+
+func (t *TargetGroup) register(arn string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.Config.Timeout)
+	defer cancel()
+
+	if err := t.Config.Client.RegisterTarget(ctx, arn, t.Config.TargetID, t.Config.Port); err != nil {
+		return fmt.Errorf("Unable to register target with '%v': %v", arn, err)
+	}
+
+	delete(t.deregistered, arn)
+
+	return nil
+}
+
+func validateTargetGroupConfig(cfg *TargetGroupConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.Client == nil {
+		return errors.New("Client must be set")
+	}
+
+	if len(cfg.TargetGroupARNs) == 0 {
+		return errors.New("TargetGroupARNs must be set")
+	}
+
+	if cfg.TargetID == "" {
+		return errors.New("TargetID must be set")
+	}
+
+	if cfg.MinHealthyFraction < 0 || cfg.MinHealthyFraction > 1 {
+		return errors.New("MinHealthyFraction must be between 0 and 1")
+	}
+
+	if cfg.MinHealthyFraction == 0 {
+		cfg.MinHealthyFraction = defaultTargetGroupMinHealthyFraction
+	}
+
+	if cfg.FailureDuration == 0 {
+		cfg.FailureDuration = defaultTargetGroupFailureDuration
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type fakeTargetGroupClient struct {
+	targets map[string]bool
+	calls   []string
+	lock    sync.Mutex
+}
+
+func (f *fakeTargetGroupClient) RegisterTarget(ctx context.Context, arn, id string, port int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls = append(f.calls, "register "+arn+" "+id)
+	return nil
+}
+
+func (f *fakeTargetGroupClient) DeregisterTarget(ctx context.Context, arn, id string, port int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls = append(f.calls, "deregister "+arn+" "+id)
+	return nil
+}
+
+func (f *fakeTargetGroupClient) TargetHealth(ctx context.Context, arn string) (map[string]bool, error) {
+	return f.targets, nil
+}
+
+func (f *fakeTargetGroupClient) Calls() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]string{}, f.calls...)
+}
+
+// returns a started health instance with a single fatal check that fails once
+func newFailingHealth() *health.Health {
+	checker := &fakes.FakeICheckable{}
+	checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+
+	h := health.New()
+	h.DisableLogging()
+	h.AddCheck(&health.Config{Name: "db", Checker: checker, Interval: time.Hour, Fatal: true})
+	h.Start()
+
+	Eventually(h.Failed).Should(BeTrue())
+
+	return h
+}
+
+func TestNewTargetGroup(t *testing.T) {
+	RegisterTestingT(t)
+
+	client := &fakeTargetGroupClient{}
+
+	_, err := NewTargetGroup(nil, &TargetGroupConfig{})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Health instance cannot be nil"))
+
+	_, err = NewTargetGroup(health.New(), nil)
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+
+	_, err = NewTargetGroup(health.New(), &TargetGroupConfig{TargetGroupARNs: []string{"arn"}, TargetID: "i-1"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("Client must be set"))
+
+	_, err = NewTargetGroup(health.New(), &TargetGroupConfig{Client: client, TargetID: "i-1"})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("TargetGroupARNs must be set"))
+
+	_, err = NewTargetGroup(health.New(), &TargetGroupConfig{Client: client, TargetGroupARNs: []string{"arn"}})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("TargetID must be set"))
+
+	_, err = NewTargetGroup(health.New(), &TargetGroupConfig{Client: client, TargetGroupARNs: []string{"arn"}, TargetID: "i-1", MinHealthyFraction: 2})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("MinHealthyFraction must be between 0 and 1"))
+
+	tg, err := NewTargetGroup(health.New(), &TargetGroupConfig{Client: client, TargetGroupARNs: []string{"arn"}, TargetID: "i-1"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(tg.Config.FailureDuration).To(Equal(30 * time.Second))
+	Expect(tg.Config.MinHealthyFraction).To(Equal(0.5))
+}
+
+func TestTargetGroupCheckCompleted(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should deregister on persistent failure and re-register on recovery", func(t *testing.T) {
+		h := newFailingHealth()
+		defer h.Stop()
+
+		client := &fakeTargetGroupClient{targets: map[string]bool{"i-1": true, "i-2": true, "i-3": true}}
+
+		tg, _ := NewTargetGroup(h, &TargetGroupConfig{
+			Client:          client,
+			TargetGroupARNs: []string{"arn-1", "arn-2"},
+			TargetID:        "i-1",
+			FailureDuration: 20 * time.Millisecond,
+		})
+
+		// not failing for long enough yet
+		tg.CheckCompleted(&health.State{})
+		Expect(client.Calls()).To(BeEmpty())
+
+		time.Sleep(25 * time.Millisecond)
+
+		tg.CheckCompleted(&health.State{})
+		Expect(client.Calls()).To(Equal([]string{"deregister arn-1 i-1", "deregister arn-2 i-1"}))
+		Expect(tg.Deregistered()).To(Equal([]string{"arn-1", "arn-2"}))
+
+		// only deregistered once
+		tg.CheckCompleted(&health.State{})
+		Expect(client.Calls()).To(HaveLen(2))
+
+		_, err := h.RunCheck("db")
+		Expect(err).ToNot(HaveOccurred())
+
+		tg.CheckCompleted(&health.State{})
+		Expect(client.Calls()).To(HaveLen(4))
+		Expect(client.Calls()[2:]).To(Equal([]string{"register arn-1 i-1", "register arn-2 i-1"}))
+		Expect(tg.Deregistered()).To(BeEmpty())
+	})
+
+	t.Run("Should refuse to deregister if too few targets would remain healthy", func(t *testing.T) {
+		h := newFailingHealth()
+		defer h.Stop()
+
+		client := &fakeTargetGroupClient{targets: map[string]bool{"i-1": true, "i-2": false, "i-3": true}}
+
+		var errs []error
+
+		tg, _ := NewTargetGroup(h, &TargetGroupConfig{
+			Client:             client,
+			TargetGroupARNs:    []string{"arn-1"},
+			TargetID:           "i-1",
+			FailureDuration:    time.Nanosecond,
+			MinHealthyFraction: 0.5,
+			OnError:            func(err error) { errs = append(errs, err) },
+		})
+
+		tg.CheckCompleted(&health.State{})
+		Expect(client.Calls()).To(BeEmpty())
+		Expect(tg.Deregistered()).To(BeEmpty())
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Error()).To(ContainSubstring("only 1 of 3 targets would remain healthy"))
+	})
+
+	t.Run("Should wait for the failure duration in maintenance mode", func(t *testing.T) {
+		h := health.New()
+		h.DisableLogging()
+		h.AddCheck(&health.Config{Name: "db", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Fatal: true})
+		h.Start()
+		defer h.Stop()
+
+		client := &fakeTargetGroupClient{targets: map[string]bool{"i-1": true, "i-2": true, "i-3": true}}
+
+		tg, _ := NewTargetGroup(h, &TargetGroupConfig{
+			Client:          client,
+			TargetGroupARNs: []string{"arn-1"},
+			TargetID:        "i-1",
+			FailureDuration: 20 * time.Millisecond,
+		})
+
+		// no check has failed, yet the health has
+		h.SetMaintenanceMode(true, "draining")
+		Expect(h.Failed()).To(BeTrue())
+
+		tg.CheckCompleted(&health.State{Name: "db", Status: "ok", Fatal: true})
+		Expect(client.Calls()).To(BeEmpty())
+
+		time.Sleep(25 * time.Millisecond)
+
+		tg.CheckCompleted(&health.State{Name: "db", Status: "ok", Fatal: true})
+		Expect(client.Calls()).To(Equal([]string{"deregister arn-1 i-1"}))
+
+		h.SetMaintenanceMode(false, "")
+
+		tg.CheckCompleted(&health.State{Name: "db", Status: "ok", Fatal: true})
+		Expect(client.Calls()).To(Equal([]string{"deregister arn-1 i-1", "register arn-1 i-1"}))
+	})
+
+	t.Run("Should count from the first failure of a fatal check", func(t *testing.T) {
+		h := newFailingHealth()
+		defer h.Stop()
+
+		client := &fakeTargetGroupClient{targets: map[string]bool{"i-1": true, "i-2": true, "i-3": true}}
+
+		tg, _ := NewTargetGroup(h, &TargetGroupConfig{
+			Client:          client,
+			TargetGroupARNs: []string{"arn-1"},
+			TargetID:        "i-1",
+			FailureDuration: time.Minute,
+		})
+
+		tg.CheckCompleted(&health.State{
+			Name:               "db",
+			Status:             "failed",
+			Fatal:              true,
+			TimeOfFirstFailure: time.Now().Add(-time.Hour),
+		})
+		Expect(client.Calls()).To(Equal([]string{"deregister arn-1 i-1"}))
+	})
+}
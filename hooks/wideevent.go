@@ -0,0 +1,111 @@
+package hooks
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// error categories used in wide events
+const (
+	ErrorCategoryTimeout           = "timeout"
+	ErrorCategoryConnectionRefused = "connection_refused"
+	ErrorCategoryDNS               = "dns"
+	ErrorCategoryTLS               = "tls"
+	ErrorCategoryStatusCode        = "status_code"
+	ErrorCategoryOther             = "other"
+)
+
+// the substrings that map an error to a category, in order of precedence
+var errorCategories = []struct {
+	category   string
+	substrings []string
+}{
+	{ErrorCategoryTimeout, []string{"timeout", "timed out", "deadline exceeded"}},
+	{ErrorCategoryConnectionRefused, []string{"connection refused"}},
+	{ErrorCategoryDNS, []string{"no such host"}},
+	{ErrorCategoryTLS, []string{"tls", "x509", "certificate"}},
+	{ErrorCategoryStatusCode, []string{"status code"}},
+}
+
+// WideEvent builds a single wide, flat event from the state of a check
+// execution; it contains all of the state attributes, the check details
+// (flattened as "details.*") and a categorical "error.category" for failed
+// checks, enabling high-cardinality analysis of health behavior over time.
+func WideEvent(state *health.State) map[string]interface{} {
+	event := map[string]interface{}{
+		"name":         state.Name,
+		"status":       state.Status,
+		"fatal":        state.Fatal,
+		"check_time":   state.CheckTime,
+		"duration_ms":  float64(state.Duration.Nanoseconds()) / 1e6,
+		"num_failures": state.ContiguousFailures,
+		"missed_ticks": state.MissedTicks,
+	}
+
+	if state.Err != "" {
+		event["error"] = state.Err
+		event["error.category"] = ErrorCategory(state.Err)
+	}
+
+	if !state.TimeOfFirstFailure.IsZero() {
+		event["first_failure_at"] = state.TimeOfFirstFailure
+	}
+
+	if state.Warning != "" {
+		event["warning"] = state.Warning
+	}
+
+	if state.IncidentID != "" {
+		event["incident_id"] = state.IncidentID
+	}
+
+	if state.Details != nil {
+		flattenDetails(event, "details", state.Details)
+	}
+
+	return event
+}
+
+// ErrorCategory maps an error message to one of the "ErrorCategory*" constants
+func ErrorCategory(err string) string {
+	lower := strings.ToLower(err)
+
+	for _, c := range errorCategories {
+		for _, s := range c.substrings {
+			if strings.Contains(lower, s) {
+				return c.category
+			}
+		}
+	}
+
+	return ErrorCategoryOther
+}
+
+// flattens the (JSON representation of the) details into dot separated fields
+func flattenDetails(event map[string]interface{}, prefix string, details interface{}) {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return
+	}
+
+	flatten(event, prefix, v)
+}
+
+func flatten(event map[string]interface{}, prefix string, v interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		event[prefix] = v
+		return
+	}
+
+	for k, child := range m {
+		flatten(event, prefix+"."+k, child)
+	}
+}
@@ -0,0 +1,63 @@
+package hooks
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+func TestWideEvent(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should contain all state attributes and flattened details", func(t *testing.T) {
+		now := time.Now()
+
+		event := WideEvent(&health.State{
+			Name:               "foo",
+			Status:             "failed",
+			Err:                "dial tcp: connection refused",
+			Fatal:              true,
+			CheckTime:          now,
+			Duration:           1500 * time.Microsecond,
+			ContiguousFailures: 2,
+			TimeOfFirstFailure: now,
+			IncidentID:         "abc",
+			Details: map[string]interface{}{
+				"pool": map[string]interface{}{"sockets_alive": 3},
+			},
+		})
+
+		Expect(event).To(HaveKeyWithValue("name", "foo"))
+		Expect(event).To(HaveKeyWithValue("status", "failed"))
+		Expect(event).To(HaveKeyWithValue("fatal", true))
+		Expect(event).To(HaveKeyWithValue("duration_ms", 1.5))
+		Expect(event).To(HaveKeyWithValue("num_failures", int64(2)))
+		Expect(event).To(HaveKeyWithValue("error.category", ErrorCategoryConnectionRefused))
+		Expect(event).To(HaveKeyWithValue("incident_id", "abc"))
+		Expect(event).To(HaveKeyWithValue("details.pool.sockets_alive", float64(3)))
+		Expect(event).To(HaveKey("first_failure_at"))
+	})
+
+	t.Run("Should omit empty attributes", func(t *testing.T) {
+		event := WideEvent(&health.State{Name: "foo", Status: "ok"})
+
+		Expect(event).ToNot(HaveKey("error"))
+		Expect(event).ToNot(HaveKey("error.category"))
+		Expect(event).ToNot(HaveKey("first_failure_at"))
+		Expect(event).ToNot(HaveKey("incident_id"))
+	})
+}
+
+func TestErrorCategory(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(ErrorCategory("context deadline exceeded")).To(Equal(ErrorCategoryTimeout))
+	Expect(ErrorCategory("dial tcp 127.0.0.1:1: connect: connection refused")).To(Equal(ErrorCategoryConnectionRefused))
+	Expect(ErrorCategory("lookup foo: no such host")).To(Equal(ErrorCategoryDNS))
+	Expect(ErrorCategory("x509: certificate has expired")).To(Equal(ErrorCategoryTLS))
+	Expect(ErrorCategory("Received status code '500' does not match")).To(Equal(ErrorCategoryStatusCode))
+	Expect(ErrorCategory("something else")).To(Equal(ErrorCategoryOther))
+}
@@ -0,0 +1,221 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const (
+	defaultZabbixTimeout = time.Duration(3) * time.Second
+	defaultZabbixPort    = "10051"
+)
+
+var zabbixHeader = []byte("ZBXD\x01")
+
+// ZabbixConfig is used for configuring the Zabbix sender hook.
+//
+// "Addr" is _required_; the address of the Zabbix server (or proxy) in
+// `host[:port]` format; the port defaults to `10051`.
+//
+// "Host" is _required_ (unless "HostFunc" is set); the name of the host in
+// Zabbix that the items belong to.
+//
+// "HostFunc" is optional; maps a check to a Zabbix host name.
+//
+// "KeyFunc" is optional; maps a check to a Zabbix item key; defaults to
+// `health.status[<check name>]`.
+//
+// "Timeout" is optional and defaults to "3s".
+//
+// "OnError" is optional; called if pushing a value to Zabbix failed.
+//
+// The value pushed for every check is `1` if the check is ok and `0` if it
+// has failed (items should be configured as "Zabbix trapper" items).
+type ZabbixConfig struct {
+	Addr     string
+	Host     string
+	HostFunc func(state *health.State) string
+	KeyFunc  func(state *health.State) string
+	Timeout  time.Duration
+	OnError  func(err error)
+}
+
+// Zabbix implements the "health.IResultHook" interface; it pushes the result
+// of every check to a Zabbix server via the Zabbix sender protocol.
+type Zabbix struct {
+	Config *ZabbixConfig
+}
+
+type zabbixItem struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock"`
+}
+
+type zabbixRequest struct {
+	Request string        `json:"request"`
+	Data    []*zabbixItem `json:"data"`
+}
+
+type zabbixResponse struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+}
+
+// NewZabbix creates a new Zabbix sender hook that can be added to "health.ResultHooks".
+func NewZabbix(cfg *ZabbixConfig) (*Zabbix, error) {
+	if err := validateZabbixConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate zabbix config: %v", err)
+	}
+
+	return &Zabbix{
+		Config: cfg,
+	}, nil
+}
+
+// CheckCompleted pushes the result of the check to Zabbix; it satisfies the
+// "health.IResultHook" interface.
+func (z *Zabbix) CheckCompleted(state *health.State) {
+	if err := z.Send(state); err != nil && z.Config.OnError != nil {
+		z.Config.OnError(err)
+	}
+}
+
+// Send pushes the result of the given checks to Zabbix in a single request.
+func (z *Zabbix) Send(states ...*health.State) error {
+	req := &zabbixRequest{
+		Request: "sender data",
+		Data:    make([]*zabbixItem, 0, len(states)),
+	}
+
+	for _, state := range states {
+		req.Data = append(req.Data, z.item(state))
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal zabbix request: %v", err)
+	}
+
+	conn, err := net.DialTimeout("tcp", z.Config.Addr, z.Config.Timeout)
+	if err != nil {
+		return fmt.Errorf("Unable to connect to zabbix: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(z.Config.Timeout))
+
+	if _, err := conn.Write(zabbixPacket(payload)); err != nil {
+		return fmt.Errorf("Unable to send data to zabbix: %v", err)
+	}
+
+	data, err := readZabbixPacket(conn)
+	if err != nil {
+		return fmt.Errorf("Unable to read zabbix response: %v", err)
+	}
+
+	resp := &zabbixResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("Unable to unmarshal zabbix response: %v", err)
+	}
+
+	if resp.Response != "success" {
+		return fmt.Errorf("Zabbix rejected data: %v", resp.Info)
+	}
+
+	// zabbix responds with "success" even if items were not processed
+	if strings.Contains(resp.Info, "failed: ") && !strings.Contains(resp.Info, "failed: 0") {
+		return fmt.Errorf("Zabbix failed to process data: %v", resp.Info)
+	}
+
+	return nil
+}
+
+func (z *Zabbix) item(state *health.State) *zabbixItem {
+	item := &zabbixItem{
+		Host:  z.Config.Host,
+		Key:   fmt.Sprintf("health.status[%v]", state.Name),
+		Value: "1",
+		Clock: state.CheckTime.Unix(),
+	}
+
+	if z.Config.HostFunc != nil {
+		item.Host = z.Config.HostFunc(state)
+	}
+
+	if z.Config.KeyFunc != nil {
+		item.Key = z.Config.KeyFunc(state)
+	}
+
+	if state.Status == "failed" {
+		item.Value = "0"
+	}
+
+	return item
+}
+
+func zabbixPacket(payload []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.Write(zabbixHeader)
+	binary.Write(buf, binary.LittleEndian, uint64(len(payload)))
+	buf.Write(payload)
+
+	return buf.Bytes()
+}
+
+func readZabbixPacket(r io.Reader) ([]byte, error) {
+	header := make([]byte, len(zabbixHeader))
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(header, zabbixHeader) {
+		return nil, errors.New("invalid header")
+	}
+
+	var length uint64
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func validateZabbixConfig(cfg *ZabbixConfig) error {
+	if cfg == nil {
+		return errors.New("Main config cannot be nil")
+	}
+
+	if cfg.Addr == "" {
+		return errors.New("Addr must be set")
+	}
+
+	if cfg.Host == "" && cfg.HostFunc == nil {
+		return errors.New("Either Host or HostFunc must be set")
+	}
+
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		cfg.Addr = net.JoinHostPort(cfg.Addr, defaultZabbixPort)
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultZabbixTimeout
+	}
+
+	return nil
+}
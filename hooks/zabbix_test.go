@@ -0,0 +1,157 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+// starts a fake zabbix server that responds with the given response and
+// forwards the received requests to the returned channel
+func newFakeZabbix(response *zabbixResponse) (net.Listener, <-chan *zabbixRequest) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	requests := make(chan *zabbixRequest, 10)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			data, err := readZabbixPacket(conn)
+			if err == nil {
+				req := &zabbixRequest{}
+				json.Unmarshal(data, req)
+				requests <- req
+
+				payload, _ := json.Marshal(response)
+				conn.Write(zabbixPacket(payload))
+			}
+
+			conn.Close()
+		}
+	}()
+
+	return ln, requests
+}
+
+func TestNewZabbix(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error with nil config", func(t *testing.T) {
+		_, err := NewZabbix(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Main config cannot be nil"))
+	})
+
+	t.Run("Should error without addr", func(t *testing.T) {
+		_, err := NewZabbix(&ZabbixConfig{Host: "foo"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Addr must be set"))
+	})
+
+	t.Run("Should error without host", func(t *testing.T) {
+		_, err := NewZabbix(&ZabbixConfig{Addr: "zabbix"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Either Host or HostFunc must be set"))
+	})
+
+	t.Run("Should set defaults", func(t *testing.T) {
+		z, err := NewZabbix(&ZabbixConfig{Addr: "zabbix", Host: "foo"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(z.Config.Addr).To(Equal("zabbix:10051"))
+		Expect(z.Config.Timeout).To(Equal(defaultZabbixTimeout))
+	})
+}
+
+func TestZabbixSend(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now()
+
+	t.Run("Happy path", func(t *testing.T) {
+		ln, requests := newFakeZabbix(&zabbixResponse{Response: "success", Info: "processed: 2; failed: 0; total: 2"})
+		defer ln.Close()
+
+		z, err := NewZabbix(&ZabbixConfig{Addr: ln.Addr().String(), Host: "my-service"})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = z.Send(
+			&health.State{Name: "foo", Status: "ok", CheckTime: now},
+			&health.State{Name: "bar", Status: "failed", CheckTime: now},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		var req *zabbixRequest
+		Eventually(requests).Should(Receive(&req))
+
+		Expect(req.Request).To(Equal("sender data"))
+		Expect(req.Data).To(HaveLen(2))
+		Expect(*req.Data[0]).To(Equal(zabbixItem{Host: "my-service", Key: "health.status[foo]", Value: "1", Clock: now.Unix()}))
+		Expect(*req.Data[1]).To(Equal(zabbixItem{Host: "my-service", Key: "health.status[bar]", Value: "0", Clock: now.Unix()}))
+	})
+
+	t.Run("Should use the host and key mapping", func(t *testing.T) {
+		ln, requests := newFakeZabbix(&zabbixResponse{Response: "success", Info: "processed: 1; failed: 0; total: 1"})
+		defer ln.Close()
+
+		z, err := NewZabbix(&ZabbixConfig{
+			Addr:     ln.Addr().String(),
+			HostFunc: func(state *health.State) string { return "host-" + state.Name },
+			KeyFunc:  func(state *health.State) string { return "custom.key" },
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(z.Send(&health.State{Name: "foo", Status: "ok"})).To(Succeed())
+
+		var req *zabbixRequest
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.Data[0].Host).To(Equal("host-foo"))
+		Expect(req.Data[0].Key).To(Equal("custom.key"))
+	})
+
+	t.Run("Should error if zabbix failed to process the data", func(t *testing.T) {
+		ln, _ := newFakeZabbix(&zabbixResponse{Response: "success", Info: "processed: 0; failed: 1; total: 1"})
+		defer ln.Close()
+
+		z, _ := NewZabbix(&ZabbixConfig{Addr: ln.Addr().String(), Host: "my-service"})
+
+		err := z.Send(&health.State{Name: "foo", Status: "ok"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Zabbix failed to process data"))
+	})
+
+	t.Run("Should report errors via OnError", func(t *testing.T) {
+		errs := make(chan error, 1)
+
+		z, _ := NewZabbix(&ZabbixConfig{
+			Addr:    "127.0.0.1:1",
+			Host:    "my-service",
+			OnError: func(err error) { errs <- err },
+		})
+
+		z.CheckCompleted(&health.State{Name: "foo", Status: "ok"})
+
+		var err error
+		Eventually(errs).Should(Receive(&err))
+		Expect(err.Error()).To(ContainSubstring("Unable to connect to zabbix"))
+	})
+
+	t.Run("Should reject invalid packets", func(t *testing.T) {
+		_, err := readZabbixPacket(errReader{})
+		Expect(err).To(HaveOccurred())
+	})
+}
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) { return 0, errors.New("boom") }
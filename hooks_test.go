@@ -0,0 +1,44 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type fakeResultHook struct {
+	states chan *State
+}
+
+func (f *fakeResultHook) CheckCompleted(state *State) {
+	f.states <- state
+}
+
+func TestResultHooks(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should dispatch the result of every check", func(t *testing.T) {
+		hook := &fakeResultHook{states: make(chan *State, 100)}
+
+		h := New()
+		h.DisableLogging()
+		h.ResultHooks = []IResultHook{hook}
+
+		h.AddChecks([]*Config{
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+		})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		time.Sleep(25 * time.Millisecond)
+
+		Expect(len(hook.states)).To(BeNumerically(">=", 2))
+
+		state := <-hook.states
+		Expect(state.Name).To(Equal("foo"))
+		Expect(state.Status).To(Equal("ok"))
+	})
+}
@@ -0,0 +1,179 @@
+package health
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// the maximum number of (open and resolved) incidents that are retained
+	maxIncidents = 100
+
+	// the maximum number of error samples that are recorded per incident
+	maxIncidentErrorSamples = 10
+)
+
+// Incident is created when a check transitions to failing and is resolved once
+// the check recovers. Its ID is attached to the states passed to the status
+// listener ("State.IncidentID"), so downstream systems can correlate
+// failure and recovery events.
+type Incident struct {
+	// ID uniquely identifies the incident
+	ID string `json:"id"`
+
+	// Check is the name of the failing check
+	Check string `json:"check"`
+
+	// Fatal shows if the failing check affects the global result
+	Fatal bool `json:"fatal,omitempty"`
+
+	// Start is the time of the first failure
+	Start time.Time `json:"start"`
+
+	// End is the time of the recovery; nil while the incident is open
+	End *time.Time `json:"end,omitempty"`
+
+	// Failures is the number of failed checks during the incident
+	Failures int64 `json:"failures"`
+
+	// Errors contains samples of the (distinct, consecutive) errors returned by
+	// the check during the incident
+	Errors []string `json:"errors"`
+}
+
+// Open indicates whether the incident is still ongoing
+func (i *Incident) Open() bool {
+	return i.End == nil
+}
+
+// Duration returns the duration of the incident; for open incidents, the
+// duration up until now
+func (i *Incident) Duration() time.Duration {
+	if i.End == nil {
+		return time.Since(i.Start)
+	}
+
+	return i.End.Sub(i.Start)
+}
+
+// MarshalJSON adds the (computed) duration of the incident to its JSON representation
+func (i Incident) MarshalJSON() ([]byte, error) {
+	type incident Incident
+
+	return json.Marshal(&struct {
+		incident
+		Duration string `json:"duration"`
+	}{
+		incident: incident(i),
+		Duration: i.Duration().String(),
+	})
+}
+
+func (i *Incident) copy() Incident {
+	c := *i
+	c.Errors = append([]string{}, i.Errors...)
+
+	return c
+}
+
+// Incidents will return the retained incidents (thread-safe), oldest first.
+func (h *Health) Incidents() []Incident {
+	h.incidentsLock.Lock()
+	defer h.incidentsLock.Unlock()
+
+	incidents := make([]Incident, 0, len(h.incidents))
+	for _, i := range h.incidents {
+		incidents = append(incidents, i.copy())
+	}
+
+	return incidents
+}
+
+// Incident will return the incident with the given ID (thread-safe) and
+// whether it was found.
+func (h *Health) Incident(id string) (Incident, bool) {
+	h.incidentsLock.Lock()
+	defer h.incidentsLock.Unlock()
+
+	for _, i := range h.incidents {
+		if i.ID == id {
+			return i.copy(), true
+		}
+	}
+
+	return Incident{}, false
+}
+
+// records a failed check; opens a new incident if there is no open incident
+// for the check yet. Returns the ID of the incident.
+func (h *Health) recordIncidentFailure(stateEntry *State) string {
+	h.incidentsLock.Lock()
+	defer h.incidentsLock.Unlock()
+
+	incident, ok := h.openIncidents[stateEntry.Name]
+	if !ok {
+		incident = &Incident{
+			ID:     newIncidentID(),
+			Check:  stateEntry.Name,
+			Fatal:  stateEntry.Fatal,
+			Start:  stateEntry.CheckTime,
+			Errors: make([]string, 0),
+		}
+
+		h.openIncidents[stateEntry.Name] = incident
+		h.incidents = append(h.incidents, incident)
+
+		if len(h.incidents) > maxIncidents {
+			h.incidents = h.incidents[len(h.incidents)-maxIncidents:]
+		}
+	}
+
+	incident.Failures++
+
+	samples := len(incident.Errors)
+	if samples < maxIncidentErrorSamples && (samples == 0 || incident.Errors[samples-1] != stateEntry.Err) {
+		incident.Errors = append(incident.Errors, stateEntry.Err)
+	}
+
+	return incident.ID
+}
+
+// resolves the open incident of the check (if any); returns the ID of the incident
+func (h *Health) resolveIncident(name string, end time.Time) string {
+	h.incidentsLock.Lock()
+	defer h.incidentsLock.Unlock()
+
+	incident, ok := h.openIncidents[name]
+	if !ok {
+		return ""
+	}
+
+	incident.End = &end
+	delete(h.openIncidents, name)
+
+	return incident.ID
+}
+
+// resolves all open incidents (ie. when the checks are stopped)
+func (h *Health) resolveAllIncidents() {
+	h.incidentsLock.Lock()
+	names := make([]string, 0, len(h.openIncidents))
+	for name := range h.openIncidents {
+		names = append(names, name)
+	}
+	h.incidentsLock.Unlock()
+
+	now := h.clock().Now()
+	for _, name := range names {
+		h.resolveIncident(name, now)
+	}
+}
+
+func newIncidentID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestIncidents(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should open and resolve an incident", func(t *testing.T) {
+		checker := newToggleChecker(errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker, Interval: testCheckInterval, Fatal: true},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		time.Sleep(25 * time.Millisecond)
+
+		incidents := h.Incidents()
+		Expect(incidents).To(HaveLen(1))
+		Expect(incidents[0].ID).ToNot(BeEmpty())
+		Expect(incidents[0].Check).To(Equal("foo"))
+		Expect(incidents[0].Fatal).To(BeTrue())
+		Expect(incidents[0].Open()).To(BeTrue())
+		Expect(incidents[0].Failures).To(BeNumerically(">=", 2))
+
+		// consecutive identical errors are only sampled once
+		Expect(incidents[0].Errors).To(Equal([]string{"things broke"}))
+
+		states, _, _ := h.State()
+		Expect(states["foo"].IncidentID).To(Equal(incidents[0].ID))
+
+		checker.SetErr(nil)
+		time.Sleep(15 * time.Millisecond)
+
+		incident, ok := h.Incident(incidents[0].ID)
+		Expect(ok).To(BeTrue())
+		Expect(incident.Open()).To(BeFalse())
+		Expect(incident.Duration()).To(BeNumerically(">", 0))
+
+		// a new failure should open a new incident
+		checker.SetErr(errors.New("things broke again"))
+		time.Sleep(15 * time.Millisecond)
+
+		incidents = h.Incidents()
+		Expect(incidents).To(HaveLen(2))
+		Expect(incidents[1].ID).ToNot(Equal(incidents[0].ID))
+		Expect(incidents[1].Open()).To(BeTrue())
+	})
+
+	t.Run("Should pass the incident ID to the status listener", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		failed := &State{Name: "foo", Status: "failed", Err: "boom", CheckTime: time.Now()}
+		h.safeUpdateState(failed)
+		Expect(failed.IncidentID).ToNot(BeEmpty())
+
+		recovered := &State{Name: "foo", Status: "ok", CheckTime: time.Now()}
+		h.safeUpdateState(recovered)
+		Expect(recovered.IncidentID).To(Equal(failed.IncidentID))
+	})
+
+	t.Run("Should sample distinct errors up to a limit", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		for i := 0; i < maxIncidentErrorSamples*2; i++ {
+			h.safeUpdateState(&State{Name: "foo", Status: "failed", Err: string(rune('a' + i)), CheckTime: time.Now()})
+		}
+
+		incidents := h.Incidents()
+		Expect(incidents).To(HaveLen(1))
+		Expect(incidents[0].Errors).To(HaveLen(maxIncidentErrorSamples))
+		Expect(incidents[0].Failures).To(Equal(int64(maxIncidentErrorSamples * 2)))
+	})
+
+	t.Run("Should only retain a limited number of incidents", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		for i := 0; i < maxIncidents+5; i++ {
+			h.safeUpdateState(&State{Name: "foo", Status: "failed", Err: "boom", CheckTime: time.Now()})
+			h.safeUpdateState(&State{Name: "foo", Status: "ok", CheckTime: time.Now()})
+		}
+
+		Expect(h.Incidents()).To(HaveLen(maxIncidents))
+	})
+
+	t.Run("Should resolve open incidents on stop", func(t *testing.T) {
+		h, _, err := setupRunners(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		h.safeUpdateState(&State{Name: "baz", Status: "failed", Err: "boom", CheckTime: time.Now()})
+		Expect(h.Incidents()[0].Open()).To(BeTrue())
+
+		Expect(h.Stop()).ToNot(HaveOccurred())
+		Expect(h.Incidents()[0].Open()).To(BeFalse())
+	})
+
+	t.Run("Should include the duration in the JSON representation", func(t *testing.T) {
+		start := time.Now()
+		end := start.Add(time.Minute)
+
+		data, err := json.Marshal(Incident{ID: "foo", Start: start, End: &end})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"id":"foo"`))
+		Expect(string(data)).To(ContainSubstring(`"duration":"1m0s"`))
+	})
+}
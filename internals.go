@@ -0,0 +1,120 @@
+package health
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RunnerStats contains the internals of the runner of the checks, so that
+// operators can monitor the monitor (see "h.RunnerStats()").
+type RunnerStats struct {
+	// Checks contains the stats of every running check, by name
+	Checks map[string]CheckRunnerStats `json:"checks"`
+
+	// PendingHooks is the number of "ResultHooks" calls that have not
+	// returned yet
+	PendingHooks int64 `json:"pending_hooks"`
+
+	// ListenerQueueDepth is the number of events that are queued for the
+	// "StateListeners"
+	ListenerQueueDepth int `json:"listener_queue_depth"`
+
+	// DroppedEvents is the number of events that have been dropped since the
+	// health instance was created, because a state listener or a subscriber
+	// (see "h.Subscribe()") has fallen behind
+	DroppedEvents int64 `json:"dropped_events"`
+
+	// Goroutines is the number of goroutines of the process
+	Goroutines int `json:"goroutines"`
+}
+
+// CheckRunnerStats contains the internals of the runner of a single check.
+type CheckRunnerStats struct {
+	// Active is the number of executions of the check that are in flight
+	// (or waiting for a worker); at most one, as concurrent executions are
+	// coalesced
+	Active int64 `json:"active"`
+
+	// MissedTicks is the number of ticks that fired while the check was in
+	// flight (see "State.MissedTicks")
+	MissedTicks int64 `json:"missed_ticks"`
+
+	// CoalescedRuns is the number of executions (ie. via "h.RunCheck()")
+	// that shared the state of the execution in flight instead of executing
+	// the check again
+	CoalescedRuns int64 `json:"coalesced_runs"`
+
+	// TickDrift is how much later (or earlier, if negative) than expected the
+	// latest tick has fired; zero for scheduled and lazy checks
+	TickDrift time.Duration `json:"tick_drift"`
+}
+
+// RunnerStats returns the internals of the runner; the stats of the checks
+// are empty if the healthcheck is not running.
+func (h *Health) RunnerStats() RunnerStats {
+	stats := RunnerStats{
+		Checks:        make(map[string]CheckRunnerStats),
+		PendingHooks:  atomic.LoadInt64(&h.pendingHooks),
+		DroppedEvents: atomic.LoadInt64(&h.droppedEvents),
+		Goroutines:    runtime.NumGoroutine(),
+	}
+
+	h.runnersLock.Lock()
+	for name, r := range h.runners {
+		stats.Checks[name] = CheckRunnerStats{
+			Active:        atomic.LoadInt64(&r.active),
+			MissedTicks:   atomic.LoadInt64(&r.missedTicks),
+			CoalescedRuns: atomic.LoadInt64(&r.coalesced),
+			TickDrift:     r.drift.latest(),
+		}
+	}
+	h.runnersLock.Unlock()
+
+	h.dispatchersLock.Lock()
+	for _, d := range h.dispatchers {
+		stats.ListenerQueueDepth += len(d.events)
+	}
+	h.dispatchersLock.Unlock()
+
+	return stats
+}
+
+// tickDrift tracks how much the ticks of a check deviate from their expected
+// time
+type tickDrift struct {
+	lock     sync.Mutex
+	next     time.Time
+	interval time.Duration
+	drift    time.Duration
+}
+
+// records that the next tick is expected after "interval"
+func (d *tickDrift) expect(now time.Time, interval time.Duration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.next = now.Add(interval)
+	d.interval = interval
+}
+
+// records a tick; the following tick is expected after the same interval
+func (d *tickDrift) tick(now time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !d.next.IsZero() {
+		d.drift = now.Sub(d.next)
+	}
+
+	d.next = now.Add(d.interval)
+}
+
+// returns the drift of the latest tick
+func (d *tickDrift) latest() time.Duration {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.drift
+}
@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type blockingResultHook struct {
+	release chan struct{}
+}
+
+func (b *blockingResultHook) CheckCompleted(state *State) {
+	<-b.release
+}
+
+func TestRunnerStats(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should expose the active executions and pending hooks", func(t *testing.T) {
+		release := make(chan struct{})
+		hook := &blockingResultHook{release: make(chan struct{})}
+
+		h := setupNewTestHealth()
+		h.ResultHooks = []IResultHook{hook}
+		h.AddCheck(&Config{
+			Name: "slow",
+			Checker: CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				<-release
+				return nil, nil
+			}),
+			Interval: time.Hour,
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(func() int64 { return h.RunnerStats().Checks["slow"].Active }).Should(Equal(int64(1)))
+		Expect(h.RunnerStats().PendingHooks).To(BeZero())
+
+		close(release)
+
+		Eventually(func() int64 { return h.RunnerStats().Checks["slow"].Active }).Should(BeZero())
+		Eventually(func() int64 { return h.RunnerStats().PendingHooks }).Should(Equal(int64(1)))
+
+		close(hook.release)
+
+		Eventually(func() int64 { return h.RunnerStats().PendingHooks }).Should(BeZero())
+	})
+
+	t.Run("Should count the dropped events", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		_, cancel := h.Subscribe()
+		defer cancel()
+
+		h.statesLock.Lock()
+		for i := 0; i < subscriptionBufferSize+2; i++ {
+			h.publishStateChange(StateChange{Name: "foo"})
+		}
+		h.statesLock.Unlock()
+
+		Expect(h.RunnerStats().DroppedEvents).To(Equal(int64(2)))
+	})
+
+	t.Run("Should not expose checks while stopped", func(t *testing.T) {
+		h := setupNewTestHealth()
+		Expect(h.RunnerStats().Checks).To(BeEmpty())
+	})
+}
+
+func TestTickDrift(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now()
+
+	d := &tickDrift{}
+	d.tick(now)
+	Expect(d.latest()).To(BeZero())
+
+	d.expect(now, 10*time.Second)
+	d.tick(now.Add(12 * time.Second))
+	Expect(d.latest()).To(Equal(2 * time.Second))
+
+	d.tick(now.Add(21 * time.Second))
+	Expect(d.latest()).To(Equal(-time.Second))
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/InVisionApp/go-health/retry"
+)
+
+const (
+	// a check run is considered slow if it takes at least this fraction of the interval
+	adaptiveSlowRatio = 0.8
+
+	// a check run is considered fast if it takes at most this fraction of the interval
+	adaptiveFastRatio = 0.4
+
+	// the number of consecutive slow (or fast) runs before the interval is adapted
+	adaptiveStreak = 3
+
+	// the default "Config.MaxInterval", as a multiple of "Config.Interval"
+	defaultMaxIntervalFactor = 10
+)
+
+// intervalAdapter stretches the interval of a check that consistently takes
+// close to (or longer than) its interval and shrinks it back (down to the
+// configured interval) once the check becomes fast again.
+type intervalAdapter struct {
+	base    time.Duration
+	max     time.Duration
+	current time.Duration
+
+	slow int
+	fast int
+}
+
+func newIntervalAdapter(cfg *Config) *intervalAdapter {
+	max := cfg.MaxInterval
+	if max <= 0 {
+		max = cfg.Interval * defaultMaxIntervalFactor
+	}
+
+	return &intervalAdapter{
+		base:    cfg.Interval,
+		max:     max,
+		current: cfg.Interval,
+	}
+}
+
+// observe records the duration of a check run; returns the new interval and
+// whether it has changed.
+func (a *intervalAdapter) observe(took time.Duration) (time.Duration, bool) {
+	switch {
+	case float64(took) >= float64(a.current)*adaptiveSlowRatio:
+		a.slow++
+		a.fast = 0
+	case float64(took) <= float64(a.current)*adaptiveFastRatio:
+		a.fast++
+		a.slow = 0
+	default:
+		a.slow = 0
+		a.fast = 0
+	}
+
+	prev := a.current
+
+	if a.slow >= adaptiveStreak && a.current < a.max {
+		a.current *= 2
+		if a.current > a.max {
+			a.current = a.max
+		}
+		a.slow = 0
+	}
+
+	if a.fast >= adaptiveStreak && a.current > a.base {
+		a.current /= 2
+		if a.current < a.base {
+			a.current = a.base
+		}
+		a.fast = 0
+	}
+
+	return a.current, a.current != prev
+}
+
+// warning returns a warning message if the interval is currently stretched
+func (a *intervalAdapter) warning() string {
+	if a.current <= a.base {
+		return ""
+	}
+
+	return fmt.Sprintf("check is slow; interval stretched from %v to %v", a.base, a.current)
+}
+
+// failureBackoff doubles the interval of a check on every failed execution
+// and restores it once the check recovers.
+type failureBackoff struct {
+	max      time.Duration
+	failures int // number of consecutive failed executions
+	current  time.Duration
+}
+
+func newFailureBackoff(cfg *Config) *failureBackoff {
+	max := cfg.MaxBackoff
+	if max <= 0 {
+		max = cfg.Interval * defaultMaxIntervalFactor
+	}
+
+	return &failureBackoff{
+		max: max,
+	}
+}
+
+// observe records whether the check is failing; returns the interval to use
+// instead of the given (regular) interval and whether it has changed.
+func (b *failureBackoff) observe(failing bool, interval time.Duration) (time.Duration, bool) {
+	prev := b.current
+
+	if !failing {
+		b.failures = 0
+		b.current = 0
+		return interval, prev != 0
+	}
+
+	// the same doubling as the exponential retry policy, without a limit
+	b.failures++
+	b.current, _ = retry.Exponential{Initial: 2 * interval, Max: b.max, Retries: -1}.Next(b.failures)
+
+	if b.current < interval {
+		return interval, false
+	}
+
+	return b.current, b.current != prev
+}
+
+// warning returns a warning message if the interval is currently backed off
+func (b *failureBackoff) warning() string {
+	if b.current == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("check is failing; interval backed off to %v", b.current)
+}
+
+// jitterInterval randomizes the interval by up to +/- the given fraction
+func jitterInterval(interval time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+
+	jittered := retry.Jitter(interval, jitter)
+
+	// never let the ticker spin
+	if jittered <= 0 {
+		return time.Millisecond
+	}
+
+	return jittered
+}
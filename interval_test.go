@@ -0,0 +1,290 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestIntervalAdapter(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should stretch the interval after consecutive slow runs", func(t *testing.T) {
+		a := newIntervalAdapter(&Config{Interval: 10 * time.Millisecond})
+
+		for i := 0; i < adaptiveStreak-1; i++ {
+			_, changed := a.observe(9 * time.Millisecond)
+			Expect(changed).To(BeFalse())
+		}
+
+		interval, changed := a.observe(12 * time.Millisecond)
+		Expect(changed).To(BeTrue())
+		Expect(interval).To(Equal(20 * time.Millisecond))
+		Expect(a.warning()).To(ContainSubstring("interval stretched from 10ms to 20ms"))
+	})
+
+	t.Run("Should not stretch the interval beyond the max interval", func(t *testing.T) {
+		a := newIntervalAdapter(&Config{Interval: 10 * time.Millisecond, MaxInterval: 15 * time.Millisecond})
+
+		for i := 0; i < adaptiveStreak*3; i++ {
+			a.observe(time.Second)
+		}
+
+		Expect(a.current).To(Equal(15 * time.Millisecond))
+	})
+
+	t.Run("Should default the max interval", func(t *testing.T) {
+		a := newIntervalAdapter(&Config{Interval: 10 * time.Millisecond})
+		Expect(a.max).To(Equal(100 * time.Millisecond))
+	})
+
+	t.Run("Should shrink the interval back once the check is fast again", func(t *testing.T) {
+		a := newIntervalAdapter(&Config{Interval: 10 * time.Millisecond})
+
+		for i := 0; i < adaptiveStreak; i++ {
+			a.observe(10 * time.Millisecond)
+		}
+		Expect(a.current).To(Equal(20 * time.Millisecond))
+
+		for i := 0; i < adaptiveStreak; i++ {
+			a.observe(time.Millisecond)
+		}
+		Expect(a.current).To(Equal(10 * time.Millisecond))
+		Expect(a.warning()).To(BeEmpty())
+
+		// should not shrink below the configured interval
+		for i := 0; i < adaptiveStreak; i++ {
+			_, changed := a.observe(time.Millisecond)
+			Expect(changed).To(BeFalse())
+		}
+	})
+
+	t.Run("Should reset the streak on a normal run", func(t *testing.T) {
+		a := newIntervalAdapter(&Config{Interval: 10 * time.Millisecond})
+
+		a.observe(9 * time.Millisecond)
+		a.observe(9 * time.Millisecond)
+		a.observe(6 * time.Millisecond)
+		_, changed := a.observe(9 * time.Millisecond)
+
+		Expect(changed).To(BeFalse())
+	})
+}
+
+func TestAdaptiveInterval(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Slow check should report a warning state", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusStub = func() (interface{}, error) {
+			time.Sleep(9 * time.Millisecond)
+			return nil, nil
+		}
+
+		cfgs := []*Config{
+			{
+				Name:             "foo",
+				Checker:          checker,
+				Interval:         testCheckInterval,
+				AdaptiveInterval: true,
+			},
+		}
+
+		h, _, err := setupRunners(cfgs, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() string {
+			states, _, _ := h.State()
+			return states["foo"].Warning
+		}, "500ms").Should(ContainSubstring("interval stretched"))
+
+		states, _, _ := h.State()
+		Expect(states["foo"].Status).To(Equal("ok"))
+	})
+}
+
+func TestJitterInterval(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should not alter the interval without jitter", func(t *testing.T) {
+		Expect(jitterInterval(time.Second, 0)).To(Equal(time.Second))
+	})
+
+	t.Run("Should randomize the interval within the jitter window", func(t *testing.T) {
+		seen := map[time.Duration]bool{}
+
+		for i := 0; i < 100; i++ {
+			interval := jitterInterval(time.Second, 0.1)
+			Expect(interval).To(BeNumerically(">=", 900*time.Millisecond))
+			Expect(interval).To(BeNumerically("<=", 1100*time.Millisecond))
+			seen[interval] = true
+		}
+
+		Expect(len(seen)).To(BeNumerically(">", 1))
+	})
+
+	t.Run("Should cap the jitter at 1", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			interval := jitterInterval(time.Second, 5)
+			Expect(interval).To(BeNumerically(">", 0))
+			Expect(interval).To(BeNumerically("<=", 2*time.Second))
+		}
+	})
+}
+
+func TestJitter(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should keep running checks with jittered intervals", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:     "foo",
+				Checker:  checker,
+				Interval: testCheckInterval,
+				Jitter:   0.5,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		// with +/- 50% jitter, 100ms fit between 6 and 20 intervals
+		time.Sleep(100 * time.Millisecond)
+		Expect(checker.StatusCallCount()).To(BeNumerically(">=", 6))
+		Expect(checker.StatusCallCount()).To(BeNumerically("<=", 21))
+	})
+}
+
+func TestInitialDelay(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should execute the check after the initial delay, then on the interval", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:         "foo",
+				Checker:      checker,
+				Interval:     testCheckInterval,
+				InitialDelay: 50 * time.Millisecond,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		time.Sleep(30 * time.Millisecond)
+		Expect(checker.StatusCallCount()).To(Equal(0))
+
+		states, _, _ := h.State()
+		Expect(states).ToNot(HaveKey("foo"))
+
+		// first execution at 50ms, then every 10ms
+		time.Sleep(70 * time.Millisecond)
+		Expect(checker.StatusCallCount()).To(BeNumerically(">=", 4))
+		Expect(checker.StatusCallCount()).To(BeNumerically("<=", 6))
+	})
+
+	t.Run("Should not wait for delayed checks on start", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.StartTimeout = 20 * time.Millisecond
+
+		h.AddCheck(&Config{
+			Name:         "foo",
+			Checker:      checker,
+			Interval:     testCheckInterval,
+			InitialDelay: time.Minute,
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(checker.StatusCallCount()).To(Equal(0))
+	})
+}
+
+func TestFailureBackoff(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should double the interval while failing, up to the max", func(t *testing.T) {
+		b := newFailureBackoff(&Config{Interval: 10 * time.Millisecond, MaxBackoff: 30 * time.Millisecond})
+
+		interval, changed := b.observe(true, 10*time.Millisecond)
+		Expect(changed).To(BeTrue())
+		Expect(interval).To(Equal(20 * time.Millisecond))
+		Expect(b.warning()).To(ContainSubstring("interval backed off to 20ms"))
+
+		interval, changed = b.observe(true, 10*time.Millisecond)
+		Expect(changed).To(BeTrue())
+		Expect(interval).To(Equal(30 * time.Millisecond))
+
+		interval, changed = b.observe(true, 10*time.Millisecond)
+		Expect(changed).To(BeFalse())
+		Expect(interval).To(Equal(30 * time.Millisecond))
+	})
+
+	t.Run("Should restore the interval once the check recovers", func(t *testing.T) {
+		b := newFailureBackoff(&Config{Interval: 10 * time.Millisecond})
+
+		b.observe(true, 10*time.Millisecond)
+
+		interval, changed := b.observe(false, 10*time.Millisecond)
+		Expect(changed).To(BeTrue())
+		Expect(interval).To(Equal(10 * time.Millisecond))
+		Expect(b.warning()).To(BeEmpty())
+
+		_, changed = b.observe(false, 10*time.Millisecond)
+		Expect(changed).To(BeFalse())
+	})
+
+	t.Run("Should default the max backoff to 10x the interval", func(t *testing.T) {
+		b := newFailureBackoff(&Config{Interval: 10 * time.Millisecond})
+		Expect(b.max).To(Equal(100 * time.Millisecond))
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should back off the interval of a failing check and restore it on recovery", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+		checker.StatusReturnsOnCall(1, nil, errors.New("things broke"))
+		checker.StatusReturnsOnCall(2, nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:       "foo",
+				Checker:    checker,
+				Interval:   testCheckInterval,
+				Backoff:    true,
+				MaxBackoff: 40 * time.Millisecond,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		// executions at 0ms and 20ms; the next one is at 60ms
+		time.Sleep(50 * time.Millisecond)
+		Expect(checker.StatusCallCount()).To(Equal(2))
+
+		states, _, _ := h.State()
+		Expect(states["foo"].Warning).To(ContainSubstring("interval backed off to 40ms"))
+
+		// failure at 60ms, recovery at 100ms, then every 10ms
+		time.Sleep(100 * time.Millisecond)
+		Expect(checker.StatusCallCount()).To(BeNumerically(">=", 6))
+
+		states, _, _ = h.State()
+		Expect(states["foo"].Status).To(Equal("ok"))
+		Expect(states["foo"].Warning).To(BeEmpty())
+	})
+}
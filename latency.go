@@ -0,0 +1,72 @@
+package health
+
+import (
+	"sort"
+	"time"
+)
+
+// LatencyStats summarizes the durations of the latest executions of a check
+// (see "Health.LatencyWindow").
+type LatencyStats struct {
+	// Samples is the number of executions the stats are computed from
+	Samples int `json:"samples"`
+
+	Min time.Duration `json:"min"`
+	Max time.Duration `json:"max"`
+	Avg time.Duration `json:"avg"`
+	P95 time.Duration `json:"p95"`
+}
+
+// latencyWindow is a sliding window of the latest durations of a check; it
+// is not thread-safe
+type latencyWindow struct {
+	samples []time.Duration
+	next    int // index of the next (and oldest, if full) sample
+	full    bool
+}
+
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{samples: make([]time.Duration, size)}
+}
+
+// adds the duration, evicting the oldest one if the window is full
+func (w *latencyWindow) observe(d time.Duration) {
+	w.samples[w.next] = d
+
+	w.next = (w.next + 1) % len(w.samples)
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+// returns the stats of the samples in the window; nil if it is empty
+func (w *latencyWindow) stats() *LatencyStats {
+	n := w.next
+	if w.full {
+		n = len(w.samples)
+	}
+
+	if n == 0 {
+		return nil
+	}
+
+	sorted := make([]time.Duration, n)
+	copy(sorted, w.samples[:n])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+
+	// nearest-rank percentile
+	rank := (95*n + 99) / 100
+
+	return &LatencyStats{
+		Samples: n,
+		Min:     sorted[0],
+		Max:     sorted[n-1],
+		Avg:     total / time.Duration(n),
+		P95:     sorted[rank-1],
+	}
+}
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestLatencyStats(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should summarize the latest durations of a check", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		durations := []time.Duration{30, 10, 20, 60}
+
+		checker := &fakes.FakeICheckable{}
+		checker.StatusStub = func() (interface{}, error) {
+			clock.Advance(durations[0] * time.Millisecond)
+			durations = durations[1:]
+			return nil, nil
+		}
+
+		h := setupNewTestHealth()
+		h.Clock = clock
+		h.LatencyWindow = 3
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: checker, Interval: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		state, _ := h.lookupState("foo")
+		Expect(state.Latency).To(Equal(&LatencyStats{
+			Samples: 1,
+			Min:     30 * time.Millisecond,
+			Max:     30 * time.Millisecond,
+			Avg:     30 * time.Millisecond,
+			P95:     30 * time.Millisecond,
+		}))
+
+		for range durations {
+			_, err := h.RunCheck("foo")
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		// the first duration has slid out of the window
+		state, _ = h.lookupState("foo")
+		Expect(state.Latency).To(Equal(&LatencyStats{
+			Samples: 3,
+			Min:     10 * time.Millisecond,
+			Max:     60 * time.Millisecond,
+			Avg:     30 * time.Millisecond,
+			P95:     60 * time.Millisecond,
+		}))
+	})
+
+	t.Run("Should not compute stats by default", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		state, _ := h.lookupState("foo")
+		Expect(state.Latency).To(BeNil())
+	})
+}
+
+func TestLatencyWindow(t *testing.T) {
+	RegisterTestingT(t)
+
+	w := newLatencyWindow(100)
+	Expect(w.stats()).To(BeNil())
+
+	for i := 1; i <= 100; i++ {
+		w.observe(time.Duration(i))
+	}
+
+	stats := w.stats()
+	Expect(stats.Samples).To(Equal(100))
+	Expect(stats.Min).To(Equal(time.Duration(1)))
+	Expect(stats.Max).To(Equal(time.Duration(100)))
+	Expect(stats.P95).To(Equal(time.Duration(95)))
+
+	// the oldest samples are evicted first
+	for i := 0; i < 10; i++ {
+		w.observe(0)
+	}
+
+	stats = w.stats()
+	Expect(stats.Samples).To(Equal(100))
+	Expect(stats.Min).To(Equal(time.Duration(0)))
+	Expect(stats.P95).To(Equal(time.Duration(95)))
+}
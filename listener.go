@@ -0,0 +1,178 @@
+package health
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+const (
+	// the maximum number of events that are queued per state listener; further
+	// events are dropped until the listener catches up
+	stateListenerQueueSize = 100
+)
+
+// IStateListener is an interface that is notified about completed checks and
+// state transitions, ie. for alerting or logging.
+//
+// As opposed to "IStatusListener", the callbacks of a state listener are
+// dispatched asynchronously (and in order) from a dedicated goroutine per
+// listener, so that a slow listener can never block the execution of checks.
+// If a listener falls too far behind, events are dropped (and a warning is
+// logged) until it catches up.
+type IStateListener interface {
+	// OnCheckCompleted is called after every execution of a check.
+	// 	* state - The recorded state of the check
+	OnCheckCompleted(state *State)
+
+	// OnCheckFailed is called when a check transitions from passing to failing.
+	// 	* state - The recorded state of the check that triggered the failure
+	OnCheckFailed(state *State)
+
+	// OnCheckRecovered is called when a failed check recovers.
+	// 	* state - The recorded state of the check that triggered the recovery
+	// 	* recordedFailures - the total failed health checks that lapsed
+	// 	  between the failure and recovery
+	//	* failureDurationSeconds - the lapsed time, in seconds, of the recovered failure
+	OnCheckRecovered(state *State, recordedFailures int64, failureDurationSeconds float64)
+
+	// OnOverallStateChange is called when the overall status of the
+	// healthcheck changes ("ok", "degraded" or "failed"; the overall status
+	// has failed if any fatal check has failed and is degraded if any check
+	// is degraded).
+	// 	* prevStatus - The previous overall status
+	// 	* status - The new overall status
+	OnOverallStateChange(prevStatus, status string)
+}
+
+// StateListenerFuncs implements the "IStateListener" interface using
+// (optional) functions, so that only the callbacks of interest need to be
+// defined.
+type StateListenerFuncs struct {
+	CheckCompleted     func(state *State)
+	CheckFailed        func(state *State)
+	CheckRecovered     func(state *State, recordedFailures int64, failureDurationSeconds float64)
+	OverallStateChange func(prevStatus, status string)
+}
+
+// OnCheckCompleted calls "CheckCompleted" (if defined).
+func (f *StateListenerFuncs) OnCheckCompleted(state *State) {
+	if f.CheckCompleted != nil {
+		f.CheckCompleted(state)
+	}
+}
+
+// OnCheckFailed calls "CheckFailed" (if defined).
+func (f *StateListenerFuncs) OnCheckFailed(state *State) {
+	if f.CheckFailed != nil {
+		f.CheckFailed(state)
+	}
+}
+
+// OnCheckRecovered calls "CheckRecovered" (if defined).
+func (f *StateListenerFuncs) OnCheckRecovered(state *State, recordedFailures int64, failureDurationSeconds float64) {
+	if f.CheckRecovered != nil {
+		f.CheckRecovered(state, recordedFailures, failureDurationSeconds)
+	}
+}
+
+// OnOverallStateChange calls "OverallStateChange" (if defined).
+func (f *StateListenerFuncs) OnOverallStateChange(prevStatus, status string) {
+	if f.OverallStateChange != nil {
+		f.OverallStateChange(prevStatus, status)
+	}
+}
+
+// listenerDispatcher delivers the events of a single state listener, in
+// order, from its own goroutine
+type listenerDispatcher struct {
+	listener IStateListener
+	events   chan func(IStateListener)
+
+	// guards against dispatching to a closed dispatcher
+	lock   sync.RWMutex
+	closed bool
+}
+
+func newListenerDispatcher(listener IStateListener) *listenerDispatcher {
+	d := &listenerDispatcher{
+		listener: listener,
+		events:   make(chan func(IStateListener), stateListenerQueueSize),
+	}
+
+	go func() {
+		for event := range d.events {
+			event(d.listener)
+		}
+	}()
+
+	return d
+}
+
+// queues the event; returns false if the event had to be dropped
+func (d *listenerDispatcher) dispatch(event func(IStateListener)) bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	// events of checks that were still in flight during "h.Stop()"
+	if d.closed {
+		return true
+	}
+
+	select {
+	case d.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// stops the dispatcher once all queued events have been delivered
+func (d *listenerDispatcher) close() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !d.closed {
+		d.closed = true
+		close(d.events)
+	}
+}
+
+// starts a dispatcher for every state listener
+func (h *Health) startStateListeners() {
+	dispatchers := make([]*listenerDispatcher, 0, len(h.StateListeners))
+	for _, l := range h.StateListeners {
+		dispatchers = append(dispatchers, newListenerDispatcher(l))
+	}
+
+	h.dispatchersLock.Lock()
+	h.dispatchers = dispatchers
+	h.dispatchersLock.Unlock()
+}
+
+// stops the dispatchers of the state listeners
+func (h *Health) stopStateListeners() {
+	h.dispatchersLock.Lock()
+	dispatchers := h.dispatchers
+	h.dispatchers = nil
+	h.dispatchersLock.Unlock()
+
+	for _, d := range dispatchers {
+		d.close()
+	}
+}
+
+// dispatches the event to all state listeners
+func (h *Health) dispatchStateEvent(name string, event func(IStateListener)) {
+	h.dispatchersLock.Lock()
+	dispatchers := h.dispatchers
+	h.dispatchersLock.Unlock()
+
+	for _, d := range dispatchers {
+		if !d.dispatch(event) {
+			atomic.AddInt64(&h.droppedEvents, 1)
+			h.Logger.WithFields(log.Fields{"event": name}).Warn("state listener is falling behind, dropped event")
+		}
+	}
+}
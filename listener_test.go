@@ -0,0 +1,95 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestStateListeners(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should dispatch failures, recoveries and overall state changes in order", func(t *testing.T) {
+		events := make(chan string, 1000)
+
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+		checker.StatusReturnsOnCall(1, nil, errors.New("things broke"))
+
+		h := New()
+		h.DisableLogging()
+		h.StateListeners = []IStateListener{&StateListenerFuncs{
+			CheckFailed: func(state *State) {
+				events <- fmt.Sprintf("failed %v %v", state.Name, state.ContiguousFailures)
+			},
+			CheckRecovered: func(state *State, recordedFailures int64, failureDurationSeconds float64) {
+				events <- fmt.Sprintf("recovered %v %v", state.Name, recordedFailures)
+			},
+			OverallStateChange: func(prevStatus, status string) {
+				events <- fmt.Sprintf("overall %v -> %v", prevStatus, status)
+			},
+		}}
+
+		h.AddChecks([]*Config{
+			{Name: "foo", Checker: checker, Interval: testCheckInterval, Fatal: true},
+		})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(events).Should(Receive(Equal("failed foo 1")))
+		Eventually(events).Should(Receive(Equal("overall ok -> failed")))
+		Eventually(events).Should(Receive(Equal("recovered foo 2")))
+		Eventually(events).Should(Receive(Equal("overall failed -> ok")))
+		Consistently(events).ShouldNot(Receive())
+	})
+
+	t.Run("Should dispatch every completed check", func(t *testing.T) {
+		states := make(chan *State, 1000)
+
+		h := New()
+		h.DisableLogging()
+		h.StateListeners = []IStateListener{&StateListenerFuncs{
+			CheckCompleted: func(state *State) { states <- state },
+		}}
+
+		h.AddChecks([]*Config{
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+		})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		time.Sleep(25 * time.Millisecond)
+
+		Expect(len(states)).To(BeNumerically(">=", 2))
+
+		state := <-states
+		Expect(state.Name).To(Equal("foo"))
+		Expect(state.Status).To(Equal("ok"))
+	})
+
+	t.Run("A blocked listener should not block the checks", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+
+		h := New()
+		h.DisableLogging()
+		h.StateListeners = []IStateListener{&StateListenerFuncs{
+			CheckCompleted: func(state *State) { <-unblock },
+		}}
+
+		checker := &fakes.FakeICheckable{}
+
+		h.AddChecks([]*Config{
+			{Name: "foo", Checker: checker, Interval: time.Millisecond},
+		})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(checker.StatusCallCount).Should(BeNumerically(">", stateListenerQueueSize+10))
+	})
+}
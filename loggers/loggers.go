@@ -0,0 +1,51 @@
+// Package loggers contains adapters that plug zap, zerolog and logrus loggers
+// into a health instance (as "Health.Logger"), so that consumers do not have
+// to write shim code themselves:
+//
+//	h.Logger = loggers.NewZap(zapLogger.Sugar())
+//	h.Logger = loggers.NewZerolog(&zerologLogger)
+//	h.Logger = loggers.NewLogrus(logrus.StandardLogger())
+//
+// None of the libraries is imported; the adapters are generic over the
+// methods of their loggers, so that go-health does not pull them in as
+// dependencies.
+package loggers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+// formats the message like "fmt.Sprintln()", without the trailing newline
+func sprintln(msg ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(msg...), "\n")
+}
+
+// returns the keys of the fields, sorted
+func sortedKeys(fields log.Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// merges the fields into a copy of the existing ones
+func mergeFields(existing, fields log.Fields) log.Fields {
+	merged := make(log.Fields, len(existing)+len(fields))
+	for k, v := range existing {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return merged
+}
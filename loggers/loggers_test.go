@@ -0,0 +1,29 @@
+package loggers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// entry is a message written to one of the fake loggers, along with its
+// fields (formatted as "k=v", sorted)
+type entry struct {
+	level  string
+	msg    string
+	fields string
+}
+
+type recorder struct {
+	entries []entry
+}
+
+func (r *recorder) write(level, msg string, fields map[string]interface{}) {
+	kv := make([]string, 0, len(fields))
+	for k, v := range fields {
+		kv = append(kv, fmt.Sprintf("%v=%v", k, v))
+	}
+	sort.Strings(kv)
+
+	r.entries = append(r.entries, entry{level: level, msg: msg, fields: strings.Join(kv, " ")})
+}
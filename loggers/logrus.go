@@ -0,0 +1,56 @@
+package loggers
+
+import (
+	"github.com/InVisionApp/go-logger"
+)
+
+// LogrusLogger contains the methods of "*logrus.Logger" and "*logrus.Entry"
+// used by the adapter; "E" is "*logrus.Entry".
+type LogrusLogger[E any] interface {
+	WithField(key string, value interface{}) E
+
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type logrusShim[E LogrusLogger[E]] struct {
+	logger LogrusLogger[E]
+}
+
+// NewLogrus returns a logger that writes to a "*logrus.Logger" (or
+// "*logrus.Entry"); fields are added via "WithField()".
+func NewLogrus[E LogrusLogger[E], L LogrusLogger[E]](logger L) log.Logger {
+	return &logrusShim[E]{logger: logger}
+}
+
+func (s *logrusShim[E]) Debug(msg ...interface{}) { s.logger.Debug(msg...) }
+func (s *logrusShim[E]) Info(msg ...interface{})  { s.logger.Info(msg...) }
+func (s *logrusShim[E]) Warn(msg ...interface{})  { s.logger.Warn(msg...) }
+func (s *logrusShim[E]) Error(msg ...interface{}) { s.logger.Error(msg...) }
+
+func (s *logrusShim[E]) Debugln(msg ...interface{}) { s.logger.Debug(sprintln(msg...)) }
+func (s *logrusShim[E]) Infoln(msg ...interface{})  { s.logger.Info(sprintln(msg...)) }
+func (s *logrusShim[E]) Warnln(msg ...interface{})  { s.logger.Warn(sprintln(msg...)) }
+func (s *logrusShim[E]) Errorln(msg ...interface{}) { s.logger.Error(sprintln(msg...)) }
+
+func (s *logrusShim[E]) Debugf(format string, args ...interface{}) { s.logger.Debugf(format, args...) }
+func (s *logrusShim[E]) Infof(format string, args ...interface{})  { s.logger.Infof(format, args...) }
+func (s *logrusShim[E]) Warnf(format string, args ...interface{})  { s.logger.Warnf(format, args...) }
+func (s *logrusShim[E]) Errorf(format string, args ...interface{}) { s.logger.Errorf(format, args...) }
+
+// WithFields returns a logger that adds the fields to every entry.
+func (s *logrusShim[E]) WithFields(fields log.Fields) log.Logger {
+	logger := s.logger
+	for _, k := range sortedKeys(fields) {
+		logger = logger.WithField(k, fields[k])
+	}
+
+	return &logrusShim[E]{logger: logger}
+}
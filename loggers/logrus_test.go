@@ -0,0 +1,72 @@
+package loggers
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+// mirrors the methods of "*logrus.Logger" and "*logrus.Entry"
+type fakeLogrusEntry struct {
+	rec    *recorder
+	fields map[string]interface{}
+}
+
+type fakeLogrusLogger struct {
+	fakeLogrusEntry
+}
+
+func (e *fakeLogrusEntry) WithField(key string, value interface{}) *fakeLogrusEntry {
+	fields := map[string]interface{}{key: value}
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+
+	return &fakeLogrusEntry{rec: e.rec, fields: fields}
+}
+
+func (e *fakeLogrusEntry) Debug(args ...interface{}) {
+	e.rec.write("debug", fmt.Sprint(args...), e.fields)
+}
+func (e *fakeLogrusEntry) Info(args ...interface{}) {
+	e.rec.write("info", fmt.Sprint(args...), e.fields)
+}
+func (e *fakeLogrusEntry) Warn(args ...interface{}) {
+	e.rec.write("warn", fmt.Sprint(args...), e.fields)
+}
+func (e *fakeLogrusEntry) Error(args ...interface{}) {
+	e.rec.write("error", fmt.Sprint(args...), e.fields)
+}
+
+func (e *fakeLogrusEntry) Debugf(format string, args ...interface{}) {
+	e.Debug(fmt.Sprintf(format, args...))
+}
+func (e *fakeLogrusEntry) Infof(format string, args ...interface{}) {
+	e.Info(fmt.Sprintf(format, args...))
+}
+func (e *fakeLogrusEntry) Warnf(format string, args ...interface{}) {
+	e.Warn(fmt.Sprintf(format, args...))
+}
+func (e *fakeLogrusEntry) Errorf(format string, args ...interface{}) {
+	e.Error(fmt.Sprintf(format, args...))
+}
+
+func TestNewLogrus(t *testing.T) {
+	RegisterTestingT(t)
+
+	rec := &recorder{}
+	logger := NewLogrus(&fakeLogrusLogger{fakeLogrusEntry{rec: rec}})
+
+	logger.Info("Starting checker")
+	logger.WithFields(log.Fields{"name": "redis", "fatal": true}).Errorf("healthcheck has %v", "failed")
+	logger.Warnln("state listener", "is falling behind")
+
+	Expect(rec.entries).To(Equal([]entry{
+		{level: "info", msg: "Starting checker"},
+		{level: "error", msg: "healthcheck has failed", fields: "fatal=true name=redis"},
+		{level: "warn", msg: "state listener is falling behind"},
+	}))
+}
@@ -0,0 +1,56 @@
+package loggers
+
+import (
+	"github.com/InVisionApp/go-logger"
+)
+
+// ZapLogger contains the methods of "*zap.SugaredLogger" used by the adapter;
+// "S" is "*zap.SugaredLogger".
+type ZapLogger[S any] interface {
+	With(args ...interface{}) S
+
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+
+	Debugf(template string, args ...interface{})
+	Infof(template string, args ...interface{})
+	Warnf(template string, args ...interface{})
+	Errorf(template string, args ...interface{})
+}
+
+type zapShim[S ZapLogger[S]] struct {
+	logger ZapLogger[S]
+}
+
+// NewZap returns a logger that writes to a "*zap.SugaredLogger" (see
+// "zap.Logger.Sugar()"); fields are added as key-value pairs via "With()".
+func NewZap[S ZapLogger[S]](logger S) log.Logger {
+	return &zapShim[S]{logger: logger}
+}
+
+func (s *zapShim[S]) Debug(msg ...interface{}) { s.logger.Debug(msg...) }
+func (s *zapShim[S]) Info(msg ...interface{})  { s.logger.Info(msg...) }
+func (s *zapShim[S]) Warn(msg ...interface{})  { s.logger.Warn(msg...) }
+func (s *zapShim[S]) Error(msg ...interface{}) { s.logger.Error(msg...) }
+
+func (s *zapShim[S]) Debugln(msg ...interface{}) { s.logger.Debug(sprintln(msg...)) }
+func (s *zapShim[S]) Infoln(msg ...interface{})  { s.logger.Info(sprintln(msg...)) }
+func (s *zapShim[S]) Warnln(msg ...interface{})  { s.logger.Warn(sprintln(msg...)) }
+func (s *zapShim[S]) Errorln(msg ...interface{}) { s.logger.Error(sprintln(msg...)) }
+
+func (s *zapShim[S]) Debugf(format string, args ...interface{}) { s.logger.Debugf(format, args...) }
+func (s *zapShim[S]) Infof(format string, args ...interface{})  { s.logger.Infof(format, args...) }
+func (s *zapShim[S]) Warnf(format string, args ...interface{})  { s.logger.Warnf(format, args...) }
+func (s *zapShim[S]) Errorf(format string, args ...interface{}) { s.logger.Errorf(format, args...) }
+
+// WithFields returns a logger that adds the fields to every entry.
+func (s *zapShim[S]) WithFields(fields log.Fields) log.Logger {
+	args := make([]interface{}, 0, 2*len(fields))
+	for _, k := range sortedKeys(fields) {
+		args = append(args, k, fields[k])
+	}
+
+	return &zapShim[S]{logger: s.logger.With(args...)}
+}
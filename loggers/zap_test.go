@@ -0,0 +1,75 @@
+package loggers
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+// mirrors the methods of "*zap.SugaredLogger"
+type fakeSugaredLogger struct {
+	rec    *recorder
+	fields map[string]interface{}
+}
+
+func (s *fakeSugaredLogger) With(args ...interface{}) *fakeSugaredLogger {
+	fields := make(map[string]interface{})
+	for k, v := range s.fields {
+		fields[k] = v
+	}
+
+	for i := 0; i+1 < len(args); i += 2 {
+		fields[args[i].(string)] = args[i+1]
+	}
+
+	return &fakeSugaredLogger{rec: s.rec, fields: fields}
+}
+
+func (s *fakeSugaredLogger) Debug(args ...interface{}) {
+	s.rec.write("debug", fmt.Sprint(args...), s.fields)
+}
+func (s *fakeSugaredLogger) Info(args ...interface{}) {
+	s.rec.write("info", fmt.Sprint(args...), s.fields)
+}
+func (s *fakeSugaredLogger) Warn(args ...interface{}) {
+	s.rec.write("warn", fmt.Sprint(args...), s.fields)
+}
+func (s *fakeSugaredLogger) Error(args ...interface{}) {
+	s.rec.write("error", fmt.Sprint(args...), s.fields)
+}
+
+func (s *fakeSugaredLogger) Debugf(template string, args ...interface{}) {
+	s.Debug(fmt.Sprintf(template, args...))
+}
+
+func (s *fakeSugaredLogger) Infof(template string, args ...interface{}) {
+	s.Info(fmt.Sprintf(template, args...))
+}
+
+func (s *fakeSugaredLogger) Warnf(template string, args ...interface{}) {
+	s.Warn(fmt.Sprintf(template, args...))
+}
+
+func (s *fakeSugaredLogger) Errorf(template string, args ...interface{}) {
+	s.Error(fmt.Sprintf(template, args...))
+}
+
+func TestNewZap(t *testing.T) {
+	RegisterTestingT(t)
+
+	rec := &recorder{}
+	logger := NewZap(&fakeSugaredLogger{rec: rec})
+
+	logger.Debug("Starting checker")
+	logger.WithFields(log.Fields{"check": "redis"}).WithFields(log.Fields{"interval": "1m"}).Warn("adapted check interval")
+	logger.Errorln("Unable", "to close checker")
+
+	Expect(rec.entries).To(Equal([]entry{
+		{level: "debug", msg: "Starting checker"},
+		{level: "warn", msg: "adapted check interval", fields: "check=redis interval=1m"},
+		{level: "error", msg: "Unable to close checker"},
+	}))
+}
@@ -0,0 +1,74 @@
+package loggers
+
+import (
+	"fmt"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+// ZerologEvent contains the methods of "*zerolog.Event" used by the adapter.
+type ZerologEvent[E any] interface {
+	Fields(fields interface{}) E
+	Msg(msg string)
+}
+
+// ZerologLogger contains the methods of "*zerolog.Logger" used by the
+// adapter; "E" is "*zerolog.Event".
+type ZerologLogger[E ZerologEvent[E]] interface {
+	Debug() E
+	Info() E
+	Warn() E
+	Error() E
+}
+
+type zerologShim[E ZerologEvent[E]] struct {
+	logger ZerologLogger[E]
+	fields log.Fields
+}
+
+// NewZerolog returns a logger that writes to a "*zerolog.Logger"; fields are
+// added to every event via "Fields()".
+func NewZerolog[E ZerologEvent[E], L ZerologLogger[E]](logger L) log.Logger {
+	return &zerologShim[E]{logger: logger}
+}
+
+// writes the message to the event, along with the fields
+func (s *zerologShim[E]) write(event E, msg string) {
+	if len(s.fields) > 0 {
+		event = event.Fields(map[string]interface{}(s.fields))
+	}
+
+	event.Msg(msg)
+}
+
+func (s *zerologShim[E]) Debug(msg ...interface{}) { s.write(s.logger.Debug(), fmt.Sprint(msg...)) }
+func (s *zerologShim[E]) Info(msg ...interface{})  { s.write(s.logger.Info(), fmt.Sprint(msg...)) }
+func (s *zerologShim[E]) Warn(msg ...interface{})  { s.write(s.logger.Warn(), fmt.Sprint(msg...)) }
+func (s *zerologShim[E]) Error(msg ...interface{}) { s.write(s.logger.Error(), fmt.Sprint(msg...)) }
+
+func (s *zerologShim[E]) Debugln(msg ...interface{}) { s.write(s.logger.Debug(), sprintln(msg...)) }
+func (s *zerologShim[E]) Infoln(msg ...interface{})  { s.write(s.logger.Info(), sprintln(msg...)) }
+func (s *zerologShim[E]) Warnln(msg ...interface{})  { s.write(s.logger.Warn(), sprintln(msg...)) }
+func (s *zerologShim[E]) Errorln(msg ...interface{}) { s.write(s.logger.Error(), sprintln(msg...)) }
+
+func (s *zerologShim[E]) Debugf(format string, args ...interface{}) {
+	s.write(s.logger.Debug(), fmt.Sprintf(format, args...))
+}
+
+func (s *zerologShim[E]) Infof(format string, args ...interface{}) {
+	s.write(s.logger.Info(), fmt.Sprintf(format, args...))
+}
+
+func (s *zerologShim[E]) Warnf(format string, args ...interface{}) {
+	s.write(s.logger.Warn(), fmt.Sprintf(format, args...))
+}
+
+func (s *zerologShim[E]) Errorf(format string, args ...interface{}) {
+	s.write(s.logger.Error(), fmt.Sprintf(format, args...))
+}
+
+// WithFields returns a logger that adds the fields (merged with the existing
+// ones) to every event.
+func (s *zerologShim[E]) WithFields(fields log.Fields) log.Logger {
+	return &zerologShim[E]{logger: s.logger, fields: mergeFields(s.fields, fields)}
+}
@@ -0,0 +1,61 @@
+package loggers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+// mirrors the methods of "*zerolog.Logger" and "*zerolog.Event"
+type fakeZerologLogger struct {
+	rec *recorder
+}
+
+type fakeZerologEvent struct {
+	rec    *recorder
+	level  string
+	fields map[string]interface{}
+}
+
+func (l *fakeZerologLogger) Debug() *fakeZerologEvent {
+	return &fakeZerologEvent{rec: l.rec, level: "debug"}
+}
+func (l *fakeZerologLogger) Info() *fakeZerologEvent {
+	return &fakeZerologEvent{rec: l.rec, level: "info"}
+}
+func (l *fakeZerologLogger) Warn() *fakeZerologEvent {
+	return &fakeZerologEvent{rec: l.rec, level: "warn"}
+}
+func (l *fakeZerologLogger) Error() *fakeZerologEvent {
+	return &fakeZerologEvent{rec: l.rec, level: "error"}
+}
+
+func (e *fakeZerologEvent) Fields(fields interface{}) *fakeZerologEvent {
+	e.fields = fields.(map[string]interface{})
+	return e
+}
+
+func (e *fakeZerologEvent) Msg(msg string) {
+	e.rec.write(e.level, msg, e.fields)
+}
+
+func TestNewZerolog(t *testing.T) {
+	RegisterTestingT(t)
+
+	rec := &recorder{}
+	logger := NewZerolog(&fakeZerologLogger{rec: rec})
+
+	logger.Infof("Pausing %v", "checker")
+
+	withName := logger.WithFields(log.Fields{"name": "redis"})
+	withName.WithFields(log.Fields{"err": "timeout"}).Error("healthcheck has failed")
+	withName.Debugln("Checker", "exiting")
+
+	Expect(rec.entries).To(Equal([]entry{
+		{level: "info", msg: "Pausing checker"},
+		{level: "error", msg: "healthcheck has failed", fields: "err=timeout name=redis"},
+		{level: "debug", msg: "Checker exiting", fields: "name=redis"},
+	}))
+}
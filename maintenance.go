@@ -0,0 +1,60 @@
+package health
+
+import (
+	"time"
+)
+
+// Maintenance describes the active maintenance mode (see
+// "h.SetMaintenanceMode()").
+type Maintenance struct {
+	// Status is the forced overall status ("failed" or "degraded")
+	Status string `json:"status"`
+
+	// Reason is the reason passed to "h.SetMaintenanceMode()"
+	Reason string `json:"reason,omitempty"`
+
+	// Since is the time the maintenance mode has been turned on
+	Since time.Time `json:"since"`
+}
+
+// SetMaintenanceMode turns the maintenance mode on or off. While it is on, the
+// overall status is forced to "Health.MaintenanceStatus" ("failed" by
+// default) regardless of the states of the checks, so that deploy tooling can
+// drain traffic without killing the process; the checks keep running. The
+// bundled handlers annotate their output with the reason (see
+// "h.Maintenance()").
+func (h *Health) SetMaintenanceMode(on bool, reason string) {
+	h.statesLock.Lock()
+	defer h.statesLock.Unlock()
+
+	prevStatus := h.overallStatus()
+
+	if on {
+		status := h.MaintenanceStatus
+		if status != "degraded" {
+			status = "failed"
+		}
+
+		h.maintenance.Store(&Maintenance{Status: status, Reason: reason, Since: h.clock().Now()})
+	} else {
+		h.maintenance.Store((*Maintenance)(nil))
+	}
+
+	// cached responses must be re-rendered
+	h.invalidateSnapshot()
+
+	if status := h.overallStatus(); status != prevStatus {
+		h.dispatchStateEvent("overall_state_change", func(l IStateListener) { l.OnOverallStateChange(prevStatus, status) })
+	}
+}
+
+// Maintenance returns the active maintenance mode; false if the maintenance
+// mode is off.
+func (h *Health) Maintenance() (Maintenance, bool) {
+	m, _ := h.maintenance.Load().(*Maintenance)
+	if m == nil {
+		return Maintenance{}, false
+	}
+
+	return *m, true
+}
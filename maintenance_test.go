@@ -0,0 +1,88 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestSetMaintenanceMode(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should force the overall status to failed", func(t *testing.T) {
+		events := make(chan string, 100)
+
+		h := setupNewTestHealth()
+		h.StateListeners = []IStateListener{&StateListenerFuncs{
+			OverallStateChange: func(prevStatus, status string) {
+				events <- fmt.Sprintf("overall %v -> %v", prevStatus, status)
+			},
+		}}
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval, Fatal: true})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Failed()).To(BeFalse())
+
+		_, ok := h.Maintenance()
+		Expect(ok).To(BeFalse())
+
+		version := h.StateVersion()
+		h.SetMaintenanceMode(true, "draining for deploy")
+
+		m, ok := h.Maintenance()
+		Expect(ok).To(BeTrue())
+		Expect(m.Status).To(Equal("failed"))
+		Expect(m.Reason).To(Equal("draining for deploy"))
+		Expect(m.Since).ToNot(BeZero())
+
+		Expect(h.Failed()).To(BeTrue())
+		Expect(h.StateVersion()).ToNot(Equal(version))
+
+		states, failed, err := h.State()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed).To(BeTrue())
+		Expect(states["foo"].Status).To(Equal("ok"))
+
+		Eventually(events).Should(Receive(Equal("overall ok -> failed")))
+
+		h.SetMaintenanceMode(false, "")
+
+		_, ok = h.Maintenance()
+		Expect(ok).To(BeFalse())
+		Expect(h.Failed()).To(BeFalse())
+
+		Eventually(events).Should(Receive(Equal("overall failed -> ok")))
+	})
+
+	t.Run("Should force the configured status", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h := setupNewTestHealth()
+		h.MaintenanceStatus = "degraded"
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: checker, Interval: testCheckInterval, Fatal: true})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Failed()).To(BeTrue())
+
+		h.SetMaintenanceMode(true, "")
+
+		m, _ := h.Maintenance()
+		Expect(m.Status).To(Equal("degraded"))
+
+		// the checks keep running, but the overall status is forced
+		Expect(h.Failed()).To(BeFalse())
+		Expect(h.overallStatus()).To(Equal("degraded"))
+		Consistently(h.Failed, 50*testCheckInterval).Should(BeFalse())
+	})
+}
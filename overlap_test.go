@@ -0,0 +1,79 @@
+package health
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func newSlowChecker(took time.Duration, running, maxRunning *int64) *fakes.FakeICheckable {
+	checker := &fakes.FakeICheckable{}
+	checker.StatusStub = func() (interface{}, error) {
+		n := atomic.AddInt64(running, 1)
+		if n > atomic.LoadInt64(maxRunning) {
+			atomic.StoreInt64(maxRunning, n)
+		}
+
+		time.Sleep(took)
+		atomic.AddInt64(running, -1)
+
+		return nil, nil
+	}
+
+	return checker
+}
+
+func TestOverlapPrevention(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should skip ticks while the check is in flight", func(t *testing.T) {
+		var running, maxRunning int64
+		checker := newSlowChecker(35*time.Millisecond, &running, &maxRunning)
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:     "foo",
+				Checker:  checker,
+				Interval: testCheckInterval,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		time.Sleep(80 * time.Millisecond)
+
+		Expect(atomic.LoadInt64(&maxRunning)).To(Equal(int64(1)))
+
+		states, _, _ := h.State()
+		Expect(states["foo"].MissedTicks).To(BeNumerically(">=", 2))
+
+		// skipped ticks should not cause executions to stack up
+		Expect(checker.StatusCallCount()).To(BeNumerically("<=", 3))
+	})
+
+	t.Run("Should queue a single execution while the check is in flight", func(t *testing.T) {
+		var running, maxRunning int64
+		checker := newSlowChecker(25*time.Millisecond, &running, &maxRunning)
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:          "foo",
+				Checker:       checker,
+				Interval:      testCheckInterval,
+				OverlapPolicy: OverlapQueue,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		// queued executions should start as soon as the previous one completes
+		time.Sleep(65 * time.Millisecond)
+
+		Expect(atomic.LoadInt64(&maxRunning)).To(Equal(int64(1)))
+		Expect(checker.StatusCallCount()).To(Equal(3))
+	})
+}
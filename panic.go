@@ -0,0 +1,47 @@
+package health
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+// PanicError is recorded (as "State.Err", along with the stack in
+// "State.Details") when a checker panics. The panic is recovered, so that a
+// misbehaving (ie. third-party) checker cannot crash the process.
+type PanicError struct {
+	// Value is the value passed to "panic()"
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine of the checker
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("Checker panicked: %v", p.Value)
+}
+
+// calls the checker, recovering a panic as a "*PanicError"
+func recoverStatus(call func() (interface{}, error)) (data interface{}, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			data, err = nil, &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return call()
+}
+
+// logs the recovered panic of the check and calls "Health.PanicHandler"
+func (h *Health) handlePanic(name string, p *PanicError) {
+	h.Logger.WithFields(log.Fields{
+		"check": name,
+		"panic": p.Value,
+		"stack": string(p.Stack),
+	}).Error("checker has panicked")
+
+	if h.PanicHandler != nil {
+		h.PanicHandler(name, p)
+	}
+}
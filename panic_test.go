@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	RegisterTestingT(t)
+
+	panicking := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+		panic("nil map")
+	})
+
+	for _, timeout := range []time.Duration{0, time.Second} {
+		t.Run("Should mark a panicking check as failed with a timeout of "+timeout.String(), func(t *testing.T) {
+			var lock sync.Mutex
+			panics := make(map[string]*PanicError)
+
+			h := setupNewTestHealth()
+			h.WaitOnStart = true
+			h.PanicHandler = func(name string, err *PanicError) {
+				lock.Lock()
+				defer lock.Unlock()
+
+				panics[name] = err
+			}
+			h.AddCheck(&Config{Name: "foo", Checker: panicking, Interval: time.Hour, Timeout: timeout, Fatal: true})
+
+			Expect(h.Start()).To(Succeed())
+			defer h.Stop()
+
+			state, ok := h.lookupState("foo")
+			Expect(ok).To(BeTrue())
+			Expect(state.Status).To(Equal("failed"))
+			Expect(state.Err).To(Equal("Checker panicked: nil map"))
+			Expect(state.Details).To(HaveKeyWithValue("panic", "nil map"))
+			Expect(state.Details).To(HaveKeyWithValue("stack", ContainSubstring("panic_test.go")))
+			Expect(h.Failed()).To(BeTrue())
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			Expect(panics).To(HaveKey("foo"))
+			Expect(panics["foo"].Value).To(Equal("nil map"))
+		})
+	}
+
+	t.Run("Should recover panics of the recovery probe", func(t *testing.T) {
+		fail := true
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{
+			Name: "foo",
+			Checker: CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				if fail {
+					return nil, context.DeadlineExceeded
+				}
+
+				return nil, nil
+			}),
+			RecoveryProbe: panicking,
+			Interval:      time.Hour,
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		fail = false
+
+		state, err := h.RunCheck("foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("failed"))
+		Expect(state.Err).To(Equal("recovery probe failed: Checker panicked: nil map"))
+	})
+}
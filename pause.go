@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+// PauseCheck temporarily suspends the check with the given name (ie. during a
+// planned migration of its dependency) without removing its config; until it
+// is resumed via "ResumeCheck()", the check is not executed and reported as
+// "paused". A paused check never fails the healthcheck (nor resolves an open
+// incident); the result of an execution that is in flight while the check is
+// paused is discarded. Pausing a startup check that has passed (see
+// "Config.Startup") is a noop.
+func (h *Health) PauseCheck(name string) error {
+	r, err := h.getRunner(name)
+	if err != nil {
+		return err
+	}
+
+	if r.hasPassed() {
+		return nil
+	}
+
+	r.pauseLock.Lock()
+	defer r.pauseLock.Unlock()
+
+	if !r.pause() {
+		return nil
+	}
+
+	h.Logger.WithFields(log.Fields{"name": name}).Debug("Pausing checker")
+
+	// recorded after "stopLock" has been released, so that the listeners and
+	// hooks may call back into the check (ie. "h.IsHealthy()")
+	stateEntry := &State{
+		Name:      name,
+		Status:    "paused",
+		CheckTime: h.clock().Now(),
+		Fatal:     r.cfg.isCritical(),
+		Tags:      r.cfg.Tags,
+	}
+
+	h.safeUpdateState(stateEntry)
+	h.handleResultHooks(stateEntry)
+
+	return nil
+}
+
+// ResumeCheck resumes a check that has been paused via "PauseCheck()"; the
+// check is executed right away and then again on its regular schedule.
+// Resuming a check that is not paused is a noop.
+func (h *Health) ResumeCheck(name string) error {
+	r, err := h.getRunner(name)
+	if err != nil {
+		return err
+	}
+
+	r.pauseLock.Lock()
+	resumed := r.resume()
+	r.pauseLock.Unlock()
+
+	if resumed {
+		h.Logger.WithFields(log.Fields{"name": name}).Debug("Resuming checker")
+		go r.exec(context.Background())
+	}
+
+	return nil
+}
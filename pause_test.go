@@ -0,0 +1,134 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type pausedResultHook struct {
+	onPaused func(state *State)
+}
+
+func (p *pausedResultHook) CheckCompleted(state *State) {
+	if state.isPaused() {
+		p.onPaused(state)
+	}
+}
+
+func TestPauseCheck(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should suspend the check and report it as paused", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker, Interval: testCheckInterval, Fatal: true, Tags: []string{"db"}},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(h.Failed).Should(BeTrue())
+
+		Expect(h.PauseCheck("foo")).To(Succeed())
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states["foo"].Status).To(Equal("paused"))
+		Expect(states["foo"].Tags).To(Equal([]string{"db"}))
+		Expect(h.IsHealthy("foo")).To(BeFalse())
+
+		// let an execution that was in flight complete
+		time.Sleep(5 * time.Millisecond)
+
+		calls := checker.StatusCallCount()
+		Consistently(checker.StatusCallCount, 25*time.Millisecond).Should(Equal(calls))
+
+		state, err := h.RunCheck("foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("paused"))
+		Expect(checker.StatusCallCount()).To(Equal(calls))
+
+		// the incident stays open while paused
+		Expect(h.Incidents()).To(HaveLen(1))
+		Expect(h.Incidents()[0].Open()).To(BeTrue())
+	})
+
+	t.Run("Should execute the check right away once resumed", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker, Interval: time.Minute, Fatal: true},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(h.Failed).Should(BeTrue())
+
+		Expect(h.PauseCheck("foo")).To(Succeed())
+		Expect(h.ResumeCheck("foo")).To(Succeed())
+
+		Eventually(func() string { states, _, _ := h.State(); return states["foo"].Status }).Should(Equal("ok"))
+		Expect(checker.StatusCallCount()).To(Equal(2))
+
+		// the incident is resolved by the recovery
+		Expect(h.Incidents()).To(HaveLen(1))
+		Expect(h.Incidents()[0].Open()).To(BeFalse())
+
+		// resuming a running check is a noop
+		Expect(h.ResumeCheck("foo")).To(Succeed())
+		Consistently(checker.StatusCallCount).Should(Equal(2))
+	})
+
+	t.Run("Should let the result hooks call back into the paused check", func(t *testing.T) {
+		done := make(chan error, 1)
+
+		h := setupNewTestHealth()
+		h.ResultHooks = []IResultHook{&pausedResultHook{onPaused: func(state *State) {
+			if h.IsHealthy(state.Name) {
+				done <- errors.New("paused check is healthy")
+				return
+			}
+
+			if _, err := h.RunCheck(state.Name); err != nil {
+				done <- err
+				return
+			}
+
+			done <- h.PauseCheck(state.Name)
+		}}}
+
+		Expect(h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval})).To(Succeed())
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.PauseCheck("foo")).To(Succeed())
+		Eventually(done).Should(Receive(BeNil()))
+
+		// the paused state is not recorded once the check has been removed
+		Expect(h.RemoveCheck("foo")).To(Succeed())
+		Expect(h.PauseCheck("foo")).To(Equal(ErrCheckNotFound))
+
+		states, _, _ := h.State()
+		Expect(states).ToNot(HaveKey("foo"))
+	})
+
+	t.Run("Should error if the check does not exist or healthcheck is not running", func(t *testing.T) {
+		h := setupNewTestHealth()
+		Expect(h.PauseCheck("foo")).To(Equal(ErrNotRunning))
+		Expect(h.ResumeCheck("foo")).To(Equal(ErrNotRunning))
+
+		h, _, err := setupRunners(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Expect(h.PauseCheck("baz")).To(Equal(ErrCheckNotFound))
+		Expect(h.ResumeCheck("baz")).To(Equal(ErrCheckNotFound))
+	})
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// pipelineKey is the context key of the values that are published by the
+// execution of a check
+type pipelineKey struct{}
+
+// pipeline collects the values that are published by an execution of a check
+// (see "Publish()") and resolves the values of its dependencies (see
+// "Lookup()")
+type pipeline struct {
+	h   *Health
+	cfg *Config
+
+	lock   sync.Mutex
+	values map[string]interface{}
+	sealed bool
+}
+
+// Publish publishes a value (ie. the discovered replica endpoints) from within
+// the execution of a check, so that the checks that depend on it (see
+// "Config.DependsOn") can consume it via "Lookup()"; "ctx" is the context
+// that is passed to checkers that implement "ICheckableContext". The values
+// of an execution replace the values of the previous one once it has
+// succeeded; the values of a failed execution are discarded, as are values
+// that are published after the check has timed out. Publishing outside the
+// execution of a check is a noop.
+//
+// Use "Config.Chained" to execute the consuming checks right after every
+// execution of the publishing check, ie. for "list the shards, then ping each"
+// pipelines.
+func Publish(ctx context.Context, key string, value interface{}) {
+	p, ok := ctx.Value(pipelineKey{}).(*pipeline)
+	if !ok {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.sealed {
+		return
+	}
+
+	if p.values == nil {
+		p.values = make(map[string]interface{})
+	}
+
+	p.values[key] = value
+}
+
+// Lookup returns the value that the latest successful execution of the given
+// check has published under the key (see "Publish()"), from within the
+// execution of a check that depends on it (see "Config.DependsOn"); false if
+// the value has not been published, the check is not a dependency or "ctx"
+// is not the context of an execution.
+func Lookup(ctx context.Context, check, key string) (interface{}, bool) {
+	p, ok := ctx.Value(pipelineKey{}).(*pipeline)
+	if !ok || !hasDependency(p.cfg, check) {
+		return nil, false
+	}
+
+	p.h.runnersLock.Lock()
+	r, ok := p.h.runners[check]
+	p.h.runnersLock.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return r.output(key)
+}
+
+// returns the context of an execution of the check
+func (h *Health) withPipeline(ctx context.Context, cfg *Config) (context.Context, *pipeline) {
+	p := &pipeline{h: h, cfg: cfg}
+	return context.WithValue(ctx, pipelineKey{}, p), p
+}
+
+// returns the published values; later values are discarded
+func (p *pipeline) seal() map[string]interface{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.sealed = true
+
+	return p.values
+}
+
+// replaces the values published by the check
+func (r *runner) setOutputs(values map[string]interface{}) {
+	r.outputsLock.Lock()
+	defer r.outputsLock.Unlock()
+
+	r.outputs = values
+}
+
+// returns a value published by the check
+func (r *runner) output(key string) (interface{}, bool) {
+	r.outputsLock.RLock()
+	defer r.outputsLock.RUnlock()
+
+	value, ok := r.outputs[key]
+
+	return value, ok
+}
+
+func hasDependency(cfg *Config, name string) bool {
+	for _, dep := range cfg.DependsOn {
+		if dep == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validates the chained checks; they are executed by their dependencies, so
+// options that alter their timer are rejected
+func validateChainedChecks(cfgs []*Config) error {
+	for _, c := range cfgs {
+		if !c.Chained {
+			continue
+		}
+
+		if len(c.DependsOn) == 0 {
+			return fmt.Errorf("Check '%v' is chained, but does not depend on any check", c.Name)
+		}
+
+		if c.Schedule != "" || c.InitialDelay > 0 || c.CacheTTL > 0 {
+			return fmt.Errorf("Check '%v' cannot combine chaining with a schedule, initial delay or cache TTL", c.Name)
+		}
+	}
+
+	return nil
+}
+
+// executes the chained checks that depend on the check with the given name
+// (see "Config.Chained")
+func (h *Health) triggerChained(name string) {
+	h.runnersLock.Lock()
+	triggers := make([]func(), 0)
+	for _, r := range h.runners {
+		if r.trigger != nil && hasDependency(r.cfg, name) {
+			triggers = append(triggers, r.trigger)
+		}
+	}
+	h.runnersLock.Unlock()
+
+	for _, trigger := range triggers {
+		trigger()
+	}
+}
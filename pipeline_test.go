@@ -0,0 +1,167 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestPipeline(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should pass the published values to chained checks", func(t *testing.T) {
+		var lock sync.Mutex
+		replicas := []string{"db-1", "db-2"}
+		pinged := make(chan []string, 10)
+
+		discover := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			Publish(ctx, "replicas", replicas)
+
+			return nil, nil
+		})
+
+		ping := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			value, ok := Lookup(ctx, "discover", "replicas")
+			if !ok {
+				return nil, errors.New("no replicas discovered")
+			}
+
+			pinged <- value.([]string)
+
+			return nil, nil
+		})
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "discover", Checker: discover, Interval: 50 * time.Millisecond},
+			{Name: "ping", Checker: ping, DependsOn: []string{"discover"}, Chained: true},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(pinged).Should(Receive(Equal([]string{"db-1", "db-2"})))
+
+		lock.Lock()
+		replicas = []string{"db-3"}
+		lock.Unlock()
+
+		Eventually(pinged).Should(Receive(Equal([]string{"db-3"})))
+
+		states, _, _ := h.State()
+		Expect(states["ping"].Status).To(Equal("ok"))
+	})
+
+	t.Run("Should discard the values of failed executions", func(t *testing.T) {
+		var failing int32
+
+		discover := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			Publish(ctx, "shards", 3)
+
+			if atomic.LoadInt32(&failing) == 1 {
+				return nil, errors.New("discovery failed")
+			}
+
+			return nil, nil
+		})
+
+		lookups := make(chan bool, 10)
+
+		consume := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			_, ok := Lookup(ctx, "discover", "shards")
+			lookups <- ok
+
+			return nil, nil
+		})
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "discover", Checker: discover, Interval: testCheckInterval},
+			{Name: "consume", Checker: consume, Interval: time.Minute, DependsOn: []string{"discover"}},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() bool {
+			h.RunCheck("consume")
+			return <-lookups
+		}).Should(BeTrue())
+
+		atomic.StoreInt32(&failing, 1)
+
+		Eventually(func() string { states, _, _ := h.State(); return states["discover"].Status }).Should(Equal("failed"))
+
+		r, _ := h.getRunner("discover")
+		_, ok := r.output("shards")
+		Expect(ok).To(BeFalse())
+	})
+
+	t.Run("Should skip chained checks while the dependency fails", func(t *testing.T) {
+		failing := &fakes.FakeICheckable{}
+		failing.StatusReturns(nil, errors.New("things broke"))
+
+		chained := &fakes.FakeICheckable{}
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "discover", Checker: failing, Interval: testCheckInterval},
+			{Name: "ping", Checker: chained, DependsOn: []string{"discover"}, Chained: true},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() string { states, _, _ := h.State(); return states["ping"].Status }).Should(Equal("skipped"))
+		Expect(chained.StatusCallCount()).To(Equal(0))
+	})
+
+	t.Run("Should only look up the values of dependencies", func(t *testing.T) {
+		Publish(context.Background(), "key", "value")
+
+		_, ok := Lookup(context.Background(), "foo", "key")
+		Expect(ok).To(BeFalse())
+
+		found := make(chan bool, 10)
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				Publish(ctx, "key", "value")
+				return nil, nil
+			}), Interval: testCheckInterval},
+			{Name: "bar", Checker: CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				_, ok := Lookup(ctx, "foo", "key")
+				found <- ok
+				return nil, nil
+			}), Interval: testCheckInterval},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() bool { _, ok := h.runners["foo"].output("key"); return ok }).Should(BeTrue())
+		Eventually(found).Should(Receive(BeFalse()))
+	})
+
+	t.Run("Should error on invalid chained checks", func(t *testing.T) {
+		for cfg, msg := range map[*Config]string{
+			{Name: "bar", Chained: true}: "Check 'bar' is chained, but does not depend on any check",
+			{Name: "bar", Chained: true, DependsOn: []string{"foo"}, CacheTTL: time.Minute}:   "Check 'bar' cannot combine chaining",
+			{Name: "bar", Chained: true, DependsOn: []string{"foo"}, Schedule: "* * * * *"}:   "Check 'bar' cannot combine chaining",
+			{Name: "bar", Chained: true, DependsOn: []string{"foo"}, InitialDelay: time.Hour}: "Check 'bar' cannot combine chaining",
+		} {
+			cfg.Checker = &fakes.FakeICheckable{}
+
+			h := setupNewTestHealth()
+			h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Minute})
+			h.AddCheck(cfg)
+
+			err := h.Start()
+			Expect(err).To(MatchError(ContainSubstring(msg)), fmt.Sprint(cfg))
+		}
+	})
+}
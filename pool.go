@@ -0,0 +1,89 @@
+package health
+
+import (
+	"fmt"
+)
+
+// Priority determines which worker pool executes a check.
+type Priority int
+
+const (
+	// PriorityNormal is the default priority of a check
+	PriorityNormal Priority = iota
+
+	// PriorityHigh is meant for cheap, local checks (ie. disk space or number
+	// of goroutines) that should never wait behind slow network checks
+	PriorityHigh
+
+	// PriorityLow is meant for slow or expensive checks (ie. remote
+	// dependencies that may time out during a partial outage)
+	PriorityLow
+)
+
+// String returns the name of the priority
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	}
+
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+// workerPool limits the number of concurrent executions of the checks of a
+// given priority; a nil pool is unbounded
+type workerPool struct {
+	slots chan struct{}
+}
+
+func newWorkerPool(workers int) *workerPool {
+	if workers <= 0 {
+		return nil
+	}
+
+	return &workerPool{slots: make(chan struct{}, workers)}
+}
+
+// blocks until a worker is available; returns a function that releases the
+// worker
+func (p *workerPool) acquire() func() {
+	if p == nil {
+		return func() {}
+	}
+
+	p.slots <- struct{}{}
+
+	return func() { <-p.slots }
+}
+
+// creates the worker pools of all priorities
+func newWorkerPools(workers map[Priority]int) map[Priority]*workerPool {
+	pools := make(map[Priority]*workerPool, len(workers))
+
+	for priority, n := range workers {
+		pools[priority] = newWorkerPool(n)
+	}
+
+	return pools
+}
+
+// validates the priorities of the checks and the size of the worker pools
+func validatePriorities(cfgs []*Config, workers map[Priority]int) error {
+	for _, c := range cfgs {
+		if c.Priority < PriorityNormal || c.Priority > PriorityLow {
+			return fmt.Errorf("Check '%v' has an unknown priority '%v'", c.Name, c.Priority)
+		}
+	}
+
+	for priority, n := range workers {
+		if n < 0 {
+			return fmt.Errorf("Number of %v priority workers cannot be negative", priority)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+// slowChecker tracks the maximum number of concurrent executions
+type slowChecker struct {
+	delay time.Duration
+
+	running    *int64
+	maxRunning *int64
+}
+
+func (s *slowChecker) Status() (interface{}, error) {
+	n := atomic.AddInt64(s.running, 1)
+	defer atomic.AddInt64(s.running, -1)
+
+	for {
+		max := atomic.LoadInt64(s.maxRunning)
+		if n <= max || atomic.CompareAndSwapInt64(s.maxRunning, max, n) {
+			break
+		}
+	}
+
+	time.Sleep(s.delay)
+
+	return nil, nil
+}
+
+func TestWorkerPools(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should limit the concurrency per priority without starving other priorities", func(t *testing.T) {
+		var running, maxRunning int64
+
+		slow := func() ICheckable {
+			return &slowChecker{delay: 20 * time.Millisecond, running: &running, maxRunning: &maxRunning}
+		}
+		cheap := &fakes.FakeICheckable{}
+
+		h := New()
+		h.DisableLogging()
+		h.Workers = map[Priority]int{PriorityLow: 1, PriorityHigh: 1}
+
+		h.AddChecks([]*Config{
+			{Name: "slow1", Checker: slow(), Interval: testCheckInterval, Priority: PriorityLow},
+			{Name: "slow2", Checker: slow(), Interval: testCheckInterval, Priority: PriorityLow},
+			{Name: "slow3", Checker: slow(), Interval: testCheckInterval, Priority: PriorityLow},
+			{Name: "cheap", Checker: cheap, Interval: testCheckInterval, Priority: PriorityHigh},
+		})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+
+		Expect(atomic.LoadInt64(&maxRunning)).To(Equal(int64(1)))
+		Expect(cheap.StatusCallCount()).To(BeNumerically(">=", 8))
+	})
+
+	t.Run("Should not limit priorities without workers", func(t *testing.T) {
+		var running, maxRunning int64
+
+		h := New()
+		h.DisableLogging()
+		h.Workers = map[Priority]int{PriorityLow: 1}
+
+		cfgs := make([]*Config, 0)
+		for _, name := range []string{"foo", "bar", "baz"} {
+			cfgs = append(cfgs, &Config{
+				Name:     name,
+				Checker:  &slowChecker{delay: 20 * time.Millisecond, running: &running, maxRunning: &maxRunning},
+				Interval: testCheckInterval,
+			})
+		}
+
+		h.AddChecks(cfgs)
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(func() int64 { return atomic.LoadInt64(&maxRunning) }).Should(Equal(int64(3)))
+	})
+
+	t.Run("Should error with an unknown priority or a negative number of workers", func(t *testing.T) {
+		h := New()
+		h.DisableLogging()
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval, Priority: 42})
+
+		err := h.Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Check 'foo' has an unknown priority 'Priority(42)'"))
+
+		h = New()
+		h.DisableLogging()
+		h.Workers = map[Priority]int{PriorityHigh: -1}
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval})
+
+		err = h.Start()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Number of high priority workers cannot be negative"))
+	})
+}
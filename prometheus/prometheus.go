@@ -0,0 +1,261 @@
+// Package prometheus exposes the state of a healthcheck as Prometheus metrics
+// (in the text exposition format), so that health data shows up in existing
+// dashboards without scraping the JSON endpoint. The metrics are computed from
+// "h.State()" on every scrape; no client library is required.
+package prometheus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/InVisionApp/go-health"
+)
+
+const (
+	defaultNamespace = "health"
+
+	// ContentType is the content type of the text exposition format
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	validName  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	validLabel = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// Config is used for configuring the collector.
+//
+// "Namespace" is optional; the prefix of all metric names; defaults to
+// `health`.
+//
+// "ConstLabels" is optional; labels that are added to every metric (ie.
+// `service`).
+//
+// The following gauges are exposed (with the default namespace):
+//
+//	health_check_status{check="mongo"}                1 unless the check has failed
+//	health_check_degraded{check="mongo"}              1 if the check is degraded
+//	health_check_duration_seconds{check="mongo"}      duration of the latest execution
+//	health_check_consecutive_failures{check="mongo"}  number of failures in a row
+//	health_overall_status                             1 unless the healthcheck has failed
+//	health_overall_degraded                           1 if the healthcheck is degraded
+//	health_overall_score                              weighted health score (0-100)
+//
+// Additionally, the internals of the runner (see "h.RunnerStats()") are
+// exposed, so that the monitor itself can be monitored:
+//
+//	health_runner_active{check="mongo"}               goroutines executing the check
+//	health_runner_missed_ticks{check="mongo"}         ticks that fired while the check was in flight
+//	health_runner_tick_drift_seconds{check="mongo"}   drift of the latest tick
+//	health_runner_pending_hooks                       result hook calls in flight
+//	health_runner_listener_queue_depth                events queued for the state listeners
+//	health_runner_dropped_events                      events dropped by slow listeners and subscribers
+type Config struct {
+	Namespace   string
+	ConstLabels map[string]string
+}
+
+// Collector writes the metrics of a healthcheck; it implements
+// "http.Handler", so it can be mounted as (or next to) the `/metrics`
+// endpoint.
+type Collector struct {
+	Config *Config
+
+	health health.IHealth
+	labels string // the encoded const labels, sorted by name
+}
+
+// NewCollector creates a new collector for the given healthcheck; "cfg" may
+// be nil.
+func NewCollector(h health.IHealth, cfg *Config) (*Collector, error) {
+	if h == nil {
+		return nil, errors.New("Health instance cannot be nil")
+	}
+
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("Unable to validate prometheus config: %v", err)
+	}
+
+	names := make([]string, 0, len(cfg.ConstLabels))
+	for name := range cfg.ConstLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	labels := make([]string, 0, len(names))
+	for _, name := range names {
+		labels = append(labels, label(name, cfg.ConstLabels[name]))
+	}
+
+	return &Collector{
+		Config: cfg,
+		health: h,
+		labels: strings.Join(labels, ","),
+	}, nil
+}
+
+// ServeHTTP writes the metrics in the text exposition format.
+func (c *Collector) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	buf := &bytes.Buffer{}
+
+	if _, err := c.WriteTo(buf); err != nil {
+		http.Error(rw, fmt.Sprintf("Unable to collect metrics: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", ContentType)
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(buf.Bytes())
+}
+
+// WriteTo writes the metrics in the text exposition format to "w".
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	states, failed, err := c.health.State()
+	if err != nil {
+		return 0, fmt.Errorf("Unable to fetch states: %v", err)
+	}
+
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+
+	c.writeChecks(buf, "check_status", "Whether the check has passed (1) or failed (0).", names,
+		func(name string) float64 { return boolValue(states[name].Status != "failed") })
+
+	c.writeChecks(buf, "check_degraded", "Whether the check is degraded.", names,
+		func(name string) float64 { return boolValue(isDegraded(states[name])) })
+
+	c.writeChecks(buf, "check_duration_seconds", "Duration of the latest execution of the check.", names,
+		func(name string) float64 { return states[name].Duration.Seconds() })
+
+	c.writeChecks(buf, "check_consecutive_failures", "Number of consecutive failures of the check.", names,
+		func(name string) float64 { return float64(states[name].ContiguousFailures) })
+
+	degraded := false
+	if m, ok := c.health.Maintenance(); ok {
+		degraded = m.Status == "degraded"
+	}
+
+	for _, state := range states {
+		degraded = degraded || isDegraded(state)
+	}
+
+	c.writeMetric(buf, "overall_status", "Whether the healthcheck has passed (1) or failed (0).", boolValue(!failed))
+	c.writeMetric(buf, "overall_degraded", "Whether the healthcheck is degraded.", boolValue(!failed && degraded))
+	c.writeMetric(buf, "overall_score", "Weighted health score of the checks (0-100).", float64(c.health.Score()))
+
+	c.writeRunnerStats(buf)
+
+	n, err := w.Write(buf.Bytes())
+
+	return int64(n), err
+}
+
+// writes the internals of the runner
+func (c *Collector) writeRunnerStats(buf *bytes.Buffer) {
+	stats := c.health.RunnerStats()
+
+	names := make([]string, 0, len(stats.Checks))
+	for name := range stats.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c.writeChecks(buf, "runner_active", "Number of executions of the check in flight.", names,
+		func(name string) float64 { return float64(stats.Checks[name].Active) })
+
+	c.writeChecks(buf, "runner_missed_ticks", "Number of ticks that fired while the check was in flight.", names,
+		func(name string) float64 { return float64(stats.Checks[name].MissedTicks) })
+
+	c.writeChecks(buf, "runner_coalesced_runs", "Number of executions that shared the state of the execution in flight.", names,
+		func(name string) float64 { return float64(stats.Checks[name].CoalescedRuns) })
+
+	c.writeChecks(buf, "runner_tick_drift_seconds", "Drift of the latest tick of the check from its expected time.", names,
+		func(name string) float64 { return stats.Checks[name].TickDrift.Seconds() })
+
+	c.writeMetric(buf, "runner_pending_hooks", "Number of result hook calls in flight.", float64(stats.PendingHooks))
+	c.writeMetric(buf, "runner_listener_queue_depth", "Number of events queued for the state listeners.", float64(stats.ListenerQueueDepth))
+	c.writeMetric(buf, "runner_dropped_events", "Number of events dropped by slow state listeners and subscribers.", float64(stats.DroppedEvents))
+}
+
+// writes a gauge with a sample per check
+func (c *Collector) writeChecks(buf *bytes.Buffer, name, help string, checks []string, value func(check string) float64) {
+	c.writeHeader(buf, name, help)
+
+	for _, check := range checks {
+		labels := label("check", check)
+		if c.labels != "" {
+			labels = c.labels + "," + labels
+		}
+
+		fmt.Fprintf(buf, "%v_%v{%v} %v\n", c.Config.Namespace, name, labels, value(check))
+	}
+}
+
+// writes a gauge with a single sample
+func (c *Collector) writeMetric(buf *bytes.Buffer, name, help string, value float64) {
+	c.writeHeader(buf, name, help)
+
+	if c.labels != "" {
+		fmt.Fprintf(buf, "%v_%v{%v} %v\n", c.Config.Namespace, name, c.labels, value)
+	} else {
+		fmt.Fprintf(buf, "%v_%v %v\n", c.Config.Namespace, name, value)
+	}
+}
+
+func (c *Collector) writeHeader(buf *bytes.Buffer, name, help string) {
+	fmt.Fprintf(buf, "# HELP %v_%v %v\n", c.Config.Namespace, name, help)
+	fmt.Fprintf(buf, "# TYPE %v_%v gauge\n", c.Config.Namespace, name)
+}
+
+// indicates whether the check is degraded (or its failure has been
+// downgraded during a deploy)
+func isDegraded(state health.State) bool {
+	return state.Status == "degraded" || state.Downgraded
+}
+
+func label(name, value string) string {
+	return fmt.Sprintf(`%v="%v"`, name, labelEscaper.Replace(value))
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func validateConfig(cfg *Config) error {
+	if cfg.Namespace == "" {
+		cfg.Namespace = defaultNamespace
+	}
+
+	if !validName.MatchString(cfg.Namespace) {
+		return fmt.Errorf("Invalid namespace '%v'", cfg.Namespace)
+	}
+
+	for name := range cfg.ConstLabels {
+		if !validLabel.MatchString(name) || strings.HasPrefix(name, "__") || name == "check" {
+			return fmt.Errorf("Invalid const label '%v'", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health"
+)
+
+func newHealth(checks ...*health.Config) *health.Health {
+	h := health.New()
+	h.DisableLogging()
+	h.WaitOnStart = true
+
+	Expect(h.AddChecks(checks)).To(Succeed())
+	Expect(h.Start()).To(Succeed())
+
+	return h
+}
+
+func checker(err error) health.ICheckable {
+	return health.CheckerFunc(func(ctx context.Context) (interface{}, error) {
+		return nil, err
+	})
+}
+
+func TestNewCollector(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should error with an invalid config", func(t *testing.T) {
+		_, err := NewCollector(health.New(), &Config{Namespace: "my-service"})
+		Expect(err).To(MatchError("Unable to validate prometheus config: Invalid namespace 'my-service'"))
+
+		_, err = NewCollector(health.New(), &Config{ConstLabels: map[string]string{"check": "x"}})
+		Expect(err).To(MatchError("Unable to validate prometheus config: Invalid const label 'check'"))
+
+		_, err = NewCollector(nil, nil)
+		Expect(err).To(MatchError("Health instance cannot be nil"))
+	})
+
+	t.Run("Should default the namespace", func(t *testing.T) {
+		c, err := NewCollector(health.New(), nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Config.Namespace).To(Equal("health"))
+	})
+}
+
+func TestCollector(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should expose the state of every check", func(t *testing.T) {
+		h := newHealth(
+			&health.Config{Name: "mongo", Checker: checker(nil), Interval: time.Hour, Fatal: true},
+			&health.Config{Name: "redis", Checker: checker(health.Degraded("replica lag")), Interval: time.Hour},
+			&health.Config{Name: `s3 "eu"`, Checker: checker(context.DeadlineExceeded), Interval: time.Hour},
+		)
+		defer h.Stop()
+
+		c, err := NewCollector(h, &Config{ConstLabels: map[string]string{"service": "api"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		rw := httptest.NewRecorder()
+		c.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(rw.Code).To(Equal(http.StatusOK))
+		Expect(rw.Header().Get("Content-Type")).To(Equal(ContentType))
+
+		body := rw.Body.String()
+		Expect(body).To(ContainSubstring("# TYPE health_check_status gauge\n"))
+		Expect(body).To(ContainSubstring(`health_check_status{service="api",check="mongo"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`health_check_status{service="api",check="s3 \"eu\""} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`health_check_degraded{service="api",check="redis"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`health_check_degraded{service="api",check="mongo"} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`health_check_consecutive_failures{service="api",check="s3 \"eu\""} 1` + "\n"))
+		Expect(body).To(MatchRegexp(`health_check_duration_seconds\{service="api",check="mongo"\} [0-9.e-]+\n`))
+
+		// the failed check is not fatal
+		Expect(body).To(ContainSubstring(`health_overall_status{service="api"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`health_overall_degraded{service="api"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`health_overall_score{service="api"} 50` + "\n"))
+
+		Expect(body).To(ContainSubstring(`health_runner_active{service="api",check="mongo"} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`health_runner_missed_ticks{service="api",check="mongo"} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`health_runner_coalesced_runs{service="api",check="mongo"} 0` + "\n"))
+		Expect(body).To(ContainSubstring(`health_runner_dropped_events{service="api"} 0` + "\n"))
+	})
+
+	t.Run("Should expose the overall failure", func(t *testing.T) {
+		h := newHealth(&health.Config{Name: "mongo", Checker: checker(context.Canceled), Interval: time.Hour, Fatal: true})
+		defer h.Stop()
+
+		c, err := NewCollector(h, &Config{Namespace: "app_health"})
+		Expect(err).ToNot(HaveOccurred())
+
+		rw := httptest.NewRecorder()
+		c.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		body := rw.Body.String()
+		Expect(body).To(ContainSubstring(`app_health_check_status{check="mongo"} 0` + "\n"))
+		Expect(body).To(ContainSubstring("app_health_overall_status 0\n"))
+		Expect(body).To(ContainSubstring("app_health_overall_degraded 0\n"))
+	})
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRecoveryProbe(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should stay failed until the recovery probe passes", func(t *testing.T) {
+		checker := newToggleChecker(errors.New("things broke"))
+		probe := newToggleChecker(errors.New("write failed"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker, Interval: testCheckInterval, Fatal: true, RecoveryProbe: probe},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		time.Sleep(5 * time.Millisecond)
+
+		// the probe is not used while the check itself is failing
+		Expect(probe.StatusCallCount()).To(Equal(0))
+
+		checker.SetErr(nil)
+		time.Sleep(15 * time.Millisecond)
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeTrue())
+		Expect(states["foo"].Err).To(Equal("recovery probe failed: write failed"))
+		Expect(probe.StatusCallCount()).To(BeNumerically(">=", 1))
+
+		probe.SetErr(nil)
+		time.Sleep(15 * time.Millisecond)
+
+		states, failed, _ = h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states["foo"].Status).To(Equal("ok"))
+
+		// the probe is not used while the check is healthy
+		calls := probe.StatusCallCount()
+		time.Sleep(15 * time.Millisecond)
+		Expect(probe.StatusCallCount()).To(Equal(calls))
+	})
+}
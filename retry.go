@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/InVisionApp/go-health/retry"
+)
+
+// RetryPolicy determines whether and when a failed execution of a check is
+// retried (see "Config.Retry"); the "retry" package contains the constant,
+// exponential and jittered policies (or "retry.None").
+type RetryPolicy = retry.Policy
+
+// calls the checker (see "callChecker()") and retries it according to the
+// retry policy of the check; degraded results are not retried. The delays are
+// driven by the clock, but no more retries are made once the context is done.
+func callCheckerWithRetry(ctx context.Context, cfg *Config, clock Clock, timeout time.Duration) (interface{}, error, <-chan struct{}) {
+	data, err, done := callChecker(ctx, cfg.Checker, timeout)
+
+	for n := 1; err != nil && cfg.Retry != nil && !isDegradedError(err); n++ {
+		delay, ok := cfg.Retry.Next(n)
+		if !ok || !sleep(ctx, clock, delay) {
+			break
+		}
+
+		// an aborted attempt must return before the next one starts
+		<-done
+
+		data, err, done = callChecker(ctx, cfg.Checker, timeout)
+	}
+
+	return data, err, done
+}
+
+// sleeps for the given duration; returns false if the context is done first
+func sleep(ctx context.Context, clock Clock, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	ticker := clock.NewTicker(d)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
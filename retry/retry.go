@@ -0,0 +1,141 @@
+// Package retry contains the retry policies shared by the health package
+// ("health.Config.Retry") and the bundled checkers that retry internally
+// (which cannot import the health package), so that retries behave the same
+// everywhere.
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Policy determines whether and when a failed attempt is retried.
+type Policy interface {
+	// Next returns the delay before the given retry (1 for the first retry)
+	// and false if no more retries should be made.
+	Next(retry int) (time.Duration, bool)
+}
+
+// None never retries.
+type None struct{}
+
+// Next satisfies the "Policy" interface.
+func (None) Next(retry int) (time.Duration, bool) {
+	return 0, false
+}
+
+// Constant retries after the same delay every time.
+type Constant struct {
+	Delay   time.Duration // Optional (default: retry right away)
+	Retries int           // Optional (default: no retries; unlimited if negative)
+}
+
+// Next satisfies the "Policy" interface.
+func (c Constant) Next(retry int) (time.Duration, bool) {
+	if !allowed(retry, c.Retries) {
+		return 0, false
+	}
+
+	return c.Delay, true
+}
+
+// Exponential doubles the delay on every retry, starting at "Initial" and
+// capped at "Max".
+type Exponential struct {
+	Initial time.Duration // Required
+	Max     time.Duration // Optional (default: uncapped)
+	Retries int           // Optional (default: no retries; unlimited if negative)
+}
+
+// Next satisfies the "Policy" interface.
+func (e Exponential) Next(retry int) (time.Duration, bool) {
+	if !allowed(retry, e.Retries) {
+		return 0, false
+	}
+
+	delay := e.Initial
+	for i := 1; i < retry && (e.Max <= 0 || delay < e.Max); i++ {
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64
+			break
+		}
+
+		delay *= 2
+	}
+
+	if e.Max > 0 && delay > e.Max {
+		delay = e.Max
+	}
+
+	return delay, true
+}
+
+// Jittered randomizes the delays of another policy by up to +/- "Jitter"
+// (a fraction between 0 and 1), so that many clients do not retry in lockstep.
+type Jittered struct {
+	Policy Policy  // Required
+	Jitter float64 // Required
+}
+
+// Next satisfies the "Policy" interface.
+func (j Jittered) Next(retry int) (time.Duration, bool) {
+	delay, ok := j.Policy.Next(retry)
+	if !ok {
+		return 0, false
+	}
+
+	return Jitter(delay, j.Jitter), true
+}
+
+// Jitter randomizes the duration by up to +/- the given fraction (capped at
+// 1); the result is never negative.
+func Jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+
+	if fraction > 1 {
+		fraction = 1
+	}
+
+	jittered := time.Duration(float64(d) * (1 + fraction*(2*rand.Float64()-1)))
+	if jittered < 0 {
+		return 0
+	}
+
+	return jittered
+}
+
+// Do calls "attempt" until it succeeds or the policy stops retrying; returns
+// the error of the last attempt. A nil policy never retries. No more retries
+// are made once the context is done.
+func Do(ctx context.Context, p Policy, attempt func() error) error {
+	err := attempt()
+
+	for retry := 1; err != nil && p != nil; retry++ {
+		delay, ok := p.Next(retry)
+		if !ok {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+
+		err = attempt()
+	}
+
+	return err
+}
+
+// whether the given retry is within the maximum number of retries
+func allowed(retry, retries int) bool {
+	return retries < 0 || retry <= retries
+}
@@ -0,0 +1,111 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// returns the delays of all retries, up to "max"
+func delays(p Policy, max int) []time.Duration {
+	result := make([]time.Duration, 0)
+
+	for retry := 1; retry <= max; retry++ {
+		delay, ok := p.Next(retry)
+		if !ok {
+			break
+		}
+
+		result = append(result, delay)
+	}
+
+	return result
+}
+
+func TestPolicies(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("None should never retry", func(t *testing.T) {
+		Expect(delays(None{}, 10)).To(BeEmpty())
+	})
+
+	t.Run("Constant should retry after the same delay", func(t *testing.T) {
+		Expect(delays(Constant{Delay: time.Second, Retries: 3}, 10)).To(Equal([]time.Duration{time.Second, time.Second, time.Second}))
+		Expect(delays(Constant{Delay: time.Second}, 10)).To(BeEmpty())
+		Expect(delays(Constant{Retries: -1}, 10)).To(HaveLen(10))
+	})
+
+	t.Run("Exponential should double the delay up to the max", func(t *testing.T) {
+		p := Exponential{Initial: time.Second, Max: 5 * time.Second, Retries: 5}
+		Expect(delays(p, 10)).To(Equal([]time.Duration{
+			time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+		}))
+
+		// never overflows
+		delay, ok := Exponential{Initial: time.Second, Retries: -1}.Next(1000)
+		Expect(ok).To(BeTrue())
+		Expect(delay).To(BeNumerically(">", time.Second))
+	})
+
+	t.Run("Jittered should randomize the delays of the policy", func(t *testing.T) {
+		p := Jittered{Policy: Constant{Delay: time.Second, Retries: 100}, Jitter: 0.5}
+
+		for _, delay := range delays(p, 100) {
+			Expect(delay).To(BeNumerically("~", time.Second, 500*time.Millisecond))
+		}
+
+		Expect(delays(Jittered{Policy: None{}, Jitter: 0.5}, 10)).To(BeEmpty())
+	})
+}
+
+func TestDo(t *testing.T) {
+	RegisterTestingT(t)
+
+	failing := errors.New("connection refused")
+
+	t.Run("Should retry until the attempt succeeds", func(t *testing.T) {
+		attempts := 0
+
+		err := Do(context.Background(), Constant{Retries: 5}, func() error {
+			attempts++
+			if attempts < 3 {
+				return failing
+			}
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(Equal(3))
+	})
+
+	t.Run("Should return the last error once the policy stops retrying", func(t *testing.T) {
+		attempts := 0
+
+		err := Do(context.Background(), Constant{Retries: 2}, func() error {
+			attempts++
+			return failing
+		})
+		Expect(err).To(Equal(failing))
+		Expect(attempts).To(Equal(3))
+
+		attempts = 0
+		Expect(Do(context.Background(), nil, func() error { attempts++; return failing })).To(Equal(failing))
+		Expect(attempts).To(Equal(1))
+	})
+
+	t.Run("Should stop retrying once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		attempts := 0
+
+		err := Do(ctx, Constant{Delay: time.Hour, Retries: -1}, func() error {
+			attempts++
+			return failing
+		})
+		Expect(err).To(Equal(failing))
+		Expect(attempts).To(Equal(1))
+	})
+}
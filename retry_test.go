@@ -0,0 +1,99 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+	"github.com/InVisionApp/go-health/retry"
+)
+
+func TestRetry(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should retry a failed execution before recording the failure", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+		checker.StatusReturnsOnCall(1, nil, errors.New("things broke"))
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{
+			Name:    "foo",
+			Checker: checker,
+			Retry:   retry.Constant{Delay: time.Millisecond, Retries: 2},
+			Fatal:   true,
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Failed()).To(BeFalse())
+		Expect(checker.StatusCallCount()).To(Equal(3))
+	})
+
+	t.Run("Should record the failure once the policy stops retrying", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{
+			Name:    "foo",
+			Checker: checker,
+			Retry:   retry.Constant{Retries: 1},
+			Fatal:   true,
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Failed()).To(BeTrue())
+		Expect(checker.StatusCallCount()).To(Equal(2))
+	})
+
+	t.Run("Should not retry degraded results", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, Degraded("slow"))
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: checker, Retry: retry.Constant{Retries: 3}})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(checker.StatusCallCount()).To(Equal(1))
+	})
+
+	t.Run("Should drive the delays with the clock", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+
+		h := setupNewTestHealth()
+		h.Clock = clock
+		h.AddCheck(&Config{
+			Name:     "foo",
+			Checker:  checker,
+			Interval: time.Hour,
+			Retry:    retry.Constant{Delay: time.Minute, Retries: 1},
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(checker.StatusCallCount).Should(Equal(1))
+		Consistently(checker.StatusCallCount, 50*time.Millisecond).Should(Equal(1))
+
+		// the retry waits on the clock
+		Eventually(func() int {
+			clock.Advance(time.Minute)
+			return checker.StatusCallCount()
+		}).Should(Equal(2))
+	})
+}
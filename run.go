@@ -0,0 +1,255 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrNotRunning is returned when you attempt to run a check via "h.RunCheck()"
+	// or "h.RunAll()" on a non-running healthcheck instance
+	ErrNotRunning = errors.New("Healthcheck is not running")
+
+	// ErrCheckNotFound is returned when you attempt to run a check that does not exist via "h.RunCheck()"
+	ErrCheckNotFound = errors.New("Check not found")
+)
+
+// flight is an execution of a check that is in flight; callers that attempt
+// to execute the check meanwhile share its state instead
+type flight struct {
+	// done is closed once the state has been set
+	done  chan struct{}
+	state State
+}
+
+// waits for the state of the execution; if "ctx" is done first, the latest
+// recorded state is returned instead
+func (f *flight) wait(ctx context.Context, latest func() State) State {
+	select {
+	case <-f.done:
+		return f.state
+	case <-ctx.Done():
+		return latest()
+	}
+}
+
+// runner contains an active runner of a check
+type runner struct {
+	// exec executes the check synchronously; the deadline of the context
+	// overrides the timeout of the check if it expires first
+	exec func(ctx context.Context) State
+
+	// timing contains the effective interval and timeout
+	timing Timing
+
+	// refresh executes a lazy check ("Config.CacheTTL") if its state has
+	// expired; nil for checks that run on a timer
+	refresh func()
+
+	// trigger executes a chained check ("Config.Chained") once its dependency
+	// has been executed; nil for checks that run on a timer
+	trigger func()
+
+	// cfg is the config of the check
+	cfg *Config
+
+	// onStop is called once the runner has been stopped
+	onStop func()
+
+	// firstRun is closed once the first execution has completed
+	firstRun     chan struct{}
+	firstRunOnce sync.Once
+
+	// guards against recording the state of an execution that completes
+	// after the runner has been stopped (or paused)
+	stopLock sync.RWMutex
+	stopped  bool
+	paused   bool
+
+	// serializes pausing, resuming and stopping the runner, so that the
+	// paused state (which is recorded without holding "stopLock") is neither
+	// recorded after the check has been resumed nor after it has been removed
+	pauseLock sync.Mutex
+
+	// passed is set once a startup check ("Config.Startup") has succeeded
+	passed bool
+
+	// the values published by the latest successful execution, see
+	// "Publish()"
+	outputsLock sync.RWMutex
+	outputs     map[string]interface{}
+
+	// internals exposed via "h.RunnerStats()"; the counters are accessed
+	// atomically
+	active      int64
+	missedTicks int64
+	coalesced   int64
+	drift       tickDrift
+}
+
+// stops the runner
+func (r *runner) halt() {
+	r.stopLock.Lock()
+	defer r.stopLock.Unlock()
+
+	if !r.stopped {
+		r.stopped = true
+
+		if r.onStop != nil {
+			r.onStop()
+		}
+	}
+}
+
+func (r *runner) isStopped() bool {
+	r.stopLock.RLock()
+	defer r.stopLock.RUnlock()
+
+	return r.stopped
+}
+
+// calls "record" unless the runner has been stopped or paused
+func (r *runner) record(record func()) {
+	r.stopLock.RLock()
+	defer r.stopLock.RUnlock()
+
+	if !r.stopped && !r.paused {
+		record()
+	}
+}
+
+// pauses the runner; returns false if the runner has been stopped or is
+// paused already. The caller must hold "pauseLock".
+func (r *runner) pause() bool {
+	r.stopLock.Lock()
+	defer r.stopLock.Unlock()
+
+	if r.stopped || r.paused {
+		return false
+	}
+
+	r.paused = true
+
+	return true
+}
+
+// resumes the runner; returns false if the runner was not paused. The caller
+// must hold "pauseLock".
+func (r *runner) resume() bool {
+	r.stopLock.Lock()
+	defer r.stopLock.Unlock()
+
+	resumed := r.paused
+	r.paused = false
+
+	return resumed
+}
+
+func (r *runner) isPaused() bool {
+	r.stopLock.RLock()
+	defer r.stopLock.RUnlock()
+
+	return r.paused
+}
+
+// marks the startup check as passed; returns false if it has passed already
+func (r *runner) pass() bool {
+	r.stopLock.Lock()
+	defer r.stopLock.Unlock()
+
+	passed := r.passed
+	r.passed = true
+
+	return !passed
+}
+
+func (r *runner) hasPassed() bool {
+	r.stopLock.RLock()
+	defer r.stopLock.RUnlock()
+
+	return r.passed
+}
+
+// RunCheck executes the check with the given name immediately (outside its
+// periodic schedule) and returns its fresh state, ie. before accepting traffic
+// or from an admin endpoint. The state is recorded as if the check ran on
+// schedule; if the check is already in flight (ie. because its timer has
+// fired), it is not executed again, but RunCheck waits for the execution in
+// flight and returns its state.
+//
+// A paused check (see "PauseCheck()") is not executed; its paused state is
+// returned instead. The same applies to a startup check that has passed (see
+// "Config.Startup").
+func (h *Health) RunCheck(name string) (State, error) {
+	return h.RunCheckContext(context.Background(), name)
+}
+
+// RunCheckContext is like "RunCheck()"; if the deadline of "ctx" expires
+// before the timeout of the check, the check is aborted (and recorded as
+// timed out) once the deadline is exceeded.
+func (h *Health) RunCheckContext(ctx context.Context, name string) (State, error) {
+	r, err := h.getRunner(name)
+	if err != nil {
+		return State{}, err
+	}
+
+	return r.exec(ctx), nil
+}
+
+// returns the runner of the check with the given name
+func (h *Health) getRunner(name string) (*runner, error) {
+	h.runnersLock.Lock()
+	r, ok := h.runners[name]
+	h.runnersLock.Unlock()
+
+	if !ok {
+		if !h.active.val() {
+			return nil, ErrNotRunning
+		}
+
+		return nil, ErrCheckNotFound
+	}
+
+	return r, nil
+}
+
+// RunAll executes all checks immediately and concurrently (see "RunCheck()");
+// returns the fresh states along with the overall failure status (see
+// "Health.Aggregation"). The deadline of "ctx" applies to every check (see
+// "RunCheckContext()"); if "ctx" is done before all checks have completed,
+// the states of the completed checks are returned along with the context
+// error.
+func (h *Health) RunAll(ctx context.Context) (map[string]State, bool, error) {
+	if !h.active.val() {
+		return nil, false, ErrNotRunning
+	}
+
+	h.runnersLock.Lock()
+	runners := make(map[string]*runner, len(h.runners))
+	for name, r := range h.runners {
+		runners[name] = r
+	}
+	h.runnersLock.Unlock()
+
+	results := make(chan State, len(runners))
+
+	for _, r := range runners {
+		go func(r *runner) {
+			results <- r.exec(ctx)
+		}(r)
+	}
+
+	states := make(map[string]State, len(runners))
+
+	for range runners {
+		select {
+		case state := <-results:
+			states[state.Name] = state
+		case <-ctx.Done():
+			return states, h.aggregate(states), ctx.Err()
+		}
+	}
+
+	return states, h.aggregate(states), nil
+}
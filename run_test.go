@@ -0,0 +1,203 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestRunCheck(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should execute the check immediately and return its state", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, nil)
+		checker.StatusReturnsOnCall(1, nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:     "foo",
+				Checker:  checker,
+				Interval: time.Hour,
+				Fatal:    true,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		waitFirstRuns(h)
+		Expect(checker.StatusCallCount()).To(Equal(1))
+
+		state, err := h.RunCheck("foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(checker.StatusCallCount()).To(Equal(2))
+		Expect(state.Status).To(Equal("failed"))
+		Expect(state.Err).To(Equal("things broke"))
+		Expect(state.IncidentID).ToNot(BeEmpty())
+
+		// the state is recorded
+		Expect(h.Failed()).To(BeTrue())
+	})
+
+	t.Run("Should share the state of the execution in flight", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+
+		checker := &fakes.FakeICheckable{}
+		checker.StatusStub = func() (interface{}, error) {
+			started <- struct{}{}
+			<-release
+			return nil, errors.New("things broke")
+		}
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker, Interval: time.Hour},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		// the initial execution is in flight
+		<-started
+
+		states := make(chan State, 3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				state, _ := h.RunCheck("foo")
+				states <- state
+			}()
+		}
+
+		Eventually(func() int64 { return h.RunnerStats().Checks["foo"].CoalescedRuns }).Should(Equal(int64(3)))
+		close(release)
+
+		for i := 0; i < 3; i++ {
+			Expect((<-states).Err).To(Equal("things broke"))
+		}
+
+		Expect(checker.StatusCallCount()).To(Equal(1))
+		Expect(h.RunnerStats().Checks["foo"].Active).To(BeZero())
+
+		// the next execution is not coalesced
+		waitFirstRuns(h)
+
+		_, err = h.RunCheck("foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(checker.StatusCallCount()).To(Equal(2))
+	})
+
+	t.Run("Should return the latest state once the context is done while waiting", func(t *testing.T) {
+		release := make(chan struct{})
+
+		calls := 0
+		checker := &fakes.FakeICheckable{}
+		checker.StatusStub = func() (interface{}, error) {
+			calls++
+			if calls > 1 {
+				<-release
+			}
+			return nil, nil
+		}
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker, Interval: time.Hour, Timeout: time.Minute},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+		defer close(release)
+
+		waitFirstRuns(h)
+
+		go h.RunCheck("foo")
+		Eventually(func() int64 { return h.RunnerStats().Checks["foo"].Active }).Should(Equal(int64(1)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		state, err := h.RunCheckContext(ctx, "foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("ok"))
+		Expect(checker.StatusCallCount()).To(Equal(2))
+	})
+
+	t.Run("Should error for unknown checks", func(t *testing.T) {
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:     "foo",
+				Checker:  &fakes.FakeICheckable{},
+				Interval: time.Hour,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		_, err = h.RunCheck("bar")
+		Expect(err).To(Equal(ErrCheckNotFound))
+	})
+
+	t.Run("Should error if not running", func(t *testing.T) {
+		h := New()
+
+		_, err := h.RunCheck("foo")
+		Expect(err).To(Equal(ErrNotRunning))
+
+		_, _, err = h.RunAll(context.Background())
+		Expect(err).To(Equal(ErrNotRunning))
+	})
+}
+
+func TestRunAll(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should execute all checks and return their states", func(t *testing.T) {
+		checker1 := &fakes.FakeICheckable{}
+		checker2 := &fakes.FakeICheckable{}
+		checker2.StatusReturns(nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: checker1, Interval: time.Hour, Fatal: true},
+			{Name: "bar", Checker: checker2, Interval: time.Hour},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		waitFirstRuns(h)
+
+		states, failed, err := h.RunAll(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed).To(BeFalse())
+		Expect(states).To(HaveLen(2))
+		Expect(states["foo"].Status).To(Equal("ok"))
+		Expect(states["bar"].Status).To(Equal("failed"))
+
+		Expect(checker1.StatusCallCount()).To(Equal(2))
+		Expect(checker2.StatusCallCount()).To(Equal(2))
+	})
+
+	t.Run("Should return the completed states once the context is done", func(t *testing.T) {
+		slow := &fakes.FakeICheckable{}
+		slow.StatusStub = func() (interface{}, error) {
+			time.Sleep(50 * time.Millisecond)
+			return nil, nil
+		}
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "fast", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+			{Name: "slow", Checker: slow, Interval: time.Hour},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		states, _, err := h.RunAll(ctx)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(states).To(HaveKey("fast"))
+		Expect(states).ToNot(HaveKey("slow"))
+	})
+}
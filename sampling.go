@@ -0,0 +1,282 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+const (
+	// DefaultHistoryRetention is the default retention of the sampled
+	// history (see "Health.HistorySampling")
+	DefaultHistoryRetention = 24 * time.Hour
+)
+
+// IHistoryStore persists the sampled history of the checks (see
+// "Health.HistoryStore"), so that it survives restarts.
+type IHistoryStore interface {
+	// Load returns the persisted samples by check name, oldest first
+	Load() (map[string][]HistoryEntry, error)
+
+	// Save persists the samples of all checks, replacing the persisted ones
+	Save(samples map[string][]HistoryEntry) error
+}
+
+// FileHistoryStore persists the sampled history as JSON to a local file; the
+// file is replaced atomically on every save.
+type FileHistoryStore struct {
+	Path string
+}
+
+// NewFileHistoryStore returns a store that persists the sampled history to
+// the file at the given path.
+func NewFileHistoryStore(path string) *FileHistoryStore {
+	return &FileHistoryStore{Path: path}
+}
+
+// Load reads the samples from the file; a missing file contains no samples.
+func (f *FileHistoryStore) Load() (map[string][]HistoryEntry, error) {
+	data, err := os.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read history file: %v", err)
+	}
+
+	samples := make(map[string][]HistoryEntry)
+	if err := json.Unmarshal(data, &samples); err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal history file: %v", err)
+	}
+
+	return samples, nil
+}
+
+// Save writes the samples to a temporary file, which then replaces the file.
+func (f *FileHistoryStore) Save(samples map[string][]HistoryEntry) error {
+	data, err := json.Marshal(samples)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal history: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("Unable to create history file: %v", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Unable to write history file: %v", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Unable to write history file: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return fmt.Errorf("Unable to replace history file: %v", err)
+	}
+
+	return nil
+}
+
+// sampler downsamples the results of the checks into one sample per
+// "Health.HistorySampling"; a sample contains the worst status (and its
+// error) and the longest duration of the results within its interval
+type sampler struct {
+	lock    sync.Mutex
+	samples map[string][]HistoryEntry // by check name, oldest first
+	loaded  bool                      // whether the persisted samples have been loaded
+
+	dirty chan struct{}  // signals the writer that a sample has been completed
+	done  chan struct{}  // stops the writer
+	wg    sync.WaitGroup // waits for the writer to exit
+}
+
+// the higher the rank, the worse the status; the worst status of the results
+// is retained in a sample
+func statusRank(status string) int {
+	switch status {
+	case "failed":
+		return 3
+	case "degraded":
+		return 2
+	case "ok":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SampledHistory returns the downsampled results of the check with the given
+// name (see "Health.HistorySampling"), oldest first (thread-safe), ie. for
+// incident timelines; the samples are persisted with "Health.HistoryStore".
+// The check time of a sample is the start of its interval. Returns nil if the
+// check has no samples (or the sampling is disabled).
+func (h *Health) SampledHistory(name string) []HistoryEntry {
+	h.sampler.lock.Lock()
+	defer h.sampler.lock.Unlock()
+
+	samples, ok := h.sampler.samples[name]
+	if !ok {
+		return nil
+	}
+
+	return append([]HistoryEntry{}, samples...)
+}
+
+// records the state in the sampled history of the check
+func (h *Health) recordSample(state *State) {
+	if h.HistorySampling <= 0 {
+		return
+	}
+
+	s := &h.sampler
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.samples == nil {
+		s.samples = make(map[string][]HistoryEntry)
+	}
+
+	entry := HistoryEntry{
+		Status:    state.Status,
+		Err:       state.Err,
+		Duration:  state.Duration,
+		CheckTime: state.CheckTime.Truncate(h.HistorySampling),
+	}
+
+	samples := s.samples[state.Name]
+
+	// merge the result into the current sample
+	if n := len(samples); n > 0 && samples[n-1].CheckTime.Equal(entry.CheckTime) {
+		sample := &samples[n-1]
+
+		if statusRank(entry.Status) > statusRank(sample.Status) {
+			sample.Status, sample.Err = entry.Status, entry.Err
+		}
+
+		if entry.Duration > sample.Duration {
+			sample.Duration = entry.Duration
+		}
+
+		return
+	}
+
+	s.samples[state.Name] = pruneSamples(append(samples, entry), state.CheckTime.Add(-h.historyRetention()))
+
+	// the previous sample is complete
+	if len(samples) > 0 && s.dirty != nil {
+		select {
+		case s.dirty <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// returns the effective retention of the sampled history
+func (h *Health) historyRetention() time.Duration {
+	if h.HistoryRetention > 0 {
+		return h.HistoryRetention
+	}
+
+	return DefaultHistoryRetention
+}
+
+// drops the samples before the given time
+func pruneSamples(samples []HistoryEntry, since time.Time) []HistoryEntry {
+	i := sort.Search(len(samples), func(i int) bool {
+		return !samples[i].CheckTime.Before(since)
+	})
+
+	return samples[i:]
+}
+
+// loads the persisted samples (once) and starts the writer of the sampled
+// history; the caller must hold "configsLock"
+func (h *Health) startSampler() {
+	if h.HistorySampling <= 0 || h.HistoryStore == nil {
+		return
+	}
+
+	s := &h.sampler
+
+	s.lock.Lock()
+	if !s.loaded {
+		s.loaded = true
+
+		if samples, err := h.HistoryStore.Load(); err != nil {
+			h.Logger.WithFields(log.Fields{"err": err}).Error("Unable to load sampled history")
+		} else if samples != nil {
+			since := h.clock().Now().Add(-h.historyRetention())
+			for name, entries := range samples {
+				samples[name] = pruneSamples(entries, since)
+			}
+
+			s.samples = samples
+		}
+	}
+
+	s.dirty = make(chan struct{}, 1)
+	s.done = make(chan struct{})
+	dirty, done := s.dirty, s.done
+	s.lock.Unlock()
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			select {
+			case <-dirty:
+				h.saveSamples()
+			case <-done:
+				h.saveSamples()
+				return
+			}
+		}
+	}()
+}
+
+// stops the writer of the sampled history once it has saved the samples; the
+// caller must hold "configsLock"
+func (h *Health) stopSampler() {
+	s := &h.sampler
+
+	s.lock.Lock()
+	done := s.done
+	s.dirty, s.done = nil, nil
+	s.lock.Unlock()
+
+	if done != nil {
+		close(done)
+		s.wg.Wait()
+	}
+}
+
+// persists a copy of the samples
+func (h *Health) saveSamples() {
+	s := &h.sampler
+
+	s.lock.Lock()
+	samples := make(map[string][]HistoryEntry, len(s.samples))
+	for name, entries := range s.samples {
+		samples[name] = append([]HistoryEntry{}, entries...)
+	}
+	s.lock.Unlock()
+
+	if err := h.HistoryStore.Save(samples); err != nil {
+		h.Logger.WithFields(log.Fields{"err": err}).Error("Unable to save sampled history")
+	}
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+// returns a started health instance with a single check that fails while
+// "*fail" is true
+func setupSampledHealth(clock *FakeClock, store IHistoryStore, fail *bool) *Health {
+	checker := &fakes.FakeICheckable{}
+	checker.StatusStub = func() (interface{}, error) {
+		if *fail {
+			return nil, errors.New("connection refused")
+		}
+
+		return nil, nil
+	}
+
+	h := setupNewTestHealth()
+	h.Clock = clock
+	h.HistorySampling = time.Minute
+	h.HistoryRetention = 3 * time.Minute
+	h.HistoryStore = store
+	h.WaitOnStart = true
+	h.AddCheck(&Config{Name: "foo", Checker: checker, Interval: time.Hour})
+
+	Expect(h.Start()).To(Succeed())
+
+	return h
+}
+
+func TestSampledHistory(t *testing.T) {
+	RegisterTestingT(t)
+
+	start := time.Date(2020, 1, 1, 0, 0, 30, 0, time.UTC)
+
+	t.Run("Should downsample the results of a check", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		fail := false
+
+		h := setupSampledHealth(clock, nil, &fail)
+		defer h.Stop()
+
+		fail = true
+		_, err := h.RunCheck("foo")
+		Expect(err).ToNot(HaveOccurred())
+
+		clock.Advance(time.Minute)
+		fail = false
+		_, err = h.RunCheck("foo")
+		Expect(err).ToNot(HaveOccurred())
+
+		samples := h.SampledHistory("foo")
+		Expect(samples).To(HaveLen(2))
+
+		// the worst result of the interval is retained
+		Expect(samples[0].Status).To(Equal("failed"))
+		Expect(samples[0].Err).To(Equal("connection refused"))
+		Expect(samples[0].CheckTime).To(Equal(start.Truncate(time.Minute)))
+		Expect(samples[1].Status).To(Equal("ok"))
+
+		// the samples outside of the retention are dropped
+		for i := 0; i < 4; i++ {
+			clock.Advance(time.Minute)
+			_, err = h.RunCheck("foo")
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		samples = h.SampledHistory("foo")
+		Expect(samples).To(HaveLen(3))
+		Expect(samples[0].CheckTime).To(Equal(start.Truncate(time.Minute).Add(3 * time.Minute)))
+
+		Expect(h.SampledHistory("bar")).To(BeNil())
+	})
+
+	t.Run("Should persist the samples across restarts", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		store := NewFileHistoryStore(filepath.Join(t.TempDir(), "history.json"))
+		fail := true
+
+		h := setupSampledHealth(clock, store, &fail)
+
+		clock.Advance(time.Minute)
+		fail = false
+		_, err := h.RunCheck("foo")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(h.Stop()).To(Succeed())
+
+		samples, err := store.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples["foo"]).To(HaveLen(2))
+
+		clock.Advance(time.Minute)
+
+		restarted := setupSampledHealth(clock, store, &fail)
+		defer restarted.Stop()
+
+		// the persisted samples are followed by the one of the restarted check
+		restored := restarted.SampledHistory("foo")
+		Expect(restored).To(HaveLen(3))
+		Expect(restored[0].Status).To(Equal("failed"))
+	})
+
+	t.Run("Should not sample by default", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.SampledHistory("foo")).To(BeNil())
+	})
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	RegisterTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	store := NewFileHistoryStore(path)
+
+	samples, err := store.Load()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(samples).To(BeNil())
+
+	Expect(os.WriteFile(path, []byte("{"), 0600)).To(Succeed())
+
+	_, err = store.Load()
+	Expect(err).To(MatchError(ContainSubstring("Unable to unmarshal history file")))
+}
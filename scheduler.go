@@ -0,0 +1,317 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// the default resolution of the timing wheel
+	defaultWheelResolution = time.Duration(100) * time.Millisecond
+
+	// the number of slots of the timing wheel
+	wheelSlots = 512
+
+	// the default number of workers of "SchedulerWorkerPool"
+	defaultConcurrency = 10
+)
+
+// Scheduler determines how the periodic executions of the checks are
+// scheduled.
+type Scheduler int
+
+const (
+	// SchedulerTicker runs one goroutine and "time.Ticker" per check; this is
+	// the default and the best fit for a small number of checks
+	SchedulerTicker Scheduler = iota
+
+	// SchedulerTimingWheel schedules all checks off a single timing wheel
+	// (one goroutine and timer in total), so that services with thousands of
+	// checks do not pay one goroutine and timer per check. Intervals are
+	// rounded up to "Health.WheelResolution".
+	SchedulerTimingWheel
+
+	// SchedulerWorkerPool schedules all checks off a single timing wheel (see
+	// "SchedulerTimingWheel") and queues their executions to a bounded pool
+	// of "Health.Concurrency" workers, so that hundreds of checks neither
+	// spawn a goroutine per execution nor stampede their dependencies (ie.
+	// when they are all started at once).
+	SchedulerWorkerPool
+)
+
+// schedule delivers the ticks of a single check
+type schedule interface {
+	// reset changes the interval; the next tick is delivered after "d"
+	reset(d time.Duration)
+
+	// stop stops delivering ticks
+	stop()
+}
+
+// creates the schedule of a check according to "h.Scheduler"; "fire" is
+// called on every tick and must not block
+func (h *Health) newSchedule(interval time.Duration, fire func()) schedule {
+	if h.wheel != nil {
+		return h.wheel.add(interval, fire)
+	}
+
+	return newTickerSchedule(h.clock(), interval, fire)
+}
+
+// tickerSchedule delivers the ticks of a "Ticker" from its own goroutine
+type tickerSchedule struct {
+	ticker Ticker
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newTickerSchedule(clock Clock, interval time.Duration, fire func()) *tickerSchedule {
+	t := &tickerSchedule{
+		ticker: clock.NewTicker(interval),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer t.ticker.Stop()
+
+		for {
+			select {
+			case <-t.ticker.C():
+				fire()
+			case <-t.done:
+				return
+			}
+		}
+	}()
+
+	return t
+}
+
+func (t *tickerSchedule) reset(d time.Duration) {
+	t.ticker.Reset(d)
+}
+
+func (t *tickerSchedule) stop() {
+	t.once.Do(func() { close(t.done) })
+}
+
+// timingWheel is a (single level) hashed timing wheel: every slot holds the
+// entries that are due when the wheel reaches the slot; entries that are due
+// more than one revolution ahead wait for the remaining rounds.
+type timingWheel struct {
+	resolution time.Duration
+
+	lock   sync.Mutex
+	slots  []map[*wheelEntry]struct{}
+	cursor int
+
+	done chan struct{}
+}
+
+// wheelEntry is the schedule of a single check on the timing wheel
+type wheelEntry struct {
+	wheel    *timingWheel
+	fire     func()
+	interval time.Duration
+
+	// guarded by the lock of the wheel
+	slot   int
+	rounds int
+	active bool
+}
+
+func newTimingWheel(clock Clock, resolution time.Duration) *timingWheel {
+	if resolution <= 0 {
+		resolution = defaultWheelResolution
+	}
+
+	w := &timingWheel{
+		resolution: resolution,
+		slots:      make([]map[*wheelEntry]struct{}, wheelSlots),
+		done:       make(chan struct{}),
+	}
+
+	for i := range w.slots {
+		w.slots[i] = make(map[*wheelEntry]struct{}, 0)
+	}
+
+	// created right away, so that no tick of a fake clock is missed
+	go w.run(clock.NewTicker(resolution))
+
+	return w
+}
+
+func (w *timingWheel) run(ticker Ticker) {
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			w.advance()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// advances the wheel by one slot and fires the entries that are due
+func (w *timingWheel) advance() {
+	w.lock.Lock()
+
+	w.cursor = (w.cursor + 1) % len(w.slots)
+
+	due := make([]*wheelEntry, 0)
+
+	for e := range w.slots[w.cursor] {
+		if e.rounds > 0 {
+			e.rounds--
+			continue
+		}
+
+		delete(w.slots[w.cursor], e)
+		due = append(due, e)
+	}
+
+	// reschedule before firing, as firing may reset the entries
+	for _, e := range due {
+		w.schedule(e, e.interval)
+	}
+
+	w.lock.Unlock()
+
+	for _, e := range due {
+		e.fire()
+	}
+}
+
+// adds a periodic entry to the wheel
+func (w *timingWheel) add(interval time.Duration, fire func()) *wheelEntry {
+	e := &wheelEntry{wheel: w, fire: fire, interval: interval, active: true}
+
+	w.lock.Lock()
+	w.schedule(e, interval)
+	w.lock.Unlock()
+
+	return e
+}
+
+// places the entry "d" ahead of the cursor; the caller must hold the lock
+func (w *timingWheel) schedule(e *wheelEntry, d time.Duration) {
+	ticks := int((d + w.resolution - 1) / w.resolution)
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	e.slot = (w.cursor + ticks) % len(w.slots)
+	e.rounds = (ticks - 1) / len(w.slots)
+
+	w.slots[e.slot][e] = struct{}{}
+}
+
+// stops the wheel; the entries are no longer fired
+func (w *timingWheel) stop() {
+	close(w.done)
+}
+
+func (e *wheelEntry) reset(d time.Duration) {
+	e.wheel.lock.Lock()
+	defer e.wheel.lock.Unlock()
+
+	if !e.active {
+		return
+	}
+
+	delete(e.wheel.slots[e.slot], e)
+
+	e.interval = d
+	e.wheel.schedule(e, d)
+}
+
+func (e *wheelEntry) stop() {
+	e.wheel.lock.Lock()
+	defer e.wheel.lock.Unlock()
+
+	if e.active {
+		e.active = false
+		delete(e.wheel.slots[e.slot], e)
+	}
+}
+
+// workQueue executes the queued jobs on a fixed number of workers; the queue
+// is unbounded, as every check has at most one execution queued or in flight
+type workQueue struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	jobs    []func()
+	stopped bool
+}
+
+func newWorkQueue(workers int) *workQueue {
+	if workers <= 0 {
+		workers = defaultConcurrency
+	}
+
+	q := &workQueue{jobs: make([]func(), 0)}
+	q.cond = sync.NewCond(&q.lock)
+
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+func (q *workQueue) work() {
+	for {
+		q.lock.Lock()
+		for len(q.jobs) == 0 && !q.stopped {
+			q.cond.Wait()
+		}
+
+		if q.stopped {
+			q.lock.Unlock()
+			return
+		}
+
+		job := q.jobs[0]
+		q.jobs[0] = nil
+		q.jobs = q.jobs[1:]
+		q.lock.Unlock()
+
+		job()
+	}
+}
+
+// queues the job; a nil queue runs the job in its own goroutine instead
+func (q *workQueue) dispatch(job func()) {
+	if q == nil {
+		go job()
+		return
+	}
+
+	q.push(job)
+}
+
+// queues the job; the job is dropped if the queue has been stopped
+func (q *workQueue) push(job func()) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.stopped {
+		return
+	}
+
+	q.jobs = append(q.jobs, job)
+	q.cond.Signal()
+}
+
+// stops the workers (once they complete their current job); queued jobs are
+// dropped
+func (q *workQueue) stop() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.stopped = true
+	q.jobs = nil
+	q.cond.Broadcast()
+}
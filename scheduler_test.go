@@ -0,0 +1,206 @@
+package health
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+// creates a timing wheel that is advanced manually
+func newManualWheel() *timingWheel {
+	w := &timingWheel{
+		resolution: time.Millisecond,
+		slots:      make([]map[*wheelEntry]struct{}, wheelSlots),
+	}
+
+	for i := range w.slots {
+		w.slots[i] = make(map[*wheelEntry]struct{}, 0)
+	}
+
+	return w
+}
+
+func TestTimingWheel(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should fire entries periodically, including after multiple rounds", func(t *testing.T) {
+		w := newManualWheel()
+
+		fired := 0
+		w.add(time.Duration(wheelSlots+2)*time.Millisecond, func() { fired++ })
+
+		for i := 0; i < wheelSlots+1; i++ {
+			w.advance()
+		}
+		Expect(fired).To(Equal(0))
+
+		w.advance()
+		Expect(fired).To(Equal(1))
+
+		for i := 0; i < wheelSlots+2; i++ {
+			w.advance()
+		}
+		Expect(fired).To(Equal(2))
+	})
+
+	t.Run("Should round intervals up to the resolution", func(t *testing.T) {
+		w := newManualWheel()
+
+		fired := 0
+		w.add(2500*time.Microsecond, func() { fired++ })
+
+		w.advance()
+		w.advance()
+		Expect(fired).To(Equal(0))
+
+		w.advance()
+		Expect(fired).To(Equal(1))
+	})
+
+	t.Run("Should reset and stop entries", func(t *testing.T) {
+		w := newManualWheel()
+
+		fired := 0
+		e := w.add(10*time.Millisecond, func() { fired++ })
+
+		w.advance()
+		e.reset(2 * time.Millisecond)
+
+		w.advance()
+		w.advance()
+		Expect(fired).To(Equal(1))
+
+		e.stop()
+		for i := 0; i < 10; i++ {
+			w.advance()
+		}
+		Expect(fired).To(Equal(1))
+
+		// resetting a stopped entry is a noop
+		e.reset(time.Millisecond)
+		w.advance()
+		Expect(fired).To(Equal(1))
+	})
+}
+
+func TestSchedulerTimingWheel(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should execute the checks periodically off the timing wheel", func(t *testing.T) {
+		checkers := []*fakes.FakeICheckable{{}, {}, {}}
+
+		h := New()
+		h.DisableLogging()
+		h.Scheduler = SchedulerTimingWheel
+		h.WheelResolution = 2 * time.Millisecond
+
+		for i, name := range []string{"foo", "bar", "baz"} {
+			h.AddCheck(&Config{Name: name, Checker: checkers[i], Interval: testCheckInterval})
+		}
+		Expect(h.Start()).To(Succeed())
+		Expect(h.wheel).ToNot(BeNil())
+
+		time.Sleep(55 * time.Millisecond)
+
+		for _, c := range checkers {
+			Expect(c.StatusCallCount()).To(BeNumerically(">=", 4))
+			Expect(c.StatusCallCount()).To(BeNumerically("<=", 7))
+		}
+
+		states, _, _ := h.State()
+		Expect(states).To(HaveLen(3))
+
+		Expect(h.Stop()).To(Succeed())
+		Expect(h.wheel).To(BeNil())
+
+		// let executions that were in flight complete
+		time.Sleep(5 * time.Millisecond)
+
+		calls := checkers[0].StatusCallCount()
+		Consistently(checkers[0].StatusCallCount, 25*time.Millisecond).Should(Equal(calls))
+	})
+
+	t.Run("Should no longer fire removed checks", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		h := New()
+		h.DisableLogging()
+		h.Scheduler = SchedulerTimingWheel
+		h.WheelResolution = 2 * time.Millisecond
+
+		h.AddChecks([]*Config{
+			{Name: "foo", Checker: checker, Interval: testCheckInterval},
+			{Name: "bar", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+		})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(checker.StatusCallCount).Should(BeNumerically(">=", 2))
+		Expect(h.RemoveCheck("foo")).To(Succeed())
+
+		time.Sleep(5 * time.Millisecond)
+
+		calls := checker.StatusCallCount()
+		Consistently(checker.StatusCallCount, 25*time.Millisecond).Should(Equal(calls))
+	})
+}
+
+func TestSchedulerWorkerPool(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should execute the checks on a bounded number of workers", func(t *testing.T) {
+		var running, maxRunning int64
+
+		h := New()
+		h.DisableLogging()
+		h.Scheduler = SchedulerWorkerPool
+		h.Concurrency = 2
+		h.WheelResolution = 2 * time.Millisecond
+
+		checkers := make([]*slowChecker, 0)
+		for _, name := range []string{"foo", "bar", "baz", "qux", "quux"} {
+			c := &slowChecker{delay: 5 * time.Millisecond, running: &running, maxRunning: &maxRunning}
+			checkers = append(checkers, c)
+
+			h.AddCheck(&Config{Name: name, Checker: c, Interval: testCheckInterval})
+		}
+		Expect(h.Start()).To(Succeed())
+		Expect(h.queue).ToNot(BeNil())
+
+		Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(5))
+		time.Sleep(30 * time.Millisecond)
+
+		Expect(atomic.LoadInt64(&maxRunning)).To(Equal(int64(2)))
+
+		Expect(h.Stop()).To(Succeed())
+		Expect(h.queue).To(BeNil())
+		Expect(h.wheel).To(BeNil())
+	})
+
+	t.Run("Should count the ticks that fire while the check is queued or in flight", func(t *testing.T) {
+		var running, maxRunning int64
+
+		h := New()
+		h.DisableLogging()
+		h.Scheduler = SchedulerWorkerPool
+		h.Concurrency = 1
+		h.WheelResolution = 2 * time.Millisecond
+
+		h.AddCheck(&Config{
+			Name:     "foo",
+			Checker:  &slowChecker{delay: 25 * time.Millisecond, running: &running, maxRunning: &maxRunning},
+			Interval: testCheckInterval,
+		})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(func() int64 {
+			states, _, _ := h.State()
+			return states["foo"].MissedTicks
+		}).Should(BeNumerically(">=", 1))
+	})
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	defaultDegradedWeight = 0.5
+)
+
+// validates the weights of the checks
+func validateWeights(cfgs []*Config) error {
+	for _, c := range cfgs {
+		if c.Weight < 0 {
+			return fmt.Errorf("Check '%v' cannot have a negative weight", c.Name)
+		}
+	}
+
+	return nil
+}
+
+// Score returns the weighted health score of the checks between 0 (all
+// checks have failed) and 100 (all checks are healthy), ie. for load
+// balancers or autoscalers that make graded decisions instead of binary ones.
+// Every check counts with its "Config.Weight": failed checks lower the score
+// by their full weight, degraded checks by "DegradedWeight" of it; skipped,
+// paused and expired checks are ignored. Only the checks matching the options
+// (ie. "WithTags()") are taken into account. Returns 100 if there are no
+// states (yet).
+func (h *Health) Score(opts ...StateOption) int {
+	degradedWeight := h.DegradedWeight
+	if degradedWeight <= 0 || degradedWeight > 1 {
+		degradedWeight = defaultDegradedWeight
+	}
+
+	return int(math.Round(100 * h.weightedScore(opts, degradedWeight)))
+}
+
+// returns the weighted health score of the checks matching the options
+// between 0 and 1; computed off the shared snapshot, since it is on the path
+// of every request that passes through the shedder (see "Shedder.Admit()")
+func (h *Health) weightedScore(opts []StateOption, degradedWeight float64) float64 {
+	h.refreshLazyChecks(opts)
+
+	return weightedScore(h.snapshotStates(), newStateOptions(opts).tags, degradedWeight)
+}
+
+// returns the weighted health score of the states between 0 and 1; only the
+// states with at least one of the tags are included, unless there are none
+func weightedScore(states map[string]State, tags []string, degradedWeight float64) float64 {
+	total, penalty := 0.0, 0.0
+
+	for _, state := range states {
+		if len(tags) > 0 && !hasAnyTag(state.Tags, tags) {
+			continue
+		}
+
+		weight := state.weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		switch {
+		case state.isSkipped(), state.isPaused(), state.isExpired():
+			continue
+		case state.isFailure() && !state.Downgraded:
+			penalty += weight
+		case state.isDegraded() || state.Warning != "":
+			penalty += weight * degradedWeight
+		}
+
+		total += weight
+	}
+
+	if total == 0 {
+		return 1
+	}
+
+	return 1 - penalty/total
+}
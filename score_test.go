@@ -0,0 +1,70 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestScore(t *testing.T) {
+	RegisterTestingT(t)
+
+	failing := &fakes.FakeICheckable{}
+	failing.StatusReturns(nil, errors.New("things broke"))
+
+	degraded := &fakes.FakeICheckable{}
+	degraded.StatusReturns(nil, Degraded("replica lag"))
+
+	t.Run("Should weight the checks", func(t *testing.T) {
+		h, _, err := setupRunners([]*Config{
+			{Name: "db", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Weight: 3, Tags: []string{"db"}},
+			{Name: "cache", Checker: degraded, Interval: time.Hour, Weight: 2, Tags: []string{"db"}},
+			{Name: "recommendations", Checker: failing, Interval: time.Hour, Weight: 0.5},
+			{Name: "search", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(4))
+
+		// 6.5 in total; the failed check costs 0.5, the degraded one 2 * 0.5
+		Expect(h.Score()).To(Equal(77))
+		Expect(h.Score(WithTags("db"))).To(Equal(80))
+
+		h.DegradedWeight = 1
+		Expect(h.Score()).To(Equal(62))
+
+		// the shedder uses the weights as well
+		Expect(NewShedder(h, nil).Score()).To(BeNumerically("~", 1-1.5/6.5, 0.001))
+	})
+
+	t.Run("Should ignore paused checks", func(t *testing.T) {
+		h, _, err := setupRunners([]*Config{
+			{Name: "foo", Checker: failing, Interval: time.Hour, Weight: 5},
+			{Name: "bar", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() int { return h.Score() }).Should(Equal(17))
+
+		Expect(h.PauseCheck("foo")).To(Succeed())
+		Expect(h.Score()).To(Equal(100))
+	})
+
+	t.Run("Should be 100 without states", func(t *testing.T) {
+		Expect(New().Score()).To(Equal(100))
+	})
+
+	t.Run("Should error on negative weights", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Weight: -1})
+
+		err := h.Start()
+		Expect(err).To(MatchError("Unable to start healthcheck: Check 'foo' cannot have a negative weight"))
+	})
+}
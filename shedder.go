@@ -0,0 +1,95 @@
+package health
+
+import (
+	"math/rand"
+)
+
+const (
+	defaultShedderMinAdmission = 0.1
+)
+
+// ShedderConfig is used for configuring a "Shedder".
+//
+// "MinAdmission" is optional; the lower bound of the admission probability,
+// ie. the fraction of traffic that is admitted even if all checks have
+// failed; defaults to 0.1.
+//
+// "DegradedWeight" is optional; how much a degraded check (or a check with a
+// warning, or a failure downgraded during a deploy) lowers the health score
+// compared to a failed check; defaults to 0.5.
+//
+// "StateOptions" is optional; only the checks matching the options (ie.
+// "WithTags()") are taken into account.
+type ShedderConfig struct {
+	MinAdmission   float64
+	DegradedWeight float64
+	StateOptions   []StateOption
+}
+
+// Shedder derives an admission probability from the health of the checks so
+// that an unhealthy (ie. overloaded) service sheds a fraction of its traffic
+// instead of failing closed. Plug "Admit()" into HTTP (see
+// "handlers.NewShedderMiddleware") or gRPC middleware.
+type Shedder struct {
+	Config *ShedderConfig
+
+	health IHealth
+
+	// returns a random number in [0, 1)
+	random func() float64
+}
+
+// NewShedder creates a new shedder for the given health instance; "cfg" may
+// be nil.
+func NewShedder(h IHealth, cfg *ShedderConfig) *Shedder {
+	if cfg == nil {
+		cfg = &ShedderConfig{}
+	}
+
+	if cfg.MinAdmission <= 0 || cfg.MinAdmission > 1 {
+		cfg.MinAdmission = defaultShedderMinAdmission
+	}
+
+	if cfg.DegradedWeight <= 0 || cfg.DegradedWeight > 1 {
+		cfg.DegradedWeight = defaultDegradedWeight
+	}
+
+	return &Shedder{
+		Config: cfg,
+		health: h,
+		random: rand.Float64,
+	}
+}
+
+// Score returns the health score of the checks between 0 (all checks failed)
+// and 1 (all checks are healthy), weighted by "Config.Weight" (see
+// "h.Score()"); degraded checks count with "DegradedWeight".
+func (s *Shedder) Score() float64 {
+	// avoid copying the states on every request
+	if h, ok := s.health.(*Health); ok {
+		return h.weightedScore(s.Config.StateOptions, s.Config.DegradedWeight)
+	}
+
+	states, _, err := s.health.State(s.Config.StateOptions...)
+	if err != nil {
+		return 0
+	}
+
+	return weightedScore(states, nil, s.Config.DegradedWeight)
+}
+
+// AdmissionProbability returns the probability with which a request should be
+// admitted, scaled from "MinAdmission" (score 0) to 1 (score 1).
+func (s *Shedder) AdmissionProbability() float64 {
+	return s.Config.MinAdmission + (1-s.Config.MinAdmission)*s.Score()
+}
+
+// Admit decides whether a request should be admitted (true) or shed (false).
+func (s *Shedder) Admit() bool {
+	p := s.AdmissionProbability()
+	if p >= 1 {
+		return true
+	}
+
+	return s.random() < p
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestShedder(t *testing.T) {
+	RegisterTestingT(t)
+
+	failing := &fakes.FakeICheckable{}
+	failing.StatusReturns(nil, errors.New("things broke"))
+
+	h, _, err := setupRunners([]*Config{
+		{Name: "a", Checker: failing, Interval: time.Hour, Tags: []string{"db"}},
+		{Name: "b", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Tags: []string{"db"}},
+		{Name: "c", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+		{Name: "d", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+	}, nil)
+	Expect(err).ToNot(HaveOccurred())
+	defer h.Stop()
+
+	Eventually(func() int { states, _, _ := h.State(); return len(states) }).Should(Equal(4))
+
+	t.Run("Should set defaults", func(t *testing.T) {
+		s := NewShedder(h, nil)
+		Expect(s.Config.MinAdmission).To(Equal(0.1))
+		Expect(s.Config.DegradedWeight).To(Equal(0.5))
+	})
+
+	t.Run("Should derive the admission probability from the health score", func(t *testing.T) {
+		s := NewShedder(h, &ShedderConfig{MinAdmission: 0.2})
+
+		// 1 of 4 checks failed
+		Expect(s.Score()).To(BeNumerically("~", 0.75, 0.001))
+		Expect(s.AdmissionProbability()).To(BeNumerically("~", 0.8, 0.001))
+
+		s.random = func() float64 { return 0.79 }
+		Expect(s.Admit()).To(BeTrue())
+
+		s.random = func() float64 { return 0.81 }
+		Expect(s.Admit()).To(BeFalse())
+	})
+
+	t.Run("Should only take the matching checks into account", func(t *testing.T) {
+		s := NewShedder(h, &ShedderConfig{StateOptions: []StateOption{WithTags("db")}})
+		Expect(s.Score()).To(BeNumerically("~", 0.5, 0.001))
+	})
+
+	t.Run("Should always admit if all checks are healthy", func(t *testing.T) {
+		s := NewShedder(New(), nil)
+		s.random = func() float64 { return 0.9999 }
+
+		Expect(s.Score()).To(Equal(1.0))
+		Expect(s.Admit()).To(BeTrue())
+	})
+}
@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/InVisionApp/go-logger"
+)
+
+// StopWithContext stops the healthcheck like "h.Stop()", then waits for the
+// executions of the checks that are still in flight to complete (or "ctx" to
+// expire) and finally calls "Close()" on the checkers (and recovery probes)
+// that implement "io.Closer", ie. to disconnect the Mongo or Redis clients
+// of the checkers cleanly on shutdown. The checkers are closed even if "ctx"
+// expires first; in that case, the error of the context is returned.
+// Otherwise, the first error returned by "Close()" is returned (all of them
+// are logged).
+//
+// Closed checkers cannot be used anymore; do not restart the healthcheck
+// afterwards.
+func (h *Health) StopWithContext(ctx context.Context) error {
+	if err := h.Stop(); err != nil {
+		return err
+	}
+
+	waitErr := h.executions.wait(ctx)
+	closeErr := h.closeCheckers()
+
+	if waitErr != nil {
+		return fmt.Errorf("Unable to wait for in-flight checks: %v", waitErr)
+	}
+
+	return closeErr
+}
+
+// calls "Close()" on the checkers that implement "io.Closer" (once per
+// checker); returns the first error
+func (h *Health) closeCheckers() error {
+	h.configsLock.Lock()
+	configs := make([]*Config, len(h.configs))
+	copy(configs, h.configs)
+	h.configsLock.Unlock()
+
+	var firstErr error
+	closed := make(map[interface{}]struct{})
+
+	closeChecker := func(name string, checker ICheckable) {
+		closer, ok := checker.(io.Closer)
+		if !ok {
+			return
+		}
+
+		// the same checker may be shared between checks
+		if reflect.TypeOf(closer).Comparable() {
+			if _, ok := closed[closer]; ok {
+				return
+			}
+
+			closed[closer] = struct{}{}
+		}
+
+		if err := closer.Close(); err != nil {
+			h.Logger.WithFields(log.Fields{"name": name, "err": err}).Error("Unable to close checker")
+
+			if firstErr == nil {
+				firstErr = fmt.Errorf("Unable to close checker of '%v': %v", name, err)
+			}
+		}
+	}
+
+	for _, cfg := range configs {
+		closeChecker(cfg.Name, cfg.Checker)
+
+		if cfg.RecoveryProbe != nil {
+			closeChecker(cfg.Name, cfg.RecoveryProbe)
+		}
+	}
+
+	return firstErr
+}
+
+// executions keeps track of the executions of the checks that are in flight
+type executions struct {
+	lock  sync.Mutex
+	count int
+	idle  chan struct{} // closed once the last execution has completed
+}
+
+// marks the start of an execution; the returned func marks its completion
+func (e *executions) begin() func() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.count == 0 {
+		e.idle = make(chan struct{})
+	}
+
+	e.count++
+
+	return func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+
+		e.count--
+		if e.count == 0 {
+			close(e.idle)
+		}
+	}
+}
+
+// waits until no execution is in flight or "ctx" is done
+func (e *executions) wait(ctx context.Context) error {
+	e.lock.Lock()
+	if e.count == 0 {
+		e.lock.Unlock()
+		return nil
+	}
+
+	idle := e.idle
+	e.lock.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
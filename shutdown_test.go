@@ -0,0 +1,120 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+// a checker that implements io.Closer
+type closingChecker struct {
+	fakes.FakeICheckable
+
+	closed   int32
+	closeErr error
+}
+
+func (c *closingChecker) Close() error {
+	atomic.AddInt32(&c.closed, 1)
+	return c.closeErr
+}
+
+func (c *closingChecker) closeCount() int32 {
+	return atomic.LoadInt32(&c.closed)
+}
+
+func TestStopWithContext(t *testing.T) {
+	RegisterTestingT(t)
+
+	// returns a checker that blocks until "release" is closed
+	blocking := func(started chan struct{}, release chan struct{}) *closingChecker {
+		c := &closingChecker{}
+		c.StatusStub = func() (interface{}, error) {
+			close(started)
+			<-release
+			return nil, nil
+		}
+
+		return c
+	}
+
+	t.Run("Should wait for in-flight executions before closing the checkers", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		checker := blocking(started, release)
+
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "db", Checker: checker, Interval: time.Hour, Timeout: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		Eventually(started).Should(BeClosed())
+
+		stopped := make(chan error, 1)
+		go func() {
+			stopped <- h.StopWithContext(context.Background())
+		}()
+
+		Consistently(stopped, 3*testCheckInterval).ShouldNot(Receive())
+		Expect(checker.closeCount()).To(BeZero())
+
+		close(release)
+
+		Eventually(stopped).Should(Receive(BeNil()))
+		Expect(checker.closeCount()).To(Equal(int32(1)))
+	})
+
+	t.Run("Should close the checkers once the context expires", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+
+		checker := blocking(started, release)
+
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "db", Checker: checker, Interval: time.Hour, Timeout: time.Hour})
+
+		Expect(h.Start()).To(Succeed())
+		Eventually(started).Should(BeClosed())
+
+		ctx, cancel := context.WithTimeout(context.Background(), testCheckInterval)
+		defer cancel()
+
+		err := h.StopWithContext(ctx)
+		Expect(err).To(MatchError("Unable to wait for in-flight checks: " + context.DeadlineExceeded.Error()))
+		Expect(checker.closeCount()).To(Equal(int32(1)))
+	})
+
+	t.Run("Should close shared checkers and recovery probes once", func(t *testing.T) {
+		shared := &closingChecker{}
+		probe := &closingChecker{closeErr: errors.New("connection reset")}
+
+		h := setupNewTestHealth()
+		h.WaitOnStart = true
+		h.AddChecks([]*Config{
+			{Name: "a", Checker: shared, Interval: time.Hour},
+			{Name: "b", Checker: shared, Interval: time.Hour, RecoveryProbe: probe},
+			{Name: "c", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+		})
+
+		Expect(h.Start()).To(Succeed())
+
+		err := h.StopWithContext(context.Background())
+		Expect(err).To(MatchError("Unable to close checker of 'b': connection reset"))
+		Expect(shared.closeCount()).To(Equal(int32(1)))
+		Expect(probe.closeCount()).To(Equal(int32(1)))
+	})
+
+	t.Run("Should error if the healthcheck is not running", func(t *testing.T) {
+		checker := &closingChecker{}
+
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "a", Checker: checker, Interval: time.Hour})
+
+		Expect(h.StopWithContext(context.Background())).To(Equal(ErrAlreadyStopped))
+		Expect(checker.closeCount()).To(BeZero())
+	})
+}
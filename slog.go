@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogEvent is an event logged to "Health.Slog".
+type LogEvent string
+
+const (
+	// LogEventCheckStart is logged before every execution of a check
+	LogEventCheckStart LogEvent = "check_start"
+
+	// LogEventCheckSuccess is logged after every successful (or degraded)
+	// execution of a check
+	LogEventCheckSuccess LogEvent = "check_success"
+
+	// LogEventCheckFailure is logged after every execution of a check that
+	// returned an error (even if its failure threshold is not reached yet)
+	LogEventCheckFailure LogEvent = "check_failure"
+
+	// LogEventCheckRecovery is logged instead of "LogEventCheckSuccess" for
+	// the first successful execution of a failed check
+	LogEventCheckRecovery LogEvent = "check_recovery"
+
+	// LogEventLifecycle is logged when the healthcheck or a checker is
+	// started or stopped
+	LogEventLifecycle LogEvent = "lifecycle"
+)
+
+// DefaultSlogLevels are the levels of the events logged to "Health.Slog" that
+// are not set in "Health.SlogLevels".
+var DefaultSlogLevels = map[LogEvent]slog.Level{
+	LogEventCheckStart:    slog.LevelDebug,
+	LogEventCheckSuccess:  slog.LevelDebug,
+	LogEventCheckFailure:  slog.LevelError,
+	LogEventCheckRecovery: slog.LevelInfo,
+	LogEventLifecycle:     slog.LevelInfo,
+}
+
+// returns the level of the event
+func (h *Health) slogLevel(event LogEvent) slog.Level {
+	if level, ok := h.SlogLevels[event]; ok {
+		return level
+	}
+
+	return DefaultSlogLevels[event]
+}
+
+// logs a structured record to "Health.Slog" (if set); the "event" attribute is
+// added to the given ones
+func (h *Health) logEvent(ctx context.Context, event LogEvent, msg string, attrs ...slog.Attr) {
+	if h.Slog == nil {
+		return
+	}
+
+	level := h.slogLevel(event)
+	if !h.Slog.Enabled(ctx, level) {
+		return
+	}
+
+	h.Slog.LogAttrs(ctx, level, msg, append(attrs, slog.String("event", string(event)))...)
+}
+
+// logs the outcome of the execution of a check; "wasFailing" indicates whether
+// the previous execution has failed
+func (h *Health) logResult(ctx context.Context, state *State, err error, wasFailing bool) {
+	if h.Slog == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("check", state.Name),
+		slog.String("status", state.Status),
+		slog.Bool("fatal", state.Fatal),
+		slog.Duration("duration", state.Duration),
+	}
+
+	switch {
+	case err != nil:
+		h.logEvent(ctx, LogEventCheckFailure, "Check failed", append(attrs, slog.String("err", state.Err))...)
+	case wasFailing && !state.isFailure():
+		h.logEvent(ctx, LogEventCheckRecovery, "Check recovered", attrs...)
+	default:
+		h.logEvent(ctx, LogEventCheckSuccess, "Check succeeded", attrs...)
+	}
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type logRecord struct {
+	level slog.Level
+	msg   string
+	attrs map[string]interface{}
+}
+
+// collects the records logged to it
+type recordingHandler struct {
+	records []logRecord
+	lock    sync.Mutex
+}
+
+func (r *recordingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelDebug
+}
+
+func (r *recordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	rec := logRecord{level: record.Level, msg: record.Message, attrs: make(map[string]interface{})}
+	record.Attrs(func(a slog.Attr) bool {
+		rec.attrs[a.Key] = a.Value.Any()
+		return true
+	})
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.records = append(r.records, rec)
+
+	return nil
+}
+
+func (r *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler { return r }
+
+func (r *recordingHandler) WithGroup(name string) slog.Handler { return r }
+
+// returns the records of the event
+func (r *recordingHandler) events(event LogEvent) []logRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	records := make([]logRecord, 0)
+	for _, rec := range r.records {
+		if rec.attrs["event"] == string(event) {
+			records = append(records, rec)
+		}
+	}
+
+	return records
+}
+
+func TestSlog(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should log check executions and lifecycle events", func(t *testing.T) {
+		handler := &recordingHandler{}
+		fail := true
+
+		h := setupNewTestHealth()
+		h.Slog = slog.New(handler)
+		h.SlogLevels = map[LogEvent]slog.Level{LogEventCheckFailure: slog.LevelWarn}
+		h.WaitOnStart = true
+		h.AddCheck(&Config{
+			Name: "db",
+			Checker: CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				if fail {
+					return nil, errors.New("connection refused")
+				}
+
+				return nil, nil
+			}),
+			Interval: time.Hour,
+			Fatal:    true,
+		})
+
+		Expect(h.Start()).To(Succeed())
+
+		Expect(handler.events(LogEventLifecycle)).To(HaveLen(2))
+		Expect(handler.events(LogEventCheckStart)).To(HaveLen(1))
+
+		failures := handler.events(LogEventCheckFailure)
+		Expect(failures).To(HaveLen(1))
+		Expect(failures[0].level).To(Equal(slog.LevelWarn))
+		Expect(failures[0].msg).To(Equal("Check failed"))
+		Expect(failures[0].attrs).To(HaveKeyWithValue("check", "db"))
+		Expect(failures[0].attrs).To(HaveKeyWithValue("status", "failed"))
+		Expect(failures[0].attrs).To(HaveKeyWithValue("err", "connection refused"))
+
+		fail = false
+
+		_, err := h.RunCheck("db")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = h.RunCheck("db")
+		Expect(err).ToNot(HaveOccurred())
+
+		recoveries := handler.events(LogEventCheckRecovery)
+		Expect(recoveries).To(HaveLen(1))
+		Expect(recoveries[0].level).To(Equal(slog.LevelInfo))
+		Expect(handler.events(LogEventCheckSuccess)).To(HaveLen(1))
+		Expect(handler.events(LogEventCheckSuccess)[0].level).To(Equal(slog.LevelDebug))
+
+		Expect(h.Stop()).To(Succeed())
+
+		lifecycle := handler.events(LogEventLifecycle)
+		Expect(lifecycle).To(HaveLen(4))
+		Expect(lifecycle[3].msg).To(Equal("Healthcheck stopped"))
+	})
+
+	t.Run("Should not log without a logger", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.logEvent(context.Background(), LogEventLifecycle, "Healthcheck started")
+	})
+}
@@ -0,0 +1,465 @@
+//go:build soak
+// +build soak
+
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+var (
+	soakDuration = flag.Duration("soak.duration", 30*time.Second, "how long to soak every scheduler")
+	soakChecks   = flag.Int("soak.checks", 300, "number of checks registered at any time")
+	soakSeed     = flag.Int64("soak.seed", 0, "seed of the randomized checks (default: current time)")
+)
+
+const (
+	// a check that has not been executed for this long is considered stuck
+	soakStaleAfter = 5 * time.Second
+
+	// how often the invariants are verified
+	soakVerifyEvery = time.Second
+
+	// the heap may not grow beyond this factor (plus some slack) of the heap
+	// that is in use after the warm-up
+	soakMaxHeapGrowth = 2
+	soakHeapSlack     = 16 << 20
+)
+
+// TestSoak registers hundreds of randomized flaky checks per scheduler and
+// keeps adding, removing, pausing and running them while verifying that no
+// check gets stuck, that the memory is bounded and that no goroutines are
+// leaked once the healthcheck is stopped. Run with:
+//
+//	go test -tags soak -run TestSoak -timeout 0 -soak.duration 2h .
+func TestSoak(t *testing.T) {
+	RegisterTestingT(t)
+
+	seed := *soakSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	t.Logf("soaking with -soak.seed %d", seed)
+
+	schedulers := []struct {
+		name      string
+		scheduler Scheduler
+	}{
+		{"ticker", SchedulerTicker},
+		{"timing wheel", SchedulerTimingWheel},
+		{"worker pool", SchedulerWorkerPool},
+	}
+
+	for _, s := range schedulers {
+		s := s
+
+		t.Run("Should remain stable with the "+s.name+" scheduler", func(t *testing.T) {
+			newSoak(s.scheduler, seed).run(t, *soakDuration)
+		})
+	}
+}
+
+// soakCheck is the bookkeeping of a single randomized check
+type soakCheck struct {
+	cfg *Config
+
+	execs   int64 // number of executions (atomic)
+	lastRun int64 // unix nanos of the last execution (atomic)
+
+	// guarded by the lock of the soak
+	since  time.Time // when the check was added or last resumed
+	paused bool
+}
+
+func (c *soakCheck) lazy() bool {
+	return c.cfg.CacheTTL > 0
+}
+
+type soak struct {
+	h   *Health
+	rng *rand.Rand // only used for generating configs and picking operations
+
+	lock   sync.Mutex
+	checks map[string]*soakCheck
+	names  []string
+	nextID int
+}
+
+func newSoak(scheduler Scheduler, seed int64) *soak {
+	h := New()
+	h.DisableLogging()
+	h.Scheduler = scheduler
+	h.Concurrency = 32
+	h.Workers = map[Priority]int{PriorityHigh: 32, PriorityLow: 32}
+
+	return &soak{
+		h:      h,
+		rng:    rand.New(rand.NewSource(seed)),
+		checks: make(map[string]*soakCheck),
+	}
+}
+
+// returns a randomized flaky check; the caller must hold the lock
+func (s *soak) newCheck() *soakCheck {
+	s.nextID++
+
+	c := &soakCheck{since: time.Now()}
+
+	// the behavior of every execution is randomized, but bounded
+	failureRate := s.rng.Float64() * 0.5
+	degradedRate := s.rng.Float64() * 0.2
+	maxLatency := time.Duration(1+s.rng.Intn(30)) * time.Millisecond
+	hangRate := s.rng.Float64() * 0.05
+	honorContext := s.rng.Intn(2) == 0
+
+	checker := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt64(&c.execs, 1)
+		atomic.StoreInt64(&c.lastRun, time.Now().UnixNano())
+
+		latency := time.Duration(rand.Int63n(int64(maxLatency)))
+
+		// exceed the timeout of the check every now and then
+		if rand.Float64() < hangRate {
+			latency = 300 * time.Millisecond
+		}
+
+		if honorContext {
+			select {
+			case <-time.After(latency):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		} else {
+			time.Sleep(latency)
+		}
+
+		switch p := rand.Float64(); {
+		case p < failureRate:
+			return nil, errors.New("chaos")
+		case p < failureRate+degradedRate:
+			return nil, Degraded("chaos")
+		}
+
+		return "ok", nil
+	})
+
+	cfg := &Config{
+		Name:             fmt.Sprintf("check-%d", s.nextID),
+		Checker:          checker,
+		Interval:         time.Duration(20+s.rng.Intn(180)) * time.Millisecond,
+		Timeout:          time.Duration(50+s.rng.Intn(100)) * time.Millisecond,
+		Fatal:            s.rng.Intn(4) == 0,
+		FailureThreshold: s.rng.Intn(3),
+		SuccessThreshold: s.rng.Intn(3),
+		Priority:         Priority(s.rng.Intn(3)),
+		OverlapPolicy:    OverlapPolicy(s.rng.Intn(2)),
+		Tags:             []string{fmt.Sprintf("tag-%d", s.rng.Intn(5))},
+	}
+
+	switch s.rng.Intn(10) {
+	case 0:
+		cfg.Backoff = true
+		cfg.MaxBackoff = 500 * time.Millisecond
+	case 1:
+		cfg.AdaptiveInterval = true
+		cfg.MaxInterval = 500 * time.Millisecond
+	case 2:
+		cfg.Jitter = 0.2
+	case 3:
+		cfg.Interval = 0
+		cfg.CacheTTL = 100 * time.Millisecond
+	}
+
+	c.cfg = cfg
+
+	return c
+}
+
+// adds a new randomized check
+func (s *soak) add() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := s.newCheck()
+	if err := s.h.AddCheck(c.cfg); err != nil {
+		return err
+	}
+
+	s.checks[c.cfg.Name] = c
+	s.names = append(s.names, c.cfg.Name)
+
+	return nil
+}
+
+// picks a random check; the caller must hold the lock
+func (s *soak) pick() *soakCheck {
+	return s.checks[s.names[s.rng.Intn(len(s.names))]]
+}
+
+// removes a random check and replaces it with a new one
+func (s *soak) replace() error {
+	s.lock.Lock()
+
+	c := s.pick()
+	if err := s.h.RemoveCheck(c.cfg.Name); err != nil {
+		s.lock.Unlock()
+		return fmt.Errorf("Unable to remove check '%v': %v", c.cfg.Name, err)
+	}
+
+	delete(s.checks, c.cfg.Name)
+	for i, name := range s.names {
+		if name == c.cfg.Name {
+			s.names = append(s.names[:i], s.names[i+1:]...)
+			break
+		}
+	}
+
+	s.lock.Unlock()
+
+	return s.add()
+}
+
+// pauses or resumes a random check
+func (s *soak) togglePause() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := s.pick()
+
+	if c.paused {
+		c.paused = false
+		c.since = time.Now()
+
+		return s.h.ResumeCheck(c.cfg.Name)
+	}
+
+	c.paused = true
+
+	return s.h.PauseCheck(c.cfg.Name)
+}
+
+// executes a random operation against the healthcheck; only returns the
+// errors that are not expected to occur
+func (s *soak) chaos() error {
+	s.lock.Lock()
+	op := s.rng.Intn(100)
+	name := s.pick().cfg.Name
+	s.lock.Unlock()
+
+	switch {
+	case op < 40:
+		s.h.State()
+	case op < 50:
+		s.h.State(WithTags("tag-0"))
+	case op < 60:
+		s.h.Failed()
+		s.h.IsHealthy(name)
+	case op < 70:
+		// the check may have been removed concurrently
+		s.h.RunCheck(name)
+	case op < 75:
+		s.h.Groups()
+		s.h.Changes(time.Now().Add(-time.Second))
+	case op < 80:
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		s.h.RunAll(ctx)
+		cancel()
+	case op < 90:
+		return s.replace()
+	case op < 97:
+		return s.togglePause()
+	default:
+		_, on := s.h.Maintenance()
+		s.h.SetMaintenanceMode(!on, "soak")
+	}
+
+	return nil
+}
+
+// verifies that every running check is executed; lazy checks are only
+// executed on demand and paused checks not at all
+func (s *soak) verifyNotStuck() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+
+	for name, c := range s.checks {
+		if c.lazy() || c.paused {
+			continue
+		}
+
+		last := time.Unix(0, atomic.LoadInt64(&c.lastRun))
+		if last.Before(c.since) {
+			last = c.since
+		}
+
+		Expect(now.Sub(last)).To(BeNumerically("<", soakStaleAfter),
+			"check '%v' has not been executed since %v", name, last)
+	}
+}
+
+// verifies that every scheduled check has exactly one entry on the timing
+// wheel, ie. that the entries of removed checks are released
+func (s *soak) verifyWheel() {
+	// checks are neither added nor removed in the meantime
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.h.configsLock.Lock()
+	defer s.h.configsLock.Unlock()
+
+	if s.h.wheel == nil {
+		return
+	}
+
+	s.h.wheel.lock.Lock()
+	entries := 0
+	for _, slot := range s.h.wheel.slots {
+		entries += len(slot)
+	}
+	s.h.wheel.lock.Unlock()
+
+	scheduled := 0
+	s.h.runnersLock.Lock()
+	for _, r := range s.h.runners {
+		if r.refresh == nil {
+			scheduled++
+		}
+	}
+	s.h.runnersLock.Unlock()
+
+	Expect(entries).To(Equal(scheduled), "the timing wheel has %d entries for %d checks", entries, scheduled)
+}
+
+// returns the heap in use after a garbage collection
+func heapInUse() uint64 {
+	runtime.GC()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return stats.HeapInuse
+}
+
+// returns the total number of executions of all checks
+func (s *soak) execs() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var total int64
+	for _, c := range s.checks {
+		total += atomic.LoadInt64(&c.execs)
+	}
+
+	return total
+}
+
+func (s *soak) run(t *testing.T, duration time.Duration) {
+	goroutines := runtime.NumGoroutine()
+
+	for i := 0; i < *soakChecks; i++ {
+		Expect(s.add()).To(Succeed())
+	}
+
+	Expect(s.h.Start()).To(Succeed())
+
+	deadline := time.Now().Add(duration)
+	warmedUp := time.Now().Add(duration / 10)
+
+	// the chaos is driven by a few concurrent clients; the first unexpected
+	// error is reported
+	stop := make(chan struct{})
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ticker := time.NewTicker(5 * time.Millisecond)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					if err := s.chaos(); err != nil {
+						select {
+						case errs <- err:
+						default:
+						}
+					}
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	var baseHeap uint64
+
+	for time.Now().Before(deadline) {
+		time.Sleep(soakVerifyEvery)
+
+		select {
+		case err := <-errs:
+			Expect(err).ToNot(HaveOccurred())
+		default:
+		}
+
+		s.verifyNotStuck()
+		s.verifyWheel()
+
+		if heap := heapInUse(); time.Now().After(warmedUp) {
+			if baseHeap == 0 {
+				baseHeap = heap
+				t.Logf("heap in use after the warm-up: %d bytes", baseHeap)
+			}
+
+			Expect(heap).To(BeNumerically("<", soakMaxHeapGrowth*baseHeap+soakHeapSlack),
+				"the heap has grown from %d to %d bytes", baseHeap, heap)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+
+	t.Logf("%d executions", s.execs())
+
+	Expect(s.h.Stop()).To(Succeed())
+
+	// executions that are in flight may still complete, but no new ones may
+	// be started once the healthcheck has been stopped
+	time.Sleep(time.Second)
+
+	execs := s.execs()
+	Consistently(s.execs, time.Second).Should(Equal(execs), "checks are executed after the healthcheck has been stopped")
+
+	// the goroutines of aborted executions may take a moment to return
+	for wait := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > goroutines; {
+		if time.Now().After(wait) {
+			var dump bytes.Buffer
+			pprof.Lookup("goroutine").WriteTo(&dump, 1)
+
+			t.Fatalf("%d goroutines have leaked:\n%v", runtime.NumGoroutine()-goroutines, dump.String())
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
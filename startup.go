@@ -0,0 +1,37 @@
+package health
+
+import (
+	"fmt"
+)
+
+// validates the startup checks; lazy checks only run on demand, so they may
+// never run until their first success
+func validateStartupChecks(cfgs []*Config) error {
+	for _, c := range cfgs {
+		if c.Startup && c.CacheTTL > 0 {
+			return fmt.Errorf("Check '%v' cannot combine a cache TTL with startup", c.Name)
+		}
+	}
+
+	return nil
+}
+
+// Started indicates whether all startup checks (see "Config.Startup") have
+// passed, ie. for a Kubernetes startup probe; true if there are no startup
+// checks. Returns false if the healthcheck is not running.
+func (h *Health) Started() bool {
+	if !h.active.val() {
+		return false
+	}
+
+	h.runnersLock.Lock()
+	defer h.runnersLock.Unlock()
+
+	for _, r := range h.runners {
+		if r.cfg.Startup && !r.hasPassed() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestStartupChecks(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should stop executing the check after its first success", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("migrations pending"))
+		checker.StatusReturnsOnCall(1, nil, errors.New("migrations pending"))
+		checker.StatusReturns(map[string]int{"version": 42}, nil)
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "migrations", Checker: checker, Interval: testCheckInterval, Fatal: true, Startup: true},
+			{Name: "db", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Expect(h.Started()).To(BeFalse())
+
+		Eventually(h.Started).Should(BeTrue())
+		Expect(checker.StatusCallCount()).To(Equal(3))
+
+		Consistently(checker.StatusCallCount, 25*time.Millisecond).Should(Equal(3))
+
+		// the passed state is kept and returned without executing the check
+		state, err := h.RunCheck("migrations")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.Status).To(Equal("ok"))
+		Expect(state.Details).To(Equal(map[string]int{"version": 42}))
+		Expect(checker.StatusCallCount()).To(Equal(3))
+
+		// pausing a passed check is a noop
+		Expect(h.PauseCheck("migrations")).To(Succeed())
+		states, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states["migrations"].Status).To(Equal("ok"))
+	})
+
+	t.Run("Should not pass before the failure is below the threshold", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("cache cold"))
+		checker.StatusReturns(nil, nil)
+
+		h, _, err := setupRunners([]*Config{
+			{Name: "cache", Checker: checker, Interval: testCheckInterval, FailureThreshold: 3, Startup: true},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		// the first error is tolerated by the threshold, but is not a success
+		Eventually(h.Started).Should(BeTrue())
+		Expect(checker.StatusCallCount()).To(Equal(2))
+	})
+
+	t.Run("Should not expire the state of a passed check", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.StateTTL = 20 * time.Millisecond
+		h.AddCheck(&Config{Name: "migrations", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval, Startup: true})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(h.Started).Should(BeTrue())
+
+		Consistently(func() string { states, _, _ := h.State(); return states["migrations"].Status }, 60*time.Millisecond).Should(Equal("ok"))
+	})
+
+	t.Run("Should be started without startup checks, but only while running", func(t *testing.T) {
+		h := setupNewTestHealth()
+		Expect(h.Started()).To(BeFalse())
+
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: time.Minute})
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Expect(h.Started()).To(BeTrue())
+	})
+
+	t.Run("Should error if a startup check is lazy", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "foo", Checker: &fakes.FakeICheckable{}, CacheTTL: time.Minute, Startup: true})
+
+		err := h.Start()
+		Expect(err).To(MatchError("Unable to start healthcheck: Check 'foo' cannot combine a cache TTL with startup"))
+	})
+}
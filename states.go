@@ -0,0 +1,168 @@
+package health
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// statePool recycles the states of the executions of the checks, so that
+// instances with thousands of checks do not allocate a state on every
+// execution. The state of an execution is only ever handed out as a copy (ie.
+// to the recorded states, the listeners and the result hooks), so it can be
+// reused once the execution has returned.
+var statePool = sync.Pool{New: func() interface{} { return new(State) }}
+
+// returns a (zeroed) state for an execution of a check; must be released via
+// "releaseState()" once the execution has returned
+func acquireState() *State {
+	return statePool.Get().(*State)
+}
+
+func releaseState(state *State) {
+	*state = State{}
+	statePool.Put(state)
+}
+
+// stateSnapshot is an immutable copy of the states.
+//
+// The states of the checks are updated under "statesLock", while readers
+// share a snapshot of all states that is (re)built on the first read after an
+// update (copy-on-write). Frequent reads, ie. handler requests of instances
+// with thousands of checks, thus neither contend on the lock nor copy all
+// states over and over again, unless the states have changed. The number of
+// failed (fatal) and degraded checks is maintained on every update, so that
+// the overall status is known without iterating over the states.
+//
+// The recorded states are never modified in place and their details are
+// copied from the checker (see "State.copy()"), so that a snapshot can be
+// read without holding the lock while checks are executed.
+type stateSnapshot struct {
+	states map[string]State
+}
+
+// returns a snapshot of the states (thread-safe); the returned map is shared
+// and must not be modified
+func (h *Health) snapshotStates() map[string]State {
+	if s, _ := h.snapshot.Load().(*stateSnapshot); s != nil {
+		return s.states
+	}
+
+	h.statesLock.Lock()
+	defer h.statesLock.Unlock()
+
+	// another reader may have built the snapshot in the meantime
+	if s, _ := h.snapshot.Load().(*stateSnapshot); s != nil {
+		return s.states
+	}
+
+	states := make(map[string]State, len(h.states))
+	for k, v := range h.states {
+		states[k] = v
+	}
+
+	h.snapshot.Store(&stateSnapshot{states: states})
+
+	return states
+}
+
+// returns the state of the check (thread-safe); unlike "snapshotStates()",
+// a stale snapshot is not rebuilt for the lookup of a single state
+func (h *Health) lookupState(name string) (State, bool) {
+	if s, _ := h.snapshot.Load().(*stateSnapshot); s != nil {
+		state, ok := s.states[name]
+		return state, ok
+	}
+
+	h.statesLock.Lock()
+	defer h.statesLock.Unlock()
+
+	state, ok := h.states[name]
+
+	return state, ok
+}
+
+// stores the state; the caller must hold "statesLock"
+func (h *Health) putState(state State) {
+	if prev, ok := h.states[state.Name]; ok {
+		h.countState(prev, -1)
+	}
+
+	h.states[state.Name] = state
+	h.countState(state, 1)
+
+	h.invalidateSnapshot()
+}
+
+// deletes the state (and the history) of the check; the caller must hold
+// "statesLock"
+func (h *Health) deleteState(name string) {
+	if prev, ok := h.states[name]; ok {
+		h.countState(prev, -1)
+		delete(h.states, name)
+	}
+
+	delete(h.histories, name)
+
+	h.invalidateSnapshot()
+}
+
+// deletes all states; the caller must hold "statesLock"
+func (h *Health) clearStates() {
+	h.states = make(map[string]State, 0)
+	h.removed = nil
+	h.histories = nil
+
+	atomic.StoreInt64(&h.fatalFailures, 0)
+	atomic.StoreInt64(&h.degradedChecks, 0)
+
+	h.invalidateSnapshot()
+}
+
+func (h *Health) invalidateSnapshot() {
+	h.snapshot.Store((*stateSnapshot)(nil))
+	atomic.AddUint64(&h.stateVersion, 1)
+}
+
+// StateVersion returns a counter that is incremented on every update of the
+// states (thread-safe); ie. to cache responses derived from "h.State()" until
+// the states change.
+func (h *Health) StateVersion() uint64 {
+	return atomic.LoadUint64(&h.stateVersion)
+}
+
+// adds (or removes) the state to the failed and degraded counts
+func (h *Health) countState(state State, delta int64) {
+	if state.isFatalFailure() {
+		atomic.AddInt64(&h.fatalFailures, delta)
+	}
+
+	if state.isDegraded() {
+		atomic.AddInt64(&h.degradedChecks, delta)
+	}
+}
+
+// returns the overall status of all checks ("ok", "degraded" or "failed"),
+// unless it is forced by the maintenance mode
+func (h *Health) overallStatus() string {
+	if m, ok := h.Maintenance(); ok {
+		return m.Status
+	}
+
+	fatalFailures := atomic.LoadInt64(&h.fatalFailures)
+
+	failed := fatalFailures > 0
+	if h.Aggregation != nil {
+		failed = h.Aggregation.Failed(h.states)
+	}
+
+	if failed {
+		return "failed"
+	}
+
+	// critical failures that are tolerated by the policy degrade the status
+	if fatalFailures > 0 || atomic.LoadInt64(&h.degradedChecks) > 0 {
+		return "degraded"
+	}
+
+	return "ok"
+}
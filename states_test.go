@@ -0,0 +1,184 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestStateSnapshots(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should share the snapshot until the states are updated", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.safeUpdateState(&State{Name: "foo", Status: "ok", CheckTime: time.Now()})
+
+		first := h.snapshotStates()
+		Expect(first).To(HaveKey("foo"))
+		Expect(reflect.ValueOf(h.snapshotStates()).Pointer()).To(Equal(reflect.ValueOf(first).Pointer()))
+
+		h.safeUpdateState(&State{Name: "bar", Status: "ok", CheckTime: time.Now()})
+
+		second := h.snapshotStates()
+		Expect(second).To(HaveLen(2))
+		Expect(reflect.ValueOf(second).Pointer()).ToNot(Equal(reflect.ValueOf(first).Pointer()))
+
+		// previous snapshots are immutable
+		Expect(first).To(HaveLen(1))
+	})
+
+	t.Run("State() should return a copy that can be modified", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.safeUpdateState(&State{Name: "foo", Status: "ok", CheckTime: time.Now()})
+
+		states, _, _ := h.State()
+		delete(states, "foo")
+
+		states, _, _ = h.State()
+		Expect(states).To(HaveKey("foo"))
+	})
+
+	t.Run("State() should return deep copies", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.safeUpdateState(&State{
+			Name:      "foo",
+			Status:    "ok",
+			Tags:      []string{"db"},
+			Details:   map[string]interface{}{"replicas": []string{"db-1"}},
+			Latency:   &LatencyStats{Samples: 1},
+			CheckTime: time.Now(),
+		})
+
+		states, _, _ := h.State()
+		states["foo"].Tags[0] = "cache"
+		states["foo"].Details.(map[string]interface{})["replicas"].([]string)[0] = "db-2"
+		states["foo"].Latency.Samples = 2
+
+		states, _, _ = h.State()
+		Expect(states["foo"].Tags).To(Equal([]string{"db"}))
+		Expect(states["foo"].Details).To(Equal(map[string]interface{}{"replicas": []string{"db-1"}}))
+		Expect(states["foo"].Latency.Samples).To(Equal(1))
+
+		for _, state := range h.Changes(time.Time{}) {
+			state.Details.(map[string]interface{})["replicas"] = nil
+		}
+
+		states, _, _ = h.State()
+		Expect(states["foo"].Details).To(Equal(map[string]interface{}{"replicas": []string{"db-1"}}))
+	})
+
+	t.Run("Should record a copy of the details of the checker", func(t *testing.T) {
+		type details struct {
+			Targets []string
+		}
+
+		// the checker keeps updating its details after they have been recorded
+		shared := &details{Targets: []string{"db-1"}}
+		done := make(chan struct{})
+
+		checker := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			return shared, nil
+		})
+
+		h, _, err := setupRunners([]*Config{{Name: "foo", Checker: checker, Interval: time.Hour}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(func() map[string]State { states, _, _ := h.State(); return states }).Should(HaveKey("foo"))
+
+		go func() {
+			defer close(done)
+
+			for i := 0; i < 100; i++ {
+				shared.Targets = append(shared.Targets, fmt.Sprintf("db-%v", i))
+			}
+		}()
+
+		// serializing the states does not race with the checker
+		for i := 0; i < 100; i++ {
+			states, _, _ := h.State()
+			_, err := json.Marshal(states)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		<-done
+
+		states, _, _ := h.State()
+		Expect(states["foo"].Details).To(Equal(&details{Targets: []string{"db-1"}}))
+	})
+
+	t.Run("Should keep track of the overall status", func(t *testing.T) {
+		h := setupNewTestHealth()
+		Expect(h.overallStatus()).To(Equal("ok"))
+
+		h.safeUpdateState(&State{Name: "foo", Status: "degraded", CheckTime: time.Now()})
+		Expect(h.overallStatus()).To(Equal("degraded"))
+		Expect(h.Failed()).To(BeFalse())
+
+		h.safeUpdateState(&State{Name: "bar", Status: "failed", Fatal: true, CheckTime: time.Now()})
+		h.safeUpdateState(&State{Name: "baz", Status: "failed", CheckTime: time.Now()})
+		Expect(h.overallStatus()).To(Equal("failed"))
+		Expect(h.Failed()).To(BeTrue())
+
+		h.safeUpdateState(&State{Name: "bar", Status: "ok", Fatal: true, CheckTime: time.Now()})
+		Expect(h.overallStatus()).To(Equal("degraded"))
+		Expect(h.Failed()).To(BeFalse())
+
+		h.statesLock.Lock()
+		h.deleteState("foo")
+		h.statesLock.Unlock()
+		Expect(h.overallStatus()).To(Equal("ok"))
+
+		h.safeUpdateState(&State{Name: "bar", Status: "failed", Fatal: true, CheckTime: time.Now()})
+		h.safeResetStates()
+		Expect(h.overallStatus()).To(Equal("ok"))
+		Expect(h.snapshotStates()).To(BeEmpty())
+	})
+}
+
+func TestCopyDetails(t *testing.T) {
+	RegisterTestingT(t)
+
+	type node struct {
+		Name   string
+		Next   *node
+		Values [2][]int
+		hidden *int
+	}
+
+	t.Run("Should copy nested values", func(t *testing.T) {
+		hidden := 1
+		orig := &node{Name: "foo", Values: [2][]int{{1}, {2}}, hidden: &hidden}
+
+		c := copyDetails(orig).(*node)
+		Expect(c).To(Equal(orig))
+		Expect(c).ToNot(BeIdenticalTo(orig))
+
+		c.Values[0][0] = 3
+		Expect(orig.Values[0][0]).To(Equal(1))
+
+		// unexported fields are shared
+		Expect(c.hidden).To(BeIdenticalTo(orig.hidden))
+	})
+
+	t.Run("Should preserve cycles", func(t *testing.T) {
+		orig := &node{Name: "foo"}
+		orig.Next = orig
+
+		c := copyDetails(orig).(*node)
+		Expect(c.Next).To(BeIdenticalTo(c))
+		Expect(c).ToNot(BeIdenticalTo(orig))
+	})
+
+	t.Run("Should copy plain values", func(t *testing.T) {
+		Expect(copyDetails(nil)).To(BeNil())
+		Expect(copyDetails("foo")).To(Equal("foo"))
+		Expect(copyDetails(json.RawMessage(`{}`))).To(Equal(json.RawMessage(`{}`)))
+		Expect(copyDetails([]interface{}{nil, 1})).To(Equal([]interface{}{nil, 1}))
+	})
+}
@@ -0,0 +1,120 @@
+package health
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// the number of state changes that are buffered per subscription; once
+	// full, the oldest change is dropped in favor of the newest
+	subscriptionBufferSize = 100
+)
+
+// StateChange is a change of the outcome (status, error or warning) of a
+// single check, delivered via "h.Subscribe()".
+type StateChange struct {
+	// Name of the check
+	Name string `json:"name"`
+
+	// OldState is the previous state of the check; nil for the first state
+	OldState *State `json:"old_state,omitempty"`
+
+	// NewState is the recorded state of the check
+	NewState State `json:"new_state"`
+
+	// Err is the error of the new state, if any
+	Err string `json:"error,omitempty"`
+
+	// Timestamp is the time of the check that caused the change
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// subscription delivers the state changes to a single subscriber
+type subscription struct {
+	lock   sync.Mutex
+	events chan StateChange
+	closed bool
+}
+
+// Subscribe returns a channel that receives every change of the outcome of a
+// check (see "State.ChangedAt"), so that applications can react to dependency
+// failures in their own goroutines; and a function that cancels the
+// subscription and closes the channel.
+//
+// The channel is buffered; if the subscriber falls behind, the oldest change
+// is dropped in favor of the newest, so that a slow subscriber never blocks
+// the execution of checks and always sees the latest changes. Subscriptions
+// outlive "h.Stop()" and can be created before "h.Start()".
+func (h *Health) Subscribe() (<-chan StateChange, func()) {
+	s := &subscription{events: make(chan StateChange, subscriptionBufferSize)}
+
+	h.subscribersLock.Lock()
+	if h.subscribers == nil {
+		h.subscribers = make(map[*subscription]struct{})
+	}
+	h.subscribers[s] = struct{}{}
+	h.subscribersLock.Unlock()
+
+	cancel := func() {
+		h.subscribersLock.Lock()
+		delete(h.subscribers, s)
+		h.subscribersLock.Unlock()
+
+		s.close()
+	}
+
+	return s.events, cancel
+}
+
+// publishes the change to all subscribers; the caller must hold the states
+// lock, so that the changes are published in order
+func (h *Health) publishStateChange(change StateChange) {
+	h.subscribersLock.Lock()
+	defer h.subscribersLock.Unlock()
+
+	for s := range h.subscribers {
+		if s.publish(change) {
+			atomic.AddInt64(&h.droppedEvents, 1)
+		}
+	}
+}
+
+// queues the change, dropping the oldest queued change if the buffer is full;
+// returns whether a change has been dropped
+func (s *subscription) publish(change StateChange) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	dropped := false
+
+	for {
+		select {
+		case s.events <- change:
+			return dropped
+		default:
+		}
+
+		// the subscriber may have caught up in the meantime
+		select {
+		case <-s.events:
+			dropped = true
+		default:
+		}
+	}
+}
+
+func (s *subscription) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestSubscribe(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should deliver the changes of the checks", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(1, nil, errors.New("things broke"))
+
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "foo", Checker: checker, Interval: testCheckInterval})
+
+		changes, cancel := h.Subscribe()
+		defer cancel()
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		var change StateChange
+
+		Eventually(changes).Should(Receive(&change))
+		Expect(change.Name).To(Equal("foo"))
+		Expect(change.OldState).To(BeNil())
+		Expect(change.NewState.Status).To(Equal("ok"))
+		Expect(change.Timestamp).To(Equal(change.NewState.CheckTime))
+
+		Eventually(changes).Should(Receive(&change))
+		Expect(change.OldState.Status).To(Equal("ok"))
+		Expect(change.NewState.Status).To(Equal("failed"))
+		Expect(change.Err).To(Equal("things broke"))
+
+		Eventually(changes).Should(Receive(&change))
+		Expect(change.OldState.Status).To(Equal("failed"))
+		Expect(change.NewState.Status).To(Equal("ok"))
+
+		// unchanged outcomes are not delivered
+		Consistently(changes, 5*testCheckInterval).ShouldNot(Receive())
+	})
+
+	t.Run("Should drop the oldest changes if the subscriber falls behind", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		changes, cancel := h.Subscribe()
+		defer cancel()
+
+		for i := 0; i < subscriptionBufferSize+10; i++ {
+			h.safeUpdateState(&State{Name: "foo", Status: "failed", Err: fmt.Sprint(i), CheckTime: time.Now()})
+		}
+
+		Expect(changes).To(HaveLen(subscriptionBufferSize))
+
+		var change StateChange
+		Expect(changes).To(Receive(&change))
+		Expect(change.Err).To(Equal("10"))
+	})
+
+	t.Run("Should close the channel once canceled", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		changes, cancel := h.Subscribe()
+		cancel()
+		cancel()
+
+		h.safeUpdateState(&State{Name: "foo", Status: "ok", CheckTime: time.Now()})
+
+		Eventually(changes).Should(BeClosed())
+		Expect(h.subscribers).To(BeEmpty())
+	})
+}
@@ -0,0 +1,136 @@
+package health
+
+import (
+	"fmt"
+)
+
+// ChecksDiff lists the names of the checks that have been added, updated and
+// removed by "h.SyncChecks()".
+type ChecksDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Empty indicates that no check has changed.
+func (d ChecksDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Updated) == 0 && len(d.Removed) == 0
+}
+
+// SyncOption alters which checks are replaced by "h.SyncChecks()".
+type SyncOption func(*syncOptions)
+
+type syncOptions struct {
+	scoped bool
+	names  map[string]bool
+}
+
+// SyncOnly limits "h.SyncChecks()" to the given checks, ie. the ones that
+// have been added by a previous sync: only these are removed if they are not
+// part of the synced configs anymore, while other checks (ie. added via
+// "h.AddCheck()") are kept.
+func SyncOnly(names ...string) SyncOption {
+	return func(o *syncOptions) {
+		o.scoped = true
+
+		if o.names == nil {
+			o.names = make(map[string]bool, len(names))
+		}
+
+		for _, name := range names {
+			o.names[name] = true
+		}
+	}
+}
+
+// SyncChecks replaces the checks of the health instance with the given ones,
+// ie. on a reload of the configuration (see the "config" package): checks
+// that are not part of "cfgs" anymore are removed (like "RemoveCheck()";
+// see "SyncOnly()" to limit the removed checks), new checks are added and
+// checks whose config has changed (ie. a different "*Config" with the same
+// name) are restarted with their new config. Checks whose "*Config" is
+// unchanged keep running undisturbed.
+//
+// If the healthcheck is running, the configs are validated as a whole before
+// anything changes, so that an invalid set of checks (ie. a dependency on a
+// removed check) leaves the running checks untouched. Updated checks keep
+// their state (and incidents) until their next execution.
+//
+// The checkers of removed and updated checks are not closed; it is up to the
+// caller to close the ones that are not used anymore.
+func (h *Health) SyncChecks(cfgs []*Config, opts ...SyncOption) (ChecksDiff, error) {
+	o := &syncOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	h.configsLock.Lock()
+	defer h.configsLock.Unlock()
+
+	current := make(map[string]*Config, len(h.configs))
+	for _, c := range h.configs {
+		current[c.Name] = c
+	}
+
+	diff := ChecksDiff{}
+	desired := make(map[string]*Config, len(cfgs))
+
+	for _, c := range cfgs {
+		desired[c.Name] = c
+
+		if prev, ok := current[c.Name]; !ok {
+			diff.Added = append(diff.Added, c.Name)
+		} else if prev != c {
+			diff.Updated = append(diff.Updated, c.Name)
+		}
+	}
+
+	// the running checks keep their order; added checks are appended
+	configs := make([]*Config, 0, len(h.configs)+len(diff.Added))
+
+	for _, c := range h.configs {
+		switch {
+		case desired[c.Name] != nil:
+			configs = append(configs, desired[c.Name])
+		case o.scoped && !o.names[c.Name]:
+			configs = append(configs, c)
+		default:
+			diff.Removed = append(diff.Removed, c.Name)
+		}
+	}
+
+	for _, c := range cfgs {
+		if _, ok := current[c.Name]; !ok {
+			configs = append(configs, c)
+		}
+	}
+
+	if !h.active.val() {
+		h.configs = configs
+		return diff, nil
+	}
+
+	if err := validateConfigs(configs, h.Workers); err != nil {
+		return ChecksDiff{}, fmt.Errorf("Unable to sync checks: %v", err)
+	}
+
+	h.configs = configs
+
+	for _, name := range diff.Removed {
+		if h.stopCheck(name) {
+			h.discardState(name)
+		}
+	}
+
+	for _, name := range diff.Updated {
+		h.stopCheck(name)
+	}
+
+	for _, c := range configs {
+		if prev, ok := current[c.Name]; !ok || prev != c {
+			h.startCheck(c)
+		}
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestSyncChecks(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should replace the configs of a non-running healthcheck", func(t *testing.T) {
+		h := setupNewTestHealth()
+		foo := &Config{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval}
+		h.AddCheck(foo)
+
+		bar := &Config{Name: "bar", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval}
+
+		diff, err := h.SyncChecks([]*Config{bar})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(ChecksDiff{Added: []string{"bar"}, Removed: []string{"foo"}}))
+		Expect(h.configs).To(Equal([]*Config{bar}))
+	})
+
+	t.Run("Should add, update and remove running checks", func(t *testing.T) {
+		failing := &fakes.FakeICheckable{}
+		failing.StatusReturns(nil, errors.New("things broke"))
+
+		keep := &fakes.FakeICheckable{}
+		old := &fakes.FakeICheckable{}
+
+		keepCfg := &Config{Name: "keep", Checker: keep, Interval: testCheckInterval}
+
+		h, _, err := setupRunners([]*Config{
+			keepCfg,
+			{Name: "update", Checker: old, Interval: testCheckInterval},
+			{Name: "remove", Checker: failing, Interval: testCheckInterval, Fatal: true},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		Eventually(h.Failed).Should(BeTrue())
+
+		keepRunner := h.runners["keep"]
+		updated := &fakes.FakeICheckable{}
+		added := &fakes.FakeICheckable{}
+
+		diff, err := h.SyncChecks([]*Config{
+			keepCfg,
+			{Name: "update", Checker: updated, Interval: testCheckInterval},
+			{Name: "add", Checker: added, Interval: testCheckInterval},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(ChecksDiff{Added: []string{"add"}, Updated: []string{"update"}, Removed: []string{"remove"}}))
+		Expect(diff.Empty()).To(BeFalse())
+
+		// the unchanged check keeps running undisturbed
+		Expect(h.runners["keep"]).To(BeIdenticalTo(keepRunner))
+
+		// let the executions that were in flight complete
+		time.Sleep(5 * time.Millisecond)
+
+		oldCalls, failingCalls := old.StatusCallCount(), failing.StatusCallCount()
+		Consistently(func() []int {
+			return []int{old.StatusCallCount(), failing.StatusCallCount()}
+		}, 25*time.Millisecond).Should(Equal([]int{oldCalls, failingCalls}))
+
+		Eventually(updated.StatusCallCount).Should(BeNumerically(">", 0))
+		Eventually(added.StatusCallCount).Should(BeNumerically(">", 0))
+
+		Eventually(func() map[string]State { states, _, _ := h.State(); return states }).Should(HaveKey("add"))
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states).ToNot(HaveKey("remove"))
+		Expect(states).To(HaveKey("update"))
+
+		// the incident of the removed check is resolved
+		Expect(h.Incidents()).To(HaveLen(1))
+		Expect(h.Incidents()[0].Open()).To(BeFalse())
+	})
+
+	t.Run("Should not change anything if the checks are unchanged", func(t *testing.T) {
+		cfgs := []*Config{{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval}}
+
+		h, _, err := setupRunners(cfgs, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		diff, err := h.SyncChecks(cfgs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.Empty()).To(BeTrue())
+	})
+
+	t.Run("Should only remove the given checks", func(t *testing.T) {
+		own := &Config{Name: "own", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval}
+		synced := &Config{Name: "synced", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval}
+
+		h, _, err := setupRunners([]*Config{own, synced}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		added := &Config{Name: "added", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval}
+
+		diff, err := h.SyncChecks([]*Config{added}, SyncOnly("synced"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(Equal(ChecksDiff{Added: []string{"added"}, Removed: []string{"synced"}}))
+		Expect(h.configs).To(Equal([]*Config{own, added}))
+	})
+
+	t.Run("Should leave the running checks untouched if the configs are invalid", func(t *testing.T) {
+		cfgs := []*Config{
+			{Name: "foo", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval},
+			{Name: "bar", Checker: &fakes.FakeICheckable{}, Interval: testCheckInterval, DependsOn: []string{"foo"}},
+		}
+
+		h, _, err := setupRunners(cfgs, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		_, err = h.SyncChecks(cfgs[1:])
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unable to sync checks"))
+
+		Expect(h.configs).To(Equal(cfgs))
+		Expect(h.runners).To(HaveKey("foo"))
+	})
+}
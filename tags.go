@@ -0,0 +1,145 @@
+package health
+
+import (
+	"sort"
+)
+
+// StateOption alters which states are returned by "h.State()".
+type StateOption func(*stateOptions)
+
+type stateOptions struct {
+	tags []string
+}
+
+// WithTags only includes the checks that have at least one of the given tags
+// ("Config.Tags"); ie. a readiness endpoint can be served off
+// `h.State(health.WithTags("readiness"))`.
+func WithTags(tags ...string) StateOption {
+	return func(o *stateOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// GroupState contains the aggregated state of all checks with a given tag.
+type GroupState struct {
+	// Name of the group (the tag)
+	Name string `json:"name"`
+
+	// Status of the group ("ok", "degraded" or "failed"); a group has failed
+	// if any of its fatal checks has failed and is degraded if any of its
+	// checks is degraded
+	Status string `json:"status"`
+
+	// Checks contains the names of all checks in the group
+	Checks []string `json:"checks"`
+
+	// FailedChecks contains the names of the (fatal or non-fatal) failed
+	// checks in the group
+	FailedChecks []string `json:"failed_checks,omitempty"`
+}
+
+// Groups will return the aggregated state of every group of checks, ie. of
+// all checks sharing a tag (thread-safe). Checks without tags are not part of
+// any group.
+//
+// The map key is the name of the group (the tag).
+func (h *Health) Groups() map[string]GroupState {
+	h.refreshLazyChecks(nil)
+
+	groups := make(map[string]GroupState, 0)
+
+	states := h.snapshotStates()
+
+	// iterate in a stable order so that the check names are sorted
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		state := states[name]
+
+		for _, tag := range state.Tags {
+			group, ok := groups[tag]
+			if !ok {
+				group = GroupState{Name: tag, Status: "ok", Checks: make([]string, 0)}
+			}
+
+			group.Checks = append(group.Checks, name)
+
+			if state.isFailure() {
+				group.FailedChecks = append(group.FailedChecks, name)
+
+				if state.isFatalFailure() {
+					group.Status = "failed"
+				}
+			}
+
+			if state.isDegraded() && group.Status == "ok" {
+				group.Status = "degraded"
+			}
+
+			groups[tag] = group
+		}
+	}
+
+	return groups
+}
+
+// applies the given options
+func newStateOptions(opts []StateOption) *stateOptions {
+	o := &stateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// returns a copy of the states, filtered according to the given options
+func filterStates(states map[string]State, opts []StateOption) map[string]State {
+	o := newStateOptions(opts)
+
+	if len(o.tags) == 0 {
+		filtered := make(map[string]State, len(states))
+		for name, state := range states {
+			filtered[name] = state
+		}
+
+		return filtered
+	}
+
+	filtered := make(map[string]State, 0)
+
+	for name, state := range states {
+		if hasAnyTag(state.Tags, o.tags) {
+			filtered[name] = state
+		}
+	}
+
+	return filtered
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// indicates whether any of the fatal checks has failed
+func anyFatalFailure(states map[string]State) bool {
+	for _, val := range states {
+		if val.isFatalFailure() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestWithTags(t *testing.T) {
+	RegisterTestingT(t)
+
+	failing := &fakes.FakeICheckable{}
+	failing.StatusReturns(nil, errors.New("things broke"))
+
+	h, _, err := setupRunners([]*Config{
+		{Name: "db", Checker: failing, Interval: time.Hour, Fatal: true, Tags: []string{"readiness", "db"}},
+		{Name: "cache", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Fatal: true, Tags: []string{"readiness"}},
+		{Name: "self", Checker: &fakes.FakeICheckable{}, Interval: time.Hour, Fatal: true, Tags: []string{"liveness"}},
+		{Name: "untagged", Checker: &fakes.FakeICheckable{}, Interval: time.Hour},
+	}, nil)
+	Expect(err).ToNot(HaveOccurred())
+	defer h.Stop()
+
+	time.Sleep(5 * time.Millisecond)
+
+	t.Run("Should only include the checks with any of the tags", func(t *testing.T) {
+		states, failed, err := h.State(WithTags("readiness"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed).To(BeTrue())
+		Expect(states).To(HaveLen(2))
+		Expect(states).To(HaveKey("db"))
+		Expect(states).To(HaveKey("cache"))
+		Expect(states["db"].Tags).To(Equal([]string{"readiness", "db"}))
+
+		states, failed, _ = h.State(WithTags("liveness", "foo"))
+		Expect(failed).To(BeFalse())
+		Expect(states).To(HaveLen(1))
+		Expect(states).To(HaveKey("self"))
+	})
+
+	t.Run("Should include all checks without tags", func(t *testing.T) {
+		states, failed, _ := h.State()
+		Expect(failed).To(BeTrue())
+		Expect(states).To(HaveLen(4))
+
+		states, _, _ = h.State(WithTags())
+		Expect(states).To(HaveLen(4))
+	})
+
+	t.Run("Should aggregate the status of every group", func(t *testing.T) {
+		groups := h.Groups()
+		Expect(groups).To(HaveLen(3))
+
+		Expect(groups["readiness"]).To(Equal(GroupState{
+			Name:         "readiness",
+			Status:       "failed",
+			Checks:       []string{"cache", "db"},
+			FailedChecks: []string{"db"},
+		}))
+
+		Expect(groups["db"].Status).To(Equal("failed"))
+
+		Expect(groups["liveness"].Status).To(Equal("ok"))
+		Expect(groups["liveness"].Checks).To(Equal([]string{"self"}))
+		Expect(groups["liveness"].FailedChecks).To(BeEmpty())
+	})
+}
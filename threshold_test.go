@@ -0,0 +1,146 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestFailureThreshold(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should only mark the check as failed after N consecutive errors", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns(nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:             "foo",
+				Checker:          checker,
+				Interval:         testCheckInterval,
+				Fatal:            true,
+				FailureThreshold: 3,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		// two executions
+		time.Sleep(15 * time.Millisecond)
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states["foo"].Status).To(Equal("ok"))
+		Expect(states["foo"].Err).To(Equal("things broke"))
+		Expect(states["foo"].ConsecutiveErrors).To(Equal(int64(2)))
+
+		// third execution
+		time.Sleep(10 * time.Millisecond)
+
+		states, failed, _ = h.State()
+		Expect(failed).To(BeTrue())
+		Expect(states["foo"].Status).To(Equal("failed"))
+		Expect(states["foo"].ConsecutiveErrors).To(BeNumerically(">=", 3))
+	})
+
+	t.Run("A success should reset the error streak", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+		checker.StatusReturnsOnCall(1, nil, nil)
+		checker.StatusReturnsOnCall(2, nil, errors.New("things broke"))
+		checker.StatusReturnsOnCall(3, nil, nil)
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:             "foo",
+				Checker:          checker,
+				Interval:         testCheckInterval,
+				Fatal:            true,
+				FailureThreshold: 2,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		for i := 0; i < 4; i++ {
+			Expect(h.Failed()).To(BeFalse())
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		Expect(h.Incidents()).To(BeEmpty())
+	})
+}
+
+func TestSuccessThreshold(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should only report a failed check healthy after M consecutive successes", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:             "foo",
+				Checker:          checker,
+				Interval:         testCheckInterval,
+				Fatal:            true,
+				SuccessThreshold: 3,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		// failure + first success
+		time.Sleep(15 * time.Millisecond)
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeTrue())
+		Expect(states["foo"].Status).To(Equal("failed"))
+		Expect(states["foo"].Err).To(ContainSubstring("awaiting 3 consecutive successes"))
+		Expect(states["foo"].ConsecutiveSuccesses).To(Equal(int64(1)))
+		Expect(states["foo"].ConsecutiveErrors).To(BeZero())
+
+		// second and third success
+		time.Sleep(20 * time.Millisecond)
+
+		states, failed, _ = h.State()
+		Expect(failed).To(BeFalse())
+		Expect(states["foo"].Status).To(Equal("ok"))
+		Expect(states["foo"].Err).To(BeEmpty())
+		Expect(states["foo"].ConsecutiveSuccesses).To(BeNumerically(">=", 3))
+
+		incidents := h.Incidents()
+		Expect(incidents).To(HaveLen(1))
+		Expect(incidents[0].Open()).To(BeFalse())
+	})
+
+	t.Run("An error should reset the success streak", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturnsOnCall(0, nil, errors.New("things broke"))
+		checker.StatusReturnsOnCall(2, nil, errors.New("things broke"))
+
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:             "foo",
+				Checker:          checker,
+				Interval:         testCheckInterval,
+				Fatal:            true,
+				SuccessThreshold: 2,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		// failure, success, failure
+		time.Sleep(25 * time.Millisecond)
+
+		states, _, _ := h.State()
+		Expect(states["foo"].Status).To(Equal("failed"))
+		Expect(states["foo"].Err).To(Equal("things broke"))
+		Expect(states["foo"].ConsecutiveSuccesses).To(BeZero())
+	})
+}
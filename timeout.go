@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCheckTimeout is recorded (as "State.Err") when a check exceeds its "Config.Timeout"
+var ErrCheckTimeout = errors.New("Check has timed out")
+
+// ICheckableContext can optionally be implemented by checkers that support
+// cancellation; "StatusContext()" is called (instead of "Status()") with the
+// context of the execution (see "Publish()"), which is canceled once the
+// timeout is exceeded if the check has a "Config.Timeout".
+type ICheckableContext interface {
+	ICheckable
+
+	StatusContext(ctx context.Context) (interface{}, error)
+}
+
+// closed channel returned for executions that do not need to be waited on
+var completed = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+type checkResult struct {
+	data interface{}
+	err  error
+}
+
+// calls the checker, aborting the call if it exceeds the timeout (if any); a
+// panic of the checker is returned as a "*PanicError".
+// The returned channel is closed once the checker has actually returned; an
+// aborted call keeps running in the background until then. The context passed
+// to "StatusContext()" carries the values (ie. the span, see "Health.Tracer")
+// of "parent", but is only canceled by the timeout.
+func callChecker(parent context.Context, checker ICheckable, timeout time.Duration) (interface{}, error, <-chan struct{}) {
+	if timeout <= 0 {
+		status := checker.Status
+		if c, ok := checker.(ICheckableContext); ok {
+			status = func() (interface{}, error) { return c.StatusContext(valuesContext{parent}) }
+		}
+
+		data, err := recoverStatus(status)
+		return data, err, completed
+	}
+
+	ctx, cancel := context.WithTimeout(valuesContext{parent}, timeout)
+
+	results := make(chan checkResult, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer cancel()
+
+		var r checkResult
+		if c, ok := checker.(ICheckableContext); ok {
+			r.data, r.err = recoverStatus(func() (interface{}, error) { return c.StatusContext(ctx) })
+		} else {
+			r.data, r.err = recoverStatus(checker.Status)
+		}
+
+		results <- r
+	}()
+
+	select {
+	case r := <-results:
+		return r.data, r.err, done
+	case <-ctx.Done():
+		return nil, ErrCheckTimeout, done
+	}
+}
+
+// valuesContext carries the values of its parent, but neither its deadline
+// nor its cancellation
+type valuesContext struct {
+	context.Context
+}
+
+func (valuesContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (valuesContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (valuesContext) Err() error {
+	return nil
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+type contextChecker struct {
+	canceled int64
+}
+
+func (c *contextChecker) Status() (interface{}, error) {
+	return nil, nil
+}
+
+func (c *contextChecker) StatusContext(ctx context.Context) (interface{}, error) {
+	<-ctx.Done()
+	atomic.AddInt64(&c.canceled, 1)
+	return nil, ctx.Err()
+}
+
+func TestCallChecker(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should return the result if the check completes in time", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusReturns("data", nil)
+
+		data, err, done := callChecker(context.Background(), checker, time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal("data"))
+		Eventually(done).Should(BeClosed())
+	})
+
+	t.Run("Should not use a timeout if none is configured", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+
+		_, err, done := callChecker(context.Background(), checker, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(done).To(BeClosed())
+	})
+
+	t.Run("Should abort a check that exceeds the timeout", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		checker.StatusStub = func() (interface{}, error) {
+			time.Sleep(30 * time.Millisecond)
+			return nil, nil
+		}
+
+		start := time.Now()
+		_, err, done := callChecker(context.Background(), checker, 5*time.Millisecond)
+
+		Expect(err).To(Equal(ErrCheckTimeout))
+		Expect(time.Since(start)).To(BeNumerically("<", 25*time.Millisecond))
+
+		// the aborted check keeps running until it returns
+		Expect(done).ToNot(BeClosed())
+		Eventually(done).Should(BeClosed())
+	})
+
+	t.Run("Should cancel the context of context aware checkers", func(t *testing.T) {
+		checker := &contextChecker{}
+
+		_, err, done := callChecker(context.Background(), checker, 5*time.Millisecond)
+		Expect(err).To(Equal(ErrCheckTimeout))
+
+		Eventually(done).Should(BeClosed())
+		Expect(atomic.LoadInt64(&checker.canceled)).To(Equal(int64(1)))
+	})
+}
+
+func TestCheckTimeout(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should record a timeout failure", func(t *testing.T) {
+		h, _, err := setupRunners([]*Config{
+			{
+				Name:     "foo",
+				Checker:  &contextChecker{},
+				Interval: testCheckInterval,
+				Timeout:  5 * time.Millisecond,
+				Fatal:    true,
+			},
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer h.Stop()
+
+		time.Sleep(15 * time.Millisecond)
+
+		states, failed, _ := h.State()
+		Expect(failed).To(BeTrue())
+		Expect(states["foo"].Status).To(Equal("failed"))
+		Expect(states["foo"].Err).To(Equal(ErrCheckTimeout.Error()))
+		Expect(states["foo"].TimedOut).To(BeTrue())
+	})
+}
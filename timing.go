@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	// DefaultInterval is the interval of checks that set neither
+	// "Config.Interval" nor "Health.DefaultInterval"
+	DefaultInterval = time.Duration(10) * time.Second
+
+	// DefaultTimeout is the timeout of checks that set neither
+	// "Config.Timeout" nor "Health.DefaultTimeout"; no timeout by default
+	DefaultTimeout time.Duration
+)
+
+// TimingSource indicates where an effective interval or timeout has been
+// resolved from.
+type TimingSource string
+
+const (
+	// TimingSourceGlobal is the package-level "DefaultInterval" or
+	// "DefaultTimeout"
+	TimingSourceGlobal TimingSource = "global"
+
+	// TimingSourceHealth is "Health.DefaultInterval" or "Health.DefaultTimeout"
+	TimingSourceHealth TimingSource = "health"
+
+	// TimingSourceCheck is "Config.Interval" or "Config.Timeout"
+	TimingSourceCheck TimingSource = "check"
+)
+
+// Timing contains the effective interval and timeout of a check, along with
+// where they have been resolved from. The precedence is (from lowest to
+// highest): the package-level defaults ("DefaultInterval", "DefaultTimeout"),
+// the defaults of the health instance ("Health.DefaultInterval",
+// "Health.DefaultTimeout") and the config of the check ("Config.Interval",
+// "Config.Timeout"). Additionally, the deadline of the context passed to
+// "RunCheckContext()" or "RunAll()" overrides the timeout of a single call if
+// it expires first.
+type Timing struct {
+	Interval       time.Duration
+	IntervalSource TimingSource
+
+	// Timeout is zero if the check has no timeout
+	Timeout       time.Duration
+	TimeoutSource TimingSource
+}
+
+// Timing returns the effective interval and timeout of the check with the
+// given name; the timing of a running check is resolved when the check is
+// started.
+func (h *Health) Timing(name string) (Timing, error) {
+	h.runnersLock.Lock()
+	r, ok := h.runners[name]
+	h.runnersLock.Unlock()
+
+	if ok {
+		return r.timing, nil
+	}
+
+	h.configsLock.Lock()
+	defer h.configsLock.Unlock()
+
+	for _, c := range h.configs {
+		if c.Name == name {
+			return h.resolveTiming(c), nil
+		}
+	}
+
+	return Timing{}, ErrCheckNotFound
+}
+
+// resolves the effective interval and timeout of the check
+func (h *Health) resolveTiming(cfg *Config) Timing {
+	t := Timing{
+		Interval:       DefaultInterval,
+		IntervalSource: TimingSourceGlobal,
+		Timeout:        DefaultTimeout,
+		TimeoutSource:  TimingSourceGlobal,
+	}
+
+	if cfg.Interval > 0 {
+		t.Interval, t.IntervalSource = cfg.Interval, TimingSourceCheck
+	} else if h.DefaultInterval > 0 {
+		t.Interval, t.IntervalSource = h.DefaultInterval, TimingSourceHealth
+	}
+
+	if cfg.Timeout > 0 {
+		t.Timeout, t.TimeoutSource = cfg.Timeout, TimingSourceCheck
+	} else if h.DefaultTimeout > 0 {
+		t.Timeout, t.TimeoutSource = h.DefaultTimeout, TimingSourceHealth
+	}
+
+	return t
+}
+
+// returns the timeout of a single call; the deadline of the context takes
+// precedence if it expires before the timeout (if any)
+func callTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+
+	remaining := time.Until(deadline)
+	if timeout > 0 && timeout <= remaining {
+		return timeout
+	}
+
+	// an expired deadline still aborts the call
+	if remaining <= 0 {
+		remaining = time.Nanosecond
+	}
+
+	return remaining
+}
@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/InVisionApp/go-health/fakes"
+)
+
+func TestTiming(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should resolve the interval and timeout by precedence", func(t *testing.T) {
+		h := setupNewTestHealth()
+		h.AddChecks([]*Config{
+			{Name: "global", Checker: &fakes.FakeICheckable{}},
+			{Name: "check", Checker: &fakes.FakeICheckable{}, Interval: time.Minute, Timeout: time.Second},
+		})
+
+		timing, err := h.Timing("global")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timing).To(Equal(Timing{
+			Interval:       DefaultInterval,
+			IntervalSource: TimingSourceGlobal,
+			Timeout:        0,
+			TimeoutSource:  TimingSourceGlobal,
+		}))
+
+		h.DefaultInterval = time.Hour
+		h.DefaultTimeout = 5 * time.Second
+
+		timing, err = h.Timing("global")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timing).To(Equal(Timing{
+			Interval:       time.Hour,
+			IntervalSource: TimingSourceHealth,
+			Timeout:        5 * time.Second,
+			TimeoutSource:  TimingSourceHealth,
+		}))
+
+		timing, err = h.Timing("check")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timing).To(Equal(Timing{
+			Interval:       time.Minute,
+			IntervalSource: TimingSourceCheck,
+			Timeout:        time.Second,
+			TimeoutSource:  TimingSourceCheck,
+		}))
+	})
+
+	t.Run("Should error for unknown checks", func(t *testing.T) {
+		h := setupNewTestHealth()
+
+		_, err := h.Timing("foo")
+		Expect(err).To(Equal(ErrCheckNotFound))
+	})
+
+	t.Run("Should run checks with the defaults of the health instance", func(t *testing.T) {
+		checker := &fakes.FakeICheckable{}
+		slow := &slowChecker{delay: 50 * time.Millisecond, running: new(int64), maxRunning: new(int64)}
+
+		h := setupNewTestHealth()
+		h.DefaultInterval = testCheckInterval
+		h.DefaultTimeout = 10 * time.Millisecond
+		h.AddChecks([]*Config{
+			{Name: "foo", Checker: checker},
+			{Name: "slow", Checker: slow, Interval: time.Minute},
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		Eventually(checker.StatusCallCount).Should(BeNumerically(">=", 3))
+
+		Eventually(func() bool {
+			states, _, _ := h.State()
+			return states["slow"].TimedOut
+		}).Should(BeTrue())
+
+		// the timing of a running check is resolved on start
+		h.DefaultInterval = time.Hour
+
+		timing, err := h.Timing("foo")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timing.Interval).To(Equal(testCheckInterval))
+	})
+
+	t.Run("Should abort a call once the deadline of the context is exceeded", func(t *testing.T) {
+		slow := &slowChecker{delay: 100 * time.Millisecond, running: new(int64), maxRunning: new(int64)}
+
+		h := setupNewTestHealth()
+		h.AddCheck(&Config{Name: "slow", Checker: slow, Interval: time.Minute, Timeout: time.Second, InitialDelay: time.Minute})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		state, err := h.RunCheckContext(ctx, "slow")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.TimedOut).To(BeTrue())
+		Expect(state.Err).To(Equal(ErrCheckTimeout.Error()))
+
+		// the aborted call is waited for before the next one starts
+		Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+	})
+}
+
+func TestCallTimeout(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(callTimeout(context.Background(), time.Second)).To(Equal(time.Second))
+	Expect(callTimeout(context.Background(), 0)).To(BeZero())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	Expect(callTimeout(ctx, time.Second)).To(Equal(time.Second))
+	Expect(callTimeout(ctx, 0)).To(BeNumerically("~", time.Minute, time.Second))
+	Expect(callTimeout(ctx, time.Hour)).To(BeNumerically("~", time.Minute, time.Second))
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	Expect(callTimeout(expired, time.Second)).To(Equal(time.Nanosecond))
+}
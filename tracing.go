@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"errors"
+)
+
+// spanName is the name of the span of every check execution; the name of the
+// check is set as the "health.check.name" attribute
+const spanName = "health.check"
+
+// ITracer creates a span per check execution (see "Health.Tracer"), so that
+// slow health checks appear in distributed traces. It is shaped after the
+// OpenTelemetry tracing API; refer to the README for an adapter of an
+// OpenTelemetry tracer provider.
+type ITracer interface {
+	// StartSpan starts a span; the returned context (which carries the span)
+	// is passed to checkers that implement "ICheckableContext"
+	StartSpan(ctx context.Context, name string) (context.Context, ISpan)
+}
+
+// ISpan is a span started by an "ITracer".
+type ISpan interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// starts the span of a check execution; a no-op span if no tracer is set
+func (h *Health) startSpan(ctx context.Context, cfg *Config) (context.Context, ISpan) {
+	if h.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx, span := h.Tracer.StartSpan(ctx, spanName)
+	span.SetAttribute("health.check.name", cfg.Name)
+
+	return ctx, span
+}
+
+// records the outcome of the check execution and ends its span
+func endSpan(span ISpan, state *State) {
+	span.SetAttribute("health.check.status", state.Status)
+	span.SetAttribute("health.check.fatal", state.Fatal)
+
+	if state.TimedOut {
+		span.SetAttribute("health.check.timed_out", true)
+	}
+
+	if state.Err != "" {
+		span.SetAttribute("health.check.error", state.Err)
+
+		if state.isFailure() {
+			span.RecordError(errors.New(state.Err))
+		}
+	}
+
+	span.End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+
+func (noopSpan) RecordError(err error) {}
+
+func (noopSpan) End() {}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type spanKey struct{}
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]interface{}
+	errors     []error
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *fakeSpan) RecordError(err error) {
+	s.errors = append(s.errors, err)
+}
+
+func (s *fakeSpan) End() {
+	s.ended = true
+}
+
+type fakeTracer struct {
+	spans []*fakeSpan
+	lock  sync.Mutex
+}
+
+func (t *fakeTracer) StartSpan(ctx context.Context, name string) (context.Context, ISpan) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	span := &fakeSpan{name: name, attributes: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *fakeTracer) lastSpan() *fakeSpan {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.spans[len(t.spans)-1]
+}
+
+func TestTracer(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Should create a span per check execution", func(t *testing.T) {
+		tracer := &fakeTracer{}
+		spans := make(chan interface{}, 10)
+		fail := false
+
+		h := setupNewTestHealth()
+		h.Tracer = tracer
+		h.WaitOnStart = true
+		h.AddCheck(&Config{
+			Name: "db",
+			Checker: CheckerFunc(func(ctx context.Context) (interface{}, error) {
+				spans <- ctx.Value(spanKey{})
+
+				if fail {
+					return nil, errors.New("connection refused")
+				}
+
+				return nil, nil
+			}),
+			Interval: time.Hour,
+			Timeout:  time.Second,
+			Fatal:    true,
+		})
+
+		Expect(h.Start()).To(Succeed())
+		defer h.Stop()
+
+		span := tracer.lastSpan()
+		Expect(span.name).To(Equal("health.check"))
+		Expect(span.ended).To(BeTrue())
+		Expect(span.errors).To(BeEmpty())
+		Expect(span.attributes).To(Equal(map[string]interface{}{
+			"health.check.name":   "db",
+			"health.check.status": "ok",
+			"health.check.fatal":  true,
+		}))
+
+		// the span is passed to the checker
+		Expect(spans).To(Receive(BeIdenticalTo(span)))
+
+		fail = true
+
+		_, err := h.RunCheck("db")
+		Expect(err).ToNot(HaveOccurred())
+
+		span = tracer.lastSpan()
+		Expect(span.ended).To(BeTrue())
+		Expect(span.attributes["health.check.status"]).To(Equal("failed"))
+		Expect(span.attributes["health.check.error"]).To(Equal("connection refused"))
+		Expect(span.errors).To(Equal([]error{errors.New("connection refused")}))
+	})
+
+	t.Run("Should not cancel the checker with the context of the span", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		checked := make(chan error, 1)
+		checker := CheckerFunc(func(ctx context.Context) (interface{}, error) {
+			checked <- ctx.Err()
+			return nil, nil
+		})
+
+		_, err, done := callChecker(ctx, checker, time.Second)
+		<-done
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(checked).To(Receive(BeNil()))
+	})
+}